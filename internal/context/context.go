@@ -17,7 +17,11 @@
 
 package context
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"sync"
+)
 
 // ContextKey type
 type Key int
@@ -29,16 +33,86 @@ const (
 	KeyEnd
 )
 
+var (
+	keyLock sync.RWMutex
+	// keyNames are the names of the keys indexed by the keys
+	keyNames = []string{"BufferPoolCtx", "Variables"}
+	keyIndex = map[string]Key{"BufferPoolCtx": KeyBufferPoolCtx, "Variables": KeyVariables}
+)
+
+// RegisterKey allocates a new key after the built-in keys, so that the value of the key is accessed
+// as fast as the built-in ones. It should be called at init time, e.g. assigned to a package level var,
+// and panics if the name is registered already.
+func RegisterKey(name string) Key {
+	keyLock.Lock()
+	defer keyLock.Unlock()
+	if key, ok := keyIndex[name]; ok {
+		panic(fmt.Sprintf("context key %s is already registered as %d", name, key))
+	}
+	key := Key(len(keyNames))
+	keyNames = append(keyNames, name)
+	keyIndex[name] = key
+	return key
+}
+
+// keyCount returns the count of the built-in and registered keys
+func keyCount() int {
+	keyLock.RLock()
+	defer keyLock.RUnlock()
+	return len(keyNames)
+}
+
+// String returns the name of the key
+func (k Key) String() string {
+	keyLock.RLock()
+	defer keyLock.RUnlock()
+	if k >= 0 && int(k) < len(keyNames) {
+		return keyNames[k]
+	}
+	return fmt.Sprintf("Key(%d)", int(k))
+}
+
 type valueCtx struct {
 	context.Context
 
 	builtin [KeyEnd]interface{}
+	// registered are the values of the keys allocated by RegisterKey, indexed by key - KeyEnd
+	registered []interface{}
 }
 
 func (c *valueCtx) Value(key interface{}) interface{} {
 	if contextKey, ok := key.(Key); ok {
-		return c.builtin[contextKey]
+		return c.get(contextKey)
 	}
 
 	return c.Context.Value(key)
 }
+
+func (c *valueCtx) get(key Key) interface{} {
+	if key < KeyEnd {
+		return c.builtin[key]
+	}
+	if i := int(key - KeyEnd); i < len(c.registered) {
+		return c.registered[i]
+	}
+	return nil
+}
+
+func (c *valueCtx) set(key Key, value interface{}) {
+	if key < KeyEnd {
+		c.builtin[key] = value
+		return
+	}
+	i := int(key - KeyEnd)
+	if i >= len(c.registered) {
+		// grow to hold all the registered keys at once
+		count := keyCount()
+		if int(key) >= count {
+			panic(fmt.Sprintf("context key %d is not registered", int(key)))
+		}
+		registered := make([]interface{}, count-int(KeyEnd))
+		copy(registered, c.registered)
+		c.registered = registered
+	}
+	c.registered[i] = value
+}
@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -163,3 +164,72 @@ func BenchmarkRawSet(b *testing.B) {
 
 	}
 }
+
+var (
+	testKeyA = RegisterKey("TestKeyA")
+	testKeyB = RegisterKey("TestKeyB")
+)
+
+func TestRegisterKey(t *testing.T) {
+	assert.True(t, testKeyA >= KeyEnd)
+	assert.Equal(t, testKeyA+1, testKeyB)
+	assert.Equal(t, "TestKeyA", testKeyA.String())
+	assert.Equal(t, "Variables", KeyVariables.String())
+	assert.Equal(t, "Key(-1)", Key(-1).String())
+
+	// the names of both the built-in and registered keys can't be registered again
+	assert.Panics(t, func() { RegisterKey("TestKeyA") })
+	assert.Panics(t, func() { RegisterKey("Variables") })
+
+	ctx := WithValue(context.Background(), testKeyB, "b")
+	assert.Equal(t, "b", Get(ctx, testKeyB))
+	assert.Equal(t, "b", ctx.Value(testKeyB))
+	assert.Nil(t, Get(ctx, testKeyA))
+	// the keys not allocated by RegisterKey can't be set
+	assert.Panics(t, func() { WithValue(ctx, Key(keyCount()), "x") })
+	assert.Nil(t, Get(ctx, Key(keyCount())))
+
+	// the registered values of the clone are independent of the origin
+	clone := Clone(ctx)
+	WithValue(clone, testKeyB, "clone")
+	WithValue(ctx, testKeyA, "a")
+	assert.Equal(t, "b", Get(ctx, testKeyB))
+	assert.Equal(t, "clone", Get(clone, testKeyB))
+	assert.Nil(t, Get(clone, testKeyA))
+}
+
+type stdKey struct{}
+
+func TestCloneCancellation(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	parent, cancel := context.WithDeadline(context.WithValue(context.Background(), stdKey{}, "std"), deadline)
+	ctx := WithValue(parent, KeyVariables, "v")
+	clone := Clone(Clone(ctx))
+	detached := CloneDetached(ctx)
+
+	d, ok := clone.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, d)
+	_, ok = detached.Deadline()
+	assert.False(t, ok)
+	for _, c := range []context.Context{clone, detached} {
+		assert.Equal(t, "v", Get(c, KeyVariables))
+		assert.Equal(t, "std", c.Value(stdKey{}))
+		assert.NoError(t, c.Err())
+	}
+	assert.Equal(t, ctx.Done(), clone.Done())
+	// the clones are not nested
+	assert.Equal(t, parent, clone.(*valueCtx).Context)
+
+	cancel()
+	<-clone.Done()
+	assert.Equal(t, context.Canceled, clone.Err())
+	assert.Nil(t, detached.Done())
+	assert.NoError(t, detached.Err())
+
+	// the std context is detached as well
+	std := CloneDetached(parent)
+	assert.NoError(t, std.Err())
+	assert.Equal(t, "std", std.Value(stdKey{}))
+	assert.Nil(t, Get(std, KeyVariables))
+}
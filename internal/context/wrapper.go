@@ -18,12 +18,15 @@
 //nolint
 package context
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Get is a wrapper for context.Value
 func Get(ctx context.Context, key Key) interface{} {
 	if mosnCtx, ok := ctx.(*valueCtx); ok {
-		return mosnCtx.builtin[key]
+		return mosnCtx.get(key)
 	}
 
 	return ctx.Value(key)
@@ -38,23 +41,61 @@ func Get(ctx context.Context, key Key) interface{} {
 // or create a new value context which contains the pair.
 func WithValue(parent context.Context, key Key, value interface{}) context.Context {
 	if mosnCtx, ok := parent.(*valueCtx); ok {
-		mosnCtx.builtin[key] = value
+		mosnCtx.set(key, value)
 		return mosnCtx
 	}
 
 	// create new valueCtx
 	mosnCtx := &valueCtx{Context: parent}
-	mosnCtx.builtin[key] = value
+	mosnCtx.set(key, value)
 	return mosnCtx
 }
 
-// Clone copy the origin mosn value context(if it is), and return new one
+// Clone copy the origin mosn value context(if it is), and return new one.
+// The Deadline, Done and Err of the new one always proxy the parent of the origin, so they are the
+// same as the origin, and so are the values of the keys other than the Key.
 func Clone(parent context.Context) context.Context {
 	if mosnCtx, ok := parent.(*valueCtx); ok {
-		clone := &valueCtx{Context: mosnCtx}
+		// the parent of the origin instead of the origin, so the clones of the clones are not nested
+		clone := &valueCtx{Context: mosnCtx.Context}
 		// array copy assign
 		clone.builtin = mosnCtx.builtin
+		if len(mosnCtx.registered) > 0 {
+			clone.registered = append([]interface{}(nil), mosnCtx.registered...)
+		}
 		return clone
 	}
 	return parent
 }
+
+// CloneDetached is Clone without the cancellation, the new one is never canceled and has no deadline,
+// which is used by the background work outliving the request. The values are still kept.
+func CloneDetached(parent context.Context) context.Context {
+	if mosnCtx, ok := parent.(*valueCtx); ok {
+		clone := Clone(mosnCtx).(*valueCtx)
+		clone.Context = detachedCtx{parent: mosnCtx.Context}
+		return clone
+	}
+	return detachedCtx{parent: parent}
+}
+
+// detachedCtx keeps the values of the parent but not its cancellation
+type detachedCtx struct {
+	parent context.Context
+}
+
+func (detachedCtx) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detachedCtx) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedCtx) Err() error {
+	return nil
+}
+
+func (c detachedCtx) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
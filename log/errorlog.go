@@ -46,7 +46,7 @@ func DefaultFormatter(lv string, alert string, format string) string {
 }
 
 func (l *SimpleErrorLog) Alertf(alert string, format string, args ...interface{}) {
-	if l.disable {
+	if l.disable.Load() {
 		return
 	}
 	if l.Level >= ERROR {
@@ -60,7 +60,7 @@ func (l *SimpleErrorLog) Alertf(alert string, format string, args ...interface{}
 	}
 }
 func (l *SimpleErrorLog) levelf(lv string, format string, args ...interface{}) {
-	if l.disable {
+	if l.disable.Load() {
 		return
 	}
 	fs := ""
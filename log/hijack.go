@@ -0,0 +1,103 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"os"
+	"time"
+
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+	"mosn.io/pkg/utils"
+)
+
+const megabyte = 1024 * 1024
+
+// hijackSizeCheckInterval is the interval to check the hijack file size, if the roller rotates by size
+var hijackSizeCheckInterval = 10 * time.Second
+
+// HijackStdPipeline hijacks stdout and stderr outputs into the file path, such as the crash and panic outputs.
+// The file is rotated by the roller like other logs: by the handler of the roller if it rotates by time,
+// otherwise by the lumberjack of the roller, which compresses the rotated files if the roller enables compress,
+// and removes them by the max backups and the max age of the roller.
+// If the roller is nil, the default roller is used.
+func HijackStdPipeline(path string, stdout, stderr bool, roller *Roller) {
+	utils.SetHijackStdPipelineWithRotator(path, stdout, stderr, newHijackRotator(path, roller))
+}
+
+// hijackRotator implements utils.HijackRotator by a Roller
+type hijackRotator struct {
+	roller Roller
+	create time.Time
+}
+
+func newHijackRotator(path string, roller *Roller) *hijackRotator {
+	if roller == nil {
+		roller = &defaultRoller
+	}
+	r := &hijackRotator{
+		roller: *roller,
+		create: time.Now(),
+	}
+	if r.roller.Handler == nil {
+		r.roller.Handler = rollerHandler
+	}
+	if r.roller.MaxTime == 0 && r.roller.MaxSize == 0 {
+		r.roller.MaxSize = defaultRotateSize
+	}
+	if stat, err := os.Stat(path); err == nil {
+		r.create = stat.ModTime()
+	}
+	return r
+}
+
+func (r *hijackRotator) Wait(now time.Time) time.Duration {
+	if r.roller.MaxTime == 0 {
+		return hijackSizeCheckInterval
+	}
+	// rotate right now if the file is created before the last rotate time
+	if now.Sub(r.create) > time.Duration(r.roller.MaxTime)*time.Second {
+		return 0
+	}
+	_, localOffset := now.Zone()
+	return time.Duration(r.roller.MaxTime-(now.Unix()+int64(localOffset))%r.roller.MaxTime) * time.Second
+}
+
+func (r *hijackRotator) Rotate(path string, now time.Time) bool {
+	if r.roller.MaxTime == 0 {
+		stat, err := os.Stat(path)
+		if err != nil || stat.Size() < int64(r.roller.MaxSize)*megabyte {
+			return false
+		}
+		roller := r.roller
+		roller.Filename = path
+		lj, ok := roller.GetLogWriter().(*lumberjack.Logger)
+		if !ok || lj.Rotate() != nil {
+			return false
+		}
+		r.create = now
+		return true
+	}
+	info := LoggerInfo{FileName: path, CreateTime: r.create}
+	info.LogRoller = r.roller
+	r.roller.Handler(&info)
+	r.create = now
+	return true
+}
+
+// Rotated does nothing, the rotated files are compressed and removed by the lumberjack
+func (r *hijackRotator) Rotated(path string) {}
@@ -0,0 +1,91 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHijackRotatorBySize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hijack")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stderr.log")
+
+	r := newHijackRotator(path, &Roller{MaxSize: 1, MaxBackups: 2, Compress: true})
+	assert.Equal(t, hijackSizeCheckInterval, r.Wait(time.Now()))
+	// file not exists or too small, no rotate
+	assert.False(t, r.Rotate(path, time.Now()))
+	require.Nil(t, ioutil.WriteFile(path, []byte("small"), 0644))
+	assert.False(t, r.Rotate(path, time.Now()))
+
+	data := make([]byte, megabyte)
+	for i := 0; i < 3; i++ {
+		require.Nil(t, ioutil.WriteFile(path, data, 0644))
+		require.True(t, r.Rotate(path, time.Now()))
+		r.Rotated(path)
+		time.Sleep(10 * time.Millisecond) // make sure the backups modify time is different
+	}
+	// the backups are compressed and removed by the lumberjack in the background
+	var backups []string
+	assert.Eventually(t, func() bool {
+		backups, err = filepath.Glob(filepath.Join(dir, "stderr-*.log*"))
+		return err == nil && len(backups) == 2 &&
+			filepath.Ext(backups[0]) == ".gz" && filepath.Ext(backups[1]) == ".gz"
+	}, 5*time.Second, 10*time.Millisecond)
+	for _, backup := range backups {
+		f, err := os.Open(backup)
+		require.Nil(t, err)
+		gz, err := gzip.NewReader(f)
+		require.Nil(t, err)
+		b, err := ioutil.ReadAll(gz)
+		require.Nil(t, err)
+		assert.Len(t, b, megabyte)
+		f.Close()
+	}
+}
+
+func TestHijackRotatorByTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hijack")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stderr.log")
+	require.Nil(t, ioutil.WriteFile(path, []byte("crash"), 0644))
+
+	r := newHijackRotator(path, &Roller{MaxTime: 3600})
+	now := time.Now()
+	wait := r.Wait(now)
+	assert.True(t, wait > 0 && wait <= time.Hour)
+	// created before the last rotate time, rotate right now
+	r.create = now.Add(-2 * time.Hour)
+	assert.Equal(t, time.Duration(0), r.Wait(now))
+
+	require.True(t, r.Rotate(path, now))
+	assert.Equal(t, now, r.create)
+	b, err := ioutil.ReadFile(path + "." + now.Add(-2*time.Hour).Format("2006-01-02_15"))
+	require.Nil(t, err)
+	assert.Equal(t, "crash", string(b))
+}
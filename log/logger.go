@@ -29,6 +29,7 @@ import (
 	"time"
 
 	gsyslog "github.com/hashicorp/go-syslog"
+	"mosn.io/pkg/metrics"
 	"mosn.io/pkg/utils"
 )
 
@@ -64,7 +65,7 @@ type Logger struct {
 	roller *Roller
 	// disable presents the logger state. if disable is true, the logger will write nothing
 	// the default value is false
-	disable bool
+	disable utils.AtomicBool
 	// implementation elements
 	create          time.Time
 	once            sync.Once
@@ -283,13 +284,16 @@ func (l *Logger) reopen() error {
 
 var ErrChanFull = errors.New("channel is full")
 
+// discardedCounter counts the buffers discarded by Print as the channel is full
+var discardedCounter = metrics.NewCounter("log.discarded")
+
 // Print writes the final buffere to the buffer chan
 // if discard is true and the buffer is full, returns an error
 // If a LogBuffer needs to call Print N(N>1) times, the LogBuffer.Count(N-1) should be called
 // or call LogBuffer.Count(1) N-1 times.
 // If the N is 1, LogBuffer.Count should not be called.
 func (l *Logger) Print(buf LogBuffer, discard bool) error {
-	if l.disable {
+	if l.disable.Load() {
 		// free the buf
 		PutLogBuffer(buf)
 		return nil
@@ -299,6 +303,7 @@ func (l *Logger) Print(buf LogBuffer, discard bool) error {
 	default:
 		// todo: configurable
 		if discard {
+			discardedCounter.Inc()
 			return ErrChanFull
 		} else {
 			l.writeBufferChan <- buf
@@ -308,7 +313,7 @@ func (l *Logger) Print(buf LogBuffer, discard bool) error {
 }
 
 func (l *Logger) Println(args ...interface{}) {
-	if l.disable {
+	if l.disable.Load() {
 		return
 	}
 	s := fmt.Sprintln(args...)
@@ -321,7 +326,7 @@ func (l *Logger) Println(args ...interface{}) {
 }
 
 func (l *Logger) Printf(format string, args ...interface{}) {
-	if l.disable {
+	if l.disable.Load() {
 		return
 	}
 	s := fmt.Sprintf(format, args...)
@@ -453,11 +458,11 @@ func (l *Logger) Reopen() error {
 }
 
 func (l *Logger) Toggle(disable bool) {
-	l.disable = disable
+	l.disable.Store(disable)
 }
 
 func (l *Logger) Disable() bool {
-	return l.disable
+	return l.disable.Load()
 }
 
 // syslogAddress
@@ -27,9 +27,13 @@ import (
 	"time"
 
 	"mosn.io/pkg/buffer"
+	"mosn.io/pkg/metrics"
 )
 
 func TestLogPrintDiscard(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
 	l, err := GetOrCreateLogger("/tmp/mosn_bench/benchmark.log", nil)
 	if err != nil {
 		t.Fatal(err)
@@ -43,6 +47,9 @@ func TestLogPrintDiscard(t *testing.T) {
 	for i := 0; i < 1001; i++ {
 		l.Printf("BenchmarkLog BenchmarkLog BenchmarkLog BenchmarkLog BenchmarkLog %v", l)
 	}
+	if sink.Snapshot().Counters["log.discarded"] == 0 {
+		t.Errorf("test Print discard failed, the discarded buffers are not counted")
+	}
 	lchan := make(chan struct{})
 	go func() {
 		// block
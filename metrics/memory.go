@@ -0,0 +1,199 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuckets are the upper bounds of the histogram buckets of MemorySink, which fit the latencies in milliseconds.
+var DefaultBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// MemorySink keeps the metrics in memory, the snapshot of them is returned by Snapshot.
+type MemorySink struct {
+	buckets []float64
+
+	lock       sync.RWMutex
+	counters   map[string]*int64
+	gauges     map[string]*uint64 // the bits of the float64 values
+	histograms map[string]*histogram
+}
+
+// NewMemorySink returns a MemorySink whose histograms have the bucket upper bounds, DefaultBuckets if none.
+func NewMemorySink(buckets ...float64) *MemorySink {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	s := &MemorySink{buckets: buckets}
+	s.Reset()
+	return s
+}
+
+// load returns the metric of the name in m, which is created by newMetric if it is absent.
+func load[T any](s *MemorySink, m map[string]*T, name string, newMetric func() *T) *T {
+	s.lock.RLock()
+	v, ok := m[name]
+	s.lock.RUnlock()
+	if ok {
+		return v
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if v, ok = m[name]; !ok {
+		v = newMetric()
+		m[name] = v
+	}
+	return v
+}
+
+// IncrCounter adds delta to the counter.
+func (s *MemorySink) IncrCounter(name string, delta int64) {
+	s.lock.RLock()
+	counters := s.counters
+	s.lock.RUnlock()
+	atomic.AddInt64(load(s, counters, name, func() *int64 { return new(int64) }), delta)
+}
+
+// SetGauge sets the value of the gauge.
+func (s *MemorySink) SetGauge(name string, value float64) {
+	s.lock.RLock()
+	gauges := s.gauges
+	s.lock.RUnlock()
+	atomic.StoreUint64(load(s, gauges, name, func() *uint64 { return new(uint64) }), math.Float64bits(value))
+}
+
+// AddSample adds a sample to the histogram.
+func (s *MemorySink) AddSample(name string, value float64) {
+	s.lock.RLock()
+	histograms := s.histograms
+	s.lock.RUnlock()
+	load(s, histograms, name, func() *histogram {
+		return &histogram{bounds: s.buckets, counts: make([]uint64, len(s.buckets))}
+	}).add(value)
+}
+
+// Reset removes all the metrics.
+func (s *MemorySink) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.counters = make(map[string]*int64)
+	s.gauges = make(map[string]*uint64)
+	s.histograms = make(map[string]*histogram)
+}
+
+// Snapshot is the values of the metrics of MemorySink.
+type Snapshot struct {
+	Counters   map[string]int64             `json:"counters"`
+	Gauges     map[string]float64           `json:"gauges"`
+	Histograms map[string]HistogramSnapshot `json:"histograms"`
+}
+
+// Bucket is a histogram bucket, Count is the number of the samples not greater than UpperBound.
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// HistogramSnapshot is the statistics of the samples of a histogram, the buckets are cumulative.
+type HistogramSnapshot struct {
+	Count   uint64   `json:"count"`
+	Sum     float64  `json:"sum"`
+	Min     float64  `json:"min"`
+	Max     float64  `json:"max"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Mean returns the mean of the samples, 0 if there is none.
+func (h HistogramSnapshot) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// Snapshot returns the current values of the metrics.
+func (s *MemorySink) Snapshot() Snapshot {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	snapshot := Snapshot{
+		Counters:   make(map[string]int64, len(s.counters)),
+		Gauges:     make(map[string]float64, len(s.gauges)),
+		Histograms: make(map[string]HistogramSnapshot, len(s.histograms)),
+	}
+	for name, v := range s.counters {
+		snapshot.Counters[name] = atomic.LoadInt64(v)
+	}
+	for name, v := range s.gauges {
+		snapshot.Gauges[name] = math.Float64frombits(atomic.LoadUint64(v))
+	}
+	for name, h := range s.histograms {
+		snapshot.Histograms[name] = h.snapshot()
+	}
+	return snapshot
+}
+
+type histogram struct {
+	bounds []float64
+
+	lock   sync.Mutex
+	count  uint64
+	sum    float64
+	min    float64
+	max    float64
+	counts []uint64 // the number of the samples in each bucket, not cumulative
+}
+
+func (h *histogram) add(value float64) {
+	i := sort.SearchFloat64s(h.bounds, value)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.count == 0 || value < h.min {
+		h.min = value
+	}
+	if h.count == 0 || value > h.max {
+		h.max = value
+	}
+	h.count++
+	h.sum += value
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	snapshot := HistogramSnapshot{
+		Count:   h.count,
+		Sum:     h.sum,
+		Min:     h.min,
+		Max:     h.max,
+		Buckets: make([]Bucket, len(h.bounds)),
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		snapshot.Buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	return snapshot
+}
@@ -0,0 +1,174 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package metrics is a lightweight facade of the counters, gauges and histograms shared by the subsystems.
+// The metrics are reported to a pluggable Sink, which is a MemorySink by default, so the subsystems don't
+// need their own stats structs and the users can forward the metrics to their monitoring systems.
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Sink receives the updates of the metrics, the methods must be safe for concurrent use and must not block.
+type Sink interface {
+	// IncrCounter adds delta to the counter.
+	IncrCounter(name string, delta int64)
+	// SetGauge sets the value of the gauge.
+	SetGauge(name string, value float64)
+	// AddSample adds a sample to the histogram.
+	AddSample(name string, value float64)
+}
+
+// FanoutSink reports the updates to all the sinks in order.
+type FanoutSink []Sink
+
+// IncrCounter adds delta to the counter of all the sinks.
+func (f FanoutSink) IncrCounter(name string, delta int64) {
+	for _, s := range f {
+		s.IncrCounter(name, delta)
+	}
+}
+
+// SetGauge sets the value of the gauge of all the sinks.
+func (f FanoutSink) SetGauge(name string, value float64) {
+	for _, s := range f {
+		s.SetGauge(name, value)
+	}
+}
+
+// AddSample adds a sample to the histogram of all the sinks.
+func (f FanoutSink) AddSample(name string, value float64) {
+	for _, s := range f {
+		s.AddSample(name, value)
+	}
+}
+
+// discardSink drops all the updates.
+type discardSink struct{}
+
+func (discardSink) IncrCounter(string, int64) {}
+func (discardSink) SetGauge(string, float64)  {}
+func (discardSink) AddSample(string, float64) {}
+
+// sinkBox boxes the sink, so atomic.Value can store the sinks of different dynamic types.
+type sinkBox struct {
+	sink Sink
+}
+
+var (
+	defaultSink = NewMemorySink()
+	globalSink  atomic.Value
+)
+
+func init() {
+	globalSink.Store(sinkBox{defaultSink})
+}
+
+// Default returns the default MemorySink, which is the global sink unless it is replaced by SetSink.
+func Default() *MemorySink {
+	return defaultSink
+}
+
+// SetSink replaces the global sink, nil discards all the updates.
+func SetSink(s Sink) {
+	if s == nil {
+		s = discardSink{}
+	}
+	globalSink.Store(sinkBox{s})
+}
+
+// GetSink returns the global sink.
+func GetSink() Sink {
+	return globalSink.Load().(sinkBox).sink
+}
+
+// IncrCounter adds delta to the counter of the global sink.
+func IncrCounter(name string, delta int64) {
+	GetSink().IncrCounter(name, delta)
+}
+
+// SetGauge sets the value of the gauge of the global sink.
+func SetGauge(name string, value float64) {
+	GetSink().SetGauge(name, value)
+}
+
+// AddSample adds a sample to the histogram of the global sink.
+func AddSample(name string, value float64) {
+	GetSink().AddSample(name, value)
+}
+
+// MeasureSince adds the milliseconds elapsed since start to the histogram of the global sink.
+func MeasureSince(name string, start time.Time) {
+	GetSink().AddSample(name, float64(time.Since(start))/float64(time.Millisecond))
+}
+
+// Counter is a named counter reported to the global sink.
+type Counter struct {
+	name string
+}
+
+// NewCounter returns the counter of the name.
+func NewCounter(name string) Counter {
+	return Counter{name: name}
+}
+
+// Inc adds 1 to the counter.
+func (c Counter) Inc() {
+	IncrCounter(c.name, 1)
+}
+
+// Add adds delta to the counter.
+func (c Counter) Add(delta int64) {
+	IncrCounter(c.name, delta)
+}
+
+// Gauge is a named gauge reported to the global sink.
+type Gauge struct {
+	name string
+}
+
+// NewGauge returns the gauge of the name.
+func NewGauge(name string) Gauge {
+	return Gauge{name: name}
+}
+
+// Set sets the value of the gauge.
+func (g Gauge) Set(value float64) {
+	SetGauge(g.name, value)
+}
+
+// Histogram is a named histogram reported to the global sink.
+type Histogram struct {
+	name string
+}
+
+// NewHistogram returns the histogram of the name.
+func NewHistogram(name string) Histogram {
+	return Histogram{name: name}
+}
+
+// Observe adds a sample to the histogram.
+func (h Histogram) Observe(value float64) {
+	AddSample(h.name, value)
+}
+
+// ObserveSince adds the milliseconds elapsed since start to the histogram.
+func (h Histogram) ObserveSince(start time.Time) {
+	MeasureSince(h.name, start)
+}
@@ -0,0 +1,98 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySink(t *testing.T) {
+	s := NewMemorySink(10, 1, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.IncrCounter("requests", 2)
+		}()
+	}
+	wg.Wait()
+	s.SetGauge("connections", 3)
+	s.SetGauge("connections", 1.5)
+	for _, v := range []float64{0.5, 1, 3, 7, 20} {
+		s.AddSample("latency", v)
+	}
+
+	snapshot := s.Snapshot()
+	assert.Equal(t, map[string]int64{"requests": 20}, snapshot.Counters)
+	assert.Equal(t, map[string]float64{"connections": 1.5}, snapshot.Gauges)
+	h := snapshot.Histograms["latency"]
+	assert.Equal(t, uint64(5), h.Count)
+	assert.Equal(t, 31.5, h.Sum)
+	assert.Equal(t, 0.5, h.Min)
+	assert.Equal(t, 20.0, h.Max)
+	assert.Equal(t, 6.3, h.Mean())
+	// the samples above the last bound are counted by Count only
+	assert.Equal(t, []Bucket{{1, 2}, {5, 3}, {10, 4}}, h.Buckets)
+	assert.Equal(t, 0.0, HistogramSnapshot{}.Mean())
+
+	s.Reset()
+	assert.Empty(t, s.Snapshot().Counters)
+}
+
+func TestGlobalSink(t *testing.T) {
+	defer SetSink(Default())
+	s := NewMemorySink()
+	other := NewMemorySink()
+	SetSink(FanoutSink{s, other})
+
+	NewCounter("c").Inc()
+	NewCounter("c").Add(2)
+	NewGauge("g").Set(7)
+	NewHistogram("h").Observe(3)
+	NewHistogram("d").ObserveSince(time.Now().Add(-time.Second))
+	for _, sink := range []*MemorySink{s, other} {
+		snapshot := sink.Snapshot()
+		assert.Equal(t, int64(3), snapshot.Counters["c"])
+		assert.Equal(t, 7.0, snapshot.Gauges["g"])
+		assert.Equal(t, 3.0, snapshot.Histograms["h"].Sum)
+		assert.True(t, snapshot.Histograms["d"].Min >= 1000)
+	}
+
+	SetSink(nil)
+	IncrCounter("c", 1)
+	assert.Equal(t, int64(3), s.Snapshot().Counters["c"])
+	SetSink(Default())
+	assert.Equal(t, Default(), GetSink())
+}
+
+func TestPublishExpvar(t *testing.T) {
+	s := NewMemorySink()
+	s.IncrCounter("requests", 1)
+	PublishExpvar("test_metrics", s)
+	var snapshot Snapshot
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("test_metrics").String()), &snapshot))
+	assert.Equal(t, int64(1), snapshot.Counters["requests"])
+	assert.Panics(t, func() { PublishExpvar("test_metrics", s) })
+}
@@ -0,0 +1,181 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"mosn.io/pkg/buffer"
+)
+
+// AppendCanonicalHeaders appends the canonical form of the selected headers to dst, as the SigV4 style:
+// the keys are lower-case and sorted, the values are trimmed and the sequential spaces are collapsed,
+// the repeated values are joined with ',', and each header is written as "key:value\n".
+// The absent headers are skipped.
+func (h RequestHeader) AppendCanonicalHeaders(dst []byte, keys []string) []byte {
+	return appendCanonicalHeaders(dst, h, keys)
+}
+
+// CanonicalHeaders returns the canonical form of the selected headers in a pooled buffer,
+// see AppendCanonicalHeaders. The buffer should be given back by buffer.PutBytes.
+func (h RequestHeader) CanonicalHeaders(keys []string) *[]byte {
+	buf := buffer.GetBytes(defaultHeaderBufferSize)
+	*buf = appendCanonicalHeaders((*buf)[:0], h, keys)
+	return buf
+}
+
+// SignedHeaders returns the lower-case and sorted keys of the present headers joined with ';'.
+func (h RequestHeader) SignedHeaders(keys []string) string {
+	return signedHeaders(h, keys)
+}
+
+// AppendCanonicalHeaders appends the canonical form of the selected headers to dst,
+// see RequestHeader.AppendCanonicalHeaders.
+func (h ResponseHeader) AppendCanonicalHeaders(dst []byte, keys []string) []byte {
+	return appendCanonicalHeaders(dst, h, keys)
+}
+
+// CanonicalHeaders returns the canonical form of the selected headers in a pooled buffer,
+// see AppendCanonicalHeaders. The buffer should be given back by buffer.PutBytes.
+func (h ResponseHeader) CanonicalHeaders(keys []string) *[]byte {
+	buf := buffer.GetBytes(defaultHeaderBufferSize)
+	*buf = appendCanonicalHeaders((*buf)[:0], h, keys)
+	return buf
+}
+
+// SignedHeaders returns the lower-case and sorted keys of the present headers joined with ';'.
+func (h ResponseHeader) SignedHeaders(keys []string) string {
+	return signedHeaders(h, keys)
+}
+
+func appendCanonicalHeaders(dst []byte, h headerVisitor, keys []string) []byte {
+	for _, key := range canonicalKeys(keys) {
+		found := false
+		h.VisitAll(func(k, v []byte) {
+			if !equalFoldString(k, key) {
+				return
+			}
+			if found {
+				dst = append(dst, ',')
+			} else {
+				dst = append(dst, key...)
+				dst = append(dst, ':')
+				found = true
+			}
+			dst = appendCollapsedSpaces(dst, v)
+		})
+		if found {
+			dst = append(dst, '\n')
+		}
+	}
+	return dst
+}
+
+func signedHeaders(h headerVisitor, keys []string) string {
+	var sb strings.Builder
+	for _, key := range canonicalKeys(keys) {
+		found := false
+		h.VisitAll(func(k, _ []byte) {
+			found = found || equalFoldString(k, key)
+		})
+		if !found {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(';')
+		}
+		sb.WriteString(key)
+	}
+	return sb.String()
+}
+
+// equalFoldString is same as bytes.EqualFold for ASCII, without converting s to bytes.
+func equalFoldString(b []byte, s string) bool {
+	if len(b) != len(s) {
+		return false
+	}
+	for i := 0; i < len(b); i++ {
+		c1, c2 := b[i], s[i]
+		if 'A' <= c1 && c1 <= 'Z' {
+			c1 += 'a' - 'A'
+		}
+		if 'A' <= c2 && c2 <= 'Z' {
+			c2 += 'a' - 'A'
+		}
+		if c1 != c2 {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalKeys returns the lower-case, sorted and deduplicated keys,
+// keys is returned directly if it is already canonical.
+func canonicalKeys(keys []string) []string {
+	if isCanonicalKeys(keys) {
+		return keys
+	}
+	ret := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ret = append(ret, strings.ToLower(strings.TrimSpace(key)))
+	}
+	sort.Strings(ret)
+	n := 0
+	for i, key := range ret {
+		if key == "" || (i > 0 && key == ret[i-1]) {
+			continue
+		}
+		ret[n] = key
+		n++
+	}
+	return ret[:n]
+}
+
+func isCanonicalKeys(keys []string) bool {
+	for i, key := range keys {
+		if key == "" || (i > 0 && key <= keys[i-1]) {
+			return false
+		}
+		for j := 0; j < len(key); j++ {
+			if c := key[j]; ('A' <= c && c <= 'Z') || c == ' ' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// appendCollapsedSpaces appends the trimmed value with the sequential spaces collapsed into one.
+func appendCollapsedSpaces(dst, value []byte) []byte {
+	value = bytes.TrimSpace(value)
+	space := false
+	for _, c := range value {
+		if c == ' ' || c == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			dst = append(dst, ' ')
+			space = false
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
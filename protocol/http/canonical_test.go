@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+
+	"mosn.io/pkg/buffer"
+)
+
+func TestCanonicalHeaders(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetHost("mosn.io")
+	header.Set("X-Amz-Date", "20220101T000000Z")
+	header.Add("X-Multi", "  a   b ")
+	header.Add("X-Multi", "c")
+	header.Set("X-Unsigned", "1")
+
+	keys := []string{"X-Multi", "host", "x-amz-date", "X-None", "Host"}
+	expected := "host:mosn.io\nx-amz-date:20220101T000000Z\nx-multi:a b,c\n"
+	buf := header.CanonicalHeaders(keys)
+	if string(*buf) != expected {
+		t.Errorf("expected %q, but got: %q", expected, *buf)
+	}
+	buffer.PutBytes(buf)
+	if got := string(header.AppendCanonicalHeaders([]byte("GET\n"), keys)); got != "GET\n"+expected {
+		t.Errorf("unexpected output: %q", got)
+	}
+	if got := header.SignedHeaders(keys); got != "host;x-amz-date;x-multi" {
+		t.Errorf("unexpected signed headers: %s", got)
+	}
+	// the canonical keys are used directly
+	if got := header.SignedHeaders([]string{"host", "x-multi"}); got != "host;x-multi" {
+		t.Errorf("unexpected signed headers: %s", got)
+	}
+}
+
+func TestResponseCanonicalHeaders(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.SetContentType("application/json")
+	header.Set("Digest", "sha-256=abc")
+	got := string(header.AppendCanonicalHeaders(nil, []string{"Digest", "Content-Type"}))
+	if got != "content-type:application/json\ndigest:sha-256=abc\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+	if header.SignedHeaders(nil) != "" {
+		t.Error("empty keys should be empty")
+	}
+}
+
+func BenchmarkCanonicalHeaders(b *testing.B) {
+	header := NewRequestHeader(nil)
+	header.SetHost("mosn.io")
+	header.Set("X-Amz-Date", "20220101T000000Z")
+	header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	keys := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buffer.PutBytes(header.CanonicalHeaders(keys))
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"github.com/valyala/fasthttp"
+	"mosn.io/api"
+)
+
+// headerChecker keeps the options of the checked header and the error of the last rejected write.
+type headerChecker struct {
+	// limits is enforced by Set and Add
+	limits HeaderLimits
+	// skipValidation skips ValidateHeaderField in Set and Add
+	skipValidation bool
+	// err is the error of the last rejected Set or Add
+	err error
+}
+
+// HeaderOption configures the header created by NewCheckedRequestHeader or NewCheckedResponseHeader.
+type HeaderOption func(c *headerChecker)
+
+// WithHeaderLimits makes Set and Add reject the key-value pair that exceeds the limits.
+func WithHeaderLimits(limits HeaderLimits) HeaderOption {
+	return func(c *headerChecker) {
+		c.limits = limits
+	}
+}
+
+// WithoutHeaderValidation skips the validation of illegal bytes in Set and Add,
+// it can be used if only the limits are required.
+func WithoutHeaderValidation() HeaderOption {
+	return func(c *headerChecker) {
+		c.skipValidation = true
+	}
+}
+
+func newHeaderChecker(opts []HeaderOption) *headerChecker {
+	c := &headerChecker{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// clone returns a copy of the options, the error is not copied.
+func (c *headerChecker) clone() *headerChecker {
+	if c == nil {
+		return nil
+	}
+	return &headerChecker{limits: c.limits, skipValidation: c.skipValidation}
+}
+
+// admit returns true if the key-value pair can be written into h,
+// otherwise the error is recorded and the header should be kept unchanged.
+// The nil checker admits everything, same as the unchecked header.
+func (c *headerChecker) admit(h headerVisitor, key, value string, replace bool) bool {
+	if c == nil {
+		return true
+	}
+	var err error
+	if !c.skipValidation {
+		err = ValidateHeaderField(key, value)
+	}
+	if err == nil {
+		err = c.limits.check(h, key, value, replace)
+	}
+	c.err = err
+	return err == nil
+}
+
+func (c *headerChecker) lastError() error {
+	if c == nil {
+		return nil
+	}
+	return c.err
+}
+
+// CheckedRequestHeader is a RequestHeader whose Set and Add check the key-value pair before writing,
+// the pair is validated by ValidateHeaderField and the HeaderLimits given by WithHeaderLimits.
+// The rejected pair is not written and the error can be got by Err.
+// It must be created by NewCheckedRequestHeader, the methods of the embedded RequestHeader are not checked.
+type CheckedRequestHeader struct {
+	RequestHeader
+	checker *headerChecker
+}
+
+// NewCheckedRequestHeader wraps h as a CheckedRequestHeader, h is allocated if it is nil.
+func NewCheckedRequestHeader(h *fasthttp.RequestHeader, opts ...HeaderOption) CheckedRequestHeader {
+	return CheckedRequestHeader{RequestHeader: NewRequestHeader(h), checker: newHeaderChecker(opts)}
+}
+
+// Err returns the error of the last Set or Add, such as the *HeaderFieldError and the *HeaderLimitError.
+// It is nil if the last Set or Add succeeded.
+func (h CheckedRequestHeader) Err() error {
+	return h.checker.lastError()
+}
+
+// Set key-value pair in header map if the pair is admitted, the previous pair will be replaced if exists
+func (h CheckedRequestHeader) Set(key string, value string) {
+	if h.checker.admit(h.RequestHeader, key, value, true) {
+		h.RequestHeader.Set(key, value)
+	}
+}
+
+// Add value for given key if the pair is admitted.
+func (h CheckedRequestHeader) Add(key, value string) {
+	if h.checker.admit(h.RequestHeader, key, value, false) {
+		h.RequestHeader.Add(key, value)
+	}
+}
+
+// Clone returns a deep copy of the header
+func (h CheckedRequestHeader) Clone() api.HeaderMap {
+	return h.CloneHeader()
+}
+
+// CloneHeader returns a deep copy of h with the same options.
+func (h CheckedRequestHeader) CloneHeader() CheckedRequestHeader {
+	return CheckedRequestHeader{RequestHeader: h.RequestHeader.CloneHeader(), checker: h.checker.clone()}
+}
+
+// CheckedResponseHeader is a ResponseHeader whose Set and Add check the key-value pair before writing,
+// same as CheckedRequestHeader.
+// It must be created by NewCheckedResponseHeader, the methods of the embedded ResponseHeader are not checked.
+type CheckedResponseHeader struct {
+	ResponseHeader
+	checker *headerChecker
+}
+
+// NewCheckedResponseHeader wraps h as a CheckedResponseHeader, h is allocated if it is nil.
+func NewCheckedResponseHeader(h *fasthttp.ResponseHeader, opts ...HeaderOption) CheckedResponseHeader {
+	return CheckedResponseHeader{ResponseHeader: NewResponseHeader(h), checker: newHeaderChecker(opts)}
+}
+
+// Err returns the error of the last Set or Add, such as the *HeaderFieldError and the *HeaderLimitError.
+// It is nil if the last Set or Add succeeded.
+func (h CheckedResponseHeader) Err() error {
+	return h.checker.lastError()
+}
+
+// Set key-value pair in header map if the pair is admitted, the previous pair will be replaced if exists
+func (h CheckedResponseHeader) Set(key string, value string) {
+	if h.checker.admit(h.ResponseHeader, key, value, true) {
+		h.ResponseHeader.Set(key, value)
+	}
+}
+
+// Add value for given key if the pair is admitted.
+func (h CheckedResponseHeader) Add(key, value string) {
+	if h.checker.admit(h.ResponseHeader, key, value, false) {
+		h.ResponseHeader.Add(key, value)
+	}
+}
+
+// Clone returns a deep copy of the header
+func (h CheckedResponseHeader) Clone() api.HeaderMap {
+	return h.CloneHeader()
+}
+
+// CloneHeader returns a deep copy of h with the same options.
+func (h CheckedResponseHeader) CloneHeader() CheckedResponseHeader {
+	return CheckedResponseHeader{ResponseHeader: h.ResponseHeader.CloneHeader(), checker: h.checker.clone()}
+}
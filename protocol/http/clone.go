@@ -0,0 +1,92 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"reflect"
+
+	"github.com/valyala/fasthttp"
+)
+
+// requestNoDefaultContentType is the index of the unexported noDefaultContentType field of
+// fasthttp.RequestHeader, which has a setter but no getter and is not copied by fasthttp CopyTo.
+// It is nil if the field is missing in the fasthttp version, then the flag is not copied.
+var requestNoDefaultContentType = boolFieldIndex(reflect.TypeOf(fasthttp.RequestHeader{}), "noDefaultContentType")
+
+// boolFieldIndex returns the index of the bool field, or nil if it is missing.
+func boolFieldIndex(typ reflect.Type, name string) []int {
+	field, ok := typ.FieldByName(name)
+	if !ok || field.Type.Kind() != reflect.Bool {
+		return nil
+	}
+	return field.Index
+}
+
+// CopyTo copies all the headers to dst deeply, including the multi-value entries and the trailers,
+// dst shares no memory with h after copied, and h is only read.
+// It fixes fasthttp RequestHeader.CopyTo, which shares the trailer keys with h and
+// loses the no default content type flag.
+func (h RequestHeader) CopyTo(dst *fasthttp.RequestHeader) {
+	trailer := h.trailerBytes()
+	if len(trailer) == 0 {
+		h.RequestHeader.CopyTo(dst)
+	} else {
+		// tmp shares the trailer keys with h, truncating them writes nothing,
+		// so dst gets no shared trailer from tmp and the trailers are rebuilt from the copy of names.
+		tmp := &fasthttp.RequestHeader{}
+		h.RequestHeader.CopyTo(tmp)
+		tmp.SetTrailerBytes(nil)
+		tmp.CopyTo(dst)
+		dst.SetTrailerBytes(trailer)
+	}
+	if requestNoDefaultContentType != nil {
+		dst.SetNoDefaultContentType(reflect.ValueOf(h.RequestHeader).Elem().FieldByIndex(requestNoDefaultContentType).Bool())
+	}
+}
+
+// trailerBytes returns a copy of the trailer names joined by comma
+func (h RequestHeader) trailerBytes() []byte {
+	var trailer []byte
+	h.VisitAllTrailer(func(value []byte) {
+		if len(trailer) > 0 {
+			trailer = append(trailer, ", "...)
+		}
+		trailer = append(trailer, value...)
+	})
+	return trailer
+}
+
+// CloneHeader returns a deep copy of h, which can be mutated without touching h.
+func (h RequestHeader) CloneHeader() RequestHeader {
+	cpy := &fasthttp.RequestHeader{}
+	h.CopyTo(cpy)
+	return RequestHeader{cpy}
+}
+
+// CopyTo copies all the headers to dst deeply, including the multi-value entries and the trailers,
+// dst shares no memory with h after copied.
+func (h ResponseHeader) CopyTo(dst *fasthttp.ResponseHeader) {
+	h.ResponseHeader.CopyTo(dst)
+}
+
+// CloneHeader returns a deep copy of h, which can be mutated without touching h.
+func (h ResponseHeader) CloneHeader() ResponseHeader {
+	cpy := &fasthttp.ResponseHeader{}
+	h.CopyTo(cpy)
+	return ResponseHeader{cpy}
+}
@@ -0,0 +1,163 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestHeader_CloneHeader(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetMethod("POST")
+	header.SetRequestURI("/path?a=1")
+	header.SetHost("mosn.io")
+	header.Add("X-Multi", "one")
+	header.Add("X-Multi", "two")
+	header.SetCookie("session", "abc")
+	header.SetNoDefaultContentType(true)
+	if err := header.SetTrailer("X-Trailer-One"); err != nil {
+		t.Fatal(err)
+	}
+	origin := header.String()
+
+	cpy := header.CloneHeader()
+	if cpy.String() != origin {
+		t.Fatalf("clone is not equal, expected:\n%s\nbut got:\n%s", origin, cpy.String())
+	}
+
+	// mutate the clone should not affect the origin
+	if err := cpy.SetTrailer("X-Other"); err != nil {
+		t.Fatal(err)
+	}
+	cpy.Set("X-Multi", "changed")
+	cpy.SetHost("other")
+	cpy.SetCookie("session", "changed")
+	if header.String() != origin {
+		t.Errorf("origin is changed by clone, expected:\n%s\nbut got:\n%s", origin, header.String())
+	}
+	trailers := []string{}
+	header.VisitAllTrailer(func(value []byte) {
+		trailers = append(trailers, string(value))
+	})
+	if len(trailers) != 1 || trailers[0] != "X-Trailer-One" {
+		t.Errorf("origin trailer is changed: %v", trailers)
+	}
+
+	// the clone implements api.HeaderMap
+	if v, ok := header.Clone().Get("X-Multi"); !ok || v != "one" {
+		t.Errorf("unexpected clone value: %s", v)
+	}
+}
+
+func TestRequestHeader_CloneHeaderConcurrent(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.Set("X-Key", "value")
+	if err := header.SetTrailer("X-Trailer-One, X-Trailer-Two"); err != nil {
+		t.Fatal(err)
+	}
+	origin := header.String()
+
+	// the clone only reads the origin, so it can be done concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cpy := header.CloneHeader()
+				if cpy.String() != origin {
+					t.Errorf("clone is not equal:\n%s", cpy.String())
+					return
+				}
+				cpy.SetTrailer("X-Other")
+			}
+		}()
+	}
+	wg.Wait()
+	if header.String() != origin {
+		t.Errorf("origin is changed by clone:\n%s", header.String())
+	}
+}
+
+func TestBoolFieldIndex(t *testing.T) {
+	typ := reflect.TypeOf(struct {
+		flag  bool
+		count int
+	}{})
+	if index := boolFieldIndex(typ, "flag"); len(index) != 1 || index[0] != 0 {
+		t.Errorf("unexpected index: %v", index)
+	}
+	// the missing field is skipped instead of panic
+	if index := boolFieldIndex(typ, "count"); index != nil {
+		t.Errorf("non bool field should be skipped: %v", index)
+	}
+	if index := boolFieldIndex(typ, "missing"); index != nil {
+		t.Errorf("missing field should be skipped: %v", index)
+	}
+}
+
+func TestResponseHeader_CloneHeader(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.SetStatusCode(404)
+	header.Add("Set-Cookie", "a=1")
+	header.Add("Set-Cookie", "b=2")
+	header.Add("X-Multi", "one")
+	if err := header.SetTrailer("X-Trailer"); err != nil {
+		t.Fatal(err)
+	}
+	origin := header.String()
+
+	dst := &fasthttp.ResponseHeader{}
+	header.CopyTo(dst)
+	if dst.String() != origin {
+		t.Fatalf("copy is not equal, expected:\n%s\nbut got:\n%s", origin, dst.String())
+	}
+	dst.Set("X-Multi", "changed")
+	dst.SetTrailer("X-Other")
+	dst.SetStatusCode(200)
+	if header.String() != origin {
+		t.Errorf("origin is changed by copy, expected:\n%s\nbut got:\n%s", origin, header.String())
+	}
+}
+
+// TestCopyToFasthttpVersion pins the fasthttp version that CopyTo is verified against,
+// the unexported fields and the trailer sharing of fasthttp CopyTo must be checked again on upgrade.
+func TestCopyToFasthttpVersion(t *testing.T) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("no build info")
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/valyala/fasthttp" && dep.Version != "v1.40.0" {
+			t.Errorf("CopyTo is verified with fasthttp v1.40.0, but got %s", dep.Version)
+		}
+	}
+
+	header := NewRequestHeader(nil)
+	header.SetMethod("POST")
+	header.SetNoDefaultContentType(true)
+	cpy := header.CloneHeader()
+	if _, ok := cpy.Get("Content-Type"); ok || cpy.String() != header.String() {
+		t.Errorf("no default content type is not copied:\n%s", cpy.String())
+	}
+}
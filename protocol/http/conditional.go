@@ -0,0 +1,131 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"mosn.io/pkg/utils"
+)
+
+const weakETagPrefix = "W/"
+
+// GenerateETag generates an entity tag from the content, for example: "5d6f6a7b8c9d0e1f"
+func GenerateETag(content []byte, weak bool) string {
+	etag := `"` + strconv.FormatUint(utils.XXHash64(content), 16) + `"`
+	if weak {
+		return weakETagPrefix + etag
+	}
+	return etag
+}
+
+// IsWeakETag returns true if the entity tag is weak.
+func IsWeakETag(etag string) bool {
+	return strings.HasPrefix(etag, weakETagPrefix)
+}
+
+// ETagStrongMatch compares the entity tags with the strong comparison, see RFC 7232 Section 2.3.2
+func ETagStrongMatch(a, b string) bool {
+	return !IsWeakETag(a) && !IsWeakETag(b) && a == b
+}
+
+// ETagWeakMatch compares the entity tags with the weak comparison, see RFC 7232 Section 2.3.2
+func ETagWeakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, weakETagPrefix) == strings.TrimPrefix(b, weakETagPrefix)
+}
+
+// ETag returns the ETag of the response.
+func (h ResponseHeader) ETag() (string, bool) {
+	return h.Get(fasthttp.HeaderETag)
+}
+
+// SetETag sets the ETag of the response.
+func (h ResponseHeader) SetETag(etag string) {
+	h.Set(fasthttp.HeaderETag, etag)
+}
+
+// LastModified returns the Last-Modified of the response.
+func (h ResponseHeader) LastModified() (time.Time, bool) {
+	return parseHTTPDate(h.Peek(fasthttp.HeaderLastModified))
+}
+
+// SetLastModified sets the Last-Modified of the response.
+func (h ResponseHeader) SetLastModified(t time.Time) {
+	h.SetBytesV(fasthttp.HeaderLastModified, fasthttp.AppendHTTPDate(nil, t))
+}
+
+// EvaluatePreconditions evaluates the conditional request headers against the current state
+// of the resource, in the order of RFC 7232 Section 6. An empty etag or zero lastModified
+// means the resource does not have it.
+// It returns OK if the request should be processed, NotModified or PreconditionFailed otherwise.
+func (h RequestHeader) EvaluatePreconditions(etag string, lastModified time.Time) int {
+	lastModified = lastModified.Truncate(time.Second)
+	if ifMatch := h.Peek(fasthttp.HeaderIfMatch); ifMatch != nil {
+		if !matchETagList(string(ifMatch), etag, ETagStrongMatch) {
+			return PreconditionFailed
+		}
+	} else if date, ok := parseHTTPDate(h.Peek(fasthttp.HeaderIfUnmodifiedSince)); ok {
+		if !lastModified.IsZero() && lastModified.After(date) {
+			return PreconditionFailed
+		}
+	}
+	safe := h.IsGet() || h.IsHead()
+	if ifNoneMatch := h.Peek(fasthttp.HeaderIfNoneMatch); ifNoneMatch != nil {
+		if matchETagList(string(ifNoneMatch), etag, ETagWeakMatch) {
+			if safe {
+				return NotModified
+			}
+			return PreconditionFailed
+		}
+	} else if safe {
+		if date, ok := parseHTTPDate(h.Peek(fasthttp.HeaderIfModifiedSince)); ok {
+			if !lastModified.IsZero() && !lastModified.After(date) {
+				return NotModified
+			}
+		}
+	}
+	return OK
+}
+
+// matchETagList returns true if any entity tag in the list matches, "*" matches any existing etag.
+func matchETagList(list, etag string, match func(a, b string) bool) bool {
+	if etag == "" {
+		return false
+	}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "*" || match(item, etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHTTPDate(b []byte) (time.Time, bool) {
+	if len(b) == 0 {
+		return time.Time{}, false
+	}
+	t, err := fasthttp.ParseHTTPDate(b)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
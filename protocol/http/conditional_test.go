@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestETag(t *testing.T) {
+	etag := GenerateETag([]byte("hello"), false)
+	if etag != GenerateETag([]byte("hello"), false) || etag == GenerateETag([]byte("world"), false) {
+		t.Errorf("etag should be stable: %s", etag)
+	}
+	weak := GenerateETag([]byte("hello"), true)
+	if !IsWeakETag(weak) || IsWeakETag(etag) {
+		t.Errorf("unexpected weak etag: %s", weak)
+	}
+	if ETagStrongMatch(weak, etag) || !ETagWeakMatch(weak, etag) || !ETagStrongMatch(etag, etag) {
+		t.Error("unexpected match result")
+	}
+
+	header := NewResponseHeader(nil)
+	header.SetETag(etag)
+	now := time.Now().Truncate(time.Second)
+	header.SetLastModified(now)
+	if v, ok := header.ETag(); !ok || v != etag {
+		t.Errorf("unexpected etag: %s", v)
+	}
+	if v, ok := header.LastModified(); !ok || !v.Equal(now) {
+		t.Errorf("unexpected last modified: %v", v)
+	}
+}
+
+func TestEvaluatePreconditions(t *testing.T) {
+	etag := `"abc"`
+	modified := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := string(fasthttp.AppendHTTPDate(nil, modified.Add(-time.Hour)))
+	after := string(fasthttp.AppendHTTPDate(nil, modified.Add(time.Hour)))
+
+	for i, tc := range []struct {
+		method   string
+		headers  map[string]string
+		expected int
+	}{
+		{"GET", nil, OK},
+		{"GET", map[string]string{"If-None-Match": `"xyz", W/"abc"`}, NotModified},
+		{"HEAD", map[string]string{"If-None-Match": "*"}, NotModified},
+		{"GET", map[string]string{"If-None-Match": `"xyz"`}, OK},
+		{"PUT", map[string]string{"If-None-Match": "*"}, PreconditionFailed},
+		{"GET", map[string]string{"If-Modified-Since": after}, NotModified},
+		{"GET", map[string]string{"If-Modified-Since": before}, OK},
+		{"POST", map[string]string{"If-Modified-Since": after}, OK},
+		// If-None-Match takes precedence over If-Modified-Since
+		{"GET", map[string]string{"If-None-Match": `"xyz"`, "If-Modified-Since": after}, OK},
+		{"PUT", map[string]string{"If-Match": `"abc"`}, OK},
+		{"PUT", map[string]string{"If-Match": `W/"abc"`}, PreconditionFailed},
+		{"PUT", map[string]string{"If-Unmodified-Since": before}, PreconditionFailed},
+		{"PUT", map[string]string{"If-Unmodified-Since": after}, OK},
+		{"GET", map[string]string{"If-Modified-Since": "invalid"}, OK},
+	} {
+		header := NewRequestHeader(nil)
+		header.SetMethod(tc.method)
+		for k, v := range tc.headers {
+			header.Set(k, v)
+		}
+		if got := header.EvaluatePreconditions(etag, modified); got != tc.expected {
+			t.Errorf("#%d expected %d, but got: %d", i, tc.expected, got)
+		}
+	}
+	header := NewRequestHeader(nil)
+	header.Set("If-None-Match", "*")
+	if got := header.EvaluatePreconditions("", time.Time{}); got != OK {
+		t.Errorf("* should not match the resource without etag, but got: %d", got)
+	}
+}
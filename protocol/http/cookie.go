@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CookieAttrs contains the optional attributes of a Set-Cookie header.
+// The zero value means no attribute is set.
+type CookieAttrs struct {
+	Path     string
+	Domain   string
+	Expires  time.Time
+	MaxAge   int
+	Secure   bool
+	HTTPOnly bool
+	SameSite fasthttp.CookieSameSite
+}
+
+// GetCookie returns the value of the request cookie with the given name.
+func (h RequestHeader) GetCookie(name string) (string, bool) {
+	found := false
+	var value string
+	h.VisitAllCookie(func(key, v []byte) {
+		if !found && string(key) == name {
+			found = true
+			value = string(v)
+		}
+	})
+	return value, found
+}
+
+// RangeCookies calls f sequentially for each request cookie.
+// If f returns false, range stops the iteration.
+func (h RequestHeader) RangeCookies(f func(name, value string) bool) {
+	stopped := false
+	h.VisitAllCookie(func(key, value []byte) {
+		if stopped {
+			return
+		}
+		if !f(string(key), string(value)) {
+			stopped = true
+		}
+	})
+}
+
+// GetCookie returns a copy of the Set-Cookie with the given name.
+func (h ResponseHeader) GetCookie(name string) (*fasthttp.Cookie, bool) {
+	c := &fasthttp.Cookie{}
+	c.SetKey(name)
+	if !h.Cookie(c) {
+		return nil, false
+	}
+	return c, true
+}
+
+// SetCookieValue sets a Set-Cookie with the given name, value and attributes.
+// The Set-Cookie with the same name is replaced.
+func (h ResponseHeader) SetCookieValue(name, value string, attrs CookieAttrs) {
+	c := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(c)
+	c.SetKey(name)
+	c.SetValue(value)
+	c.SetPath(attrs.Path)
+	c.SetDomain(attrs.Domain)
+	if !attrs.Expires.IsZero() {
+		c.SetExpire(attrs.Expires)
+	}
+	c.SetMaxAge(attrs.MaxAge)
+	c.SetSecure(attrs.Secure)
+	c.SetHTTPOnly(attrs.HTTPOnly)
+	c.SetSameSite(attrs.SameSite)
+	h.SetCookie(c)
+}
+
+// RangeCookies calls f sequentially for each Set-Cookie, the cookie is only valid in f.
+// If f returns false, range stops the iteration.
+func (h ResponseHeader) RangeCookies(f func(c *fasthttp.Cookie) bool) {
+	c := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(c)
+	stopped := false
+	h.VisitAllCookie(func(_, value []byte) {
+		if stopped {
+			return
+		}
+		c.Reset()
+		if err := c.ParseBytes(value); err != nil {
+			return
+		}
+		if !f(c) {
+			stopped = true
+		}
+	})
+}
@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestHeader_Cookie(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.Set("Cookie", "a=1; b=2")
+	if v, ok := header.GetCookie("b"); !ok || v != "2" {
+		t.Errorf("unexpected cookie: %s, %v", v, ok)
+	}
+	header.SetCookie("c", "3")
+	header.DelCookie("a")
+	if _, ok := header.GetCookie("a"); ok {
+		t.Error("cookie should be deleted")
+	}
+	var names []string
+	header.RangeCookies(func(name, value string) bool {
+		names = append(names, name+"="+value)
+		return true
+	})
+	if len(names) != 2 || names[0] != "b=2" || names[1] != "c=3" {
+		t.Errorf("unexpected cookies: %v", names)
+	}
+	count := 0
+	header.RangeCookies(func(name, value string) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("range should be stopped, but got: %d", count)
+	}
+}
+
+func TestResponseHeader_Cookie(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.SetCookieValue("session", "abc", CookieAttrs{
+		Path:     "/",
+		MaxAge:   60,
+		HTTPOnly: true,
+		SameSite: fasthttp.CookieSameSiteLaxMode,
+	})
+	header.SetCookieValue("theme", "dark", CookieAttrs{})
+	c, ok := header.GetCookie("session")
+	if !ok {
+		t.Fatal("cookie not found")
+	}
+	if string(c.Value()) != "abc" || string(c.Path()) != "/" || c.MaxAge() != 60 || !c.HTTPOnly() || c.SameSite() != fasthttp.CookieSameSiteLaxMode {
+		t.Errorf("unexpected cookie: %s", c.String())
+	}
+	header.SetCookieValue("session", "def", CookieAttrs{})
+	if c, _ := header.GetCookie("session"); string(c.Value()) != "def" {
+		t.Errorf("cookie should be replaced, but got: %s", c.String())
+	}
+	var names []string
+	header.RangeCookies(func(c *fasthttp.Cookie) bool {
+		names = append(names, string(c.Key()))
+		return true
+	})
+	if len(names) != 2 {
+		t.Errorf("unexpected cookies: %v", names)
+	}
+	header.DelCookie("theme")
+	if _, ok := header.GetCookie("theme"); ok {
+		t.Error("cookie should be deleted")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+	"errors"
+
+	"mosn.io/api"
+)
+
+// DefaultMaxFormFieldSize is the default max size of a key-value pair of the urlencoded form.
+const DefaultMaxFormFieldSize = 64 * 1024
+
+var (
+	ErrParseStopped      = errors.New("parse is stopped by the handler")
+	ErrFormFieldTooLarge = errors.New("form field too large")
+)
+
+// FormParser parses the application/x-www-form-urlencoded body from an IoBuffer in streaming,
+// only a key-value pair is kept in memory at most.
+// A FormParser is not safe for concurrent use.
+type FormParser struct {
+	maxFieldSize int
+	key          []byte
+	value        []byte
+}
+
+// NewFormParser creates a FormParser, maxFieldSize limits the size of a key-value pair,
+// DefaultMaxFormFieldSize is used if it is not positive.
+func NewFormParser(maxFieldSize int) *FormParser {
+	if maxFieldSize <= 0 {
+		maxFieldSize = DefaultMaxFormFieldSize
+	}
+	return &FormParser{
+		maxFieldSize: maxFieldSize,
+	}
+}
+
+// Parse calls f for each complete key-value pair in buf, the parsed data is drained from buf.
+// The incomplete pair is kept in buf until more data is appended or buf is EOF.
+// The key and value are decoded, and only valid in f.
+// If f returns false, Parse stops and returns ErrParseStopped.
+func (p *FormParser) Parse(buf api.IoBuffer, f func(key, value []byte) bool) error {
+	for buf.Len() > 0 {
+		data := buf.Bytes()
+		n := bytes.IndexByte(data, '&')
+		consumed := n + 1
+		if n < 0 {
+			if !buf.EOF() {
+				if len(data) > p.maxFieldSize {
+					return ErrFormFieldTooLarge
+				}
+				return nil
+			}
+			n, consumed = len(data), len(data)
+		}
+		if n > p.maxFieldSize {
+			return ErrFormFieldTooLarge
+		}
+		pair := data[:n]
+		if len(pair) == 0 {
+			buf.Drain(consumed)
+			continue
+		}
+		key, value := pair, pair[len(pair):]
+		if i := bytes.IndexByte(pair, '='); i >= 0 {
+			key, value = pair[:i], pair[i+1:]
+		}
+		var err error
+		if p.key, err = decodeFormComponent(p.key[:0], key); err != nil {
+			return err
+		}
+		if p.value, err = decodeFormComponent(p.value[:0], value); err != nil {
+			return err
+		}
+		buf.Drain(consumed)
+		if !f(p.key, p.value) {
+			return ErrParseStopped
+		}
+	}
+	return nil
+}
+
+// decodeFormComponent appends the decoded src to dst, '+' is decoded as space.
+func decodeFormComponent(dst, src []byte) ([]byte, error) {
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '+':
+			dst = append(dst, ' ')
+		case '%':
+			if i+2 >= len(src) {
+				return nil, ErrInvalidPercentEncoding
+			}
+			hi, ok1 := unhex(src[i+1])
+			lo, ok2 := unhex(src[i+2])
+			if !ok1 || !ok2 {
+				return nil, ErrInvalidPercentEncoding
+			}
+			dst = append(dst, hi<<4|lo)
+			i += 2
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst, nil
+}
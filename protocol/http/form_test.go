@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+
+	"mosn.io/pkg/buffer"
+)
+
+func TestFormParser(t *testing.T) {
+	body := "name=mosn&msg=hello+world%21&&empty=&flag"
+	buf := buffer.NewIoBuffer(16)
+	p := NewFormParser(0)
+	var kvs []string
+	f := func(key, value []byte) bool {
+		kvs = append(kvs, string(key)+":"+string(value))
+		return true
+	}
+	// feed the body in small chunks
+	for i := 0; i < len(body); i += 3 {
+		end := i + 3
+		if end > len(body) {
+			end = len(body)
+		}
+		buf.Write([]byte(body[i:end]))
+		if err := p.Parse(buf, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf.SetEOF(true)
+	if err := p.Parse(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"name:mosn", "msg:hello world!", "empty:", "flag:"}
+	if len(kvs) != len(expected) {
+		t.Fatalf("expected %v, but got: %v", expected, kvs)
+	}
+	for i := range expected {
+		if kvs[i] != expected[i] {
+			t.Errorf("expected %v, but got: %v", expected, kvs)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("buffer should be drained, but left: %s", buf.String())
+	}
+}
+
+func TestFormParserErrors(t *testing.T) {
+	p := NewFormParser(8)
+	buf := buffer.NewIoBufferString("key=123456789")
+	if err := p.Parse(buf, func(key, value []byte) bool { return true }); err != ErrFormFieldTooLarge {
+		t.Errorf("expected field too large, but got: %v", err)
+	}
+	buf = buffer.NewIoBufferString("a=%zz&")
+	if err := p.Parse(buf, func(key, value []byte) bool { return true }); err != ErrInvalidPercentEncoding {
+		t.Errorf("expected invalid encoding, but got: %v", err)
+	}
+	buf = buffer.NewIoBufferString("a=1&b=2&")
+	if err := p.Parse(buf, func(key, value []byte) bool { return false }); err != ErrParseStopped {
+		t.Errorf("expected stopped, but got: %v", err)
+	}
+	if buf.String() != "b=2&" {
+		t.Errorf("unexpected left data: %s", buf.String())
+	}
+}
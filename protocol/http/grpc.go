@@ -0,0 +1,131 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// The converters use map[string][]string, which is the underlying type of
+// google.golang.org/grpc/metadata.MD, so a metadata.MD can be passed or
+// received directly without depending on grpc.
+
+const binHeaderSuffix = "-bin"
+
+// grpcReservedHeaders are managed by the gRPC transport, they are not metadata.
+var grpcReservedHeaders = map[string]struct{}{
+	"content-type":            {},
+	"user-agent":              {},
+	"te":                      {},
+	"host":                    {},
+	"grpc-message-type":       {},
+	"grpc-encoding":           {},
+	"grpc-accept-encoding":    {},
+	"grpc-message":            {},
+	"grpc-status":             {},
+	"grpc-timeout":            {},
+	"grpc-status-details-bin": {},
+}
+
+// IsGRPCReservedHeader returns true if the key is a pseudo header,
+// a connection-specific header, or a header reserved by gRPC.
+func IsGRPCReservedHeader(key string) bool {
+	if IsPseudoHeader(key) {
+		return true
+	}
+	key = strings.ToLower(key)
+	if _, ok := grpcReservedHeaders[key]; ok {
+		return true
+	}
+	_, ok := connectionSpecificHeaders[key]
+	return ok
+}
+
+// ToGRPCMetadata converts the headers into gRPC metadata,
+// the keys are lower-case and the values of the "-bin" keys are base64 decoded.
+// The reserved headers are skipped.
+func (h RequestHeader) ToGRPCMetadata() (map[string][]string, error) {
+	return toGRPCMetadata(h.Range)
+}
+
+// AddGRPCMetadata adds the gRPC metadata into the headers,
+// the values of the "-bin" keys are base64 encoded. The reserved keys are skipped.
+func (h RequestHeader) AddGRPCMetadata(md map[string][]string) {
+	addGRPCMetadata(h.Add, md)
+}
+
+// ToGRPCMetadata converts the headers into gRPC metadata,
+// the keys are lower-case and the values of the "-bin" keys are base64 decoded.
+// The reserved headers are skipped.
+func (h ResponseHeader) ToGRPCMetadata() (map[string][]string, error) {
+	return toGRPCMetadata(h.Range)
+}
+
+// AddGRPCMetadata adds the gRPC metadata into the headers,
+// the values of the "-bin" keys are base64 encoded. The reserved keys are skipped.
+func (h ResponseHeader) AddGRPCMetadata(md map[string][]string) {
+	addGRPCMetadata(h.Add, md)
+}
+
+func toGRPCMetadata(rangeFunc func(func(key, value string) bool)) (map[string][]string, error) {
+	md := make(map[string][]string)
+	var err error
+	rangeFunc(func(key, value string) bool {
+		if IsGRPCReservedHeader(key) {
+			return true
+		}
+		key = strings.ToLower(key)
+		if strings.HasSuffix(key, binHeaderSuffix) {
+			if value, err = decodeBinHeader(value); err != nil {
+				return false
+			}
+		}
+		md[key] = append(md[key], value)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+func addGRPCMetadata(add func(key, value string), md map[string][]string) {
+	for key, values := range md {
+		if IsGRPCReservedHeader(key) {
+			continue
+		}
+		bin := strings.HasSuffix(strings.ToLower(key), binHeaderSuffix)
+		for _, value := range values {
+			if bin {
+				value = base64.RawStdEncoding.EncodeToString([]byte(value))
+			}
+			add(key, value)
+		}
+	}
+}
+
+// decodeBinHeader decodes the base64 value, both padded and unpadded forms are accepted.
+func decodeBinHeader(v string) (string, error) {
+	if len(v)%4 == 0 {
+		b, err := base64.StdEncoding.DecodeString(v)
+		return string(b), err
+	}
+	b, err := base64.RawStdEncoding.DecodeString(v)
+	return string(b), err
+}
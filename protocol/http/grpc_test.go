@@ -0,0 +1,76 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGRPCMetadata(t *testing.T) {
+	md := map[string][]string{
+		"x-user":       {"mosn"},
+		"x-multi":      {"a", "b"},
+		"trace-bin":    {"\x00\x01\x02\xff"},
+		"grpc-timeout": {"1S"},
+		"te":           {"trailers"},
+	}
+	header := NewRequestHeader(nil)
+	header.AddGRPCMetadata(md)
+	if v, _ := header.Get("Trace-Bin"); v != "AAEC/w" {
+		t.Errorf("binary value should be base64 encoded, but got: %s", v)
+	}
+	if _, ok := header.Get("grpc-timeout"); ok {
+		t.Error("reserved header should be skipped")
+	}
+	header.Set("Content-Type", "application/grpc")
+	header.Set("Connection", "keep-alive")
+
+	got, err := header.ToGRPCMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"x-user":    {"mosn"},
+		"x-multi":   {"a", "b"},
+		"trace-bin": {"\x00\x01\x02\xff"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, but got: %v", expected, got)
+	}
+}
+
+func TestGRPCMetadataBinaryPadding(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.Add("A-Bin", "AAEC/w==")
+	header.Add("A-Bin", "AAEC/w")
+	md, err := header.ToGRPCMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(md["a-bin"], []string{"\x00\x01\x02\xff", "\x00\x01\x02\xff"}) {
+		t.Errorf("unexpected values: %q", md["a-bin"])
+	}
+	header.Set("B-Bin", "!!!")
+	if _, err := header.ToGRPCMetadata(); err == nil {
+		t.Error("invalid base64 should be failed")
+	}
+	if !IsGRPCReservedHeader(":path") || !IsGRPCReservedHeader("Grpc-Status") || IsGRPCReservedHeader("x-user") {
+		t.Error("unexpected reserved result")
+	}
+}
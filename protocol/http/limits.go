@@ -0,0 +1,125 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTooManyHeaders      = errors.New("too many headers")
+	ErrHeaderNameTooLong   = errors.New("header name too long")
+	ErrHeaderValueTooLong  = errors.New("header value too long")
+	ErrHeaderBytesExceeded = errors.New("header bytes exceeded")
+)
+
+// HeaderLimitError is returned when a header exceeds the HeaderLimits.
+// Use errors.Is to check which limit is exceeded.
+type HeaderLimitError struct {
+	Err    error
+	Key    string
+	Limit  int
+	Actual int
+}
+
+func (e *HeaderLimitError) Error() string {
+	return fmt.Sprintf("%s: key %q, limit %d, actual %d", e.Err, e.Key, e.Limit, e.Actual)
+}
+
+func (e *HeaderLimitError) Unwrap() error {
+	return e.Err
+}
+
+// HeaderLimits limits the size of headers, zero means no limit.
+// It is enforced by Set and Add of the checked header created with WithHeaderLimits.
+type HeaderLimits struct {
+	MaxHeaders     int
+	MaxNameLength  int
+	MaxValueLength int
+	// MaxTotalBytes limits the sum of all the keys and values, same as ByteSize.
+	MaxTotalBytes int
+}
+
+type headerVisitor interface {
+	VisitAll(f func(key, value []byte))
+}
+
+// Validate checks all the headers in h.
+func (l HeaderLimits) Validate(h headerVisitor) error {
+	var err error
+	count, total := 0, 0
+	h.VisitAll(func(key, value []byte) {
+		if err != nil {
+			return
+		}
+		count++
+		total += len(key) + len(value)
+		if err = l.checkKV(string(key), len(key), len(value)); err != nil {
+			return
+		}
+		if l.MaxHeaders > 0 && count > l.MaxHeaders {
+			err = &HeaderLimitError{Err: ErrTooManyHeaders, Key: string(key), Limit: l.MaxHeaders, Actual: count}
+			return
+		}
+		if l.MaxTotalBytes > 0 && total > l.MaxTotalBytes {
+			err = &HeaderLimitError{Err: ErrHeaderBytesExceeded, Key: string(key), Limit: l.MaxTotalBytes, Actual: total}
+		}
+	})
+	return err
+}
+
+// check checks whether the header is still in limits after the key-value pair is written.
+// If replace is true, the existing value of the key is replaced.
+// The headers are visited only if MaxHeaders or MaxTotalBytes is set.
+func (l HeaderLimits) check(h headerVisitor, key, value string, replace bool) error {
+	if err := l.checkKV(key, len(key), len(value)); err != nil {
+		return err
+	}
+	if l.MaxHeaders <= 0 && l.MaxTotalBytes <= 0 {
+		return nil
+	}
+	count, total := 1, len(key)+len(value)
+	keyBytes := []byte(key)
+	h.VisitAll(func(k, v []byte) {
+		if replace && bytes.EqualFold(k, keyBytes) {
+			replace = false
+			return
+		}
+		count++
+		total += len(k) + len(v)
+	})
+	if l.MaxHeaders > 0 && count > l.MaxHeaders {
+		return &HeaderLimitError{Err: ErrTooManyHeaders, Key: key, Limit: l.MaxHeaders, Actual: count}
+	}
+	if l.MaxTotalBytes > 0 && total > l.MaxTotalBytes {
+		return &HeaderLimitError{Err: ErrHeaderBytesExceeded, Key: key, Limit: l.MaxTotalBytes, Actual: total}
+	}
+	return nil
+}
+
+func (l HeaderLimits) checkKV(key string, keyLen, valueLen int) error {
+	if l.MaxNameLength > 0 && keyLen > l.MaxNameLength {
+		return &HeaderLimitError{Err: ErrHeaderNameTooLong, Key: key, Limit: l.MaxNameLength, Actual: keyLen}
+	}
+	if l.MaxValueLength > 0 && valueLen > l.MaxValueLength {
+		return &HeaderLimitError{Err: ErrHeaderValueTooLong, Key: key, Limit: l.MaxValueLength, Actual: valueLen}
+	}
+	return nil
+}
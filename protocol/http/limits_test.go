@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHeaderLimits(t *testing.T) {
+	limits := HeaderLimits{
+		MaxHeaders:     3,
+		MaxNameLength:  8,
+		MaxValueLength: 8,
+		MaxTotalBytes:  25,
+	}
+	header := NewCheckedRequestHeader(nil, WithHeaderLimits(limits))
+	header.Add("X-Long-Name", "1")
+	if err := header.Err(); !errors.Is(err, ErrHeaderNameTooLong) {
+		t.Errorf("expected name too long, but got: %v", err)
+	}
+	header.Set("X-A", "123456789")
+	if err := header.Err(); !errors.Is(err, ErrHeaderValueTooLong) {
+		t.Errorf("expected value too long, but got: %v", err)
+	}
+	for _, key := range []string{"X-A", "X-B", "X-C"} {
+		header.Add(key, "1")
+		if v, ok := header.Get(key); !ok || v != "1" {
+			t.Fatalf("add %s failed: %v", key, header.Err())
+		}
+	}
+	header.Add("X-D", "1")
+	var limitErr *HeaderLimitError
+	if err := header.Err(); !errors.As(err, &limitErr) || limitErr.Err != ErrTooManyHeaders || limitErr.Actual != 4 {
+		t.Errorf("expected too many headers, but got: %v", err)
+	}
+	if _, ok := header.Get("X-D"); ok {
+		t.Error("header should not be added")
+	}
+	// replace does not increase the count
+	header.Set("X-A", "12345678")
+	if v, _ := header.Get("X-A"); v != "12345678" {
+		t.Errorf("set failed: %v", header.Err())
+	}
+	header.Set("X-B", "12345678")
+	if err := header.Err(); !errors.Is(err, ErrHeaderBytesExceeded) {
+		t.Errorf("expected bytes exceeded, but got: %v", err)
+	}
+	if v, _ := header.Get("X-B"); v != "1" {
+		t.Errorf("header should not be changed, but got: %s", v)
+	}
+	if err := limits.Validate(header); err != nil {
+		t.Errorf("validate failed: %v", err)
+	}
+	// the limits are kept by the clone
+	cpy := header.CloneHeader()
+	cpy.Add("X-E", "1")
+	if _, ok := cpy.Get("X-E"); ok {
+		t.Error("header should not be added to the clone")
+	}
+	// the embedded header is not limited
+	header.RequestHeader.Add("X-D", "1")
+	if err := limits.Validate(header); !errors.Is(err, ErrTooManyHeaders) {
+		t.Errorf("expected too many headers, but got: %v", err)
+	}
+}
+
+func TestResponseHeaderLimits(t *testing.T) {
+	header := NewCheckedResponseHeader(nil, WithHeaderLimits(HeaderLimits{MaxHeaders: 1}))
+	header.SetNoDefaultContentType(true)
+	header.Set("X-A", "1")
+	header.Add("X-A", "2")
+	if err := header.Err(); !errors.Is(err, ErrTooManyHeaders) {
+		t.Errorf("expected too many headers, but got: %v", err)
+	}
+	if err := (HeaderLimits{}).Validate(header); err != nil {
+		t.Errorf("no limits should pass, but got: %v", err)
+	}
+	// no limits without the option
+	header = NewCheckedResponseHeader(nil)
+	header.Add("X-A", "1")
+	header.Add("X-A", "2")
+	if header.Err() != nil || len(header.ToMultiMap()["X-A"]) != 2 {
+		t.Errorf("unexpected error: %v", header.Err())
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"sort"
+)
+
+// ToMultiMap returns all the headers as a map, the repeated keys keep all the values in order.
+func (h RequestHeader) ToMultiMap() map[string][]string {
+	m := make(map[string][]string, h.Len())
+	h.VisitAll(func(key, value []byte) {
+		m[string(key)] = append(m[string(key)], string(value))
+	})
+	return m
+}
+
+// ToMultiMap returns all the headers as a map, the repeated keys such as Set-Cookie keep all the values in order.
+func (h ResponseHeader) ToMultiMap() map[string][]string {
+	m := make(map[string][]string, h.Len())
+	h.VisitAll(func(key, value []byte) {
+		m[string(key)] = append(m[string(key)], string(value))
+	})
+	return m
+}
+
+// NewRequestHeaderFromMultiMap returns a RequestHeader contains all the values of m.
+// The keys are added in sorted order, so the result is stable.
+func NewRequestHeaderFromMultiMap(m map[string][]string) RequestHeader {
+	h := NewRequestHeader(nil)
+	setMultiMap(h, m)
+	return h
+}
+
+// NewRequestHeaderFromMap returns a RequestHeader contains all the key-value pairs of m.
+func NewRequestHeaderFromMap(m map[string]string) RequestHeader {
+	h := NewRequestHeader(nil)
+	setMap(h, m)
+	return h
+}
+
+// NewResponseHeaderFromMultiMap returns a ResponseHeader contains all the values of m.
+// The keys are added in sorted order, so the result is stable.
+func NewResponseHeaderFromMultiMap(m map[string][]string) ResponseHeader {
+	h := NewResponseHeader(nil)
+	setMultiMap(h, m)
+	return h
+}
+
+// NewResponseHeaderFromMap returns a ResponseHeader contains all the key-value pairs of m.
+func NewResponseHeaderFromMap(m map[string]string) ResponseHeader {
+	h := NewResponseHeader(nil)
+	setMap(h, m)
+	return h
+}
+
+type headerSetter interface {
+	Set(key, value string)
+	Add(key, value string)
+}
+
+func setMultiMap(h headerSetter, m map[string][]string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for i, value := range m[key] {
+			// Set keeps the empty value readable
+			if i == 0 {
+				h.Set(key, value)
+			} else {
+				h.Add(key, value)
+			}
+		}
+	}
+}
+
+func setMap(h headerSetter, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		h.Set(key, m[key])
+	}
+}
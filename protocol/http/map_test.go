@@ -0,0 +1,70 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequestHeader_MultiMap(t *testing.T) {
+	m := map[string][]string{
+		"Host":    {"mosn.io"},
+		"X-Multi": {"one", "two"},
+		"X-Empty": {""},
+	}
+	header := NewRequestHeaderFromMultiMap(m)
+	if v, ok := header.Get("X-Empty"); !ok || v != "" {
+		t.Errorf("empty value should be kept, but got: %s, %v", v, ok)
+	}
+	if string(header.Host()) != "mosn.io" {
+		t.Errorf("special header is not set: %s", header.Host())
+	}
+	if got := header.ToMultiMap(); !reflect.DeepEqual(got, m) {
+		t.Errorf("expected %v, but got: %v", m, got)
+	}
+
+	header = NewRequestHeaderFromMap(map[string]string{"X-A": "1", "X-B": "2"})
+	if got := header.ToMultiMap(); !reflect.DeepEqual(got, map[string][]string{"X-A": {"1"}, "X-B": {"2"}}) {
+		t.Errorf("unexpected map: %v", got)
+	}
+}
+
+func TestResponseHeader_MultiMap(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.SetContentType("text/html")
+	header.Add("Set-Cookie", "a=1")
+	header.Add("Set-Cookie", "b=2")
+	header.Add("X-Multi", "one")
+	header.Add("X-Multi", "two")
+	expected := map[string][]string{
+		"Content-Type": {"text/html"},
+		"Set-Cookie":   {"a=1", "b=2"},
+		"X-Multi":      {"one", "two"},
+	}
+	m := header.ToMultiMap()
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, but got: %v", expected, m)
+	}
+	if got := NewResponseHeaderFromMultiMap(m).ToMultiMap(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, but got: %v", expected, got)
+	}
+	if got := NewResponseHeaderFromMap(map[string]string{"Server": "mosn"}); string(got.Server()) != "mosn" {
+		t.Errorf("unexpected server: %s", got.Server())
+	}
+}
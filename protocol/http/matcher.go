@@ -0,0 +1,155 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+
+	"mosn.io/api"
+)
+
+// MatchType is the way a HeaderMatcher matches the header value.
+type MatchType int
+
+const (
+	MatchExact MatchType = iota
+	MatchPrefix
+	MatchSuffix
+	MatchContains
+	MatchRegex
+	MatchPresent
+)
+
+var ErrUnknownMatchType = errors.New("unknown header match type")
+
+// HeaderMatcherConfig describes a HeaderMatcher.
+type HeaderMatcherConfig struct {
+	Name  string
+	Value string
+	Type  MatchType
+	// IgnoreCase is not supported by MatchRegex, use (?i) in the pattern instead.
+	IgnoreCase bool
+	// Invert inverts the match result.
+	Invert bool
+}
+
+// HeaderMatcher matches a header, the regex is compiled only once when the matcher is created.
+// A HeaderMatcher is safe for concurrent use.
+type HeaderMatcher struct {
+	config HeaderMatcherConfig
+	name   []byte
+	value  []byte
+	regex  *regexp.Regexp
+}
+
+// NewHeaderMatcher creates a HeaderMatcher.
+func NewHeaderMatcher(config HeaderMatcherConfig) (*HeaderMatcher, error) {
+	m := &HeaderMatcher{
+		config: config,
+		name:   []byte(config.Name),
+		value:  []byte(config.Value),
+	}
+	switch config.Type {
+	case MatchExact, MatchPrefix, MatchSuffix, MatchContains, MatchPresent:
+	case MatchRegex:
+		regex, err := regexp.Compile(config.Value)
+		if err != nil {
+			return nil, err
+		}
+		m.regex = regex
+	default:
+		return nil, ErrUnknownMatchType
+	}
+	if config.IgnoreCase {
+		m.value = bytes.ToLower(m.value)
+	}
+	return m, nil
+}
+
+type bytesGetter interface {
+	GetBytes(key []byte) ([]byte, bool)
+}
+
+// Match returns true if the header matches.
+// The header wrappers in this package are matched without string conversions.
+func (m *HeaderMatcher) Match(h api.HeaderMap) bool {
+	var value []byte
+	var ok bool
+	if g, isBytes := h.(bytesGetter); isBytes {
+		value, ok = g.GetBytes(m.name)
+	} else {
+		var v string
+		v, ok = h.Get(m.config.Name)
+		value = []byte(v)
+	}
+	return m.matchValue(value, ok) != m.config.Invert
+}
+
+func (m *HeaderMatcher) matchValue(value []byte, ok bool) bool {
+	if !ok {
+		return false
+	}
+	switch m.config.Type {
+	case MatchPresent:
+		return true
+	case MatchRegex:
+		return m.regex.Match(value)
+	}
+	if m.config.IgnoreCase {
+		value = bytes.ToLower(value)
+	}
+	switch m.config.Type {
+	case MatchExact:
+		return bytes.Equal(value, m.value)
+	case MatchPrefix:
+		return bytes.HasPrefix(value, m.value)
+	case MatchSuffix:
+		return bytes.HasSuffix(value, m.value)
+	case MatchContains:
+		return bytes.Contains(value, m.value)
+	}
+	return false
+}
+
+// HeaderMatchers matches the header if all the matchers are matched.
+type HeaderMatchers []*HeaderMatcher
+
+// NewHeaderMatchers creates HeaderMatchers.
+func NewHeaderMatchers(configs ...HeaderMatcherConfig) (HeaderMatchers, error) {
+	matchers := make(HeaderMatchers, 0, len(configs))
+	for _, config := range configs {
+		m, err := NewHeaderMatcher(config)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Match returns true if all the matchers are matched, empty matchers always match.
+func (ms HeaderMatchers) Match(h api.HeaderMap) bool {
+	for _, m := range ms {
+		if !m.Match(h) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+
+	"mosn.io/pkg/header"
+)
+
+func TestHeaderMatcher(t *testing.T) {
+	h := NewRequestHeader(nil)
+	h.Set("X-Service", "mosn.io.demo")
+	h.Set("X-Empty", "")
+	common := header.CommonHeader{
+		"X-Service": "mosn.io.demo",
+		"X-Empty":   "",
+	}
+
+	for i, tc := range []struct {
+		config   HeaderMatcherConfig
+		expected bool
+	}{
+		{HeaderMatcherConfig{Name: "x-service", Value: "mosn.io.demo"}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: "MOSN.IO.DEMO"}, false},
+		{HeaderMatcherConfig{Name: "X-Service", Value: "MOSN.IO.DEMO", IgnoreCase: true}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: "mosn.", Type: MatchPrefix}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: ".demo", Type: MatchSuffix}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: ".io.", Type: MatchContains}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: "^mosn\\.[a-z]+\\.demo$", Type: MatchRegex}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: "^demo", Type: MatchRegex}, false},
+		{HeaderMatcherConfig{Name: "X-Empty", Type: MatchPresent}, true},
+		{HeaderMatcherConfig{Name: "X-None", Type: MatchPresent}, false},
+		{HeaderMatcherConfig{Name: "X-None", Type: MatchPresent, Invert: true}, true},
+		{HeaderMatcherConfig{Name: "X-Service", Value: "other", Invert: true}, true},
+	} {
+		m, err := NewHeaderMatcher(tc.config)
+		if err != nil {
+			t.Fatalf("#%d create matcher failed: %v", i, err)
+		}
+		if m.Match(h) != tc.expected {
+			t.Errorf("#%d expected %v", i, tc.expected)
+		}
+		if tc.config.Name == "x-service" {
+			continue // CommonHeader is case sensitive
+		}
+		if m.Match(common) != tc.expected {
+			t.Errorf("#%d expected %v with common header map", i, tc.expected)
+		}
+	}
+}
+
+func TestHeaderMatchers(t *testing.T) {
+	if _, err := NewHeaderMatcher(HeaderMatcherConfig{Type: MatchRegex, Value: "("}); err == nil {
+		t.Error("invalid regex should be failed")
+	}
+	if _, err := NewHeaderMatchers(HeaderMatcherConfig{Type: MatchType(100)}); err != ErrUnknownMatchType {
+		t.Errorf("expected unknown match type, but got: %v", err)
+	}
+	ms, err := NewHeaderMatchers(
+		HeaderMatcherConfig{Name: "X-A", Value: "1"},
+		HeaderMatcherConfig{Name: "X-B", Type: MatchPresent},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewResponseHeader(nil)
+	h.Set("X-A", "1")
+	if ms.Match(h) {
+		t.Error("should not match without X-B")
+	}
+	h.Set("X-B", "2")
+	if !ms.Match(h) {
+		t.Error("should match")
+	}
+	if !(HeaderMatchers{}).Match(h) {
+		t.Error("empty matchers should match")
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/textproto"
+
+	"mosn.io/api"
+)
+
+// DefaultMaxPartHeaderSize is the default max size of the headers of a part.
+const DefaultMaxPartHeaderSize = 16 * 1024
+
+var (
+	ErrMultipartHeaderTooLarge = errors.New("multipart header too large")
+	ErrMultipartMalformed      = errors.New("malformed multipart body")
+)
+
+var doubleCRLF = []byte("\r\n\r\n")
+
+// MultipartPart is a part of the multipart body.
+type MultipartPart struct {
+	Header textproto.MIMEHeader
+	// FormName is the name parameter of Content-Disposition.
+	FormName string
+	// FileName is the filename parameter of Content-Disposition, empty means the part is not a file.
+	FileName string
+}
+
+// MultipartHandler receives the parts, the nil functions are ignored.
+// If a function returns false, the parsing stops.
+type MultipartHandler struct {
+	OnPart    func(part *MultipartPart) bool
+	OnData    func(part *MultipartPart, data []byte) bool
+	OnPartEnd func(part *MultipartPart) bool
+}
+
+type multipartState int
+
+const (
+	multipartPreamble multipartState = iota
+	multipartBoundary
+	multipartHeader
+	multipartBody
+	multipartDone
+)
+
+// MultipartParser parses the multipart/form-data body from an IoBuffer in streaming,
+// the part body is passed to the handler in chunks, so the upload is never buffered entirely.
+// A MultipartParser is not safe for concurrent use.
+type MultipartParser struct {
+	dashBoundary  []byte // "--boundary"
+	delimiter     []byte // "\r\n--boundary"
+	maxHeaderSize int
+	state         multipartState
+	part          *MultipartPart
+	// inPreamble is true if some of the preamble is drained,
+	// so the data is not at the beginning of a line.
+	inPreamble bool
+}
+
+// NewMultipartParser creates a MultipartParser, the boundary can be got from MediaType.Boundary.
+// DefaultMaxPartHeaderSize is used if maxHeaderSize is not positive.
+func NewMultipartParser(boundary string, maxHeaderSize int) *MultipartParser {
+	if maxHeaderSize <= 0 {
+		maxHeaderSize = DefaultMaxPartHeaderSize
+	}
+	delimiter := []byte("\r\n--" + boundary)
+	return &MultipartParser{
+		dashBoundary:  delimiter[2:],
+		delimiter:     delimiter,
+		maxHeaderSize: maxHeaderSize,
+	}
+}
+
+// Done returns true if the close delimiter is parsed.
+func (p *MultipartParser) Done() bool {
+	return p.state == multipartDone
+}
+
+// Parse parses the data in buf and calls the handler, the parsed data is drained from buf.
+// The incomplete data is kept in buf until more data is appended.
+// If buf is EOF before the close delimiter, io.ErrUnexpectedEOF is returned.
+// If the handler stops the parsing, ErrParseStopped is returned.
+func (p *MultipartParser) Parse(buf api.IoBuffer, h MultipartHandler) error {
+	for p.state != multipartDone {
+		progress, err := p.step(buf, h)
+		if err != nil {
+			return err
+		}
+		if !progress {
+			if buf.EOF() {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+	}
+	// the epilogue is ignored
+	buf.Drain(buf.Len())
+	return nil
+}
+
+// step parses the data in current state, returns false if more data is needed.
+func (p *MultipartParser) step(buf api.IoBuffer, h MultipartHandler) (bool, error) {
+	data := buf.Bytes()
+	switch p.state {
+	case multipartPreamble:
+		if !p.inPreamble && bytes.HasPrefix(data, p.dashBoundary) {
+			buf.Drain(len(p.dashBoundary))
+			p.state = multipartBoundary
+			return true, nil
+		}
+		if i := bytes.Index(data, p.delimiter); i >= 0 {
+			buf.Drain(i + len(p.delimiter))
+			p.state = multipartBoundary
+			return true, nil
+		}
+		// keep the tail which may be a prefix of the delimiter
+		if n := len(data) - len(p.delimiter) + 1; n > 0 {
+			buf.Drain(n)
+			p.inPreamble = true
+		}
+		return false, nil
+
+	case multipartBoundary:
+		if len(data) < 2 {
+			return false, nil
+		}
+		if data[0] == '-' && data[1] == '-' {
+			p.state = multipartDone
+			return true, nil
+		}
+		i := bytes.Index(data, crlf)
+		if i < 0 {
+			if len(data) > p.maxHeaderSize {
+				return false, ErrMultipartMalformed
+			}
+			return false, nil
+		}
+		// only the transport padding is allowed after the boundary
+		if len(bytes.Trim(data[:i], " \t")) != 0 {
+			return false, ErrMultipartMalformed
+		}
+		buf.Drain(i + len(crlf))
+		p.state = multipartHeader
+		return true, nil
+
+	case multipartHeader:
+		end := 0
+		if bytes.HasPrefix(data, crlf) {
+			end = len(crlf)
+		} else if i := bytes.Index(data, doubleCRLF); i >= 0 {
+			end = i + len(doubleCRLF)
+		}
+		if end == 0 {
+			if len(data) > p.maxHeaderSize {
+				return false, ErrMultipartHeaderTooLarge
+			}
+			return false, nil
+		}
+		if end > p.maxHeaderSize {
+			return false, ErrMultipartHeaderTooLarge
+		}
+		part, err := parsePartHeader(data[:end])
+		if err != nil {
+			return false, err
+		}
+		buf.Drain(end)
+		p.part = part
+		p.state = multipartBody
+		if h.OnPart != nil && !h.OnPart(part) {
+			return false, ErrParseStopped
+		}
+		return true, nil
+
+	case multipartBody:
+		i := bytes.Index(data, p.delimiter)
+		n := i
+		if i < 0 {
+			n = len(data) - len(p.delimiter) + 1
+		}
+		if n > 0 {
+			chunk := data[:n]
+			buf.Drain(n)
+			if h.OnData != nil && !h.OnData(p.part, chunk) {
+				return false, ErrParseStopped
+			}
+		}
+		if i < 0 {
+			return false, nil
+		}
+		buf.Drain(len(p.delimiter))
+		part := p.part
+		p.part = nil
+		p.state = multipartBoundary
+		if h.OnPartEnd != nil && !h.OnPartEnd(part) {
+			return false, ErrParseStopped
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func parsePartHeader(data []byte) (*MultipartPart, error) {
+	part := &MultipartPart{}
+	if len(data) == len(crlf) {
+		part.Header = textproto.MIMEHeader{}
+		return part, nil
+	}
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		return nil, ErrMultipartMalformed
+	}
+	part.Header = header
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		part.FormName = params["name"]
+		part.FileName = params["filename"]
+	}
+	return part, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"mosn.io/pkg/buffer"
+)
+
+func buildMultipart(t *testing.T) (string, []byte) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("name", "mosn"); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := w.CreateFormFile("file", "data.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(strings.Repeat("0123456789\r\n", 100)))
+	w.Close()
+	return w.Boundary(), append([]byte("preamble\r\n"), body.Bytes()...)
+}
+
+func TestMultipartParser(t *testing.T) {
+	boundary, body := buildMultipart(t)
+	for _, chunkSize := range []int{1, 7, 64, len(body)} {
+		p := NewMultipartParser(boundary, 0)
+		buf := buffer.NewIoBuffer(128)
+		var names []string
+		var files []string
+		data := map[string]*bytes.Buffer{}
+		handler := MultipartHandler{
+			OnPart: func(part *MultipartPart) bool {
+				names = append(names, part.FormName)
+				if part.FileName != "" {
+					files = append(files, part.FileName)
+				}
+				data[part.FormName] = &bytes.Buffer{}
+				return true
+			},
+			OnData: func(part *MultipartPart, b []byte) bool {
+				data[part.FormName].Write(b)
+				return true
+			},
+		}
+		for i := 0; i < len(body); i += chunkSize {
+			end := i + chunkSize
+			if end > len(body) {
+				end = len(body)
+			}
+			buf.Write(body[i:end])
+			if err := p.Parse(buf, handler); err != nil {
+				t.Fatalf("chunk %d: %v", chunkSize, err)
+			}
+			// the buffer never keeps the whole upload
+			if buf.Len() > DefaultMaxPartHeaderSize {
+				t.Fatalf("chunk %d: too much data is kept: %d", chunkSize, buf.Len())
+			}
+		}
+		if !p.Done() {
+			t.Fatalf("chunk %d: parser should be done", chunkSize)
+		}
+		if len(names) != 2 || names[0] != "name" || names[1] != "file" || len(files) != 1 || files[0] != "data.txt" {
+			t.Errorf("chunk %d: unexpected parts: %v %v", chunkSize, names, files)
+		}
+		if data["name"].String() != "mosn" || data["file"].String() != strings.Repeat("0123456789\r\n", 100) {
+			t.Errorf("chunk %d: unexpected data", chunkSize)
+		}
+	}
+}
+
+func TestMultipartParserErrors(t *testing.T) {
+	boundary, body := buildMultipart(t)
+	buf := buffer.NewIoBufferBytes(body[:len(body)-10])
+	buf.SetEOF(true)
+	if err := NewMultipartParser(boundary, 0).Parse(buf, MultipartHandler{}); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected unexpected EOF, but got: %v", err)
+	}
+
+	buf = buffer.NewIoBufferBytes(body)
+	if err := NewMultipartParser(boundary, 16).Parse(buf, MultipartHandler{}); err != ErrMultipartHeaderTooLarge {
+		t.Errorf("expected header too large, but got: %v", err)
+	}
+
+	buf = buffer.NewIoBufferBytes(body)
+	stopped := MultipartHandler{
+		OnPartEnd: func(part *MultipartPart) bool {
+			return false
+		},
+	}
+	if err := NewMultipartParser(boundary, 0).Parse(buf, stopped); err != ErrParseStopped {
+		t.Errorf("expected stopped, but got: %v", err)
+	}
+
+	buf = buffer.NewIoBufferString("--abc garbage\r\n")
+	if err := NewMultipartParser("abc", 0).Parse(buf, MultipartHandler{}); err != ErrMultipartMalformed {
+		t.Errorf("expected malformed, but got: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"errors"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidMediaType = errors.New("invalid media type")
+
+// MediaType is a parsed media type, such as the value of Content-Type.
+// The Type, Subtype and parameter names are lower-case.
+type MediaType struct {
+	Type    string
+	Subtype string
+	Params  map[string]string
+}
+
+// ParseMediaType parses a media type with parameters, for example: text/html; charset=utf-8
+func ParseMediaType(s string) (MediaType, error) {
+	mt, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return MediaType{}, err
+	}
+	i := strings.IndexByte(mt, '/')
+	if i <= 0 || i == len(mt)-1 {
+		return MediaType{}, ErrInvalidMediaType
+	}
+	return MediaType{
+		Type:    mt[:i],
+		Subtype: mt[i+1:],
+		Params:  params,
+	}, nil
+}
+
+// String returns the media type without parameters, for example: text/html
+func (m MediaType) String() string {
+	return m.Type + "/" + m.Subtype
+}
+
+// Charset returns the charset parameter.
+func (m MediaType) Charset() string {
+	return m.Params["charset"]
+}
+
+// Boundary returns the boundary parameter of multipart types.
+func (m MediaType) Boundary() string {
+	return m.Params["boundary"]
+}
+
+// Match returns true if m matches the media range, the range may contain wildcards, such as text/* or */*
+func (m MediaType) Match(mediaRange MediaType) bool {
+	if mediaRange.Type == "*" {
+		return true
+	}
+	if mediaRange.Type != m.Type {
+		return false
+	}
+	return mediaRange.Subtype == "*" || mediaRange.Subtype == m.Subtype
+}
+
+// MediaType parses the Content-Type of the request.
+func (h RequestHeader) MediaType() (MediaType, error) {
+	return ParseMediaType(string(h.ContentType()))
+}
+
+// MediaType parses the Content-Type of the response.
+func (h ResponseHeader) MediaType() (MediaType, error) {
+	return ParseMediaType(string(h.ContentType()))
+}
+
+// AcceptSpec is an item of Accept like headers.
+type AcceptSpec struct {
+	Value  string
+	Q      float64
+	Params map[string]string
+}
+
+// ParseAccept parses the value of Accept, Accept-Encoding, Accept-Charset or Accept-Language,
+// the items are sorted by q-value in descending order, and the items with same q-value keep the original order.
+// The invalid items are ignored.
+func ParseAccept(s string) []AcceptSpec {
+	var specs []AcceptSpec
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.Split(item, ";")
+		value := strings.ToLower(strings.TrimSpace(parts[0]))
+		if value == "" {
+			continue
+		}
+		spec := AcceptSpec{Value: value, Q: 1}
+		valid := true
+		for _, param := range parts[1:] {
+			kv := strings.SplitN(param, "=", 2)
+			key := strings.ToLower(strings.TrimSpace(kv[0]))
+			if key == "" || len(kv) != 2 {
+				continue
+			}
+			val := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			if key == "q" {
+				q, err := strconv.ParseFloat(val, 64)
+				if err != nil || q < 0 || q > 1 {
+					valid = false
+					break
+				}
+				spec.Q = q
+				continue
+			}
+			if spec.Params == nil {
+				spec.Params = make(map[string]string)
+			}
+			spec.Params[key] = val
+		}
+		if valid {
+			specs = append(specs, spec)
+		}
+	}
+	sort.SliceStable(specs, func(i, j int) bool {
+		return specs[i].Q > specs[j].Q
+	})
+	return specs
+}
+
+// NegotiateContentType returns the best offer for the Accept header.
+// The q-value of the most specific matched media range is used for each offer,
+// and the earlier offer wins if the q-values are same.
+// If accept is empty, the first offer is returned.
+func NegotiateContentType(accept string, offers []string) (string, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+	specs := ParseAccept(accept)
+	ranges := make([]MediaType, len(specs))
+	for i, spec := range specs {
+		if j := strings.IndexByte(spec.Value, '/'); j > 0 {
+			ranges[i] = MediaType{Type: spec.Value[:j], Subtype: spec.Value[j+1:]}
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		mt, err := ParseMediaType(offer)
+		if err != nil {
+			continue
+		}
+		q, specificity := 0.0, -1
+		for i, r := range ranges {
+			if r.Type == "" || !mt.Match(r) {
+				continue
+			}
+			s := 0
+			if r.Type != "*" {
+				s++
+			}
+			if r.Subtype != "*" {
+				s++
+			}
+			if s > specificity {
+				q, specificity = specs[i].Q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// NegotiateEncoding returns the best offer for the Accept-Encoding header.
+// The identity encoding is acceptable unless it is excluded explicitly.
+// If acceptEncoding is empty, the first offer is returned.
+func NegotiateEncoding(acceptEncoding string, offers []string) (string, bool) {
+	if len(offers) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(acceptEncoding) == "" {
+		return offers[0], true
+	}
+	specs := ParseAccept(acceptEncoding)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, found := 0.0, false
+		wildcard, hasWildcard := 0.0, false
+		for _, spec := range specs {
+			if spec.Value == strings.ToLower(offer) && !found {
+				q, found = spec.Q, true
+			} else if spec.Value == "*" && !hasWildcard {
+				wildcard, hasWildcard = spec.Q, true
+			}
+		}
+		if !found {
+			switch {
+			case hasWildcard:
+				q = wildcard
+			case strings.EqualFold(offer, "identity"):
+				// identity is acceptable with the lowest preference
+				q = 0.001
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
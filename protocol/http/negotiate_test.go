@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+)
+
+func TestParseMediaType(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetContentType(`multipart/form-data; boundary="abc"; Charset=UTF-8`)
+	mt, err := header.MediaType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt.String() != "multipart/form-data" || mt.Boundary() != "abc" || mt.Charset() != "UTF-8" {
+		t.Errorf("unexpected media type: %+v", mt)
+	}
+	if !mt.Match(MediaType{Type: "multipart", Subtype: "*"}) || mt.Match(MediaType{Type: "text", Subtype: "*"}) {
+		t.Error("unexpected match result")
+	}
+	for _, s := range []string{"", "text", "text/", "/html"} {
+		if _, err := ParseMediaType(s); err == nil {
+			t.Errorf("%q should be invalid", s)
+		}
+	}
+}
+
+func TestParseAccept(t *testing.T) {
+	specs := ParseAccept("text/html;level=1, application/json;q=0.9, */*;q=0.1, text/plain;q=1, bad;q=x")
+	expected := []string{"text/html", "text/plain", "application/json", "*/*"}
+	if len(specs) != len(expected) {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+	for i, spec := range specs {
+		if spec.Value != expected[i] {
+			t.Errorf("#%d expected %s, but got: %s", i, expected[i], spec.Value)
+		}
+	}
+	if specs[0].Params["level"] != "1" {
+		t.Errorf("unexpected params: %v", specs[0].Params)
+	}
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "text/html", "text/plain"}
+	for i, tc := range []struct {
+		accept   string
+		expected string
+		ok       bool
+	}{
+		{"", "application/json", true},
+		{"text/html", "text/html", true},
+		{"text/*;q=0.5, application/json;q=0.4", "text/html", true},
+		{"*/*;q=0.1, text/plain", "text/plain", true},
+		{"text/*, text/html;q=0", "text/plain", true},
+		{"image/png", "", false},
+		{"*/*", "application/json", true},
+	} {
+		got, ok := NegotiateContentType(tc.accept, offers)
+		if got != tc.expected || ok != tc.ok {
+			t.Errorf("#%d expected %s %v, but got: %s %v", i, tc.expected, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	offers := []string{"br", "gzip", "identity"}
+	for i, tc := range []struct {
+		accept   string
+		expected string
+		ok       bool
+	}{
+		{"", "br", true},
+		{"gzip, deflate", "gzip", true},
+		{"gzip;q=0.5, br;q=0.8", "br", true},
+		{"deflate", "identity", true},
+		{"deflate, identity;q=0", "", false},
+		{"*;q=0.3, gzip;q=0", "br", true},
+		{"*;q=0", "", false},
+	} {
+		got, ok := NegotiateEncoding(tc.accept, offers)
+		if got != tc.expected || ok != tc.ok {
+			t.Errorf("#%d expected %s %v, but got: %s %v", i, tc.expected, tc.ok, got, ok)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+)
+
+// GetBytes returns the value of key without string conversions, same as Get.
+// The returned value is valid until the header is changed, make copies if it is stored.
+func (h RequestHeader) GetBytes(key []byte) ([]byte, bool) {
+	if result := h.PeekBytes(key); result != nil {
+		return result, true
+	}
+	return nil, false
+}
+
+// PeekAll returns all the values of the key, in the order they were added.
+// The returned values are valid until the header is changed, make copies if they are stored.
+func (h RequestHeader) PeekAll(key string) [][]byte {
+	return peekAll(h, []byte(key))
+}
+
+// PeekAllBytes is same as PeekAll, but takes the key as bytes.
+func (h RequestHeader) PeekAllBytes(key []byte) [][]byte {
+	return peekAll(h, key)
+}
+
+// GetBytes returns the value of key without string conversions, same as Get.
+// The returned value is valid until the header is changed, make copies if it is stored.
+func (h ResponseHeader) GetBytes(key []byte) ([]byte, bool) {
+	if result := h.PeekBytes(key); result != nil {
+		return result, true
+	}
+	return nil, false
+}
+
+// PeekAll returns all the values of the key, in the order they were added.
+// Each Set-Cookie is returned as a separate value.
+// The returned values are valid until the header is changed, make copies if they are stored.
+func (h ResponseHeader) PeekAll(key string) [][]byte {
+	return peekAll(h, []byte(key))
+}
+
+// PeekAllBytes is same as PeekAll, but takes the key as bytes.
+func (h ResponseHeader) PeekAllBytes(key []byte) [][]byte {
+	return peekAll(h, key)
+}
+
+func peekAll(h headerVisitor, key []byte) [][]byte {
+	var values [][]byte
+	h.VisitAll(func(k, v []byte) {
+		if bytes.EqualFold(k, key) {
+			values = append(values, v)
+		}
+	})
+	return values
+}
@@ -0,0 +1,72 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+)
+
+func TestRequestHeader_PeekAll(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.Add("X-Multi", "a")
+	header.Add("X-Other", "c")
+	header.Add("X-Multi", "b")
+	header.SetHost("mosn.io")
+
+	values := header.PeekAll("x-multi")
+	if len(values) != 2 || string(values[0]) != "a" || string(values[1]) != "b" {
+		t.Errorf("unexpected values: %q", values)
+	}
+	if values := header.PeekAllBytes([]byte("Host")); len(values) != 1 || string(values[0]) != "mosn.io" {
+		t.Errorf("unexpected values: %q", values)
+	}
+	if values := header.PeekAll("none"); values != nil {
+		t.Errorf("unexpected values: %q", values)
+	}
+	if v, ok := header.GetBytes([]byte("x-other")); !ok || string(v) != "c" {
+		t.Errorf("unexpected value: %s, %v", v, ok)
+	}
+	if _, ok := header.GetBytes([]byte("none")); ok {
+		t.Error("key should not be found")
+	}
+}
+
+func TestResponseHeader_PeekAll(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.Add("Set-Cookie", "a=1")
+	header.Add("Set-Cookie", "b=2")
+	values := header.PeekAll("Set-Cookie")
+	if len(values) != 2 || string(values[0]) != "a=1" || string(values[1]) != "b=2" {
+		t.Errorf("unexpected values: %q", values)
+	}
+	header.Set("X-Empty", "")
+	if v, ok := header.GetBytes([]byte("X-Empty")); !ok || len(v) != 0 {
+		t.Errorf("unexpected value: %s, %v", v, ok)
+	}
+}
+
+func BenchmarkRequestHeader_GetBytes(b *testing.B) {
+	header := NewRequestHeader(nil)
+	header.Set("X-Route", "service")
+	key := []byte("X-Route")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		header.GetBytes(key)
+	}
+}
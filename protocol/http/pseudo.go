@@ -0,0 +1,235 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"mosn.io/api"
+)
+
+// HTTP/2 pseudo headers
+const (
+	PseudoHeaderMethod    = ":method"
+	PseudoHeaderPath      = ":path"
+	PseudoHeaderAuthority = ":authority"
+	PseudoHeaderScheme    = ":scheme"
+	PseudoHeaderStatus    = ":status"
+)
+
+var (
+	ErrUnknownPseudoHeader = errors.New("unknown pseudo header")
+	ErrInvalidStatus       = errors.New("invalid :status pseudo header")
+	ErrSchemeNotSupported  = errors.New(":scheme pseudo header requires HTTP2RequestHeader")
+)
+
+// connectionSpecificHeaders are not allowed in HTTP/2, see RFC 7540 Section 8.1.2.2
+var connectionSpecificHeaders = map[string]struct{}{
+	"connection":        {},
+	"keep-alive":        {},
+	"proxy-connection":  {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+}
+
+// IsPseudoHeader returns true if the key is a HTTP/2 pseudo header.
+func IsPseudoHeader(key string) bool {
+	return len(key) > 0 && key[0] == ':'
+}
+
+// GetPseudoHeader returns the value of the request pseudo header.
+// The :scheme is not kept by RequestHeader, use HTTP2RequestHeader instead.
+func (h RequestHeader) GetPseudoHeader(name string) (string, bool) {
+	switch name {
+	case PseudoHeaderMethod:
+		return string(h.Method()), true
+	case PseudoHeaderPath:
+		return string(h.RequestURI()), true
+	case PseudoHeaderAuthority:
+		host := h.Host()
+		return string(host), len(host) > 0
+	}
+	return "", false
+}
+
+// SetPseudoHeader sets the request pseudo header into the matched field of the header.
+// The :scheme is not supported by RequestHeader, use HTTP2RequestHeader instead.
+func (h RequestHeader) SetPseudoHeader(name, value string) error {
+	switch name {
+	case PseudoHeaderMethod:
+		h.SetMethod(value)
+	case PseudoHeaderPath:
+		h.SetRequestURI(value)
+	case PseudoHeaderAuthority:
+		h.SetHost(value)
+	case PseudoHeaderScheme:
+		// the scheme is kept out of the regular headers, so it is never sent to a HTTP/1 upstream
+		return ErrSchemeNotSupported
+	default:
+		return ErrUnknownPseudoHeader
+	}
+	return nil
+}
+
+// SetHTTP2Header sets the pseudo header or adds the regular header received from a HTTP/2 stream.
+func (h RequestHeader) SetHTTP2Header(key, value string) error {
+	if IsPseudoHeader(key) {
+		return h.SetPseudoHeader(key, value)
+	}
+	h.Add(key, value)
+	return nil
+}
+
+// RangeHTTP2 calls f sequentially for each header in HTTP/2 form.
+// The pseudo headers come first, the keys are lower-case and
+// the connection-specific headers are skipped.
+// If f returns false, range stops the iteration.
+func (h RequestHeader) RangeHTTP2(f func(key, value string) bool) {
+	rangeRequestHTTP2(h, "", f)
+}
+
+// HTTP2RequestHeader is a RequestHeader received from or sent to a HTTP/2 stream,
+// it keeps the :scheme pseudo header which fasthttp.RequestHeader has no field for.
+type HTTP2RequestHeader struct {
+	RequestHeader
+	// Scheme is the :scheme pseudo header, it is kept out of the regular headers,
+	// so it is never sent to a HTTP/1 upstream.
+	Scheme string
+}
+
+// NewHTTP2RequestHeader wraps h as a HTTP2RequestHeader, h is allocated if it is nil.
+func NewHTTP2RequestHeader(h *fasthttp.RequestHeader) *HTTP2RequestHeader {
+	return &HTTP2RequestHeader{RequestHeader: NewRequestHeader(h)}
+}
+
+// GetPseudoHeader returns the value of the request pseudo header, including the :scheme.
+func (h *HTTP2RequestHeader) GetPseudoHeader(name string) (string, bool) {
+	if name == PseudoHeaderScheme {
+		return h.Scheme, len(h.Scheme) > 0
+	}
+	return h.RequestHeader.GetPseudoHeader(name)
+}
+
+// SetPseudoHeader sets the request pseudo header, including the :scheme.
+func (h *HTTP2RequestHeader) SetPseudoHeader(name, value string) error {
+	if name == PseudoHeaderScheme {
+		h.Scheme = value
+		return nil
+	}
+	return h.RequestHeader.SetPseudoHeader(name, value)
+}
+
+// SetHTTP2Header sets the pseudo header or adds the regular header received from a HTTP/2 stream.
+func (h *HTTP2RequestHeader) SetHTTP2Header(key, value string) error {
+	if IsPseudoHeader(key) {
+		return h.SetPseudoHeader(key, value)
+	}
+	h.Add(key, value)
+	return nil
+}
+
+// RangeHTTP2 calls f sequentially for each header in HTTP/2 form, same as RequestHeader.RangeHTTP2
+// but the :scheme is included.
+func (h *HTTP2RequestHeader) RangeHTTP2(f func(key, value string) bool) {
+	rangeRequestHTTP2(h.RequestHeader, h.Scheme, f)
+}
+
+// Clone returns a deep copy of the header
+func (h *HTTP2RequestHeader) Clone() api.HeaderMap {
+	return h.CloneHeader()
+}
+
+// CloneHeader returns a deep copy of h, including the :scheme.
+func (h *HTTP2RequestHeader) CloneHeader() *HTTP2RequestHeader {
+	return &HTTP2RequestHeader{RequestHeader: h.RequestHeader.CloneHeader(), Scheme: h.Scheme}
+}
+
+// rangeRequestHTTP2 ranges the pseudo headers in the order of RFC 7540 examples,
+// the empty scheme is skipped.
+func rangeRequestHTTP2(h RequestHeader, scheme string, f func(key, value string) bool) {
+	for _, name := range []string{PseudoHeaderMethod, PseudoHeaderScheme, PseudoHeaderAuthority, PseudoHeaderPath} {
+		value, ok := scheme, len(scheme) > 0
+		if name != PseudoHeaderScheme {
+			value, ok = h.GetPseudoHeader(name)
+		}
+		if ok && !f(name, value) {
+			return
+		}
+	}
+	rangeHTTP2Headers(h.Range, f, "host")
+}
+
+// GetPseudoHeader returns the value of the response pseudo header.
+func (h ResponseHeader) GetPseudoHeader(name string) (string, bool) {
+	if name == PseudoHeaderStatus {
+		return strconv.Itoa(h.StatusCode()), true
+	}
+	return "", false
+}
+
+// SetPseudoHeader sets the response pseudo header into the matched field of the header.
+func (h ResponseHeader) SetPseudoHeader(name, value string) error {
+	if name != PseudoHeaderStatus {
+		return ErrUnknownPseudoHeader
+	}
+	code, err := strconv.Atoi(value)
+	if err != nil || code < 100 || code > 999 {
+		return ErrInvalidStatus
+	}
+	h.SetStatusCode(code)
+	return nil
+}
+
+// SetHTTP2Header sets the pseudo header or adds the regular header received from a HTTP/2 stream.
+func (h ResponseHeader) SetHTTP2Header(key, value string) error {
+	if IsPseudoHeader(key) {
+		return h.SetPseudoHeader(key, value)
+	}
+	h.Add(key, value)
+	return nil
+}
+
+// RangeHTTP2 calls f sequentially for each header in HTTP/2 form.
+// The :status comes first, the keys are lower-case and
+// the connection-specific headers are skipped.
+// If f returns false, range stops the iteration.
+func (h ResponseHeader) RangeHTTP2(f func(key, value string) bool) {
+	status, _ := h.GetPseudoHeader(PseudoHeaderStatus)
+	if !f(PseudoHeaderStatus, status) {
+		return
+	}
+	rangeHTTP2Headers(h.Range, f)
+}
+
+func rangeHTTP2Headers(rangeFunc func(func(key, value string) bool), f func(key, value string) bool, skips ...string) {
+	rangeFunc(func(key, value string) bool {
+		key = strings.ToLower(key)
+		if _, ok := connectionSpecificHeaders[key]; ok {
+			return true
+		}
+		for _, skip := range skips {
+			if key == skip {
+				return true
+			}
+		}
+		return f(key, value)
+	})
+}
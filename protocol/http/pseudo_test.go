@@ -0,0 +1,151 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"mosn.io/api"
+)
+
+func TestRequestHeader_HTTP2(t *testing.T) {
+	header := NewHTTP2RequestHeader(nil)
+	for _, kv := range [][2]string{
+		{":method", "POST"},
+		{":scheme", "https"},
+		{":authority", "mosn.io"},
+		{":path", "/api?a=1"},
+		{"content-type", "application/json"},
+	} {
+		if err := header.SetHTTP2Header(kv[0], kv[1]); err != nil {
+			t.Fatalf("set %s failed: %v", kv[0], err)
+		}
+	}
+	if err := header.SetHTTP2Header(":unknown", "1"); err != ErrUnknownPseudoHeader {
+		t.Errorf("expected unknown pseudo header error, but got: %v", err)
+	}
+	if string(header.Method()) != "POST" || string(header.Host()) != "mosn.io" || string(header.RequestURI()) != "/api?a=1" {
+		t.Errorf("pseudo headers are not mapped: %s", header.String())
+	}
+	header.Set("Connection", "keep-alive")
+
+	var kvs [][2]string
+	header.RangeHTTP2(func(key, value string) bool {
+		kvs = append(kvs, [2]string{key, value})
+		return true
+	})
+	expected := [][2]string{
+		{":method", "POST"},
+		{":scheme", "https"},
+		{":authority", "mosn.io"},
+		{":path", "/api?a=1"},
+		{"content-type", "application/json"},
+	}
+	if !reflect.DeepEqual(kvs, expected) {
+		t.Errorf("expected %v, but got: %v", expected, kvs)
+	}
+	// the scheme is not a regular header
+	if _, ok := header.Get("X-Forwarded-Proto"); ok {
+		t.Errorf(":scheme should not be kept in X-Forwarded-Proto: %s", header.String())
+	}
+	if cpy := header.CloneHeader(); cpy.Scheme != "https" || cpy.String() != header.String() {
+		t.Errorf("scheme is not cloned: %s", cpy.Scheme)
+	}
+	var _ api.HeaderMap = header
+}
+
+func TestRequestHeader_HTTP2ForwardedProto(t *testing.T) {
+	header := NewHTTP2RequestHeader(nil)
+	header.Set("X-Forwarded-Proto", "https")
+	if _, ok := header.GetPseudoHeader(":scheme"); ok {
+		t.Error("X-Forwarded-Proto should not be taken as :scheme")
+	}
+	if err := header.SetPseudoHeader(":scheme", "http"); err != nil {
+		t.Fatal(err)
+	}
+	var kvs [][2]string
+	header.RangeHTTP2(func(key, value string) bool {
+		if key == ":scheme" || key == "x-forwarded-proto" {
+			kvs = append(kvs, [2]string{key, value})
+		}
+		return true
+	})
+	expected := [][2]string{
+		{":scheme", "http"},
+		{"x-forwarded-proto", "https"},
+	}
+	if !reflect.DeepEqual(kvs, expected) {
+		t.Errorf("expected %v, but got: %v", expected, kvs)
+	}
+
+	// the scheme is skipped by the plain RequestHeader
+	plain := RequestHeader{&fasthttp.RequestHeader{}}
+	if err := plain.SetPseudoHeader(":scheme", "https"); err != ErrSchemeNotSupported {
+		t.Errorf("expected scheme not supported error, but got: %v", err)
+	}
+	plain.SetMethod("PUT")
+	var keys []string
+	plain.RangeHTTP2(func(key, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) == 0 || keys[0] != ":method" || keys[1] != ":path" {
+		t.Errorf("unexpected pseudo headers: %v", keys)
+	}
+}
+
+func TestResponseHeader_HTTP2(t *testing.T) {
+	header := NewResponseHeader(nil)
+	if err := header.SetHTTP2Header(":status", "404"); err != nil {
+		t.Fatal(err)
+	}
+	if err := header.SetHTTP2Header(":status", "abc"); err != ErrInvalidStatus {
+		t.Errorf("expected invalid status error, but got: %v", err)
+	}
+	if err := header.SetHTTP2Header(":path", "/"); err != ErrUnknownPseudoHeader {
+		t.Errorf("expected unknown pseudo header error, but got: %v", err)
+	}
+	header.SetHTTP2Header("x-trace", "1")
+	header.Set("Transfer-Encoding", "chunked")
+	if header.StatusCode() != NotFound {
+		t.Errorf("unexpected status code: %d", header.StatusCode())
+	}
+	var keys []string
+	header.RangeHTTP2(func(key, value string) bool {
+		keys = append(keys, key)
+		return key != ":status"
+	})
+	if !reflect.DeepEqual(keys, []string{":status"}) {
+		t.Errorf("range should be stopped, but got: %v", keys)
+	}
+	keys = keys[:0]
+	header.RangeHTTP2(func(key, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	for _, key := range keys {
+		if key == "transfer-encoding" {
+			t.Errorf("connection-specific header should be skipped: %v", keys)
+		}
+	}
+	if keys[0] != ":status" {
+		t.Errorf("status should be the first: %v", keys)
+	}
+}
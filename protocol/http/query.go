@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+
+	"github.com/valyala/fasthttp"
+)
+
+// QueryArgs is the query parameters of a RequestHeader.
+// The modifications are encoded back into the RequestURI of the header.
+type QueryArgs struct {
+	header RequestHeader
+	args   fasthttp.Args
+}
+
+// QueryArgs parses the query string of the RequestURI.
+func (h RequestHeader) QueryArgs() *QueryArgs {
+	q := &QueryArgs{header: h}
+	_, query, _ := splitRequestURI(h.RequestURI())
+	q.args.ParseBytes(query)
+	return q
+}
+
+// Get returns the first value of the key.
+func (q *QueryArgs) Get(key string) (string, bool) {
+	if !q.args.Has(key) {
+		return "", false
+	}
+	return string(q.args.Peek(key)), true
+}
+
+// GetAll returns all the values of the key.
+func (q *QueryArgs) GetAll(key string) []string {
+	values := q.args.PeekMulti(key)
+	if len(values) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(values))
+	for _, v := range values {
+		ret = append(ret, string(v))
+	}
+	return ret
+}
+
+// Set sets the key with the value and updates the RequestURI.
+func (q *QueryArgs) Set(key, value string) {
+	q.args.Set(key, value)
+	q.encode()
+}
+
+// Add adds the value for the key and updates the RequestURI.
+func (q *QueryArgs) Add(key, value string) {
+	q.args.Add(key, value)
+	q.encode()
+}
+
+// Del deletes the key and updates the RequestURI.
+func (q *QueryArgs) Del(key string) {
+	q.args.Del(key)
+	q.encode()
+}
+
+// Len returns the number of the query parameters.
+func (q *QueryArgs) Len() int {
+	return q.args.Len()
+}
+
+// Range calls f sequentially for each query parameter.
+// If f returns false, range stops the iteration.
+func (q *QueryArgs) Range(f func(key, value string) bool) {
+	stopped := false
+	q.args.VisitAll(func(key, value []byte) {
+		if stopped {
+			return
+		}
+		if !f(string(key), string(value)) {
+			stopped = true
+		}
+	})
+}
+
+// String returns the encoded query string.
+func (q *QueryArgs) String() string {
+	return q.args.String()
+}
+
+func (q *QueryArgs) encode() {
+	path, _, fragment := splitRequestURI(q.header.RequestURI())
+	uri := make([]byte, 0, len(path)+len(fragment)+64)
+	uri = append(uri, path...)
+	if q.args.Len() > 0 {
+		uri = append(uri, '?')
+		uri = q.args.AppendBytes(uri)
+	}
+	uri = append(uri, fragment...)
+	q.header.SetRequestURIBytes(uri)
+}
+
+// splitRequestURI splits the uri into path, query and fragment.
+// The fragment contains the leading '#'.
+func splitRequestURI(uri []byte) (path, query, fragment []byte) {
+	if i := bytes.IndexByte(uri, '#'); i >= 0 {
+		uri, fragment = uri[:i], uri[i:]
+	}
+	if i := bytes.IndexByte(uri, '?'); i >= 0 {
+		return uri[:i], uri[i+1:], fragment
+	}
+	return uri, nil, fragment
+}
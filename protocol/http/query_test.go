@@ -0,0 +1,70 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryArgs(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetRequestURI("/api/v1?user=mosn&tag=a&tag=b")
+	q := header.QueryArgs()
+	if v, ok := q.Get("user"); !ok || v != "mosn" {
+		t.Errorf("unexpected value: %s, %v", v, ok)
+	}
+	if _, ok := q.Get("none"); ok {
+		t.Error("key should not be found")
+	}
+	if v := q.GetAll("tag"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("unexpected values: %v", v)
+	}
+	q.Set("user", "new value")
+	q.Del("tag")
+	if uri := string(header.RequestURI()); uri != "/api/v1?user=new+value" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+	q.Add("id", "1")
+	var kvs []string
+	q.Range(func(key, value string) bool {
+		kvs = append(kvs, key+"="+value)
+		return true
+	})
+	if !reflect.DeepEqual(kvs, []string{"user=new value", "id=1"}) {
+		t.Errorf("unexpected args: %v", kvs)
+	}
+	q.Del("user")
+	q.Del("id")
+	if uri := string(header.RequestURI()); uri != "/api/v1" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+}
+
+func TestQueryArgsFragment(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetRequestURI("/index#top")
+	q := header.QueryArgs()
+	if q.Len() != 0 {
+		t.Errorf("unexpected args: %s", q.String())
+	}
+	q.Set("a", "1")
+	if uri := string(header.RequestURI()); uri != "/index?a=1#top" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+}
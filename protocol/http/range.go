@@ -0,0 +1,83 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+
+	"mosn.io/pkg/buffer"
+)
+
+// defaultHeaderBufferSize is the initial buffer size to serialize the headers
+const defaultHeaderBufferSize = 4096
+
+// RangeBytes calls f sequentially for each header line in the order they are written on the wire,
+// including the repeated keys, the cookies, and the headers special-cased by fasthttp,
+// such as Host, User-Agent, Content-Type and Content-Length.
+// If f returns false, range stops the iteration.
+// The key and value are only valid in f, copy them if you need to retain them.
+func (h RequestHeader) RangeBytes(f func(key, value []byte) bool) {
+	buf := buffer.GetBytes(defaultHeaderBufferSize)
+	defer buffer.PutBytes(buf)
+	rangeHeaderLines(h.AppendBytes((*buf)[:0]), f)
+}
+
+// RangeBytes calls f sequentially for each header line in the order they are written on the wire,
+// including the repeated keys, the Set-Cookie headers, and the headers special-cased by fasthttp,
+// such as Content-Type, Content-Length and Server.
+// If f returns false, range stops the iteration.
+// The key and value are only valid in f, copy them if you need to retain them.
+func (h ResponseHeader) RangeBytes(f func(key, value []byte) bool) {
+	buf := buffer.GetBytes(defaultHeaderBufferSize)
+	defer buffer.PutBytes(buf)
+	rangeHeaderLines(h.AppendBytes((*buf)[:0]), f)
+}
+
+var (
+	crlf  = []byte("\r\n")
+	colon = []byte(":")
+)
+
+// rangeHeaderLines calls f for each header line of the serialized header b,
+// the first line is the request line or the status line, which is skipped.
+func rangeHeaderLines(b []byte, f func(key, value []byte) bool) {
+	i := bytes.Index(b, crlf)
+	if i < 0 {
+		return
+	}
+	b = b[i+len(crlf):]
+	for len(b) > 0 {
+		var line []byte
+		if i = bytes.Index(b, crlf); i < 0 {
+			line, b = b, nil
+		} else {
+			line, b = b[:i], b[i+len(crlf):]
+		}
+		// the empty line ends the header
+		if len(line) == 0 {
+			return
+		}
+		i = bytes.Index(line, colon)
+		if i < 0 {
+			continue
+		}
+		if !f(line[:i], bytes.TrimLeft(line[i+1:], " \t")) {
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func bufioReader(s string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(s))
+}
+
+type headerLine struct {
+	key, value string
+}
+
+func collectLines(rangeBytes func(f func(key, value []byte) bool)) []headerLine {
+	lines := []headerLine{}
+	rangeBytes(func(key, value []byte) bool {
+		lines = append(lines, headerLine{string(key), string(value)})
+		return true
+	})
+	return lines
+}
+
+func TestRequestHeader_RangeBytes(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetHost("mosn.io")
+	header.Add("X-Multi", "one")
+	header.Add("X-Multi", "two")
+	header.Set("X-Empty", "")
+	header.SetCookie("session", "abc")
+
+	lines := collectLines(header.RangeBytes)
+	expected := []headerLine{
+		{"Host", "mosn.io"},
+		{"X-Multi", "one"},
+		{"X-Multi", "two"},
+		{"X-Empty", ""},
+		{"Cookie", "session=abc"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected lines %v, but got: %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d expected %v, but got: %v", i, expected[i], lines[i])
+		}
+	}
+
+	// stop the iteration
+	count := 0
+	header.RangeBytes(func(key, value []byte) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Errorf("range should stop after 2 lines, but got: %d", count)
+	}
+}
+
+func TestRequestHeader_RangeBytesParsed(t *testing.T) {
+	header := NewRequestHeader(nil)
+	raw := "GET / HTTP/1.1\r\nX-A: 1\r\nHost: mosn.io\r\nX-B: 2\r\nX-A: 3\r\n\r\n"
+	if err := header.Read(bufioReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	lines := collectLines(header.RangeBytes)
+	expected := []headerLine{
+		{"Host", "mosn.io"},
+		{"X-A", "1"},
+		{"X-B", "2"},
+		{"X-A", "3"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected lines %v, but got: %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d expected %v, but got: %v", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestResponseHeader_RangeBytes(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.SetContentType("application/json")
+	header.Add("Set-Cookie", "a=1")
+	header.Add("Set-Cookie", "b=2")
+	header.Add("X-Multi", "one")
+	header.Add("X-Multi", "two")
+
+	values := map[string][]string{}
+	header.RangeBytes(func(key, value []byte) bool {
+		values[string(key)] = append(values[string(key)], string(value))
+		return true
+	})
+	if len(values["Set-Cookie"]) != 2 || len(values["X-Multi"]) != 2 {
+		t.Errorf("repeated keys are not visited: %v", values)
+	}
+	if v := values["Content-Type"]; len(v) != 1 || v[0] != "application/json" {
+		t.Errorf("special header is not visited: %v", values)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"github.com/valyala/fasthttp"
+	"mosn.io/api"
+)
+
+// StatusClass is the class of a status code, defined by the first digit.
+type StatusClass int
+
+const (
+	StatusClassUnknown StatusClass = iota
+	StatusClassInformational
+	StatusClassSuccess
+	StatusClassRedirection
+	StatusClassClientError
+	StatusClassServerError
+)
+
+func (c StatusClass) String() string {
+	switch c {
+	case StatusClassInformational:
+		return "1xx"
+	case StatusClassSuccess:
+		return "2xx"
+	case StatusClassRedirection:
+		return "3xx"
+	case StatusClassClientError:
+		return "4xx"
+	case StatusClassServerError:
+		return "5xx"
+	}
+	return "unknown"
+}
+
+// ClassOf returns the class of the status code.
+func ClassOf(code int) StatusClass {
+	if code < 100 || code > 599 {
+		return StatusClassUnknown
+	}
+	return StatusClass(code / 100)
+}
+
+// SetStatus sets the status code, and resets the reason phrase to the default one.
+func (h ResponseHeader) SetStatus(code int) {
+	h.SetStatusCode(code)
+	h.SetStatusMessage(nil)
+}
+
+// Reason returns the reason phrase, the default one is returned if it is not set.
+func (h ResponseHeader) Reason() string {
+	if msg := h.StatusMessage(); len(msg) > 0 {
+		return string(msg)
+	}
+	return fasthttp.StatusMessage(h.StatusCode())
+}
+
+// SetReason sets a custom reason phrase.
+func (h ResponseHeader) SetReason(reason string) {
+	h.SetStatusMessage([]byte(reason))
+}
+
+// StatusClass returns the class of the status code.
+func (h ResponseHeader) StatusClass() StatusClass {
+	return ClassOf(h.StatusCode())
+}
+
+// IsSuccess returns true if the status code is 2xx.
+func (h ResponseHeader) IsSuccess() bool {
+	return h.StatusClass() == StatusClassSuccess
+}
+
+// IsRedirect returns true if the status code is 3xx.
+func (h ResponseHeader) IsRedirect() bool {
+	return h.StatusClass() == StatusClassRedirection
+}
+
+// IsClientError returns true if the status code is 4xx.
+func (h ResponseHeader) IsClientError() bool {
+	return h.StatusClass() == StatusClassClientError
+}
+
+// IsServerError returns true if the status code is 5xx.
+func (h ResponseHeader) IsServerError() bool {
+	return h.StatusClass() == StatusClassServerError
+}
+
+// IsError returns true if the status code is 4xx or 5xx.
+func (h ResponseHeader) IsError() bool {
+	return h.IsClientError() || h.IsServerError()
+}
+
+// StatusFromAPICode maps the status code defined in mosn.io/api to the http status code.
+func StatusFromAPICode(code int) int {
+	switch code {
+	case api.SuccessCode:
+		return OK
+	case api.CodecExceptionCode, api.DeserialExceptionCode:
+		return BadRequest
+	case api.PermissionDeniedCode:
+		return Forbidden
+	case api.RouterUnavailableCode:
+		return NotFound
+	case api.NoHealthUpstreamCode:
+		return BadGateway
+	case api.UpstreamOverFlowCode:
+		return ServiceUnavailable
+	case api.TimeoutExceptionCode:
+		return GatewayTimeout
+	case api.LimitExceededCode:
+		return TooManyRequests
+	}
+	return InternalServerError
+}
+
+// APICodeFromStatus maps the http status code to the status code defined in mosn.io/api.
+func APICodeFromStatus(status int) int {
+	switch status {
+	case BadRequest:
+		return api.CodecExceptionCode
+	case Forbidden:
+		return api.PermissionDeniedCode
+	case NotFound:
+		return api.RouterUnavailableCode
+	case BadGateway:
+		return api.NoHealthUpstreamCode
+	case ServiceUnavailable:
+		return api.UpstreamOverFlowCode
+	case GatewayTimeout:
+		return api.TimeoutExceptionCode
+	case TooManyRequests:
+		return api.LimitExceededCode
+	}
+	switch ClassOf(status) {
+	case StatusClassSuccess:
+		return api.SuccessCode
+	case StatusClassServerError:
+		return api.InternalErrorCode
+	}
+	return api.UnknownCode
+}
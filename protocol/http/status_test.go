@@ -0,0 +1,80 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+
+	"mosn.io/api"
+)
+
+func TestResponseHeader_Status(t *testing.T) {
+	header := NewResponseHeader(nil)
+	if !header.IsSuccess() || header.Reason() != "OK" {
+		t.Errorf("unexpected default status: %d %s", header.StatusCode(), header.Reason())
+	}
+	header.SetStatus(MovedPermanently)
+	if !header.IsRedirect() || header.IsError() || header.StatusClass().String() != "3xx" {
+		t.Errorf("unexpected status class: %s", header.StatusClass())
+	}
+	header.SetReason("Gone Away")
+	if header.Reason() != "Gone Away" {
+		t.Errorf("unexpected reason: %s", header.Reason())
+	}
+	header.SetStatus(NotFound)
+	if header.Reason() != "Not Found" {
+		t.Errorf("reason should be reset, but got: %s", header.Reason())
+	}
+	if !header.IsClientError() || !header.IsError() || header.IsServerError() {
+		t.Error("404 should be client error")
+	}
+	header.SetStatus(BadGateway)
+	if !header.IsServerError() || !header.IsError() {
+		t.Error("502 should be server error")
+	}
+	if ClassOf(99) != StatusClassUnknown || ClassOf(600) != StatusClassUnknown || ClassOf(100) != StatusClassInformational {
+		t.Error("unexpected class")
+	}
+}
+
+func TestAPICodeMapping(t *testing.T) {
+	for _, code := range []int{
+		api.SuccessCode,
+		api.PermissionDeniedCode,
+		api.RouterUnavailableCode,
+		api.NoHealthUpstreamCode,
+		api.UpstreamOverFlowCode,
+		api.TimeoutExceptionCode,
+		api.LimitExceededCode,
+		api.CodecExceptionCode,
+	} {
+		if got := APICodeFromStatus(StatusFromAPICode(code)); got != code {
+			t.Errorf("api code %d is mapped back to %d", code, got)
+		}
+	}
+	if StatusFromAPICode(api.UnknownCode) != InternalServerError {
+		t.Error("unknown code should be mapped to 500")
+	}
+	if APICodeFromStatus(Created) != api.SuccessCode || APICodeFromStatus(NotImplemented) != api.InternalErrorCode || APICodeFromStatus(Found) != api.UnknownCode {
+		t.Error("unexpected api code")
+	}
+	// 509 is a server error status, not the api limit exceeded code
+	if APICodeFromStatus(509) != api.InternalErrorCode {
+		t.Error("509 should be mapped to internal error")
+	}
+}
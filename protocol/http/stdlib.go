@@ -0,0 +1,43 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	nethttp "net/http"
+)
+
+// ToHTTPHeader converts the header into a net/http.Header.
+// The keys are already canonical in fasthttp, so the multi-map is used directly.
+func (h RequestHeader) ToHTTPHeader() nethttp.Header {
+	return nethttp.Header(h.ToMultiMap())
+}
+
+// ToHTTPHeader converts the header into a net/http.Header.
+func (h ResponseHeader) ToHTTPHeader() nethttp.Header {
+	return nethttp.Header(h.ToMultiMap())
+}
+
+// NewRequestHeaderFromHTTP returns a RequestHeader contains all the values of the net/http.Header.
+func NewRequestHeaderFromHTTP(header nethttp.Header) RequestHeader {
+	return NewRequestHeaderFromMultiMap(header)
+}
+
+// NewResponseHeaderFromHTTP returns a ResponseHeader contains all the values of the net/http.Header.
+func NewResponseHeaderFromHTTP(header nethttp.Header) ResponseHeader {
+	return NewResponseHeaderFromMultiMap(header)
+}
@@ -0,0 +1,57 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHTTPHeaderConvert(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.Set("x-request-id", "1")
+	header.Add("x-multi", "a")
+	header.Add("x-multi", "b")
+	std := header.ToHTTPHeader()
+	if std.Get("X-Request-Id") != "1" || !reflect.DeepEqual(std.Values("X-Multi"), []string{"a", "b"}) {
+		t.Fatalf("unexpected net/http header: %v", std)
+	}
+	back := NewRequestHeaderFromHTTP(std)
+	if !reflect.DeepEqual(back.ToHTTPHeader(), std) {
+		t.Errorf("expected %v, but got: %v", std, back.ToHTTPHeader())
+	}
+}
+
+func TestHTTPHeaderConvertResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Add("Set-Cookie", "a=1")
+	rec.Header().Add("Set-Cookie", "b=2")
+	rec.Header().Set("Content-Type", "application/json")
+	rec.WriteHeader(nethttp.StatusOK)
+
+	header := NewResponseHeaderFromHTTP(rec.Result().Header)
+	if string(header.ContentType()) != "application/json" {
+		t.Errorf("unexpected content type: %s", header.ContentType())
+	}
+	std := header.ToHTTPHeader()
+	if !reflect.DeepEqual(std.Values("Set-Cookie"), []string{"a=1", "b=2"}) {
+		t.Errorf("unexpected cookies: %v", std.Values("Set-Cookie"))
+	}
+}
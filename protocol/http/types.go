@@ -93,6 +93,14 @@ type RequestHeader struct {
 	*fasthttp.RequestHeader
 }
 
+// NewRequestHeader wraps h as a RequestHeader, h is allocated if it is nil.
+func NewRequestHeader(h *fasthttp.RequestHeader) RequestHeader {
+	if h == nil {
+		h = &fasthttp.RequestHeader{}
+	}
+	return RequestHeader{h}
+}
+
 // Get value of key
 func (h RequestHeader) Get(key string) (string, bool) {
 	if result := h.Peek(key); result != nil {
@@ -146,10 +154,9 @@ func (h RequestHeader) Range(f func(key, value string) bool) {
 	})
 }
 
+// Clone returns a deep copy of the header
 func (h RequestHeader) Clone() api.HeaderMap {
-	cpy := &fasthttp.RequestHeader{}
-	h.CopyTo(cpy)
-	return RequestHeader{cpy}
+	return h.CloneHeader()
 }
 
 func (h RequestHeader) ByteSize() (size uint64) {
@@ -163,6 +170,14 @@ type ResponseHeader struct {
 	*fasthttp.ResponseHeader
 }
 
+// NewResponseHeader wraps h as a ResponseHeader, h is allocated if it is nil.
+func NewResponseHeader(h *fasthttp.ResponseHeader) ResponseHeader {
+	if h == nil {
+		h = &fasthttp.ResponseHeader{}
+	}
+	return ResponseHeader{h}
+}
+
 // Get value of key
 func (h ResponseHeader) Get(key string) (string, bool) {
 	if result := h.Peek(key); result != nil {
@@ -215,10 +230,9 @@ func (h ResponseHeader) Range(f func(key, value string) bool) {
 	})
 }
 
+// Clone returns a deep copy of the header
 func (h ResponseHeader) Clone() api.HeaderMap {
-	cpy := &fasthttp.ResponseHeader{}
-	h.CopyTo(cpy)
-	return ResponseHeader{cpy}
+	return h.CloneHeader()
 }
 
 func (h ResponseHeader) ByteSize() (size uint64) {
@@ -0,0 +1,176 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bytes"
+	"errors"
+
+	"mosn.io/pkg/buffer"
+)
+
+var (
+	ErrInvalidPercentEncoding = errors.New("invalid percent encoding in path")
+	ErrUnsafePathChar         = errors.New("unsafe character in path")
+)
+
+// UnsafeCharPolicy decides how to handle the control characters (include the decoded ones) in path.
+type UnsafeCharPolicy int
+
+const (
+	UnsafeCharReject UnsafeCharPolicy = iota
+	UnsafeCharAllow
+)
+
+// PathNormalizeOptions configures the path normalization.
+type PathNormalizeOptions struct {
+	// DecodePercent decodes the percent-encoded characters, except
+	// %2F ('/'), %5C ('\') and %25 ('%') which change the meaning of the path,
+	// they are kept encoded with upper-case hex digits.
+	DecodePercent bool
+	// RemoveDotSegments removes the "." and ".." segments, see RFC 3986 Section 5.2.4
+	RemoveDotSegments bool
+	// CollapseSlashes merges the duplicate slashes into one.
+	CollapseSlashes bool
+	// BackslashAsSlash treats '\' as '/'.
+	BackslashAsSlash bool
+	UnsafeChars      UnsafeCharPolicy
+}
+
+// DefaultPathNormalizeOptions enables all the normalizations and rejects the unsafe characters.
+var DefaultPathNormalizeOptions = PathNormalizeOptions{
+	DecodePercent:     true,
+	RemoveDotSegments: true,
+	CollapseSlashes:   true,
+	BackslashAsSlash:  true,
+	UnsafeChars:       UnsafeCharReject,
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// NormalizePath normalizes the path in place and returns the normalized slice of it,
+// no memory is allocated. The path should not contain the query string.
+func NormalizePath(path []byte, opts PathNormalizeOptions) ([]byte, error) {
+	w := 0
+	for r := 0; r < len(path); r++ {
+		c := path[r]
+		if c == '%' && opts.DecodePercent {
+			if r+2 >= len(path) {
+				return nil, ErrInvalidPercentEncoding
+			}
+			hi, ok1 := unhex(path[r+1])
+			lo, ok2 := unhex(path[r+2])
+			if !ok1 || !ok2 {
+				return nil, ErrInvalidPercentEncoding
+			}
+			c = hi<<4 | lo
+			r += 2
+			if c == '/' || c == '\\' || c == '%' {
+				path[w], path[w+1], path[w+2] = '%', upperHex[hi], upperHex[lo]
+				w += 3
+				continue
+			}
+		} else if c == '\\' && opts.BackslashAsSlash {
+			c = '/'
+		}
+		if (c < 0x20 || c == 0x7f) && opts.UnsafeChars == UnsafeCharReject {
+			return nil, ErrUnsafePathChar
+		}
+		path[w] = c
+		w++
+	}
+	path = path[:w]
+	if !opts.RemoveDotSegments && !opts.CollapseSlashes {
+		return path, nil
+	}
+	return normalizeSegments(path, opts.RemoveDotSegments, opts.CollapseSlashes), nil
+}
+
+// normalizeSegments rewrites the segments in place, the written part is never longer than the read part.
+func normalizeSegments(path []byte, dots, collapse bool) []byte {
+	w, i := 0, 0
+	if len(path) > 0 && path[0] == '/' {
+		w, i = 1, 1
+	}
+	base := w
+	for i <= len(path) {
+		j := i + bytes.IndexByte(path[i:], '/')
+		last := j < i
+		if last {
+			j = len(path)
+		}
+		seg := path[i:j]
+		i = j + 1
+		switch {
+		case len(seg) == 0:
+			if !last && !collapse {
+				path[w] = '/'
+				w++
+			}
+		case dots && len(seg) == 1 && seg[0] == '.':
+		case dots && len(seg) == 2 && seg[0] == '.' && seg[1] == '.':
+			if w > base {
+				w = bytes.LastIndexByte(path[:w-1], '/') + 1
+				if w < base {
+					w = base
+				}
+			}
+		default:
+			w += copy(path[w:], seg)
+			if !last {
+				path[w] = '/'
+				w++
+			}
+		}
+		if last {
+			break
+		}
+	}
+	return path[:w]
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// NormalizePath normalizes the path of the RequestURI, the query string and fragment are kept.
+func (h RequestHeader) NormalizePath(opts PathNormalizeOptions) error {
+	path, query, fragment := splitRequestURI(h.RequestURI())
+	bufPtr := buffer.GetBytes(len(path) + len(query) + len(fragment) + 1)
+	defer buffer.PutBytes(bufPtr)
+	buf := append((*bufPtr)[:0], path...)
+	normalized, err := NormalizePath(buf, opts)
+	if err != nil {
+		return err
+	}
+	if query != nil {
+		normalized = append(normalized, '?')
+		normalized = append(normalized, query...)
+	}
+	normalized = append(normalized, fragment...)
+	h.SetRequestURIBytes(normalized)
+	return nil
+}
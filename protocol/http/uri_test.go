@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	for i, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{"", ""},
+		{"/", "/"},
+		{"/a/b/c", "/a/b/c"},
+		{"/a//b///c/", "/a/b/c/"},
+		{"/a/./b/../c", "/a/c"},
+		{"/a/b/..", "/a/"},
+		{"/a/.", "/a/"},
+		{"/../../etc/passwd", "/etc/passwd"},
+		{"/a/%2e%2E/b", "/b"},
+		{"/a%2fb/%5c/%25", "/a%2Fb/%5C/%25"},
+		{"/%61%62c", "/abc"},
+		{"/a\\..\\b", "/b"},
+		{"a/../../b", "b"},
+		{"./a/b", "a/b"},
+	} {
+		got, err := NormalizePath([]byte(tc.path), DefaultPathNormalizeOptions)
+		if err != nil {
+			t.Errorf("#%d normalize %s failed: %v", i, tc.path, err)
+			continue
+		}
+		if string(got) != tc.expected {
+			t.Errorf("#%d normalize %s expected %s, but got: %s", i, tc.path, tc.expected, got)
+		}
+	}
+}
+
+func TestNormalizePathOptions(t *testing.T) {
+	for i, tc := range []struct {
+		path     string
+		opts     PathNormalizeOptions
+		expected string
+		err      error
+	}{
+		{"/a//b/../c", PathNormalizeOptions{RemoveDotSegments: true}, "/a//c", nil},
+		{"/a//b/../c", PathNormalizeOptions{CollapseSlashes: true}, "/a/b/../c", nil},
+		{"/a%2e", PathNormalizeOptions{}, "/a%2e", nil},
+		{"/a\\b", PathNormalizeOptions{}, "/a\\b", nil},
+		{"/a%0d%0a", PathNormalizeOptions{DecodePercent: true}, "", ErrUnsafePathChar},
+		{"/a%00", PathNormalizeOptions{DecodePercent: true, UnsafeChars: UnsafeCharAllow}, "/a\x00", nil},
+		{"/a%zz", PathNormalizeOptions{DecodePercent: true}, "", ErrInvalidPercentEncoding},
+		{"/a%2", PathNormalizeOptions{DecodePercent: true}, "", ErrInvalidPercentEncoding},
+	} {
+		got, err := NormalizePath([]byte(tc.path), tc.opts)
+		if err != tc.err {
+			t.Errorf("#%d expected error %v, but got: %v", i, tc.err, err)
+			continue
+		}
+		if string(got) != tc.expected {
+			t.Errorf("#%d expected %q, but got: %q", i, tc.expected, got)
+		}
+	}
+}
+
+func TestRequestHeader_NormalizePath(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetRequestURI("/api//v1/../v2/%75sers?next=/a/../b#top")
+	if err := header.NormalizePath(DefaultPathNormalizeOptions); err != nil {
+		t.Fatal(err)
+	}
+	if uri := string(header.RequestURI()); uri != "/api/v2/users?next=/a/../b#top" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+	header.SetRequestURI("/a%00")
+	if err := header.NormalizePath(DefaultPathNormalizeOptions); err != ErrUnsafePathChar {
+		t.Errorf("expected unsafe char error, but got: %v", err)
+	}
+	if uri := string(header.RequestURI()); uri != "/a%00" {
+		t.Errorf("uri should not be changed, but got: %s", uri)
+	}
+}
+
+func BenchmarkNormalizePath(b *testing.B) {
+	src := []byte("/api//v1/./users/../%75sers/%2e%2e/list")
+	path := make([]byte, len(src))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(path, src)
+		NormalizePath(path, DefaultPathNormalizeOptions)
+	}
+}
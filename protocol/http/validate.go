@@ -0,0 +1,68 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidHeaderName  = errors.New("invalid header name")
+	ErrInvalidHeaderValue = errors.New("invalid header value")
+)
+
+// HeaderFieldError is returned when a header contains illegal bytes.
+// Use errors.Is to check whether the name or value is invalid.
+type HeaderFieldError struct {
+	Err error
+	Key string
+	// Pos is the position of the illegal byte.
+	Pos int
+}
+
+func (e *HeaderFieldError) Error() string {
+	return fmt.Sprintf("%s: key %q, position %d", e.Err, e.Key, e.Pos)
+}
+
+func (e *HeaderFieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateHeaderField checks the header name and value, CR, LF and NUL are not allowed,
+// and the name can not be empty or contain colon and whitespace.
+// It is enforced by Set and Add of the checked header unless it is created with WithoutHeaderValidation,
+// as the illegal bytes may cause request smuggling or response splitting.
+func ValidateHeaderField(key, value string) error {
+	if key == "" {
+		return &HeaderFieldError{Err: ErrInvalidHeaderName}
+	}
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '\r', '\n', 0, ':', ' ', '\t':
+			return &HeaderFieldError{Err: ErrInvalidHeaderName, Key: key, Pos: i}
+		}
+	}
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\r', '\n', 0:
+			return &HeaderFieldError{Err: ErrInvalidHeaderValue, Key: key, Pos: i}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateHeaderField(t *testing.T) {
+	for i, tc := range []struct {
+		key   string
+		value string
+		err   error
+	}{
+		{"X-Normal", "value with spaces", nil},
+		{"X-Empty", "", nil},
+		{"", "v", ErrInvalidHeaderName},
+		{"X-Bad\r\nInjected", "v", ErrInvalidHeaderName},
+		{"X-Bad:", "v", ErrInvalidHeaderName},
+		{"X Bad", "v", ErrInvalidHeaderName},
+		{"X-Value", "a\r\nSet-Cookie: evil=1", ErrInvalidHeaderValue},
+		{"X-Value", "a\nb", ErrInvalidHeaderValue},
+		{"X-Value", "a\x00b", ErrInvalidHeaderValue},
+	} {
+		if err := ValidateHeaderField(tc.key, tc.value); !errors.Is(err, tc.err) {
+			t.Errorf("#%d expected %v, but got: %v", i, tc.err, err)
+		}
+	}
+	var fieldErr *HeaderFieldError
+	if err := ValidateHeaderField("X-Value", "ab\rc"); !errors.As(err, &fieldErr) || fieldErr.Pos != 2 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHeaderValidation(t *testing.T) {
+	req := NewCheckedRequestHeader(nil)
+	req.Set("X-A", "1\r\nX-B: 2")
+	if err := req.Err(); !errors.Is(err, ErrInvalidHeaderValue) {
+		t.Errorf("expected invalid value, but got: %v", err)
+	}
+	if _, ok := req.Get("X-A"); ok {
+		t.Error("invalid header should not be set")
+	}
+	req.Add("X-A", "1")
+	if v, ok := req.Get("X-A"); !ok || v != "1" || req.Err() != nil {
+		t.Errorf("add failed: %v", req.Err())
+	}
+
+	resp := NewCheckedResponseHeader(nil)
+	resp.Add("X-A\n", "1")
+	if err := resp.Err(); !errors.Is(err, ErrInvalidHeaderName) {
+		t.Errorf("expected invalid name, but got: %v", err)
+	}
+	// the unchecked header is not validated
+	unchecked := ResponseHeader{resp.ResponseHeader.ResponseHeader}
+	unchecked.Set("X-B", "a\x00b")
+	if v, ok := unchecked.Get("X-B"); !ok || v != "a\x00b" {
+		t.Errorf("unchecked header should be set, but got: %q", v)
+	}
+
+	resp = NewCheckedResponseHeader(nil, WithoutHeaderValidation())
+	resp.Set("X-B", "a\x00b")
+	if _, ok := resp.Get("X-B"); !ok || resp.Err() != nil {
+		t.Errorf("validation should be skipped, but got: %v", resp.Err())
+	}
+	// the option is kept by the clone
+	cpy := resp.CloneHeader()
+	cpy.Add("X-C", "\r")
+	if _, ok := cpy.Get("X-C"); !ok {
+		t.Error("validation should be skipped by the clone")
+	}
+}
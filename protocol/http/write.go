@@ -0,0 +1,70 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"io"
+
+	"mosn.io/api"
+	"mosn.io/pkg/buffer"
+)
+
+// WriteRequestTo writes the request line and headers in wire format into buf,
+// and then the body if it is not nil. The headers are serialized with the pooled bytes
+// instead of a string, so no big temporary string is allocated.
+func (h RequestHeader) WriteRequestTo(buf api.IoBuffer, body io.Reader) error {
+	b := buffer.GetBytes(defaultHeaderBufferSize)
+	defer buffer.PutBytes(b)
+	*b = h.AppendBytes((*b)[:0])
+	return writeMessage(buf, *b, body)
+}
+
+// WriteResponseTo writes the status line and headers in wire format into buf,
+// and then the body if it is not nil.
+func (h ResponseHeader) WriteResponseTo(buf api.IoBuffer, body io.Reader) error {
+	b := buffer.GetBytes(defaultHeaderBufferSize)
+	defer buffer.PutBytes(b)
+	*b = h.AppendBytes((*b)[:0])
+	return writeMessage(buf, *b, body)
+}
+
+func writeMessage(buf api.IoBuffer, header []byte, body io.Reader) error {
+	if _, err := buf.Write(header); err != nil {
+		return err
+	}
+	if body == nil {
+		return nil
+	}
+	// IoBuffer.ReadFrom ignores the error if nothing is read, so copy it here
+	chunk := buffer.GetBytes(defaultHeaderBufferSize)
+	defer buffer.PutBytes(chunk)
+	for {
+		n, err := body.Read(*chunk)
+		if n > 0 {
+			if _, werr := buf.Write((*chunk)[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"mosn.io/pkg/buffer"
+)
+
+func TestRequestHeader_WriteRequestTo(t *testing.T) {
+	header := NewRequestHeader(nil)
+	header.SetMethod("POST")
+	header.SetRequestURI("/api")
+	header.SetHost("mosn.io")
+	header.SetContentLength(5)
+	header.Set("X-Trace", "1")
+
+	buf := buffer.NewIoBuffer(64)
+	if err := header.WriteRequestTo(buf, strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "\r\n\r\nhello") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+	// the output can be parsed back
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	if err := req.Read(bufio.NewReader(bytes.NewReader(buf.Bytes()))); err != nil {
+		t.Fatal(err)
+	}
+	if string(req.Header.Peek("X-Trace")) != "1" || string(req.Body()) != "hello" || string(req.Host()) != "mosn.io" {
+		t.Errorf("unexpected request: %s", req.String())
+	}
+}
+
+func TestResponseHeader_WriteResponseTo(t *testing.T) {
+	header := NewResponseHeader(nil)
+	header.SetStatusCode(NotFound)
+	header.Add("Set-Cookie", "a=1")
+	buf := buffer.NewIoBuffer(16)
+	if err := header.WriteResponseTo(buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != header.String() {
+		t.Errorf("expected %q, but got: %q", header.String(), buf.String())
+	}
+	if err := header.WriteResponseTo(buffer.NewIoBuffer(16), errReader{}); err != io.ErrClosedPipe {
+		t.Errorf("expected body error, but got: %v", err)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func BenchmarkRequestHeader_WriteRequestTo(b *testing.B) {
+	header := NewRequestHeader(nil)
+	header.SetRequestURI("/api")
+	header.SetHost("mosn.io")
+	for i := 0; i < 20; i++ {
+		header.Add("X-Header", strings.Repeat("v", 32))
+	}
+	buf := buffer.NewIoBuffer(4096)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		header.WriteRequestTo(buf, nil)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime"
+	"sync"
+
+	"go.uber.org/atomic"
+	"mosn.io/pkg/metrics"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+// NotifyPolicy decides what the async dispatch does with an event when the queue is full
+type NotifyPolicy int
+
+const (
+	// NotifyBlock waits for the queue, no event is lost but the registry stalls with a slow listener
+	NotifyBlock NotifyPolicy = iota
+	// NotifyDrop drops the new event
+	NotifyDrop
+	// NotifyMerge replaces the queued event of the same service instance with the new one, which is
+	// the latest state of the instance, and waits for the queue if there is none
+	NotifyMerge
+)
+
+// the counters of all the AsyncNotifyRegistry reported to the global sink of the metrics package
+var (
+	notifyDroppedCounter = metrics.NewCounter("registry.notify.dropped")
+	notifyMergedCounter  = metrics.NewCounter("registry.notify.merged")
+)
+
+// the defaults of AsyncNotifyOptions
+const (
+	DefaultNotifyQueueSize = 256
+)
+
+// AsyncNotifyOptions are the options of the worker pool dispatching the events
+type AsyncNotifyOptions struct {
+	// Workers is the number of the goroutines notifying the listeners, runtime.GOMAXPROCS(0) if it is not positive
+	Workers int
+	// QueueSize is the capacity of the queue of every worker, DefaultNotifyQueueSize if it is not positive
+	QueueSize int
+	// Policy applies when the queue is full, except that NotifyMerge always merges the queued event
+	Policy NotifyPolicy
+}
+
+// AsyncNotifyRegistry notifies the events of the registry to the listeners in a worker pool, so a slow listener
+// doesn't stall the consumption of the events. The events of a service are notified by the same worker in order.
+type AsyncNotifyRegistry struct {
+	Registry
+	pool *notifyPool
+
+	lock      sync.Mutex
+	listeners map[subscriptionKey]*asyncNotifyListener
+	seq       uint64
+}
+
+// NewAsyncNotifyRegistry wraps the registry with the worker pool
+func NewAsyncNotifyRegistry(registry Registry, options AsyncNotifyOptions) *AsyncNotifyRegistry {
+	return &AsyncNotifyRegistry{
+		Registry:  registry,
+		pool:      newNotifyPool(options),
+		listeners: make(map[subscriptionKey]*asyncNotifyListener),
+	}
+}
+
+// Subscribe subscribes the url from the registry, the events are notified in the worker pool
+func (r *AsyncNotifyRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	key, err := newSubscriptionKey(url, notifyListener)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	r.seq++
+	l := &asyncNotifyListener{id: r.seq, pool: r.pool, listener: notifyListener}
+	r.listeners[key] = l
+	r.lock.Unlock()
+	return r.Registry.Subscribe(url, l)
+}
+
+// UnSubscribe unsubscribes the url, the queued events are still notified
+func (r *AsyncNotifyRegistry) UnSubscribe(url *common.URL, notifyListener NotifyListener) error {
+	key, err := newSubscriptionKey(url, notifyListener)
+	if err != nil {
+		return r.Registry.UnSubscribe(url, notifyListener)
+	}
+	r.lock.Lock()
+	l, ok := r.listeners[key]
+	delete(r.listeners, key)
+	r.lock.Unlock()
+	if !ok {
+		return r.Registry.UnSubscribe(url, notifyListener)
+	}
+	return r.Registry.UnSubscribe(url, l)
+}
+
+// Dropped returns the number of the events dropped by NotifyDrop
+func (r *AsyncNotifyRegistry) Dropped() uint64 {
+	return r.pool.dropped.Load()
+}
+
+// Merged returns the number of the queued events replaced by NotifyMerge
+func (r *AsyncNotifyRegistry) Merged() uint64 {
+	return r.pool.merged.Load()
+}
+
+// Destroy destroys the registry and stops the worker pool, the queued events are dropped
+func (r *AsyncNotifyRegistry) Destroy() {
+	r.Registry.Destroy()
+	r.pool.close()
+}
+
+// Close closes the registry and stops the worker pool, the queued events are dropped
+func (r *AsyncNotifyRegistry) Close(ctx context.Context) error {
+	err := r.Registry.Close(ctx)
+	r.pool.close()
+	return err
+}
+
+type asyncNotifyListener struct {
+	id       uint64
+	pool     *notifyPool
+	listener NotifyListener
+}
+
+func (l *asyncNotifyListener) Notify(event *ServiceEvent) {
+	l.pool.dispatch(l, event)
+}
+
+// notifyTask is a queued event, key identifies the service instance of the listener for merging
+type notifyTask struct {
+	key      notifyKey
+	listener NotifyListener
+	event    *ServiceEvent
+}
+
+type notifyKey struct {
+	listener uint64
+	instance string
+}
+
+type notifyPool struct {
+	workers []*notifyWorker
+	policy  NotifyPolicy
+	wg      sync.WaitGroup
+	once    sync.Once
+	dropped atomic.Uint64
+	merged  atomic.Uint64
+}
+
+func newNotifyPool(options AsyncNotifyOptions) *notifyPool {
+	if options.Workers <= 0 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultNotifyQueueSize
+	}
+	p := &notifyPool{
+		workers: make([]*notifyWorker, options.Workers),
+		policy:  options.Policy,
+	}
+	for i := range p.workers {
+		w := &notifyWorker{size: options.QueueSize, pending: make(map[notifyKey]struct{})}
+		w.notEmpty = sync.NewCond(&w.lock)
+		w.notFull = sync.NewCond(&w.lock)
+		p.workers[i] = w
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			w.run()
+		}()
+	}
+	return p
+}
+
+// dispatch queues the event to the worker of its service
+func (p *notifyPool) dispatch(l *asyncNotifyListener, event *ServiceEvent) {
+	h := fnv.New32a()
+	h.Write([]byte(event.Service.ServiceKey()))
+	w := p.workers[h.Sum32()%uint32(len(p.workers))]
+	task := notifyTask{
+		key:      notifyKey{listener: l.id, instance: event.Service.Key()},
+		listener: l.listener,
+		event:    event,
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if p.policy == NotifyMerge && w.remove(task.key) {
+		p.merged.Inc()
+		notifyMergedCounter.Inc()
+	}
+	for len(w.queue) >= w.size && !w.closed {
+		if p.policy == NotifyDrop {
+			p.dropped.Inc()
+			notifyDroppedCounter.Inc()
+			logger.Warnf("the notify queue is full, drop the event %s", event.String())
+			return
+		}
+		w.notFull.Wait()
+	}
+	if w.closed {
+		return
+	}
+	w.queue = append(w.queue, task)
+	w.pending[task.key] = struct{}{}
+	w.notEmpty.Signal()
+}
+
+func (p *notifyPool) close() {
+	p.once.Do(func() {
+		for _, w := range p.workers {
+			w.lock.Lock()
+			w.closed = true
+			w.queue = nil
+			w.notEmpty.Broadcast()
+			w.notFull.Broadcast()
+			w.lock.Unlock()
+		}
+		p.wg.Wait()
+	})
+}
+
+type notifyWorker struct {
+	lock     sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	size     int
+	queue    []notifyTask
+	pending  map[notifyKey]struct{} // the keys in the queue
+	closed   bool
+}
+
+// remove removes the queued task of the key, the order of the others is kept
+func (w *notifyWorker) remove(key notifyKey) bool {
+	if _, ok := w.pending[key]; !ok {
+		return false
+	}
+	for i, task := range w.queue {
+		if task.key == key {
+			w.queue = append(w.queue[:i], w.queue[i+1:]...)
+			break
+		}
+	}
+	delete(w.pending, key)
+	return true
+}
+
+func (w *notifyWorker) run() {
+	for {
+		w.lock.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+		if w.closed {
+			w.lock.Unlock()
+			return
+		}
+		task := w.queue[0]
+		w.queue = w.queue[1:]
+		delete(w.pending, task.key)
+		w.notFull.Signal()
+		w.lock.Unlock()
+		w.notify(task)
+	}
+}
+
+func (w *notifyWorker) notify(task notifyTask) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("notify the event %s = panic{%v}", task.event.String(), r)
+		}
+	}()
+	task.listener.Notify(task.event)
+}
@@ -0,0 +1,187 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/metrics"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+// gatedNotifyListener blocks the first event until the gate is closed
+type gatedNotifyListener struct {
+	started chan struct{}
+	gate    chan struct{}
+	events  chan *ServiceEvent
+}
+
+func newGatedNotifyListener() *gatedNotifyListener {
+	return &gatedNotifyListener{
+		started: make(chan struct{}),
+		gate:    make(chan struct{}),
+		events:  make(chan *ServiceEvent, 128),
+	}
+}
+
+func (l *gatedNotifyListener) Notify(event *ServiceEvent) {
+	select {
+	case <-l.started:
+	default:
+		close(l.started)
+		<-l.gate
+	}
+	l.events <- event
+}
+
+func (l *gatedNotifyListener) next(t *testing.T) *ServiceEvent {
+	select {
+	case event := <-l.events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event is notified")
+		return nil
+	}
+}
+
+func newInstanceEvent(action remoting.EventType, port int) *ServiceEvent {
+	event := &ServiceEvent{Action: action}
+	event.Service, _ = common.NewURL(fmt.Sprintf("dubbo://127.0.0.1:%d/com.ikurento.user.A?interface=com.ikurento.user.A", port))
+	return event
+}
+
+func newTestAsyncListener(options AsyncNotifyOptions, listener NotifyListener) (*notifyPool, *asyncNotifyListener) {
+	pool := newNotifyPool(options)
+	return pool, &asyncNotifyListener{id: 1, pool: pool, listener: listener}
+}
+
+func TestAsyncNotifyOrder(t *testing.T) {
+	listener := newGatedNotifyListener()
+	close(listener.started)
+	pool, l := newTestAsyncListener(AsyncNotifyOptions{Workers: 4}, listener)
+	defer pool.close()
+	for i := 0; i < 100; i++ {
+		l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20000+i))
+	}
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, fmt.Sprint(20000+i), listener.next(t).Service.Port)
+	}
+}
+
+func TestAsyncNotifyMerge(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
+	listener := newGatedNotifyListener()
+	pool, l := newTestAsyncListener(AsyncNotifyOptions{Workers: 1, QueueSize: 2, Policy: NotifyMerge}, listener)
+	defer pool.close()
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20000))
+	<-listener.started
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20001))
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20002))
+	// the queue is full, but the event of 20001 is merged
+	l.Notify(newInstanceEvent(remoting.EventTypeDel, 20001))
+	assert.Equal(t, uint64(1), pool.merged.Load())
+	assert.Equal(t, int64(1), sink.Snapshot().Counters["registry.notify.merged"])
+	close(listener.gate)
+
+	assert.Equal(t, "20000", listener.next(t).Service.Port)
+	assert.Equal(t, "20002", listener.next(t).Service.Port)
+	event := listener.next(t)
+	assert.Equal(t, "20001", event.Service.Port)
+	assert.EqualValues(t, remoting.EventTypeDel, event.Action)
+}
+
+func TestAsyncNotifyDrop(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
+	listener := newGatedNotifyListener()
+	pool, l := newTestAsyncListener(AsyncNotifyOptions{Workers: 1, QueueSize: 1, Policy: NotifyDrop}, listener)
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20000))
+	<-listener.started
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20001))
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20002))
+	assert.Equal(t, uint64(1), pool.dropped.Load())
+	assert.Equal(t, int64(1), sink.Snapshot().Counters["registry.notify.dropped"])
+	close(listener.gate)
+	assert.Equal(t, "20000", listener.next(t).Service.Port)
+	assert.Equal(t, "20001", listener.next(t).Service.Port)
+
+	// the events are ignored after closed
+	pool.close()
+	l.Notify(newInstanceEvent(remoting.EventTypeAdd, 20003))
+	assert.Len(t, listener.events, 0)
+}
+
+func TestAsyncNotifyRegistry(t *testing.T) {
+	mock := newTestMockRegistry(t)
+	r := NewAsyncNotifyRegistry(mock, AsyncNotifyOptions{Workers: 1})
+	defer r.Destroy()
+	listener := newGatedNotifyListener()
+	url, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.A?interface=com.ikurento.user.A")
+	assert.NoError(t, r.Subscribe(&url, listener))
+
+	// the slow listener doesn't stall the registry
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			mock.MockEvent(newInstanceEvent(remoting.EventTypeAdd, 20000+i))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the registry is stalled by the listener")
+	}
+	close(listener.gate)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, fmt.Sprint(20000+i), listener.next(t).Service.Port)
+	}
+	assert.NoError(t, r.UnSubscribe(&url, listener))
+	assert.Equal(t, &url, mock.Calls(MockCallUnSubscribe)[0].URL)
+	assert.Equal(t, uint64(0), r.Dropped())
+}
+
+func TestAsyncNotifyRegistrySameListener(t *testing.T) {
+	stub := &snapshotTestRegistry{}
+	r := NewAsyncNotifyRegistry(stub, AsyncNotifyOptions{Workers: 1})
+	defer r.pool.close()
+	listener := newGatedNotifyListener()
+	a, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.A?interface=com.ikurento.user.A")
+	b, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.B?interface=com.ikurento.user.B")
+	assert.NoError(t, r.Subscribe(&a, listener))
+	wrapperA := stub.listener
+	assert.NoError(t, r.Subscribe(&b, listener))
+	wrapperB := stub.listener
+	assert.True(t, wrapperA != wrapperB)
+
+	// every subscription of the listener unsubscribes its own wrapper
+	assert.NoError(t, r.UnSubscribe(&a, listener))
+	assert.True(t, stub.unsubscribed == wrapperA)
+	assert.NoError(t, r.UnSubscribe(&b, listener))
+	assert.True(t, stub.unsubscribed == wrapperB)
+
+	// the listener not comparable is rejected instead of panicking
+	assert.Error(t, r.Subscribe(&a, snapshotFuncListener(func(*ServiceEvent) {})))
+}
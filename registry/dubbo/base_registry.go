@@ -29,6 +29,7 @@ import (
 	"time"
 
 	gxnet "github.com/dubbogo/gost/net"
+	"go.uber.org/multierr"
 	"mosn.io/pkg/registry/dubbo/common"
 	"mosn.io/pkg/registry/dubbo/common/constant"
 	"mosn.io/pkg/registry/dubbo/common/logger"
@@ -46,6 +47,10 @@ var (
 	processID       = ""
 	localIP         = ""
 	RegisteredError = errors.New("already registered")
+	// ErrNotAvailable is returned by the health check of a destroyed registry
+	ErrNotAvailable = errors.New("registry is not available")
+	// ErrWildcardNotSupported is returned by the registries which can't subscribe the wildcard interface
+	ErrWildcardNotSupported = errors.New("wildcard subscription is not supported")
 )
 
 func init() {
@@ -87,6 +92,10 @@ type FacadeBasedRegistry interface {
 	CloseListener()
 	// InitListeners init listeners
 	InitListeners()
+	// DoHealthCheck performs a lightweight round trip to the registry center
+	DoHealthCheck(ctx context.Context) error
+	// DoDumpWatches returns the paths watched in the registry center and the states of the watches
+	DoDumpWatches() []WatchDump
 }
 
 // BaseRegistry is a common logic abstract for registry. It implement Registry interface.
@@ -99,6 +108,8 @@ type BaseRegistry struct {
 	done     chan struct{}
 	cltLock  sync.RWMutex           //ctl lock is a lock for services map
 	services map[string]*common.URL // service name + protocol -> service config, for store the service registered
+
+	subscriptions SubscriptionRecorder
 }
 
 // InitBaseRegistry for init some local variables and set BaseRegistry's subclass to it
@@ -195,6 +206,44 @@ func (r *BaseRegistry) UnRegister(conf *common.URL) error {
 	return nil
 }
 
+// UnRegisterAll unregisters all the registered urls
+func (r *BaseRegistry) UnRegisterAll() error {
+	return r.unRegisterAll(context.Background())
+}
+
+func (r *BaseRegistry) unRegisterAll(ctx context.Context) error {
+	r.cltLock.Lock()
+	services := make([]*common.URL, 0, len(r.services))
+	for _, conf := range r.services {
+		services = append(services, conf)
+	}
+	r.cltLock.Unlock()
+	return UnRegisterURLs(ctx, services, r.UnRegister)
+}
+
+// Close unregisters all the registered urls until ctx is done and then destroys the registry
+func (r *BaseRegistry) Close(ctx context.Context) error {
+	err := r.unRegisterAll(ctx)
+	r.Destroy()
+	return err
+}
+
+// UnRegisterURLs unregisters the urls one by one, it stops before the next url once ctx is done.
+// The errors of all the urls are combined.
+func UnRegisterURLs(ctx context.Context, urls []*common.URL, unRegister func(*common.URL) error) error {
+	var err error
+	for i, conf := range urls {
+		if ctx.Err() != nil {
+			return multierr.Append(err, perrors.WithMessagef(ctx.Err(), "%d urls are not unregistered", len(urls)-i))
+		}
+		if e := unRegister(conf); e != nil {
+			logger.Warnf("unregister(conf{%s}) = error{%v}", conf.Key(), e)
+			err = multierr.Append(err, e)
+		}
+	}
+	return err
+}
+
 // service is for getting service path stored in url
 func (r *BaseRegistry) service(c *common.URL) string {
 	return url.QueryEscape(c.Service())
@@ -377,6 +426,8 @@ func sleepWait(n int) {
 
 // Subscribe :subscribe from registry, event will notify by notifyListener
 func (r *BaseRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	r.subscriptions.Subscribed(url)
+	defer r.subscriptions.Unsubscribed(url)
 	n := 0
 	for {
 		n++
@@ -403,6 +454,7 @@ func (r *BaseRegistry) Subscribe(url *common.URL, notifyListener NotifyListener)
 				break
 			} else {
 				logger.Infof("update begin, service event: %v", serviceEvent.String())
+				r.subscriptions.Notified(url, serviceEvent)
 				notifyListener.Notify(serviceEvent)
 			}
 
@@ -417,6 +469,7 @@ func (r *BaseRegistry) UnSubscribe(url *common.URL, notifyListener NotifyListene
 		logger.Warnf("event listener game over.")
 		return perrors.New("BaseRegistry is not available.")
 	}
+	r.subscriptions.Unsubscribed(url)
 
 	listener, err := r.facadeBasedRegistry.DoUnsubscribe(url)
 	if err != nil {
@@ -463,6 +516,33 @@ func (r *BaseRegistry) IsAvailable() bool {
 	}
 }
 
+// HealthCheck performs the round trip of the facade registry and returns its latency
+func (r *BaseRegistry) HealthCheck(ctx context.Context) (time.Duration, error) {
+	if !r.IsAvailable() {
+		return 0, ErrNotAvailable
+	}
+	start := time.Now()
+	err := r.facadeBasedRegistry.DoHealthCheck(ctx)
+	return time.Since(start), err
+}
+
+// Dump returns the registered urls, the subscriptions and the watches of the facade registry
+func (r *BaseRegistry) Dump() *RegistryDump {
+	r.cltLock.RLock()
+	services := make([]*common.URL, 0, len(r.services))
+	for _, conf := range r.services {
+		services = append(services, conf)
+	}
+	r.cltLock.RUnlock()
+	return &RegistryDump{
+		URL:           DumpURL(r.URL),
+		Available:     r.IsAvailable(),
+		Registrations: DumpURLs(services),
+		Subscriptions: r.subscriptions.Dump(),
+		Watches:       r.facadeBasedRegistry.DoDumpWatches(),
+	}
+}
+
 // WaitGroup open for outside add the waitgroup to add some logic before registry destroyed over(graceful down)
 func (r *BaseRegistry) WaitGroup() *sync.WaitGroup {
 	return &r.wg
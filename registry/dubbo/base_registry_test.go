@@ -0,0 +1,55 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+func TestUnRegisterURLs(t *testing.T) {
+	url1, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider")
+	url2, _ := common.NewURL("dubbo://127.0.0.2:20000/com.ikurento.user.UserProvider")
+	urls := []*common.URL{&url1, &url2}
+
+	var unregistered []string
+	err := UnRegisterURLs(context.Background(), urls, func(url *common.URL) error {
+		unregistered = append(unregistered, url.Ip)
+		if url.Ip == "127.0.0.1" {
+			return errors.New("connection loss")
+		}
+		return nil
+	})
+	// the failure of a url doesn't stop the others
+	assert.Error(t, err)
+	assert.Equal(t, []string{"127.0.0.1", "127.0.0.2"}, unregistered)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	unregistered = nil
+	err = UnRegisterURLs(ctx, urls, func(url *common.URL) error {
+		unregistered = append(unregistered, url.Ip)
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"127.0.0.1"}, unregistered)
+}
@@ -102,7 +102,19 @@ const (
 	PREFERRED_KEY        = "preferred"
 	ZONE_KEY             = "zone"
 	ZONE_FORCE_KEY       = "zone.force"
+	REGION_KEY           = "region"
+	TAGS_KEY             = "tags"
 	REGISTRY_TTL_KEY     = "registry.ttl"
+	REGISTRY_CODEC_KEY   = "registry.codec"
+
+	REGISTRY_EVENT_COALESCE_KEY = "registry.event.coalesce"
+	REGISTRY_CHROOT_KEY         = "registry.chroot"
+
+	REGISTRY_BACKOFF_INITIAL_KEY      = "registry.backoff.initial"
+	REGISTRY_BACKOFF_MAX_KEY          = "registry.backoff.max"
+	REGISTRY_BACKOFF_JITTER_KEY       = "registry.backoff.jitter"
+	REGISTRY_BACKOFF_MAX_ATTEMPTS_KEY = "registry.backoff.max-attempts"
+	REGISTRY_BACKOFF_EXPONENTIAL_KEY  = "registry.backoff.exponential"
 )
 
 const (
@@ -135,6 +147,7 @@ const (
 	CONFIG_TIMEOUT_KET    = "config.timeout"
 	CONFIG_LOG_DIR_KEY    = "config.logDir"
 	CONFIG_VERSION_KEY    = "configVersion"
+	CONFIG_SECRET_KEY     = "config.secret"
 	COMPATIBLE_CONFIG_KEY = "compatible_config"
 )
 const (
@@ -167,16 +180,38 @@ const (
 	NACOS_NAMESPACE_ID           = "namespaceId"
 	NACOS_PASSWORD               = "password"
 	NACOS_USERNAME               = "username"
+	NACOS_GROUP_KEY              = "nacos.group"
+	NACOS_CLUSTER_KEY            = "nacos.cluster"
+	NACOS_DEFAULT_CLUSTER        = "DEFAULT"
 )
 
 const (
 	ZOOKEEPER_KEY = "zookeeper"
 )
 
+const (
+	CONSUL_KEY                         = "consul"
+	CONSUL_TOKEN_KEY                   = "consul-token"
+	CONSUL_CHECK_TTL_KEY               = "consul-check-ttl"
+	CONSUL_DEREGISTER_CRITICAL_KEY     = "consul-deregister-critical-service-after"
+	CONSUL_WATCH_TIMEOUT_KEY           = "consul-watch-timeout"
+	CONSUL_DEFAULT_CHECK_TTL           = "15s"
+	CONSUL_DEFAULT_DEREGISTER_CRITICAL = "1m"
+	CONSUL_DEFAULT_WATCH_TIMEOUT       = "30s"
+	CONSUL_URL_META_KEY                = "url"
+)
+
 const (
 	ETCDV3_KEY = "etcdv3"
 )
 
+const (
+	KUBERNETES_KEY               = "kubernetes"
+	KUBERNETES_NAMESPACE_KEY     = "kubernetes.namespace"
+	KUBERNETES_TOKEN_KEY         = "kubernetes.token"
+	KUBERNETES_DEFAULT_NAMESPACE = "default"
+)
+
 const (
 	TRACING_REMOTE_SPAN_CTX = "tracing.remote.span.ctx"
 )
@@ -0,0 +1,142 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"mosn.io/pkg/registry/dubbo/common/constant"
+)
+
+// millisTimestampThreshold tells the timestamps in milliseconds from the ones in seconds,
+// it is in 1973 in milliseconds and in 5138 in seconds
+const millisTimestampThreshold = 1e11
+
+// InstanceMetadata is the metadata of a provider instance, it is carried by the url params
+// which are registered with the url, so that the consumers can do weighted and locality
+// aware load balancing.
+type InstanceMetadata struct {
+	// Weight is the weight param, constant.DEFAULT_WEIGHT if absent
+	Weight int64
+	Zone   string
+	Region string
+	// Timestamp is the start time of the instance, which is the remote.timestamp param of the urls merged
+	// by the consumers, or the timestamp param. It is in seconds, or in milliseconds registered by java.
+	Timestamp time.Time
+	// Warmup is the warmup param in seconds, or in milliseconds with the timestamp in milliseconds,
+	// constant.DEFAULT_WARMUP if absent
+	Warmup time.Duration
+	// Tags is the comma separated tags param
+	Tags []string
+}
+
+// GetInstanceMetadata reads the instance metadata from the params
+func (c *URL) GetInstanceMetadata() InstanceMetadata {
+	m := InstanceMetadata{
+		Weight: c.GetParamInt(constant.WEIGHT_KEY, constant.DEFAULT_WEIGHT),
+		Zone:   c.GetParam(constant.ZONE_KEY, ""),
+		Region: c.GetParam(constant.REGION_KEY, ""),
+		Warmup: time.Duration(c.GetParamInt(constant.WARMUP_KEY, constant.DEFAULT_WARMUP)) * time.Second,
+	}
+	timestamp := c.GetParamInt(constant.REMOTE_TIMESTAMP_KEY, 0)
+	if timestamp <= 0 {
+		timestamp = c.GetParamInt(constant.TIMESTAMP_KEY, 0)
+	}
+	if timestamp >= millisTimestampThreshold {
+		// registered by java, whose warmup is in milliseconds as well
+		m.Timestamp = time.UnixMilli(timestamp)
+		if warmup := c.GetParamInt(constant.WARMUP_KEY, 0); warmup > 0 {
+			m.Warmup = time.Duration(warmup) * time.Millisecond
+		}
+	} else if timestamp > 0 {
+		m.Timestamp = time.Unix(timestamp, 0)
+	}
+	for _, tag := range strings.Split(c.GetParam(constant.TAGS_KEY, ""), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	return m
+}
+
+// SetInstanceMetadata sets the params of the instance metadata, the zero fields are left untouched
+func (c *URL) SetInstanceMetadata(m InstanceMetadata) {
+	if m.Weight > 0 {
+		c.SetParam(constant.WEIGHT_KEY, strconv.FormatInt(m.Weight, 10))
+	}
+	if m.Zone != "" {
+		c.SetParam(constant.ZONE_KEY, m.Zone)
+	}
+	if m.Region != "" {
+		c.SetParam(constant.REGION_KEY, m.Region)
+	}
+	if !m.Timestamp.IsZero() {
+		c.SetParam(constant.TIMESTAMP_KEY, strconv.FormatInt(m.Timestamp.Unix(), 10))
+	}
+	if m.Warmup > 0 {
+		c.SetParam(constant.WARMUP_KEY, strconv.FormatInt(int64(m.Warmup/time.Second), 10))
+	}
+	if len(m.Tags) > 0 {
+		c.SetParam(constant.TAGS_KEY, strings.Join(m.Tags, ","))
+	}
+}
+
+// HasTag returns whether the instance has the tag
+func (m InstanceMetadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// WarmupWeight returns the weight at @now, which grows linearly from 1 to Weight during
+// the warmup after Timestamp, the same as the warmup of dubbo. A negative Weight is 0.
+func (m InstanceMetadata) WarmupWeight(now time.Time) int64 {
+	if m.Weight <= 0 {
+		return 0
+	}
+	if m.Timestamp.IsZero() || m.Warmup <= 0 {
+		return m.Weight
+	}
+	return CalculateWarmupWeight(now.Sub(m.Timestamp), m.Warmup, m.Weight)
+}
+
+// Warming returns whether the instance is still warming up at @now
+func (m InstanceMetadata) Warming(now time.Time) bool {
+	return m.Weight > 0 && !m.Timestamp.IsZero() && m.Warmup > 0 && now.Sub(m.Timestamp) < m.Warmup
+}
+
+// CalculateWarmupWeight returns the weight of an instance which has been up for @uptime,
+// it is in [1, weight] for a positive weight
+func CalculateWarmupWeight(uptime, warmup time.Duration, weight int64) int64 {
+	if uptime <= 0 {
+		return 1
+	}
+	if uptime >= warmup {
+		return weight
+	}
+	ww := int64(float64(weight) * float64(uptime) / float64(warmup))
+	if ww < 1 {
+		return 1
+	}
+	return ww
+}
@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+)
+
+func TestInstanceMetadata(t *testing.T) {
+	url, err := NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider")
+	assert.NoError(t, err)
+	m := url.GetInstanceMetadata()
+	assert.Equal(t, int64(constant.DEFAULT_WEIGHT), m.Weight)
+	assert.Equal(t, constant.DEFAULT_WARMUP*time.Second, m.Warmup)
+	assert.True(t, m.Timestamp.IsZero())
+	assert.Empty(t, m.Tags)
+
+	start := time.Unix(1600000000, 0)
+	url.SetInstanceMetadata(InstanceMetadata{
+		Weight:    200,
+		Zone:      "hangzhou-a",
+		Region:    "hangzhou",
+		Timestamp: start,
+		Warmup:    100 * time.Second,
+		Tags:      []string{"gray", "canary"},
+	})
+	assert.Equal(t, "gray,canary", url.GetParam(constant.TAGS_KEY, ""))
+	m = url.GetInstanceMetadata()
+	assert.Equal(t, int64(200), m.Weight)
+	assert.Equal(t, "hangzhou-a", m.Zone)
+	assert.Equal(t, "hangzhou", m.Region)
+	assert.Equal(t, start, m.Timestamp)
+	assert.Equal(t, 100*time.Second, m.Warmup)
+	assert.True(t, m.HasTag("gray"))
+	assert.False(t, m.HasTag("stable"))
+
+	assert.Equal(t, int64(1), m.WarmupWeight(start))
+	assert.Equal(t, int64(100), m.WarmupWeight(start.Add(50*time.Second)))
+	assert.Equal(t, int64(200), m.WarmupWeight(start.Add(time.Hour)))
+}
+
+func TestWarmupWeight(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	url, _ := NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?weight=100&warmup=100&timestamp=1600000000")
+	m := url.GetInstanceMetadata()
+	assert.Equal(t, start, m.Timestamp)
+	assert.True(t, m.Warming(start.Add(99*time.Second)))
+	assert.False(t, m.Warming(start.Add(100*time.Second)))
+	assert.Equal(t, int64(1), m.WarmupWeight(start.Add(-time.Second)))
+	assert.Equal(t, int64(1), m.WarmupWeight(start.Add(500*time.Millisecond)))
+	assert.Equal(t, int64(25), m.WarmupWeight(start.Add(25*time.Second)))
+
+	// java registers the timestamp and warmup in milliseconds
+	url, _ = NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?weight=100&warmup=100000&timestamp=1600000000000")
+	m = url.GetInstanceMetadata()
+	assert.Equal(t, start, m.Timestamp)
+	assert.Equal(t, 100*time.Second, m.Warmup)
+	assert.Equal(t, int64(25), m.WarmupWeight(start.Add(25*time.Second)))
+
+	// the timestamp of the provider is remote.timestamp in the merged url
+	url.SetParam(constant.REMOTE_TIMESTAMP_KEY, "1600000050000")
+	assert.Equal(t, start.Add(50*time.Second), url.GetInstanceMetadata().Timestamp)
+
+	url.SetParam(constant.WEIGHT_KEY, "-1")
+	m = url.GetInstanceMetadata()
+	assert.Equal(t, int64(0), m.WarmupWeight(start))
+	assert.False(t, m.Warming(start))
+}
@@ -27,6 +27,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	gxset "github.com/dubbogo/gost/container/set"
 	"github.com/jinzhu/copier"
@@ -377,6 +378,28 @@ func (c URL) Service() string {
 	return ""
 }
 
+// IsAnyService returns whether the url subscribes all the services by the wildcard interface
+func (c *URL) IsAnyService() bool {
+	return c.Service() == constant.ANY_VALUE
+}
+
+// MatchService returns whether the service url matches the subscription url.
+// A subscription of a specific interface matches the services of the same service key. A subscription
+// of the wildcard interface matches the services in its group and version, or in any of them if the
+// group or version is empty or the wildcard.
+func (c *URL) MatchService(service *URL) bool {
+	if !c.IsAnyService() {
+		return c.ServiceKey() == service.ServiceKey()
+	}
+	for _, key := range []string{constant.GROUP_KEY, constant.VERSION_KEY} {
+		value := c.GetParam(key, "")
+		if value != "" && value != constant.ANY_VALUE && value != service.GetParam(key, "") {
+			return false
+		}
+	}
+	return true
+}
+
 // AddParam ...
 func (c *URL) AddParam(key string, value string) {
 	c.paramsLock.Lock()
@@ -469,6 +492,15 @@ func (c URL) GetParamInt(s string, d int64) int64 {
 	return int64(r)
 }
 
+// GetParamDuration parses the param as a duration like 3s, the default is returned if it is absent or malformed
+func (c *URL) GetParamDuration(s string, d time.Duration) time.Duration {
+	r, err := time.ParseDuration(c.GetParam(s, ""))
+	if err != nil {
+		return d
+	}
+	return r
+}
+
 // GetMethodParamInt ...
 func (c URL) GetMethodParamInt(method string, key string, d int64) int64 {
 	var r int
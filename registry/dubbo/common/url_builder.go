@@ -0,0 +1,149 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	perrors "github.com/pkg/errors"
+)
+
+// URLBuilder builds a URL step by step and validates it on Build, e.g.
+//
+//	url, err := common.NewURLBuilder().
+//		WithProtocol("dubbo").
+//		WithAddress("127.0.0.1:20000").
+//		WithPath("com.ikurento.user.UserProvider").
+//		WithParam(constant.INTERFACE_KEY, "com.ikurento.user.UserProvider").
+//		WithRequiredParams(constant.INTERFACE_KEY).
+//		Build()
+//
+// The first invalid step is kept as the error of Build, and the following steps are ignored.
+type URLBuilder struct {
+	url      *URL
+	required []string
+	err      error
+}
+
+// NewURLBuilder returns an empty builder
+func NewURLBuilder() *URLBuilder {
+	return &URLBuilder{url: &URL{baseUrl: baseUrl{params: url.Values{}}}}
+}
+
+// WithProtocol sets the scheme of the url, which is required
+func (b *URLBuilder) WithProtocol(protocol string) *URLBuilder {
+	if b.err == nil {
+		if len(protocol) == 0 || strings.ContainsAny(protocol, ":/?#") {
+			b.err = perrors.Errorf("invalid protocol %q", protocol)
+			return b
+		}
+		b.url.Protocol = protocol
+	}
+	return b
+}
+
+// WithAddress sets the host and port of the url, which is required, an ipv6 host should be in brackets
+func (b *URLBuilder) WithAddress(address string) *URLBuilder {
+	if b.err == nil {
+		ip, port, err := net.SplitHostPort(address)
+		if err != nil {
+			b.err = perrors.WithMessagef(err, "invalid address %q", address)
+			return b
+		}
+		b.url.Ip = ip
+		b.url.Port = port
+		b.url.Location = address
+	}
+	return b
+}
+
+// WithPath sets the path of the url, the leading slash is optional
+func (b *URLBuilder) WithPath(path string) *URLBuilder {
+	if b.err == nil {
+		b.url.Path = "/" + strings.TrimPrefix(path, "/")
+	}
+	return b
+}
+
+// WithAuth sets the username and password of the url
+func (b *URLBuilder) WithAuth(username, password string) *URLBuilder {
+	if b.err == nil {
+		b.url.Username = username
+		b.url.Password = password
+	}
+	return b
+}
+
+// WithMethods sets the methods of the url
+func (b *URLBuilder) WithMethods(methods ...string) *URLBuilder {
+	if b.err == nil {
+		b.url.Methods = methods
+	}
+	return b
+}
+
+// WithParam sets the param of the url, the key must not be empty and the value is escaped when encoded
+func (b *URLBuilder) WithParam(key, value string) *URLBuilder {
+	if b.err == nil {
+		if len(key) == 0 {
+			b.err = perrors.Errorf("empty param key of value %q", value)
+			return b
+		}
+		b.url.params.Set(key, value)
+	}
+	return b
+}
+
+// WithRequiredParams makes Build fail if any of the params is absent or empty
+func (b *URLBuilder) WithRequiredParams(keys ...string) *URLBuilder {
+	b.required = append(b.required, keys...)
+	return b
+}
+
+// Build validates and returns the url, whose PrimitiveURL is the escaped form of it
+func (b *URLBuilder) Build() (*URL, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.url.Protocol) == 0 {
+		return nil, perrors.New("the protocol of the url is required")
+	}
+	if len(b.url.Location) == 0 {
+		return nil, perrors.New("the address of the url is required")
+	}
+	for _, key := range b.required {
+		if len(b.url.params.Get(key)) == 0 {
+			return nil, perrors.Errorf("the param %s of the url is required", key)
+		}
+	}
+
+	u := b.url.Clone()
+	primitive := url.URL{
+		Scheme:   u.Protocol,
+		Host:     u.Location,
+		Path:     u.Path,
+		RawQuery: u.params.Encode(),
+	}
+	if len(u.Username) > 0 || len(u.Password) > 0 {
+		primitive.User = url.UserPassword(u.Username, u.Password)
+	}
+	u.PrimitiveURL = primitive.String()
+	return u, nil
+}
@@ -0,0 +1,360 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apollo
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gxset "github.com/dubbogo/gost/container/set"
+	gxnet "github.com/dubbogo/gost/net"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/config_center/parser"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/apollo"
+)
+
+const (
+	defaultNamespace = "application"
+	defaultCluster   = "default"
+	// contentKey is the key of the content of the namespaces which are not in the properties format
+	contentKey = "content"
+	// longPollTimeout is longer than the 60 seconds the config service holds the poll
+	longPollTimeout = 90 * time.Second
+)
+
+var (
+	// pollRetryDelay is the delay of polling again after the poll fails
+	pollRetryDelay = time.Second
+
+	errUnsupportedOperation = perrors.New("apollo config center doesn't support the operation")
+)
+
+type listenerKey struct {
+	namespace string // empty for the listeners of the key in all the namespaces
+	key       string
+}
+
+// apolloDynamicConfiguration reads the configurations released to the namespaces of an apollo app.
+// The namespaces of the config.namespace parameter are watched by the long poll of the notifications,
+// and the changes of their keys are sent to the listeners.
+type apolloDynamicConfiguration struct {
+	url        *common.URL
+	client     *apollo.Client
+	namespaces []string
+	timeout    time.Duration
+	parser     parser.ConfigurationParser
+
+	lock          sync.RWMutex
+	configs       map[string]*apollo.Config // namespace -> released config
+	notifications map[string]int64          // namespace -> notification id
+	listeners     map[listenerKey]*config_center.ListenerSet
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newApolloDynamicConfiguration(url *common.URL) (*apolloDynamicConfiguration, error) {
+	appID := url.GetParam(constant.CONFIG_APP_ID_KEY, "")
+	if appID == "" {
+		return nil, perrors.Errorf("%s of the apollo config center is empty", constant.CONFIG_APP_ID_KEY)
+	}
+	timeout, err := time.ParseDuration(url.GetParam(constant.CONFIG_TIMEOUT_KET, config_center.DEFAULT_CONFIG_TIMEOUT))
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse %s", constant.CONFIG_TIMEOUT_KET)
+	}
+	var namespaces []string
+	for _, namespace := range strings.Split(url.GetParam(constant.CONFIG_NAMESPACE_KEY, defaultNamespace), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{defaultNamespace}
+	}
+	ip, _ := gxnet.GetLocalIP()
+	address := strings.Split(url.Location, ",")[0]
+	client := apollo.NewClient(address, appID, url.GetParam(constant.CONFIG_CLUSTER_KEY, defaultCluster),
+		url.GetParam(constant.CONFIG_SECRET_KEY, ""), ip)
+
+	c := &apolloDynamicConfiguration{
+		url:           url,
+		client:        client,
+		namespaces:    namespaces,
+		timeout:       timeout,
+		configs:       make(map[string]*apollo.Config),
+		notifications: make(map[string]int64),
+		listeners:     make(map[listenerKey]*config_center.ListenerSet),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	check := url.GetParamBool(constant.CONFIG_CHECK_KEY, true)
+	for _, namespace := range namespaces {
+		c.notifications[namespace] = -1
+		if err = c.refresh(namespace); err != nil {
+			if check {
+				c.cancel()
+				return nil, err
+			}
+			logger.Warnf("apollo load namespace{%s} = error{%v}", namespace, err)
+		}
+	}
+	c.wg.Add(1)
+	go c.poll()
+	return c, nil
+}
+
+// poll polls the notifications and refreshes the changed namespaces until the configuration is destroyed
+func (c *apolloDynamicConfiguration) poll() {
+	defer c.wg.Done()
+	for {
+		ctx, cancel := context.WithTimeout(c.ctx, longPollTimeout)
+		changed, err := c.client.PollNotifications(ctx, c.currentNotifications())
+		cancel()
+		if c.ctx.Err() != nil {
+			return
+		}
+		for _, notification := range changed {
+			if err = c.refresh(notification.NamespaceName); err != nil {
+				break
+			}
+			c.lock.Lock()
+			c.notifications[notification.NamespaceName] = notification.NotificationID
+			c.lock.Unlock()
+		}
+		if err != nil {
+			logger.Warnf("apollo poll the notifications of app{%s} = error{%v}", c.url.GetParam(constant.CONFIG_APP_ID_KEY, ""), err)
+			select {
+			case <-time.After(pollRetryDelay):
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (c *apolloDynamicConfiguration) currentNotifications() []apollo.Notification {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	notifications := make([]apollo.Notification, 0, len(c.notifications))
+	for _, namespace := range c.namespaces {
+		notifications = append(notifications, apollo.Notification{NamespaceName: namespace, NotificationID: c.notifications[namespace]})
+	}
+	return notifications
+}
+
+// refresh loads the namespace and sends the changes of its keys to the listeners
+func (c *apolloDynamicConfiguration) refresh(namespace string) error {
+	c.lock.RLock()
+	old := c.configs[namespace]
+	c.lock.RUnlock()
+	releaseKey := ""
+	if old != nil {
+		releaseKey = old.ReleaseKey
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	config, err := c.client.GetConfig(ctx, namespace, releaseKey)
+	cancel()
+	if perrors.Cause(err) == apollo.ErrNamespaceNotFound {
+		// the namespace is deleted or not released yet
+		config, err = &apollo.Config{NamespaceName: namespace}, nil
+	}
+	if err != nil || config == nil {
+		return err
+	}
+
+	c.lock.Lock()
+	c.configs[namespace] = config
+	c.lock.Unlock()
+	if old != nil {
+		c.notify(namespace, old.Configurations, config.Configurations)
+	}
+	return nil
+}
+
+// notify sends the add, update and delete events of the keys to the listeners
+func (c *apolloDynamicConfiguration) notify(namespace string, old, current map[string]string) {
+	for key, value := range current {
+		oldValue, ok := old[key]
+		switch {
+		case !ok:
+			c.process(namespace, &config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeAdd})
+		case oldValue != value:
+			c.process(namespace, &config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeUpdate})
+		}
+	}
+	for key, value := range old {
+		if _, ok := current[key]; !ok {
+			c.process(namespace, &config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeDel})
+		}
+	}
+}
+
+func (c *apolloDynamicConfiguration) process(namespace string, event *config_center.ConfigChangeEvent) {
+	c.lock.RLock()
+	namespaceListeners := c.listeners[listenerKey{namespace: namespace, key: event.Key}]
+	keyListeners := c.listeners[listenerKey{key: event.Key}]
+	c.lock.RUnlock()
+	config_center.Dispatch(event, namespaceListeners, keyListeners)
+}
+
+// getConfig returns the config of the namespace, the namespaces which are not watched are loaded at once
+func (c *apolloDynamicConfiguration) getConfig(namespace string) (*apollo.Config, error) {
+	c.lock.RLock()
+	config, ok := c.configs[namespace]
+	c.lock.RUnlock()
+	if ok {
+		return config, nil
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	defer cancel()
+	return c.client.GetConfig(ctx, namespace, "")
+}
+
+// namespace returns the namespace of the group option, or the first namespace if it is absent
+func (c *apolloDynamicConfiguration) namespace(opts ...config_center.Option) string {
+	options := &config_center.Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.Group != "" {
+		return options.Group
+	}
+	return c.namespaces[0]
+}
+
+// AddListener listens the key in the namespace of the group option, or in all the watched namespaces
+func (c *apolloDynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	options := &config_center.Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	k := listenerKey{namespace: options.Group, key: key}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.listeners[k] == nil {
+		c.listeners[k] = config_center.NewListenerSet()
+	}
+	c.listeners[k].Add(listener, options.Priority)
+}
+
+func (c *apolloDynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	options := &config_center.Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	k := listenerKey{namespace: options.Group, key: key}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if listeners, ok := c.listeners[k]; ok && listeners.Remove(listener) == 0 {
+		delete(c.listeners, k)
+	}
+}
+
+// GetProperties returns the content of the namespace named @key, the namespaces in the properties
+// format are returned as the sorted key=value lines
+func (c *apolloDynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	config, err := c.getConfig(key)
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	ext := filepath.Ext(key)
+	if ext != "" && ext != ".properties" {
+		return config.Configurations[contentKey], nil
+	}
+	keys := make([]string, 0, len(config.Configurations))
+	for k := range config.Configurations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf strings.Builder
+	for _, k := range keys {
+		buf.WriteString(k + "=" + config.Configurations[k] + "\n")
+	}
+	return buf.String(), nil
+}
+
+// GetInternalProperty returns the value of the key in the namespace of the group option, or in the first namespace
+func (c *apolloDynamicConfiguration) GetInternalProperty(key string, opts ...config_center.Option) (string, error) {
+	namespace := c.namespace(opts...)
+	config, err := c.getConfig(namespace)
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	value, ok := config.Configurations[key]
+	if !ok {
+		return "", perrors.Errorf("key %s is not found in apollo namespace %s", key, namespace)
+	}
+	return value, nil
+}
+
+func (c *apolloDynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
+	return c.GetInternalProperty(key, opts...)
+}
+
+// PublishConfig is not supported, the configurations are released by the portal of apollo
+func (c *apolloDynamicConfiguration) PublishConfig(string, string, string) error {
+	return errUnsupportedOperation
+}
+
+// GetConfigKeysByGroup will return all keys of the namespace named @group
+func (c *apolloDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
+	config, err := c.getConfig(c.namespace(config_center.WithGroup(group)))
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	if len(config.Configurations) == 0 {
+		return nil, perrors.New("could not find keys with group: " + group)
+	}
+	set := gxset.NewSet()
+	for k := range config.Configurations {
+		set.Add(k)
+	}
+	return set, nil
+}
+
+func (c *apolloDynamicConfiguration) Parser() parser.ConfigurationParser {
+	return c.parser
+}
+
+func (c *apolloDynamicConfiguration) SetParser(p parser.ConfigurationParser) {
+	c.parser = p
+}
+
+func (c *apolloDynamicConfiguration) GetUrl() common.URL {
+	return *c.url.Clone()
+}
+
+func (c *apolloDynamicConfiguration) IsAvailable() bool {
+	return c.ctx.Err() == nil
+}
+
+// Destroy stops polling the notifications
+func (c *apolloDynamicConfiguration) Destroy() {
+	c.cancel()
+	c.wg.Wait()
+}
@@ -0,0 +1,207 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apollo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/apollo"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+// mockApollo serves the configs and the notifications of the namespaces like the apollo config service
+type mockApollo struct {
+	lock          sync.Mutex
+	configs       map[string]map[string]string
+	notifications map[string]int64
+}
+
+func (m *mockApollo) release(namespace string, configurations map[string]string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.configs[namespace] = configurations
+	m.notifications[namespace]++
+}
+
+func (m *mockApollo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/configs/") {
+		namespace := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		m.lock.Lock()
+		configurations, ok := m.configs[namespace]
+		releaseKey := time.Unix(0, m.notifications[namespace]).String()
+		m.lock.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("releaseKey") == releaseKey {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&apollo.Config{NamespaceName: namespace, Configurations: configurations, ReleaseKey: releaseKey})
+		return
+	}
+	var notifications []apollo.Notification
+	_ = json.Unmarshal([]byte(r.URL.Query().Get("notifications")), &notifications)
+	for i := 0; i < 20; i++ {
+		var changed []apollo.Notification
+		m.lock.Lock()
+		for _, n := range notifications {
+			if id := m.notifications[n.NamespaceName]; id > n.NotificationID {
+				changed = append(changed, apollo.Notification{NamespaceName: n.NamespaceName, NotificationID: id})
+			}
+		}
+		m.lock.Unlock()
+		if len(changed) > 0 {
+			_ = json.NewEncoder(w).Encode(changed)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+type mockListener struct {
+	events chan *config_center.ConfigChangeEvent
+}
+
+func (l *mockListener) Process(event *config_center.ConfigChangeEvent) {
+	l.events <- event
+}
+
+func newTestConfiguration(t *testing.T, m *mockApollo, namespaces string) *apolloDynamicConfiguration {
+	server := httptest.NewServer(m)
+	t.Cleanup(server.Close)
+	url, err := common.NewURL("registry://" + server.Listener.Addr().String() +
+		"?config.appId=test&config.namespace=" + namespaces)
+	require.NoError(t, err)
+	factory := &apolloDynamicConfigurationFactory{}
+	configuration, err := factory.GetDynamicConfiguration(&url)
+	require.NoError(t, err)
+	t.Cleanup(configuration.(*apolloDynamicConfiguration).Destroy)
+	return configuration.(*apolloDynamicConfiguration)
+}
+
+func TestApolloGetConfig(t *testing.T) {
+	m := &mockApollo{
+		configs: map[string]map[string]string{
+			"application":   {"b": "2", "a": "1"},
+			"dubbo.yaml":    {"content": "a: 1"},
+			"dubbo.routers": {"rule": "force: true"},
+		},
+		notifications: map[string]int64{},
+	}
+	c := newTestConfiguration(t, m, "application,dubbo.routers")
+
+	properties, err := c.GetProperties("application")
+	assert.NoError(t, err)
+	assert.Equal(t, "a=1\nb=2\n", properties)
+	properties, err = c.GetProperties("dubbo.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "a: 1", properties)
+
+	value, err := c.GetInternalProperty("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	_, err = c.GetInternalProperty("c")
+	assert.Error(t, err)
+	rule, err := c.GetRule("rule", config_center.WithGroup("dubbo.routers"))
+	assert.NoError(t, err)
+	assert.Equal(t, "force: true", rule)
+
+	keys, err := c.GetConfigKeysByGroup("application")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keys.Size())
+	assert.Error(t, c.PublishConfig("a", "application", "1"))
+}
+
+func TestApolloCheckNamespace(t *testing.T) {
+	m := &mockApollo{configs: map[string]map[string]string{}, notifications: map[string]int64{}}
+	server := httptest.NewServer(m)
+	defer server.Close()
+	url, err := common.NewURL("registry://" + server.Listener.Addr().String() + "?config.appId=test")
+	require.NoError(t, err)
+
+	// the namespace which is not released yet is empty
+	c, err := newApolloDynamicConfiguration(&url)
+	require.NoError(t, err)
+	c.Destroy()
+	assert.False(t, c.IsAvailable())
+
+	server.Close()
+	_, err = newApolloDynamicConfiguration(&url)
+	assert.Error(t, err)
+	url.SetParam("config.check", "false")
+	c, err = newApolloDynamicConfiguration(&url)
+	assert.NoError(t, err)
+	c.Destroy()
+}
+
+func TestApolloListener(t *testing.T) {
+	m := &mockApollo{
+		configs:       map[string]map[string]string{"application": {"a": "1", "b": "2"}},
+		notifications: map[string]int64{"application": 1},
+	}
+	c := newTestConfiguration(t, m, "application")
+	listener := &mockListener{events: make(chan *config_center.ConfigChangeEvent, 10)}
+	c.AddListener("a", listener)
+	c.AddListener("b", listener, config_center.WithGroup("application"))
+	c.AddListener("c", listener, config_center.WithGroup("application"))
+
+	m.release("application", map[string]string{"a": "3", "c": "4"})
+	events := map[string]*config_center.ConfigChangeEvent{}
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-listener.events:
+			events[event.Key] = event
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout waiting for the config change events")
+		}
+	}
+	assert.EqualValues(t, remoting.EventTypeUpdate, events["a"].ConfigType)
+	assert.Equal(t, "3", events["a"].Value)
+	assert.EqualValues(t, remoting.EventTypeDel, events["b"].ConfigType)
+	assert.EqualValues(t, remoting.EventTypeAdd, events["c"].ConfigType)
+	assert.Equal(t, "4", events["c"].Value)
+
+	c.RemoveListener("a", listener)
+	m.release("application", map[string]string{"a": "5", "c": "4"})
+	select {
+	case event := <-listener.events:
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+	value, err := c.GetInternalProperty("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "5", value)
+}
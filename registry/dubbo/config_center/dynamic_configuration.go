@@ -59,8 +59,9 @@ type DynamicConfiguration interface {
 
 // Options ...
 type Options struct {
-	Group   string
-	Timeout time.Duration
+	Group    string
+	Timeout  time.Duration
+	Priority int
 }
 
 // Option ...
@@ -73,6 +74,13 @@ func WithGroup(group string) Option {
 	}
 }
 
+// WithPriority sets the priority of the listener, the listeners of higher priorities are notified first
+func WithPriority(priority int) Option {
+	return func(opt *Options) {
+		opt.Priority = priority
+	}
+}
+
 // WithTimeout ...
 func WithTimeout(time time.Duration) Option {
 	return func(opt *Options) {
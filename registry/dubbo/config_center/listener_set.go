@@ -0,0 +1,188 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+// SlowListenerTimeout is how long the dispatch waits for a listener before moving on to the next one,
+// the events of the slow listener are still delivered to it in order once it returns
+var SlowListenerTimeout = 3 * time.Second
+
+// listenerSeq orders the listeners of the same priority by the time they are added, across the sets
+var listenerSeq uint64
+
+// ListenerSet is a set of the configuration listeners notified in the order of their priorities,
+// the listeners of higher priorities are notified first and those of the same priority are notified
+// in the order they are added. Each listener is isolated from the others: its panics are recovered
+// and a blocking listener only delays its own events.
+type ListenerSet struct {
+	lock    sync.RWMutex
+	entries []*listenerEntry
+}
+
+// NewListenerSet returns an empty listener set
+func NewListenerSet() *ListenerSet {
+	return &ListenerSet{}
+}
+
+// Add adds the listener with the priority, adding an existing listener updates its priority
+func (s *ListenerSet) Add(listener ConfigurationListener, priority int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, e := range s.entries {
+		if e.listener == listener {
+			e.priority = priority
+			sortEntries(s.entries)
+			return
+		}
+	}
+	s.entries = append(s.entries, &listenerEntry{
+		listener: listener,
+		priority: priority,
+		seq:      atomic.AddUint64(&listenerSeq, 1),
+	})
+	sortEntries(s.entries)
+}
+
+// Remove removes the listener and returns the count of the remaining listeners
+func (s *ListenerSet) Remove(listener ConfigurationListener) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, e := range s.entries {
+		if e.listener == listener {
+			s.entries = append(s.entries[:i:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	return len(s.entries)
+}
+
+// Len returns the count of the listeners
+func (s *ListenerSet) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.entries)
+}
+
+// Dispatch notifies the listeners of the set in order
+func (s *ListenerSet) Dispatch(event *ConfigChangeEvent) {
+	Dispatch(event, s)
+}
+
+func (s *ListenerSet) snapshot() []*listenerEntry {
+	if s == nil {
+		return nil
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]*listenerEntry(nil), s.entries...)
+}
+
+// Dispatch notifies the listeners of all the sets in the order of their priorities, the nil sets are skipped
+func Dispatch(event *ConfigChangeEvent, sets ...*ListenerSet) {
+	var entries []*listenerEntry
+	for _, s := range sets {
+		entries = append(entries, s.snapshot()...)
+	}
+	if len(sets) > 1 {
+		sortEntries(entries)
+	}
+	for _, e := range entries {
+		finished := e.enqueue(event)
+		timer := time.NewTimer(SlowListenerTimeout)
+		select {
+		case <-finished:
+		case <-timer.C:
+			logger.Warnf("config listener %T doesn't return in %v for %v, notify the next listener",
+				e.listener, SlowListenerTimeout, event)
+		}
+		timer.Stop()
+	}
+}
+
+func sortEntries(entries []*listenerEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority > entries[j].priority
+		}
+		return entries[i].seq < entries[j].seq
+	})
+}
+
+type listenerTask struct {
+	event    *ConfigChangeEvent
+	finished chan struct{}
+}
+
+// listenerEntry queues the events of a listener, so a listener never processes the events concurrently
+// and the events are processed in order even if the dispatch has moved on from a slow listener
+type listenerEntry struct {
+	listener ConfigurationListener
+	priority int
+	seq      uint64
+
+	lock    sync.Mutex
+	pending []listenerTask
+	running bool
+}
+
+func (e *listenerEntry) enqueue(event *ConfigChangeEvent) <-chan struct{} {
+	task := listenerTask{event: event, finished: make(chan struct{})}
+	e.lock.Lock()
+	e.pending = append(e.pending, task)
+	if !e.running {
+		e.running = true
+		go e.drain()
+	}
+	e.lock.Unlock()
+	return task.finished
+}
+
+func (e *listenerEntry) drain() {
+	for {
+		e.lock.Lock()
+		if len(e.pending) == 0 {
+			e.running = false
+			e.lock.Unlock()
+			return
+		}
+		task := e.pending[0]
+		e.pending = e.pending[1:]
+		e.lock.Unlock()
+
+		e.process(task.event)
+		close(task.finished)
+	}
+}
+
+func (e *listenerEntry) process(event *ConfigChangeEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("config listener %T panics when processing %v: %v\n%s", e.listener, event, r, debug.Stack())
+		}
+	}()
+	e.listener.Process(event)
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+type recordListener struct {
+	name    string
+	lock    *sync.Mutex
+	records *[]string
+	process func()
+}
+
+func (l *recordListener) Process(event *ConfigChangeEvent) {
+	if l.process != nil {
+		l.process()
+	}
+	l.lock.Lock()
+	*l.records = append(*l.records, l.name+":"+event.Key)
+	l.lock.Unlock()
+}
+
+func TestListenerSetOrder(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		records []string
+	)
+	newListener := func(name string) *recordListener {
+		return &recordListener{name: name, lock: &lock, records: &records}
+	}
+	a, b, c, d := newListener("a"), newListener("b"), newListener("c"), newListener("d")
+	s1, s2 := NewListenerSet(), NewListenerSet()
+	s1.Add(a, 0)
+	s2.Add(b, 10)
+	s1.Add(c, 10)
+	s2.Add(d, -1)
+	s1.Add(a, 0) // adding again doesn't duplicate the listener
+
+	Dispatch(&ConfigChangeEvent{Key: "k1"}, s1, nil, s2)
+	assert.Equal(t, []string{"b:k1", "c:k1", "a:k1", "d:k1"}, records)
+
+	records = nil
+	s1.Add(a, 20)
+	assert.Equal(t, 1, s1.Remove(c))
+	assert.Equal(t, 1, s1.Len())
+	s1.Dispatch(&ConfigChangeEvent{Key: "k2"})
+	s2.Dispatch(&ConfigChangeEvent{Key: "k3"})
+	assert.Equal(t, []string{"a:k2", "b:k3", "d:k3"}, records)
+}
+
+func TestListenerSetIsolation(t *testing.T) {
+	defer func(timeout time.Duration) {
+		SlowListenerTimeout = timeout
+	}(SlowListenerTimeout)
+	SlowListenerTimeout = 10 * time.Millisecond
+
+	var (
+		lock    sync.Mutex
+		records []string
+	)
+	block := make(chan struct{})
+	panicking := &recordListener{name: "panic", lock: &lock, records: &records, process: func() { panic("bad rule") }}
+	blocking := &recordListener{name: "block", lock: &lock, records: &records, process: func() { <-block }}
+	normal := &recordListener{name: "normal", lock: &lock, records: &records}
+	s := NewListenerSet()
+	s.Add(panicking, 2)
+	s.Add(blocking, 1)
+	s.Add(normal, 0)
+
+	s.Dispatch(&ConfigChangeEvent{Key: "k1"})
+	s.Dispatch(&ConfigChangeEvent{Key: "k2"})
+	lock.Lock()
+	assert.Equal(t, []string{"normal:k1", "normal:k2"}, records)
+	lock.Unlock()
+
+	// the blocked events are processed in order once the listener returns
+	close(block)
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(records) == 4
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"block:k1", "block:k2"}, records[2:])
+}
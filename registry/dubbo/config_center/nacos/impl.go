@@ -0,0 +1,297 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"sync"
+
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/nacos-group/nacos-sdk-go/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/util"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/config_center/parser"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/nacos"
+)
+
+const (
+	// NacosClient nacos client name
+	NacosClient = "nacos config_center"
+	// searchPageSize is the page size of searching the keys of a group
+	searchPageSize = 100
+)
+
+type configKey struct {
+	dataID string
+	group  string
+}
+
+// nacosDynamicConfiguration stores the configs as the nacos configs, the key is the data id and
+// the group is the nacos group, which is the config.group parameter if the group option is absent
+type nacosDynamicConfiguration struct {
+	url         *common.URL
+	group       string
+	client      config_client.IConfigClient
+	closeClient func()
+	parser      parser.ConfigurationParser
+
+	listenerLock sync.Mutex
+	listeners    map[configKey]*nacosConfigListener
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+func newNacosDynamicConfiguration(url *common.URL) (*nacosDynamicConfiguration, error) {
+	configClient, err := nacos.NewNacosConfigClient(NacosClient, url)
+	if err != nil {
+		logger.Errorf("nacos config client create error {%v}", err)
+		return nil, err
+	}
+	return newNacosDynamicConfigurationWithClient(url, configClient.Client(), configClient.Close), nil
+}
+
+func newNacosDynamicConfigurationWithClient(url *common.URL, client config_client.IConfigClient, closeClient func()) *nacosDynamicConfiguration {
+	return &nacosDynamicConfiguration{
+		url:         url,
+		group:       url.GetParam(constant.CONFIG_GROUP_KEY, config_center.DEFAULT_GROUP),
+		client:      client,
+		closeClient: closeClient,
+		listeners:   make(map[configKey]*nacosConfigListener),
+		done:        make(chan struct{}),
+	}
+}
+
+func (c *nacosDynamicConfiguration) getGroup(group string) string {
+	if len(group) == 0 {
+		return c.group
+	}
+	return group
+}
+
+func (c *nacosDynamicConfiguration) configKey(key string, opts ...config_center.Option) configKey {
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	return configKey{dataID: key, group: c.getGroup(tmpOpts.Group)}
+}
+
+// AddListener listens the config of the key, the listeners of the same config share one nacos listener
+func (c *nacosDynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	k := c.configKey(key, opts...)
+	c.listenerLock.Lock()
+	defer c.listenerLock.Unlock()
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if l, ok := c.listeners[k]; ok {
+		l.listeners.Add(listener, tmpOpts.Priority)
+		return
+	}
+	l := newNacosConfigListener(k.dataID)
+	l.listeners.Add(listener, tmpOpts.Priority)
+	// the current content is the base of the diffing, so the content the listener starts with is not notified
+	if content, err := c.client.GetConfig(vo.ConfigParam{DataId: k.dataID, Group: k.group}); err == nil {
+		l.md5 = md5(content)
+	}
+	err := c.client.ListenConfig(vo.ConfigParam{
+		DataId: k.dataID,
+		Group:  k.group,
+		OnChange: func(_, _, _, data string) {
+			l.onChange(data)
+		},
+	})
+	if err != nil {
+		logger.Errorf("nacos listen config{dataId:%s, group:%s} = error{%v}", k.dataID, k.group, err)
+		return
+	}
+	c.listeners[k] = l
+}
+
+// RemoveListener removes the listener, the nacos listener is cancelled once the config has no listeners
+func (c *nacosDynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	k := c.configKey(key, opts...)
+	c.listenerLock.Lock()
+	defer c.listenerLock.Unlock()
+	l, ok := c.listeners[k]
+	if !ok || l.listeners.Remove(listener) > 0 {
+		return
+	}
+	delete(c.listeners, k)
+	if err := c.client.CancelListenConfig(vo.ConfigParam{DataId: k.dataID, Group: k.group}); err != nil {
+		logger.Warnf("nacos cancel listen config{dataId:%s, group:%s} = error{%v}", k.dataID, k.group, err)
+	}
+}
+
+// GetProperties returns the content of the config of the key
+func (c *nacosDynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	k := c.configKey(key, opts...)
+	content, err := c.client.GetConfig(vo.ConfigParam{DataId: k.dataID, Group: k.group})
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	return content, nil
+}
+
+// GetInternalProperty For nacos, getConfig and getConfigs have the same meaning.
+func (c *nacosDynamicConfiguration) GetInternalProperty(key string, opts ...config_center.Option) (string, error) {
+	return c.GetProperties(key, opts...)
+}
+
+func (c *nacosDynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
+	return c.GetProperties(key, opts...)
+}
+
+// PublishConfig will publish the value as the config of the key in the group
+func (c *nacosDynamicConfiguration) PublishConfig(key string, group string, value string) error {
+	ok, err := c.client.PublishConfig(vo.ConfigParam{DataId: key, Group: c.getGroup(group), Content: value})
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	if !ok {
+		return perrors.Errorf("publish config{dataId:%s, group:%s} failed", key, c.getGroup(group))
+	}
+	return nil
+}
+
+// RemoveConfig will remove the config of the key in the group
+func (c *nacosDynamicConfiguration) RemoveConfig(key string, group string) error {
+	ok, err := c.client.DeleteConfig(vo.ConfigParam{DataId: key, Group: c.getGroup(group)})
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	if !ok {
+		return perrors.Errorf("remove config{dataId:%s, group:%s} failed", key, c.getGroup(group))
+	}
+	return nil
+}
+
+// GetConfigKeysByGroup will return all keys with the group
+func (c *nacosDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
+	set := gxset.NewSet()
+	for pageNo := 1; ; pageNo++ {
+		page, err := c.client.SearchConfig(vo.SearchConfigParam{
+			Search:   "accurate",
+			Group:    c.getGroup(group),
+			PageNo:   pageNo,
+			PageSize: searchPageSize,
+		})
+		if err != nil {
+			return nil, perrors.WithStack(err)
+		}
+		for _, item := range page.PageItems {
+			set.Add(item.DataId)
+		}
+		if pageNo >= page.PagesAvailable || len(page.PageItems) == 0 {
+			break
+		}
+	}
+	if set.Empty() {
+		return nil, perrors.New("could not find keys with group: " + group)
+	}
+	return set, nil
+}
+
+func (c *nacosDynamicConfiguration) Parser() parser.ConfigurationParser {
+	return c.parser
+}
+
+func (c *nacosDynamicConfiguration) SetParser(p parser.ConfigurationParser) {
+	c.parser = p
+}
+
+func (c *nacosDynamicConfiguration) GetUrl() common.URL {
+	return *c.url.Clone()
+}
+
+func (c *nacosDynamicConfiguration) IsAvailable() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy cancels all the listeners and closes the config client
+func (c *nacosDynamicConfiguration) Destroy() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.listenerLock.Lock()
+		listeners := c.listeners
+		c.listeners = make(map[configKey]*nacosConfigListener)
+		c.listenerLock.Unlock()
+		for k := range listeners {
+			if err := c.client.CancelListenConfig(vo.ConfigParam{DataId: k.dataID, Group: k.group}); err != nil {
+				logger.Warnf("nacos cancel listen config{dataId:%s, group:%s} = error{%v}", k.dataID, k.group, err)
+			}
+		}
+		if c.closeClient != nil {
+			c.closeClient()
+		}
+	})
+}
+
+// md5 returns the md5 of the content, the md5 of the empty content is empty
+func md5(content string) string {
+	if content == "" {
+		return ""
+	}
+	return util.Md5(content)
+}
+
+// nacosConfigListener dispatches the changes of a nacos config to the configuration listeners,
+// the changes are diffed with the md5 of the content, so the repeated contents are not notified
+type nacosConfigListener struct {
+	key       string
+	lock      sync.Mutex
+	md5       string
+	listeners *config_center.ListenerSet
+}
+
+func newNacosConfigListener(key string) *nacosConfigListener {
+	return &nacosConfigListener{
+		key:       key,
+		listeners: config_center.NewListenerSet(),
+	}
+}
+
+func (l *nacosConfigListener) onChange(data string) {
+	l.lock.Lock()
+	newMD5 := md5(data)
+	if newMD5 == l.md5 {
+		l.lock.Unlock()
+		return
+	}
+	event := &config_center.ConfigChangeEvent{Key: l.key, Value: data, ConfigType: remoting.EventTypeUpdate}
+	switch {
+	case l.md5 == "":
+		event.ConfigType = remoting.EventTypeAdd
+	case newMD5 == "":
+		event.ConfigType = remoting.EventTypeDel
+	}
+	l.md5 = newMD5
+	l.lock.Unlock()
+	l.listeners.Dispatch(event)
+}
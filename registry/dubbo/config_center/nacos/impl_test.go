@@ -0,0 +1,181 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+// mockConfigClient keeps the configs in memory and calls the listeners synchronously
+type mockConfigClient struct {
+	lock      sync.Mutex
+	configs   map[configKey]string
+	listeners map[configKey]func(namespace, group, dataId, data string)
+}
+
+func newMockConfigClient() *mockConfigClient {
+	return &mockConfigClient{
+		configs:   make(map[configKey]string),
+		listeners: make(map[configKey]func(namespace, group, dataId, data string)),
+	}
+}
+
+func (m *mockConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.configs[configKey{param.DataId, param.Group}], nil
+}
+
+func (m *mockConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	m.set(configKey{param.DataId, param.Group}, param.Content)
+	return true, nil
+}
+
+func (m *mockConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	m.set(configKey{param.DataId, param.Group}, "")
+	return true, nil
+}
+
+func (m *mockConfigClient) set(k configKey, content string) {
+	m.lock.Lock()
+	if content == "" {
+		delete(m.configs, k)
+	} else {
+		m.configs[k] = content
+	}
+	onChange := m.listeners[k]
+	m.lock.Unlock()
+	if onChange != nil {
+		onChange("", k.group, k.dataID, content)
+	}
+}
+
+func (m *mockConfigClient) ListenConfig(param vo.ConfigParam) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.listeners[configKey{param.DataId, param.Group}] = param.OnChange
+	return nil
+}
+
+func (m *mockConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.listeners, configKey{param.DataId, param.Group})
+	return nil
+}
+
+func (m *mockConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	page := &model.ConfigPage{PageNumber: param.PageNo, PagesAvailable: 1}
+	for k := range m.configs {
+		if k.group == param.Group {
+			page.PageItems = append(page.PageItems, model.ConfigItem{DataId: k.dataID, Group: k.group})
+		}
+	}
+	return page, nil
+}
+
+func (m *mockConfigClient) PublishAggr(param vo.ConfigParam) (bool, error) {
+	return m.PublishConfig(param)
+}
+
+type mockListener struct {
+	events []*config_center.ConfigChangeEvent
+}
+
+func (l *mockListener) Process(event *config_center.ConfigChangeEvent) {
+	l.events = append(l.events, event)
+}
+
+func newTestConfiguration(t *testing.T) (*mockConfigClient, *nacosDynamicConfiguration) {
+	url, err := common.NewURL("registry://127.0.0.1:8848?config.group=governance")
+	assert.NoError(t, err)
+	client := newMockConfigClient()
+	return client, newNacosDynamicConfigurationWithClient(&url, client, nil)
+}
+
+func TestNacosPublishAndGetConfig(t *testing.T) {
+	_, c := newTestConfiguration(t)
+	defer c.Destroy()
+
+	assert.NoError(t, c.PublishConfig("demo.configurators", "", "enabled: true"))
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "app", "a=1"))
+	rule, err := c.GetRule("demo.configurators")
+	assert.NoError(t, err)
+	assert.Equal(t, "enabled: true", rule)
+	properties, err := c.GetProperties("dubbo.properties", config_center.WithGroup("app"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a=1", properties)
+
+	keys, err := c.GetConfigKeysByGroup("")
+	assert.NoError(t, err)
+	assert.True(t, keys.Contains("demo.configurators"))
+	assert.Equal(t, 1, keys.Size())
+
+	assert.NoError(t, c.RemoveConfig("demo.configurators", ""))
+	rule, err = c.GetRule("demo.configurators")
+	assert.NoError(t, err)
+	assert.Empty(t, rule)
+	_, err = c.GetConfigKeysByGroup("")
+	assert.Error(t, err)
+}
+
+func TestNacosListener(t *testing.T) {
+	client, c := newTestConfiguration(t)
+	assert.NoError(t, c.PublishConfig("demo.configurators", "", "v1"))
+
+	listener := &mockListener{}
+	c.AddListener("demo.configurators", listener)
+	// the content the listener starts with and the repeated contents are not notified
+	client.set(configKey{"demo.configurators", "governance"}, "v1")
+	assert.Empty(t, listener.events)
+
+	assert.NoError(t, c.PublishConfig("demo.configurators", "", "v2"))
+	assert.NoError(t, c.PublishConfig("demo.configurators", "", "v2"))
+	assert.NoError(t, c.RemoveConfig("demo.configurators", ""))
+	assert.NoError(t, c.PublishConfig("demo.configurators", "", "v3"))
+	assert.Len(t, listener.events, 3)
+	assert.EqualValues(t, remoting.EventTypeUpdate, listener.events[0].ConfigType)
+	assert.Equal(t, "v2", listener.events[0].Value)
+	assert.EqualValues(t, remoting.EventTypeDel, listener.events[1].ConfigType)
+	assert.EqualValues(t, remoting.EventTypeAdd, listener.events[2].ConfigType)
+	assert.Equal(t, "demo.configurators", listener.events[2].Key)
+
+	c.RemoveListener("demo.configurators", listener)
+	assert.Empty(t, client.listeners)
+
+	c.AddListener("demo.configurators", listener, config_center.WithGroup("app"))
+	assert.Len(t, client.listeners, 1)
+	c.Destroy()
+	assert.Empty(t, client.listeners)
+	assert.False(t, c.IsAvailable())
+}
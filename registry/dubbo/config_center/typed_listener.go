@@ -0,0 +1,344 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/magiconair/properties"
+	perrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+// ConfigFormat is the format of the configuration content decoded by TypedListener
+type ConfigFormat string
+
+// the formats of the configuration content
+const (
+	// FormatProperties decodes the fields by the `properties` tags, see properties.Decode, which requires
+	// the struct to have no unexported field and the fields without the default in the tags to be present
+	FormatProperties ConfigFormat = "properties"
+	// FormatYAML decodes the fields by the `yaml` tags
+	FormatYAML ConfigFormat = "yaml"
+	// FormatJSON decodes the fields by the `json` tags
+	FormatJSON ConfigFormat = "json"
+)
+
+// FieldChange is a changed field of the typed configuration
+type FieldChange struct {
+	// Path is the path of the field, e.g. "Server.Port", "Tags[1]" or "Params[timeout]"
+	Path string
+	// Old is nil if the field is added, New is nil if the field is removed
+	Old interface{}
+	New interface{}
+}
+
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// TypedChangeEvent is the change of the typed configuration, Old and New are the pointers to the structs
+// of the registered type, Old is nil for the first event
+type TypedChangeEvent struct {
+	Key        string
+	ConfigType remoting.EventType
+	Old        interface{}
+	New        interface{}
+	Changes    []FieldChange
+}
+
+func (e TypedChangeEvent) String() string {
+	return fmt.Sprintf("TypedChangeEvent{key = %v , changeType = %v , changes = %v}", e.Key, e.ConfigType, e.Changes)
+}
+
+// TypedConfigurationListener is notified of the changes of the typed configuration
+type TypedConfigurationListener interface {
+	ProcessTyped(*TypedChangeEvent)
+}
+
+// TypedListener decodes the content of the configuration into the registered struct and notifies the
+// TypedConfigurationListener of the changed fields. The content which fails to be decoded is ignored,
+// and so is the content without any changed field. The deleted configuration is decoded as the defaults.
+type TypedListener struct {
+	target   reflect.Value
+	format   ConfigFormat
+	listener TypedConfigurationListener
+
+	lock    sync.Mutex
+	current reflect.Value
+}
+
+// NewTypedListener returns the ConfigurationListener decoding the content in the format into the struct
+// of the type of @target, which is a pointer to the struct holding the defaults
+func NewTypedListener(target interface{}, format ConfigFormat, listener TypedConfigurationListener) (*TypedListener, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, perrors.Errorf("the target of the typed listener must be a pointer to a struct, but got %T", target)
+	}
+	switch format {
+	case FormatProperties, FormatYAML, FormatJSON:
+	default:
+		return nil, perrors.Errorf("unsupported config format %q", format)
+	}
+	return &TypedListener{target: v, format: format, listener: listener}, nil
+}
+
+// AddTypedListener adds a TypedListener of the key to the dynamic configuration,
+// the returned listener is used to remove it by RemoveListener
+func AddTypedListener(dc DynamicConfiguration, key string, target interface{}, format ConfigFormat,
+	listener TypedConfigurationListener, opts ...Option) (*TypedListener, error) {
+	l, err := NewTypedListener(target, format, listener)
+	if err != nil {
+		return nil, err
+	}
+	dc.AddListener(key, l, opts...)
+	return l, nil
+}
+
+// Current returns the pointer to the latest struct, nil if none is decoded yet
+func (l *TypedListener) Current() interface{} {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.current.IsValid() {
+		return nil
+	}
+	return l.current.Interface()
+}
+
+// Process decodes the content of the event and notifies the changes
+func (l *TypedListener) Process(event *ConfigChangeEvent) {
+	// the defaults are copied deeply, or the decoded maps and slices would be shared with the defaults
+	value := copyValue(l.target)
+	if event.ConfigType != remoting.EventTypeDel {
+		if err := l.decode(contentOf(event.Value), value.Interface()); err != nil {
+			logger.Errorf("decode the %s config of key %s error: %v", l.format, event.Key, err)
+			return
+		}
+	}
+
+	l.lock.Lock()
+	old := l.current
+	l.current = value
+	l.lock.Unlock()
+
+	typed := &TypedChangeEvent{Key: event.Key, ConfigType: event.ConfigType, New: value.Interface()}
+	if old.IsValid() {
+		typed.Old = old.Interface()
+		typed.Changes = DiffFields(typed.Old, typed.New)
+		if len(typed.Changes) == 0 {
+			logger.Debugf("the config of key %s is not changed", event.Key)
+			return
+		}
+	} else {
+		typed.Changes = DiffFields(l.target.Interface(), typed.New)
+	}
+	l.listener.ProcessTyped(typed)
+}
+
+func (l *TypedListener) decode(content string, v interface{}) error {
+	switch l.format {
+	case FormatProperties:
+		p, err := properties.LoadString(content)
+		if err != nil {
+			return err
+		}
+		return p.Decode(v)
+	case FormatYAML:
+		return yaml.Unmarshal([]byte(content), v)
+	default:
+		return json.Unmarshal([]byte(content), v)
+	}
+}
+
+func contentOf(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// copyValue returns a deep copy of the value, the maps, slices and pointers reachable from the exported
+// fields are copied, while the unexported fields are copied as they are
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(copyValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				c.Field(i).Set(copyValue(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// hasExported returns whether the struct type has any exported field
+func hasExported(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffFields returns the changed exported fields from @old to @new, which are the values or the pointers
+// of the same type. The structs, maps, slices and pointers are compared field by field, element by element,
+// except that the structs without exported fields, e.g. time.Time, are compared as a whole.
+func DiffFields(old, new interface{}) []FieldChange {
+	var changes []FieldChange
+	diffValue("", reflect.ValueOf(old), reflect.ValueOf(new), &changes)
+	return changes
+}
+
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func joinPath(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
+
+func diffValue(path string, old, new reflect.Value, changes *[]FieldChange) {
+	if !old.IsValid() || !new.IsValid() || old.Type() != new.Type() {
+		if old.IsValid() || new.IsValid() {
+			*changes = append(*changes, FieldChange{Path: path, Old: interfaceOf(old), New: interfaceOf(new)})
+		}
+		return
+	}
+	switch old.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if old.IsNil() || new.IsNil() {
+			if old.IsNil() != new.IsNil() {
+				*changes = append(*changes, FieldChange{Path: path, Old: old.Interface(), New: new.Interface()})
+			}
+			return
+		}
+		diffValue(path, old.Elem(), new.Elem(), changes)
+	case reflect.Struct:
+		if !hasExported(old.Type()) {
+			if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+				*changes = append(*changes, FieldChange{Path: path, Old: old.Interface(), New: new.Interface()})
+			}
+			return
+		}
+		for i := 0; i < old.NumField(); i++ {
+			field := old.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			diffValue(joinPath(path, field.Name), old.Field(i), new.Field(i), changes)
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range old.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range new.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			diffValue(path+"["+name+"]", old.MapIndex(keys[name]), new.MapIndex(keys[name]), changes)
+		}
+	case reflect.Slice, reflect.Array:
+		n := old.Len()
+		if new.Len() > n {
+			n = new.Len()
+		}
+		for i := 0; i < n; i++ {
+			var o, v reflect.Value
+			if i < old.Len() {
+				o = old.Index(i)
+			}
+			if i < new.Len() {
+				v = new.Index(i)
+			}
+			diffValue(path+"["+strconv.Itoa(i)+"]", o, v, changes)
+		}
+	default:
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*changes = append(*changes, FieldChange{Path: path, Old: old.Interface(), New: new.Interface()})
+		}
+	}
+}
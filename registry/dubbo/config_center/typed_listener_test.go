@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+type typedServer struct {
+	Host   string            `properties:"host,default=" yaml:"host" json:"host"`
+	Port   int               `properties:"port,default=0" yaml:"port" json:"port"`
+	Tags   []string          `properties:"tags,default=" yaml:"tags" json:"tags"`
+	Params map[string]string `properties:"-" yaml:"params" json:"params"`
+}
+
+type recordTypedListener struct {
+	events []*TypedChangeEvent
+}
+
+func (l *recordTypedListener) ProcessTyped(event *TypedChangeEvent) {
+	l.events = append(l.events, event)
+}
+
+func TestTypedListener(t *testing.T) {
+	_, err := NewTypedListener(typedServer{}, FormatYAML, &recordTypedListener{})
+	assert.Error(t, err)
+	_, err = NewTypedListener(&typedServer{}, "toml", &recordTypedListener{})
+	assert.Error(t, err)
+
+	for _, c := range []struct {
+		format   ConfigFormat
+		contents []string
+	}{
+		{FormatYAML, []string{"host: a\nport: 80\ntags: [x]", "host: a\nport: 8080\ntags: [x, y]", "{{"}},
+		{FormatJSON, []string{`{"host":"a","port":80,"tags":["x"]}`, `{"host":"a","port":8080,"tags":["x","y"]}`, "{{"}},
+		{FormatProperties, []string{"host=a\nport=80\ntags=x", "host=a\nport=8080\ntags=x;y", "port=x"}},
+	} {
+		recorder := &recordTypedListener{}
+		l, err := NewTypedListener(&typedServer{Port: 20000}, c.format, recorder)
+		assert.NoError(t, err)
+		assert.Nil(t, l.Current())
+
+		l.Process(&ConfigChangeEvent{Key: "server", Value: c.contents[0], ConfigType: remoting.EventTypeAdd})
+		// the content without any change and the invalid content are ignored
+		l.Process(&ConfigChangeEvent{Key: "server", Value: []byte(c.contents[0]), ConfigType: remoting.EventTypeUpdate})
+		l.Process(&ConfigChangeEvent{Key: "server", Value: c.contents[2], ConfigType: remoting.EventTypeUpdate})
+		l.Process(&ConfigChangeEvent{Key: "server", Value: c.contents[1], ConfigType: remoting.EventTypeUpdate})
+		l.Process(&ConfigChangeEvent{Key: "server", ConfigType: remoting.EventTypeDel})
+
+		assert.Len(t, recorder.events, 3, c.format)
+		first := recorder.events[0]
+		assert.Nil(t, first.Old)
+		assert.Equal(t, &typedServer{Host: "a", Port: 80, Tags: []string{"x"}}, first.New, c.format)
+		assert.Equal(t, []FieldChange{
+			{Path: "Host", Old: "", New: "a"},
+			{Path: "Port", Old: 20000, New: 80},
+			{Path: "Tags[0]", Old: nil, New: "x"},
+		}, first.Changes, c.format)
+
+		update := recorder.events[1]
+		assert.EqualValues(t, remoting.EventTypeUpdate, update.ConfigType)
+		assert.Equal(t, first.New, update.Old)
+		assert.Equal(t, []FieldChange{
+			{Path: "Port", Old: 80, New: 8080},
+			{Path: "Tags[1]", Old: nil, New: "y"},
+		}, update.Changes, c.format)
+
+		// the deleted config falls back to the defaults
+		del := recorder.events[2]
+		assert.EqualValues(t, remoting.EventTypeDel, del.ConfigType)
+		assert.Equal(t, &typedServer{Port: 20000}, del.New)
+		assert.Equal(t, del.New, l.Current())
+	}
+}
+
+func TestTypedListenerMap(t *testing.T) {
+	for _, c := range []struct {
+		format   ConfigFormat
+		contents []string
+	}{
+		{FormatYAML, []string{"params: {a: '1'}", "params: {b: '2'}"}},
+		{FormatJSON, []string{`{"params":{"a":"1"}}`, `{"params":{"b":"2"}}`}},
+	} {
+		recorder := &recordTypedListener{}
+		defaults := &typedServer{Params: map[string]string{"timeout": "1s"}}
+		l, err := NewTypedListener(defaults, c.format, recorder)
+		assert.NoError(t, err)
+		l.Process(&ConfigChangeEvent{Key: "server", Value: c.contents[0], ConfigType: remoting.EventTypeAdd})
+		l.Process(&ConfigChangeEvent{Key: "server", Value: c.contents[1], ConfigType: remoting.EventTypeUpdate})
+
+		// the decoded maps don't share the defaults, so the removed keys are dropped
+		assert.Len(t, recorder.events, 2, c.format)
+		assert.Equal(t, []FieldChange{
+			{Path: "Params[a]", Old: nil, New: "1"},
+		}, recorder.events[0].Changes, c.format)
+		assert.Equal(t, []FieldChange{
+			{Path: "Params[a]", Old: "1", New: nil},
+			{Path: "Params[b]", Old: nil, New: "2"},
+		}, recorder.events[1].Changes, c.format)
+		assert.Equal(t, &typedServer{Params: map[string]string{"timeout": "1s", "b": "2"}}, l.Current(), c.format)
+		assert.Equal(t, map[string]string{"timeout": "1s"}, defaults.Params, c.format)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	type config struct {
+		Params  map[string]string
+		private int
+	}
+	old := &config{Params: map[string]string{"a": "1", "b": "2"}, private: 1}
+	new := &config{Params: map[string]string{"b": "3", "c": "4"}, private: 2}
+	assert.Equal(t, []FieldChange{
+		{Path: "Params[a]", Old: "1", New: nil},
+		{Path: "Params[b]", Old: "2", New: "3"},
+		{Path: "Params[c]", Old: nil, New: "4"},
+	}, DiffFields(old, new))
+	assert.Empty(t, DiffFields(old, old))
+
+	// the structs without exported fields are compared as a whole
+	type schedule struct {
+		Start time.Time
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []FieldChange{
+		{Path: "Start", Old: start, New: start.Add(time.Hour)},
+	}, DiffFields(schedule{Start: start}, schedule{Start: start.Add(time.Hour)}))
+	assert.Empty(t, DiffFields(schedule{Start: start}, schedule{Start: start}))
+	assert.Equal(t, "Port: 1 -> 2", FieldChange{Path: "Port", Old: 1, New: 2}.String())
+}
+
+func TestAddTypedListener(t *testing.T) {
+	dc := &MockDynamicConfiguration{listener: map[string]ConfigurationListener{}}
+	recorder := &recordTypedListener{}
+	l, err := AddTypedListener(dc, "server", &typedServer{}, FormatJSON, recorder)
+	assert.NoError(t, err)
+	assert.Equal(t, l, dc.listener["server"])
+	dc.listener["server"].Process(&ConfigChangeEvent{Key: "server", Value: `{"port":1}`, ConfigType: remoting.EventTypeAdd})
+	assert.Equal(t, &typedServer{Port: 1}, l.Current())
+}
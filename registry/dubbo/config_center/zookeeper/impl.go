@@ -103,7 +103,7 @@ func newMockZookeeperDynamicConfiguration(url *common.URL, opts ...zookeeper.Opt
 }
 
 func (c *zookeeperDynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opions ...config_center.Option) {
-	c.cacheListener.AddListener(key, listener)
+	c.cacheListener.AddListener(key, listener, opions...)
 }
 
 func (c *zookeeperDynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opions ...config_center.Option) {
@@ -145,10 +145,12 @@ func (c *zookeeperDynamicConfiguration) GetInternalProperty(key string, opts ...
 	return c.GetProperties(key, opts...)
 }
 
-// PublishConfig will put the value into Zk with specific path
+// PublishConfig will put the value into Zk with specific path, the existing value is replaced
 func (c *zookeeperDynamicConfiguration) PublishConfig(key string, group string, value string) error {
 	path := c.getPath(key, group)
-	err := c.client.CreateWithValue(path, []byte(value))
+	_, err := c.client.UpdateContent(path, func([]byte) ([]byte, error) {
+		return []byte(value), nil
+	}, zookeeper.DefaultUpdateRetries)
 	if err != nil {
 		return perrors.WithStack(err)
 	}
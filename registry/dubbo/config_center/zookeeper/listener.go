@@ -36,23 +36,21 @@ func NewCacheListener(rootPath string) *CacheListener {
 	return &CacheListener{rootPath: rootPath}
 }
 
-// AddListener ...
-func (l *CacheListener) AddListener(key string, listener config_center.ConfigurationListener) {
-
-	// reference from https://stackoverflow.com/questions/34018908/golang-why-dont-we-have-a-set-datastructure
-	// make a map[your type]struct{} like set in java
-	listeners, loaded := l.keyListeners.LoadOrStore(key, map[config_center.ConfigurationListener]struct{}{listener: {}})
-	if loaded {
-		listeners.(map[config_center.ConfigurationListener]struct{})[listener] = struct{}{}
-		l.keyListeners.Store(key, listeners)
+// AddListener adds the listener of the key, the priority option orders the listeners of the key
+func (l *CacheListener) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
 	}
+	listeners, _ := l.keyListeners.LoadOrStore(key, config_center.NewListenerSet())
+	listeners.(*config_center.ListenerSet).Add(listener, tmpOpts.Priority)
 }
 
 // RemoveListener ...
 func (l *CacheListener) RemoveListener(key string, listener config_center.ConfigurationListener) {
 	listeners, loaded := l.keyListeners.Load(key)
 	if loaded {
-		delete(listeners.(map[config_center.ConfigurationListener]struct{}), listener)
+		listeners.(*config_center.ListenerSet).Remove(listener)
 	}
 }
 
@@ -65,9 +63,7 @@ func (l *CacheListener) DataChange(event remoting.Event) bool {
 	key := l.pathToKey(event.Path)
 	if key != "" {
 		if listeners, ok := l.keyListeners.Load(key); ok {
-			for listener := range listeners.(map[config_center.ConfigurationListener]struct{}) {
-				listener.Process(&config_center.ConfigChangeEvent{Key: key, Value: event.Content, ConfigType: event.Action})
-			}
+			listeners.(*config_center.ListenerSet).Dispatch(&config_center.ConfigChangeEvent{Key: key, Value: event.Content, ConfigType: event.Action})
 			return true
 		}
 	}
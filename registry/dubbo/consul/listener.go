@@ -0,0 +1,157 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/consul"
+)
+
+const (
+	// ConnDelay is the delay in seconds before retrying a failed query
+	ConnDelay = 3
+)
+
+// errAlreadySubscribed is returned when subscribing a service which is being subscribed
+var errAlreadySubscribed = perrors.New("service has already been subscribed")
+
+// consulListener watches the providers of a service by the blocking health queries
+type consulListener struct {
+	client       *consul.Client
+	subscribeURL *common.URL
+	timeout      time.Duration
+	watchTimeout time.Duration
+	instances    map[string]*common.URL // service id -> url
+	events       chan *config_center.ConfigChangeEvent
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+func newConsulListener(client *consul.Client, conf *common.URL, timeout, watchTimeout time.Duration) *consulListener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &consulListener{
+		client:       client,
+		subscribeURL: conf,
+		timeout:      timeout,
+		watchTimeout: watchTimeout,
+		instances:    make(map[string]*common.URL),
+		events:       make(chan *config_center.ConfigChangeEvent, 32),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// run queries the providers until the listener is closed
+func (l *consulListener) run() {
+	var index uint64
+	for {
+		ctx, cancel := context.WithTimeout(l.ctx, l.timeout+l.watchTimeout)
+		entries, newIndex, err := l.client.HealthService(ctx, l.subscribeURL.Service(),
+			common.DubboNodes[common.PROVIDER], index, l.watchTimeout)
+		cancel()
+		if l.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warnf("consul query service %s error {%v}", l.subscribeURL.Service(), err)
+			index = 0
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-time.After(ConnDelay * time.Second):
+			}
+			continue
+		}
+		if index > 0 && newIndex == index {
+			// the wait time elapses without change
+			continue
+		}
+		index = newIndex
+		l.update(entries)
+	}
+}
+
+// update compares the entries with the last ones, and emits the add, update and delete events
+func (l *consulListener) update(entries []consul.ServiceEntry) {
+	newInstances := make(map[string]*common.URL, len(entries))
+	for _, entry := range entries {
+		if entry.Service == nil {
+			continue
+		}
+		rawURL, ok := entry.Service.Meta[constant.CONSUL_URL_META_KEY]
+		if !ok {
+			continue
+		}
+		url, err := common.NewURL(rawURL)
+		if err != nil {
+			logger.Warnf("consul service %s has invalid url %s, error {%v}", entry.Service.ID, rawURL, err)
+			continue
+		}
+		if url.ServiceKey() != l.subscribeURL.ServiceKey() {
+			continue
+		}
+		newInstances[entry.Service.ID] = &url
+	}
+
+	for id, url := range newInstances {
+		old, ok := l.instances[id]
+		if !ok {
+			l.process(url, remoting.EventTypeAdd)
+		} else if old.String() != url.String() {
+			l.process(url, remoting.EventTypeUpdate)
+		}
+	}
+	for id, url := range l.instances {
+		if _, ok := newInstances[id]; !ok {
+			l.process(url, remoting.EventTypeDel)
+		}
+	}
+	l.instances = newInstances
+}
+
+func (l *consulListener) process(url *common.URL, action remoting.EventType) {
+	select {
+	case l.events <- &config_center.ConfigChangeEvent{Key: url.Key(), Value: url, ConfigType: action}:
+	case <-l.ctx.Done():
+	}
+}
+
+// Next returns the next service event of the providers
+func (l *consulListener) Next() (*registry.ServiceEvent, error) {
+	select {
+	case <-l.ctx.Done():
+		return nil, perrors.New("listener have been closed")
+	case e := <-l.events:
+		logger.Debugf("got consul event %s", e)
+		return &registry.ServiceEvent{Action: e.ConfigType, Service: *e.Value.(*common.URL).Clone()}, nil
+	}
+}
+
+// Close stops the queries of the listener
+func (l *consulListener) Close() {
+	l.cancel()
+}
@@ -0,0 +1,358 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	gxnet "github.com/dubbogo/gost/net"
+	perrors "github.com/pkg/errors"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting/consul"
+)
+
+var localIP = ""
+
+func init() {
+	localIP, _ = gxnet.GetLocalIP()
+}
+
+/////////////////////////////////////
+// consul registry
+/////////////////////////////////////
+
+// consulRegistry implements the Registry interface directly on the consul agent api. The services are
+// registered with a ttl check which is passed periodically, so the critical services of a dead process
+// are deregistered by consul, and Subscribe is driven by the blocking health queries.
+type consulRegistry struct {
+	*common.URL
+	client          *consul.Client
+	timeout         time.Duration
+	checkTTL        time.Duration
+	deregisterAfter string
+	watchTimeout    time.Duration
+	lock            sync.Mutex
+	registered      map[string]*registration   // service id -> registration
+	listeners       map[string]*consulListener // service key -> listener
+	done            chan struct{}
+	closeOnce       sync.Once
+
+	subscriptions registry.SubscriptionRecorder
+}
+
+// registration is a registered url and the stop of its ttl heartbeat
+type registration struct {
+	url  *common.URL
+	stop context.CancelFunc
+}
+
+// NewConsulRegistry returns a new consul registry
+func NewConsulRegistry(url *common.URL) (registry.Registry, error) {
+	timeout, err := time.ParseDuration(url.GetParam(constant.REGISTRY_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT))
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_TIMEOUT_KEY)
+	}
+	checkTTL, err := time.ParseDuration(url.GetParam(constant.CONSUL_CHECK_TTL_KEY, constant.CONSUL_DEFAULT_CHECK_TTL))
+	if err != nil || checkTTL <= 0 {
+		return nil, perrors.Errorf("invalid %s %s", constant.CONSUL_CHECK_TTL_KEY, url.GetParam(constant.CONSUL_CHECK_TTL_KEY, ""))
+	}
+	watchTimeout, err := time.ParseDuration(url.GetParam(constant.CONSUL_WATCH_TIMEOUT_KEY, constant.CONSUL_DEFAULT_WATCH_TIMEOUT))
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse %s", constant.CONSUL_WATCH_TIMEOUT_KEY)
+	}
+	return &consulRegistry{
+		URL:             url,
+		client:          consul.NewClient(url.Location, url.GetParam(constant.CONSUL_TOKEN_KEY, "")),
+		timeout:         timeout,
+		checkTTL:        checkTTL,
+		deregisterAfter: url.GetParam(constant.CONSUL_DEREGISTER_CRITICAL_KEY, constant.CONSUL_DEFAULT_DEREGISTER_CRITICAL),
+		watchTimeout:    watchTimeout,
+		registered:      make(map[string]*registration),
+		listeners:       make(map[string]*consulListener),
+		done:            make(chan struct{}),
+	}, nil
+}
+
+// getCategory returns the category of the registry, which depends on the role of the registry url
+func getCategory(url *common.URL) string {
+	role, _ := strconv.Atoi(url.GetParam(constant.ROLE_KEY, ""))
+	if role < 0 || role >= len(common.DubboNodes) {
+		role = common.PROVIDER
+	}
+	return common.DubboNodes[role]
+}
+
+// buildID returns the id of the url in consul, it is unique per url and category
+func buildID(url *common.URL, category string) string {
+	return fmt.Sprintf("%s-%x", url.Service(), md5.Sum([]byte(category+url.String())))
+}
+
+func (r *consulRegistry) buildService(url *common.URL) (*consul.AgentServiceRegistration, error) {
+	category := getCategory(r.URL)
+	ip := url.Ip
+	if len(ip) == 0 {
+		ip = localIP
+	}
+	port, err := strconv.Atoi(url.Port)
+	if err != nil && category == common.DubboNodes[common.PROVIDER] {
+		return nil, perrors.WithMessagef(err, "parse port of %s", url.Key())
+	}
+	id := buildID(url, category)
+	return &consul.AgentServiceRegistration{
+		ID:      id,
+		Name:    url.Service(),
+		Tags:    []string{category, constant.DUBBO},
+		Address: ip,
+		Port:    port,
+		Meta: map[string]string{
+			constant.CONSUL_URL_META_KEY: url.String(),
+		},
+		Check: &consul.AgentServiceCheck{
+			CheckID:                        consul.CheckID(id),
+			TTL:                            r.checkTTL.String(),
+			DeregisterCriticalServiceAfter: r.deregisterAfter,
+		},
+	}, nil
+}
+
+// Register registers the url to consul and keeps passing its ttl check until it is unregistered
+func (r *consulRegistry) Register(url *common.URL) error {
+	service, err := r.buildService(url)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	err = r.client.RegisterService(ctx, service)
+	cancel()
+	if err != nil {
+		return perrors.WithMessagef(err, "register service %s to consul", service.Name)
+	}
+
+	heartbeat, stop := context.WithCancel(context.Background())
+	r.lock.Lock()
+	if old, ok := r.registered[service.ID]; ok {
+		old.stop()
+	}
+	r.registered[service.ID] = &registration{url: url, stop: stop}
+	r.lock.Unlock()
+	go r.keepAlive(heartbeat, service.Check.CheckID)
+	logger.Infof("register service %s to consul, id %s", service.Name, service.ID)
+	return nil
+}
+
+// keepAlive passes the ttl check periodically, the interval is a third of the ttl
+// so that a lost heartbeat does not make the service critical
+func (r *consulRegistry) keepAlive(ctx context.Context, checkID string) {
+	ticker := time.NewTicker(r.checkTTL / 3)
+	defer ticker.Stop()
+	for {
+		if err := r.passTTL(ctx, checkID); err != nil && ctx.Err() == nil {
+			logger.Warnf("consul pass ttl check %s error {%v}", checkID, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *consulRegistry) passTTL(ctx context.Context, checkID string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.client.PassTTL(ctx, checkID)
+}
+
+// UnRegister stops the ttl heartbeat of the url and removes it from consul
+func (r *consulRegistry) UnRegister(url *common.URL) error {
+	return r.unRegister(context.Background(), url)
+}
+
+func (r *consulRegistry) unRegister(ctx context.Context, url *common.URL) error {
+	id := buildID(url, getCategory(r.URL))
+	r.lock.Lock()
+	if reg, ok := r.registered[id]; ok {
+		reg.stop()
+		delete(r.registered, id)
+	}
+	r.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.client.DeregisterService(ctx, id); err != nil {
+		return perrors.WithMessagef(err, "deregister service %s from consul", url.Service())
+	}
+	return nil
+}
+
+func (r *consulRegistry) subscribe(conf *common.URL) (*consulListener, error) {
+	if conf.IsAnyService() {
+		return nil, registry.ErrWildcardNotSupported
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.listeners[conf.ServiceKey()]; ok {
+		return nil, errAlreadySubscribed
+	}
+	listener := newConsulListener(r.client, conf, r.timeout, r.watchTimeout)
+	r.listeners[conf.ServiceKey()] = listener
+	go listener.run()
+	return listener, nil
+}
+
+func (r *consulRegistry) removeListener(conf *common.URL, listener *consulListener) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.listeners[conf.ServiceKey()] == listener {
+		delete(r.listeners, conf.ServiceKey())
+	}
+}
+
+// Subscribe watches the providers of the url and notifies the changes, it blocks until
+// the url is unsubscribed or the registry is destroyed
+func (r *consulRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	if !r.IsAvailable() {
+		logger.Warnf("event listener game over.")
+		return perrors.New("consulRegistry is not available.")
+	}
+	listener, err := r.subscribe(url)
+	if err != nil {
+		return err
+	}
+	defer r.removeListener(url, listener)
+	r.subscriptions.Subscribed(url)
+	defer r.subscriptions.Unsubscribed(url)
+	for {
+		serviceEvent, err := listener.Next()
+		if err != nil {
+			logger.Warnf("Selector.watch() = error{%v}", perrors.WithStack(err))
+			listener.Close()
+			return err
+		}
+		logger.Infof("update begin, service event: %v", serviceEvent.String())
+		r.subscriptions.Notified(url, serviceEvent)
+		notifyListener.Notify(serviceEvent)
+	}
+}
+
+// UnSubscribe stops the subscription of the url, which makes the Subscribe of the url return
+func (r *consulRegistry) UnSubscribe(url *common.URL, _ registry.NotifyListener) error {
+	r.lock.Lock()
+	listener, ok := r.listeners[url.ServiceKey()]
+	delete(r.listeners, url.ServiceKey())
+	r.lock.Unlock()
+	if ok {
+		listener.Close()
+	}
+	return nil
+}
+
+// Dump returns the registered urls and the subscriptions, the services are watched by blocking queries per subscription
+func (r *consulRegistry) Dump() *registry.RegistryDump {
+	r.lock.Lock()
+	urls := make([]*common.URL, 0, len(r.registered))
+	for _, reg := range r.registered {
+		urls = append(urls, reg.url)
+	}
+	r.lock.Unlock()
+	return &registry.RegistryDump{
+		URL:           registry.DumpURL(r.URL),
+		Available:     r.IsAvailable(),
+		Registrations: registry.DumpURLs(urls),
+		Subscriptions: r.subscriptions.Dump(),
+	}
+}
+
+// GetUrl gets its registration URL
+func (r *consulRegistry) GetUrl() common.URL {
+	return *r.URL.Clone()
+}
+
+// IsAvailable determines consul registry center whether it is available
+func (r *consulRegistry) IsAvailable() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// UnRegisterAll deregisters all the registered urls from consul
+func (r *consulRegistry) UnRegisterAll() error {
+	return r.unRegisterAll(context.Background())
+}
+
+func (r *consulRegistry) unRegisterAll(ctx context.Context) error {
+	r.lock.Lock()
+	urls := make([]*common.URL, 0, len(r.registered))
+	for _, reg := range r.registered {
+		urls = append(urls, reg.url)
+	}
+	r.lock.Unlock()
+	return registry.UnRegisterURLs(ctx, urls, func(url *common.URL) error {
+		return r.unRegister(ctx, url)
+	})
+}
+
+// Close deregisters the registered urls until ctx is done and then destroys the registry,
+// so the services are removed at once instead of after deregister-critical-service-after
+func (r *consulRegistry) Close(ctx context.Context) error {
+	err := r.unRegisterAll(ctx)
+	r.Destroy()
+	return err
+}
+
+// HealthCheck queries the raft leader of the consul cluster and returns the latency
+func (r *consulRegistry) HealthCheck(ctx context.Context) (time.Duration, error) {
+	if !r.IsAvailable() {
+		return 0, registry.ErrNotAvailable
+	}
+	start := time.Now()
+	_, err := r.client.Leader(ctx)
+	return time.Since(start), err
+}
+
+// Destroy stops all the ttl heartbeats and the listeners, the registered services are left
+// to the deregister-critical-service-after of consul
+func (r *consulRegistry) Destroy() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.lock.Lock()
+		for id, reg := range r.registered {
+			reg.stop()
+			delete(r.registered, id)
+		}
+		listeners := r.listeners
+		r.listeners = make(map[string]*consulListener)
+		r.lock.Unlock()
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	})
+}
@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/consul"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+// mockConsul is an in memory consul agent which serves the apis used by the registry
+type mockConsul struct {
+	sync.Mutex
+	index    uint64
+	services map[string]*consul.AgentServiceRegistration
+	passed   map[string]int
+	token    string
+}
+
+func (m *mockConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.Lock()
+	defer m.Unlock()
+	m.token = r.Header.Get(consul.HeaderConsulToken)
+	switch {
+	case r.URL.Path == "/v1/agent/service/register":
+		var s consul.AgentServiceRegistration
+		json.NewDecoder(r.Body).Decode(&s)
+		m.services[s.ID] = &s
+		m.index++
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		delete(m.services, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+		m.index++
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/check/pass/"):
+		m.passed[strings.TrimPrefix(r.URL.Path, "/v1/agent/check/pass/")]++
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		if r.URL.Query().Get("index") != "" {
+			// emulate the blocking query by a short wait
+			m.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			m.Lock()
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/v1/health/service/")
+		entries := []consul.ServiceEntry{}
+		for _, s := range m.services {
+			if s.Name == name && s.Tags[0] == r.URL.Query().Get("tag") {
+				entries = append(entries, consul.ServiceEntry{Service: &consul.AgentService{
+					ID: s.ID, Service: s.Name, Tags: s.Tags, Address: s.Address, Port: s.Port, Meta: s.Meta,
+				}})
+			}
+		}
+		w.Header().Set(consul.HeaderConsulIndex, strconv.FormatUint(m.index, 10))
+		json.NewEncoder(w).Encode(entries)
+	case r.URL.Path == "/v1/status/leader":
+		json.NewEncoder(w).Encode("127.0.0.1:8300")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type eventRecord struct {
+	action remoting.EventType
+	ip     string
+}
+
+type mockNotify struct {
+	events chan *eventRecord
+}
+
+func (n *mockNotify) Notify(e *registry.ServiceEvent) {
+	n.events <- &eventRecord{action: e.Action, ip: e.Service.Ip}
+}
+
+func TestConsulRegistry(t *testing.T) {
+	mock := &mockConsul{services: map[string]*consul.AgentServiceRegistration{}, passed: map[string]int{}}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	regURL, _ := common.NewURL("registry://" + strings.TrimPrefix(server.URL, "http://") +
+		"?registry.role=3&consul-token=secret&consul-check-ttl=300ms&consul-watch-timeout=50ms")
+	r, err := NewConsulRegistry(&regURL)
+	assert.Nil(t, err)
+	defer r.Destroy()
+
+	url, _ := common.NewURL("dubbo://10.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&version=1.0.0",
+		common.WithMethods([]string{"GetUser"}))
+	assert.Nil(t, r.Register(&url))
+
+	id := buildID(&url, "providers")
+	mock.Lock()
+	service := mock.services[id]
+	token := mock.token
+	mock.Unlock()
+	assert.NotNil(t, service)
+	assert.Equal(t, "com.ikurento.user.UserProvider", service.Name)
+	assert.Equal(t, "10.0.0.1", service.Address)
+	assert.Equal(t, 20000, service.Port)
+	assert.Equal(t, "300ms", service.Check.TTL)
+	assert.Equal(t, "secret", token)
+
+	// the ttl check is passed periodically
+	assert.Eventually(t, func() bool {
+		mock.Lock()
+		defer mock.Unlock()
+		return mock.passed[consul.CheckID(id)] >= 2
+	}, time.Second, 10*time.Millisecond)
+
+	notify := &mockNotify{events: make(chan *eventRecord, 8)}
+	subscribed := make(chan error, 1)
+	consumerURL, _ := common.NewURL("consumer://10.0.0.2/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&version=1.0.0")
+	go func() {
+		subscribed <- r.Subscribe(&consumerURL, notify)
+	}()
+
+	e := <-notify.events
+	assert.EqualValues(t, remoting.EventTypeAdd, e.action)
+	assert.Equal(t, "10.0.0.1", e.ip)
+
+	assert.Nil(t, r.UnRegister(&url))
+	e = <-notify.events
+	assert.EqualValues(t, remoting.EventTypeDel, e.action)
+
+	assert.Nil(t, r.UnSubscribe(&consumerURL, notify))
+	assert.NotNil(t, <-subscribed)
+}
+
+func TestConsulRegistryClose(t *testing.T) {
+	mock := &mockConsul{services: map[string]*consul.AgentServiceRegistration{}, passed: map[string]int{}}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	regURL, _ := common.NewURL("registry://" + strings.TrimPrefix(server.URL, "http://") + "?registry.role=3")
+	r, err := NewConsulRegistry(&regURL)
+	assert.Nil(t, err)
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		url, _ := common.NewURL("dubbo://"+ip+":20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider",
+			common.WithMethods([]string{"GetUser"}))
+		assert.Nil(t, r.Register(&url))
+	}
+	mock.Lock()
+	assert.Len(t, mock.services, 2)
+	mock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, r.Close(ctx))
+	assert.False(t, r.IsAvailable())
+	mock.Lock()
+	assert.Empty(t, mock.services)
+	mock.Unlock()
+}
+
+func TestConsulRegistryHealthCheck(t *testing.T) {
+	mock := &mockConsul{services: map[string]*consul.AgentServiceRegistration{}, passed: map[string]int{}}
+	server := httptest.NewServer(mock)
+
+	regURL, _ := common.NewURL("registry://" + strings.TrimPrefix(server.URL, "http://") + "?registry.role=3")
+	r, err := NewConsulRegistry(&regURL)
+	assert.Nil(t, err)
+	latency, err := r.HealthCheck(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, latency > 0)
+
+	server.Close()
+	_, err = r.HealthCheck(context.Background())
+	assert.NotNil(t, err)
+	r.Destroy()
+	_, err = r.HealthCheck(context.Background())
+	assert.Equal(t, registry.ErrNotAvailable, err)
+}
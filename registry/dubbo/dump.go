@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+// RegistryDump is the state of a registry for debugging, e.g. a "service not found" incident,
+// it is serializable to json for the admin endpoint
+type RegistryDump struct {
+	URL           string             `json:"url"`
+	Available     bool               `json:"available"`
+	Registrations []string           `json:"registrations"`
+	Subscriptions []SubscriptionDump `json:"subscriptions"`
+	Watches       []WatchDump        `json:"watches,omitempty"`
+	Retries       []RetryStatus      `json:"retries,omitempty"`
+}
+
+// SubscriptionDump is a subscription of the registry and the last event notified to it
+type SubscriptionDump struct {
+	URL       string     `json:"url"`
+	Since     time.Time  `json:"since"`
+	Events    uint64     `json:"events"`
+	LastEvent *EventDump `json:"lastEvent,omitempty"`
+}
+
+// EventDump is a notified service event
+type EventDump struct {
+	Action  string    `json:"action"`
+	Service string    `json:"service"`
+	Stale   bool      `json:"stale,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// WatchDump is a path or key watched in the registry center and the state of the watch
+type WatchDump struct {
+	Path  string `json:"path"`
+	State string `json:"state"`
+}
+
+// DumpURL returns the url without its password
+func DumpURL(url *common.URL) string {
+	if len(url.Password) == 0 {
+		return url.String()
+	}
+	masked := url.Clone()
+	masked.Password = "******"
+	return masked.String()
+}
+
+// DumpURLs returns the sorted urls without their passwords
+func DumpURLs(urls []*common.URL) []string {
+	dumps := make([]string, 0, len(urls))
+	for _, url := range urls {
+		dumps = append(dumps, DumpURL(url))
+	}
+	sort.Strings(dumps)
+	return dumps
+}
+
+// SubscriptionRecorder records the subscriptions of a registry and their last events for Dump,
+// the zero value is ready to use
+type SubscriptionRecorder struct {
+	lock          sync.Mutex
+	subscriptions map[string]*SubscriptionDump // url key -> subscription
+}
+
+// Subscribed records the subscription of the url
+func (s *SubscriptionRecorder) Subscribed(url *common.URL) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[string]*SubscriptionDump)
+	}
+	if _, ok := s.subscriptions[url.Key()]; !ok {
+		s.subscriptions[url.Key()] = &SubscriptionDump{URL: DumpURL(url), Since: time.Now()}
+	}
+}
+
+// Unsubscribed removes the subscription of the url
+func (s *SubscriptionRecorder) Unsubscribed(url *common.URL) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.subscriptions, url.Key())
+}
+
+// Notified records the event notified to the subscription of the url
+func (s *SubscriptionRecorder) Notified(url *common.URL, event *ServiceEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	subscription, ok := s.subscriptions[url.Key()]
+	if !ok {
+		return
+	}
+	subscription.Events++
+	subscription.LastEvent = &EventDump{
+		Action:  event.Action.String(),
+		Service: DumpURL(&event.Service),
+		Stale:   event.Stale,
+		Time:    time.Now(),
+	}
+}
+
+// Dump returns a copy of the subscriptions sorted by their urls
+func (s *SubscriptionRecorder) Dump() []SubscriptionDump {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	dumps := make([]SubscriptionDump, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		dump := *subscription
+		if dump.LastEvent != nil {
+			event := *dump.LastEvent
+			dump.LastEvent = &event
+		}
+		dumps = append(dumps, dump)
+	}
+	sort.Slice(dumps, func(i, j int) bool {
+		return dumps[i].URL < dumps[j].URL
+	})
+	return dumps
+}
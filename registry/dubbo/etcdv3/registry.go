@@ -0,0 +1,294 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	gxetcd "github.com/dubbogo/gost/database/kv/etcd/v3"
+	perrors "github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting/etcdv3"
+)
+
+/////////////////////////////////////
+// etcd v3 registry
+/////////////////////////////////////
+
+type etcdV3Registry struct {
+	registry.BaseRegistry
+	client  *gxetcd.Client
+	cltLock sync.Mutex
+	// listenerLock guards listener and dataListener, which are replaced after reconnecting,
+	// it is locked before the mutex of dataListener
+	listenerLock sync.Mutex
+	listener     *etcdv3.EventListener
+	dataListener *RegistryDataListener
+}
+
+// NewEtcdV3Registry returns a new etcd v3 registry
+func NewEtcdV3Registry(url *common.URL) (registry.Registry, error) {
+	r := &etcdV3Registry{}
+	r.InitBaseRegistry(url, r)
+
+	if err := etcdv3.ValidateClient(r, etcdv3.WithName(etcdv3.RegistryETCDV3Client)); err != nil {
+		return nil, err
+	}
+
+	go etcdv3.HandleClientRestart(r)
+
+	r.InitListeners()
+
+	return r, nil
+}
+
+// InitListeners initializes listeners of etcd registry center
+func (r *etcdV3Registry) InitListeners() {
+	r.cltLock.Lock()
+	client := r.client
+	r.cltLock.Unlock()
+
+	r.listenerLock.Lock()
+	oldListener := r.listener
+	r.listener = etcdv3.NewEventListener(client)
+	r.dataListener = r.recoverDataListener(client, r.dataListener)
+	r.listenerLock.Unlock()
+	// the watches of the old listener are stopped with the old client
+	if oldListener != nil {
+		oldListener.Close()
+	}
+}
+
+// recoverDataListener closes the listeners of the old data listener and subscribes their urls again,
+// the listenerLock must be held
+func (r *etcdV3Registry) recoverDataListener(client *gxetcd.Client, oldDataListener *RegistryDataListener) *RegistryDataListener {
+	newDataListener := NewRegistryDataListener()
+	// should recover if dataListener isn't nil before
+	if oldDataListener == nil {
+		return newDataListener
+	}
+	// close all old listener
+	oldDataListener.mutex.Lock()
+	defer oldDataListener.mutex.Unlock()
+	for _, oldListener := range oldDataListener.subscribed {
+		regConfigListener, ok := oldListener.(*RegistryConfigurationListener)
+		if !ok {
+			continue
+		}
+		regConfigListener.Close()
+		subscribeURL := regConfigListener.subscribeURL
+		newDataListener.SubscribeURL(subscribeURL, NewRegistryConfigurationListener(client, r, subscribeURL))
+		go r.listener.ListenServiceEvent(providersPath(subscribeURL), newDataListener)
+	}
+	return newDataListener
+}
+
+// CreatePath creates every directory of the path in the registry center of etcd
+func (r *etcdV3Registry) CreatePath(k string) error {
+	var tmpPath string
+	for _, str := range strings.Split(k, "/")[1:] {
+		tmpPath = path.Join(tmpPath, "/", str)
+		if err := r.client.Create(tmpPath, ""); err != nil && perrors.Cause(err) != gxetcd.ErrCompareFail {
+			return perrors.WithMessagef(err, "create path %s in etcd", tmpPath)
+		}
+	}
+	return nil
+}
+
+// DoRegister actually do the register job in the registry center of etcd, the node is bound to a lease
+// which is kept alive by the client, so it disappears once the client is gone
+func (r *etcdV3Registry) DoRegister(root string, node string) error {
+	r.cltLock.Lock()
+	defer r.cltLock.Unlock()
+	if r.client == nil || !r.client.Valid() {
+		return perrors.Errorf("etcd client is not valid.")
+	}
+	err := r.client.RegisterTemp(path.Join(root, node), "")
+	if err != nil {
+		logger.Errorf("Register temp node(root{%s}, node{%s}) = error{%v}", root, node, perrors.WithStack(err))
+		return perrors.WithMessagef(err, "RegisterTempNode(root{%s}, node{%s})", root, node)
+	}
+	logger.Debugf("Create a etcd node:%s", path.Join(root, node))
+	return nil
+}
+
+func (r *etcdV3Registry) DoUnregister(root string, node string) error {
+	r.cltLock.Lock()
+	defer r.cltLock.Unlock()
+	if r.client == nil || !r.client.Valid() {
+		return perrors.Errorf("etcd client is not valid.")
+	}
+	return r.client.Delete(path.Join(root, node))
+}
+
+// DoSubscribe actually subscribes the provider URL
+func (r *etcdV3Registry) DoSubscribe(conf *common.URL) (registry.Listener, error) {
+	return r.getListener(conf)
+}
+
+func (r *etcdV3Registry) DoUnsubscribe(conf *common.URL) (registry.Listener, error) {
+	return r.getCloseListener(conf)
+}
+
+// CloseAndNilClient closes listeners and clear client
+func (r *etcdV3Registry) CloseAndNilClient() {
+	r.client.Close()
+	r.client = nil
+}
+
+// nolint
+func (r *etcdV3Registry) Client() *gxetcd.Client {
+	return r.client
+}
+
+// nolint
+func (r *etcdV3Registry) SetClient(client *gxetcd.Client) {
+	r.client = client
+}
+
+// nolint
+func (r *etcdV3Registry) ClientLock() *sync.Mutex {
+	return &r.cltLock
+}
+
+// DoHealthCheck counts the keys of the dubbo root, which is a lightweight round trip to etcd
+func (r *etcdV3Registry) DoHealthCheck(ctx context.Context) error {
+	r.cltLock.Lock()
+	client := r.client
+	r.cltLock.Unlock()
+	if client == nil || client.GetRawClient() == nil {
+		return gxetcd.ErrNilETCDV3Client
+	}
+	_, err := client.GetRawClient().Get(ctx, "/dubbo", clientv3.WithPrefix(), clientv3.WithCountOnly())
+	return err
+}
+
+// DoDumpWatches returns the listened keys with the state of the client
+func (r *etcdV3Registry) DoDumpWatches() []registry.WatchDump {
+	r.listenerLock.Lock()
+	listener := r.listener
+	r.listenerLock.Unlock()
+	if listener == nil {
+		return nil
+	}
+	state := "disconnected"
+	r.cltLock.Lock()
+	if r.client != nil && r.client.Valid() {
+		state = "connected"
+	}
+	r.cltLock.Unlock()
+	keys := listener.ListenedKeys()
+	watches := make([]registry.WatchDump, 0, len(keys))
+	for _, k := range keys {
+		watches = append(watches, registry.WatchDump{Path: k, State: state})
+	}
+	return watches
+}
+
+// CloseListener closes listeners
+func (r *etcdV3Registry) CloseListener() {
+	r.listenerLock.Lock()
+	dataListener, listener := r.dataListener, r.listener
+	r.listenerLock.Unlock()
+	if dataListener != nil {
+		dataListener.Close()
+	}
+	if listener != nil {
+		listener.Close()
+	}
+}
+
+func (r *etcdV3Registry) getListener(conf *common.URL) (*RegistryConfigurationListener, error) {
+	ttl := r.GetParam(constant.REGISTRY_TTL_KEY, constant.DEFAULT_REG_TTL)
+	conf.SetParam(constant.REGISTRY_TTL_KEY, ttl)
+
+	r.cltLock.Lock()
+	client := r.client
+	r.cltLock.Unlock()
+
+	r.listenerLock.Lock()
+	defer r.listenerLock.Unlock()
+	dataListener := r.dataListener
+	dataListener.mutex.Lock()
+	defer dataListener.mutex.Unlock()
+	if old, ok := dataListener.subscribed[conf.ServiceKey()].(*RegistryConfigurationListener); ok && old != nil {
+		if old.isClosed {
+			return nil, perrors.New("configListener already been closed")
+		}
+		return old, nil
+	}
+
+	if client == nil {
+		return nil, perrors.New("etcd connection broken")
+	}
+	if r.listener == nil {
+		// new listener
+		r.listener = etcdv3.NewEventListener(client)
+	}
+
+	etcdListener := NewRegistryConfigurationListener(client, r, conf)
+	//Interested register to dataconfig.
+	dataListener.SubscribeURL(conf, etcdListener)
+
+	go r.listener.ListenServiceEvent(providersPath(conf), dataListener)
+
+	return etcdListener, nil
+}
+
+func (r *etcdV3Registry) getCloseListener(conf *common.URL) (*RegistryConfigurationListener, error) {
+	r.listenerLock.Lock()
+	dataListener := r.dataListener
+	dataListener.mutex.Lock()
+	if old, ok := dataListener.subscribed[conf.ServiceKey()].(*RegistryConfigurationListener); ok && old != nil && old.isClosed {
+		dataListener.mutex.Unlock()
+		r.listenerLock.Unlock()
+		return nil, perrors.New("configListener already been closed")
+	}
+	etcdListener, _ := dataListener.UnSubscribeURL(conf).(*RegistryConfigurationListener)
+	dataListener.mutex.Unlock()
+	listener := r.listener
+	r.listener = nil
+	r.listenerLock.Unlock()
+
+	if listener == nil {
+		return nil, perrors.New("listener is null can not close.")
+	}
+
+	dataListener.Close()
+	listener.Close()
+
+	return etcdListener, nil
+}
+
+// providersPath returns the directory of the providers of the service
+func providersPath(conf *common.URL) string {
+	if conf.IsAnyService() {
+		// watch the providers of all the services, the events are matched by the data listener
+		return "/dubbo/"
+	}
+	return fmt.Sprintf("/dubbo/%s/"+constant.DEFAULT_CATEGORY, url.QueryEscape(conf.Service()))
+}
@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/server/v3/embed"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// startEtcd starts an embedded etcd server listening clients on addr
+func startEtcd(t *testing.T, addr string) *embed.Etcd {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL := url.URL{Scheme: "http", Host: addr}
+	peerURL := url.URL{Scheme: "http", Host: freeAddr(t)}
+	cfg.LCUrls, cfg.ACUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		e.Close()
+		t.Fatal("etcd server is not ready")
+	}
+	return e
+}
+
+type chanNotifyListener chan *registry.ServiceEvent
+
+func (l chanNotifyListener) Notify(e *registry.ServiceEvent) {
+	l <- e
+}
+
+func (l chanNotifyListener) next(t *testing.T) *registry.ServiceEvent {
+	select {
+	case e := <-l:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no service event")
+	}
+	return nil
+}
+
+func newTestRegistry(t *testing.T) (*etcdV3Registry, *embed.Etcd) {
+	return newTestRegistryAt(t, freeAddr(t))
+}
+
+func newTestRegistryAt(t *testing.T, addr string) (*etcdV3Registry, *embed.Etcd) {
+	e := startEtcd(t, addr)
+	u, err := common.NewURL("registry://" + addr + "?registry.timeout=1s&registry.role=3")
+	assert.Nil(t, err)
+	r, err := NewEtcdV3Registry(&u)
+	if err != nil {
+		e.Close()
+		t.Fatal(err)
+	}
+	return r.(*etcdV3Registry), e
+}
+
+func TestEtcdV3RegistryRegister(t *testing.T) {
+	r, e := newTestRegistry(t)
+	defer e.Close()
+	defer r.Destroy()
+
+	service, _ := common.NewURL("dubbo://127.0.0.1:20000/com.mosn.test.UserProvider?interface=com.mosn.test.UserProvider&category=providers",
+		common.WithMethods([]string{"GetUser"}))
+	assert.Nil(t, r.Register(&service))
+	keys, _, err := r.client.GetChildrenKVList("/dubbo/com.mosn.test.UserProvider/providers")
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+	// register again is allowed
+	assert.True(t, r.RestartCallBack())
+
+	assert.Nil(t, r.UnRegister(&service))
+	keys, _, _ = r.client.GetChildrenKVList("/dubbo/com.mosn.test.UserProvider/providers")
+	assert.Empty(t, keys)
+}
+
+func TestEtcdV3RegistrySubscribe(t *testing.T) {
+	r, e := newTestRegistry(t)
+	defer e.Close()
+
+	service, _ := common.NewURL("dubbo://127.0.0.1:20000/com.mosn.test.UserProvider?interface=com.mosn.test.UserProvider&category=providers",
+		common.WithMethods([]string{"GetUser"}))
+	assert.Nil(t, r.Register(&service))
+
+	consumer, _ := common.NewURL("consumer://127.0.0.1/com.mosn.test.UserProvider?interface=com.mosn.test.UserProvider")
+	listener := make(chanNotifyListener, 8)
+	subscribed := make(chan error, 1)
+	go func() {
+		subscribed <- r.Subscribe(&consumer, listener)
+	}()
+	// the existing provider is notified first
+	event := listener.next(t)
+	assert.EqualValues(t, remoting.EventTypeAdd, event.Action)
+	assert.Equal(t, "com.mosn.test.UserProvider", event.Service.ServiceKey())
+
+	other, _ := common.NewURL("dubbo://127.0.0.2:20000/com.mosn.test.UserProvider?interface=com.mosn.test.UserProvider&category=providers",
+		common.WithMethods([]string{"GetUser"}))
+	assert.Nil(t, r.Register(&other))
+	event = listener.next(t)
+	assert.EqualValues(t, remoting.EventTypeAdd, event.Action)
+	assert.Equal(t, "127.0.0.2", event.Service.Ip)
+
+	assert.Nil(t, r.UnRegister(&other))
+	event = listener.next(t)
+	assert.EqualValues(t, remoting.EventTypeDel, event.Action)
+
+	r.Destroy()
+	select {
+	case err := <-subscribed:
+		assert.NotNil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscribe is not stopped by destroy")
+	}
+}
+
+func TestEtcdV3RegistryReconnect(t *testing.T) {
+	addr := freeAddr(t)
+	r, e := newTestRegistryAt(t, addr)
+	service, _ := common.NewURL("dubbo://127.0.0.1:20000/com.mosn.test.UserProvider?interface=com.mosn.test.UserProvider&category=providers",
+		common.WithMethods([]string{"GetUser"}))
+	assert.Nil(t, r.Register(&service))
+
+	// the new server has no data, the provider is registered again after reconnecting
+	lost := r.Client()
+	e.Close()
+	select {
+	case <-lost.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd client is not stopped")
+	}
+	e = startEtcd(t, addr)
+	defer e.Close()
+
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		r.cltLock.Lock()
+		client := r.client
+		r.cltLock.Unlock()
+		if client != nil && client != lost && client.Valid() {
+			keys, _, _ := client.GetChildrenKVList("/dubbo/com.mosn.test.UserProvider/providers")
+			if len(keys) == 1 {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("provider is not registered after reconnecting")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	r.Destroy()
+}
@@ -38,6 +38,8 @@ func init() {
 type ServiceEvent struct {
 	Action  remoting.EventType
 	Service common.URL
+	// Stale marks the service is loaded from the local snapshot, see SnapshotRegistry
+	Stale bool
 }
 
 // String return the description of event
@@ -45,6 +47,17 @@ func (e ServiceEvent) String() string {
 	return fmt.Sprintf("ServiceEvent{Action{%s}, Path{%s}}", e.Action, e.Service)
 }
 
+// Metadata returns the instance metadata of the service, see common.InstanceMetadata
+func (e *ServiceEvent) Metadata() common.InstanceMetadata {
+	return e.Service.GetInstanceMetadata()
+}
+
+// Weight returns the effective weight of the service at @now, which is ramped up during the warmup,
+// see common.InstanceMetadata.WarmupWeight
+func (e *ServiceEvent) Weight(now time.Time) int64 {
+	return e.Service.GetInstanceMetadata().WarmupWeight(now)
+}
+
 // Event is align with Event interface in Java.
 // it's the top abstraction
 // Align with 2.7.5
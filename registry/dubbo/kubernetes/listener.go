@@ -0,0 +1,250 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/kubernetes"
+)
+
+const (
+	// ConnDelay is the delay in seconds before retrying a failed list or watch
+	ConnDelay = 3
+)
+
+// errAlreadySubscribed is returned when the listener subscribes a service which it is subscribing
+var errAlreadySubscribed = perrors.New("service has already been subscribed")
+
+// kubernetesListener watches the endpoints of the kubernetes service which provides the subscribed url
+type kubernetesListener struct {
+	client       *kubernetes.Client
+	namespace    string
+	subscribeURL *common.URL
+	timeout      time.Duration
+	instances    map[string]*common.URL // ip:port -> url
+	events       chan *config_center.ConfigChangeEvent
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+func newKubernetesListener(client *kubernetes.Client, namespace string, conf *common.URL, timeout time.Duration) *kubernetesListener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &kubernetesListener{
+		client:       client,
+		namespace:    namespace,
+		subscribeURL: conf,
+		timeout:      timeout,
+		instances:    make(map[string]*common.URL),
+		events:       make(chan *config_center.ConfigChangeEvent, 32),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// matchService returns whether the kubernetes service provides the url
+func matchService(service *kubernetes.Service, conf *common.URL) bool {
+	annotations := service.Metadata.Annotations
+	if annotations[AnnotationVersion] != conf.GetParam(constant.VERSION_KEY, "") ||
+		annotations[AnnotationGroup] != conf.GetParam(constant.GROUP_KEY, "") {
+		return false
+	}
+	for _, intf := range strings.Split(annotations[AnnotationInterface], ",") {
+		if strings.TrimSpace(intf) == conf.Service() {
+			return true
+		}
+	}
+	return false
+}
+
+// findService returns the kubernetes service which provides the subscribed url
+func (l *kubernetesListener) findService() (*kubernetes.Service, error) {
+	ctx, cancel := context.WithTimeout(l.ctx, l.timeout)
+	defer cancel()
+	list, err := l.client.ListServices(ctx, l.namespace, LabelProvider+"=true")
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if matchService(&list.Items[i], l.subscribeURL) {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, perrors.Errorf("no kubernetes service in namespace %s provides %s", l.namespace, l.subscribeURL.ServiceKey())
+}
+
+// run lists and watches the endpoints until the listener is closed, the service is looked up again
+// once the watch ends, so the changes of the annotations are picked up
+func (l *kubernetesListener) run() {
+	for {
+		err := l.listAndWatch()
+		if l.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warnf("kubernetes watch %s error {%v}", l.subscribeURL.ServiceKey(), err)
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-time.After(ConnDelay * time.Second):
+			}
+		}
+	}
+}
+
+func (l *kubernetesListener) listAndWatch() error {
+	service, err := l.findService()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, l.timeout)
+	endpoints, err := l.client.GetEndpoints(ctx, l.namespace, service.Metadata.Name)
+	cancel()
+	if err != nil {
+		return err
+	}
+	l.update(service, endpoints)
+
+	watchCtx, watchCancel := context.WithCancel(l.ctx)
+	defer watchCancel()
+	events, err := l.client.WatchEndpoints(watchCtx, l.namespace, service.Metadata.Name, endpoints.Metadata.ResourceVersion)
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		switch event.Type {
+		case kubernetes.WatchAdded, kubernetes.WatchModified:
+			l.update(service, &event.Object)
+		case kubernetes.WatchDeleted:
+			l.update(service, &kubernetes.Endpoints{})
+		case kubernetes.WatchError:
+			return perrors.Errorf("watch endpoints %s error", service.Metadata.Name)
+		}
+	}
+	return nil
+}
+
+// endpointPort returns the port of the subset, which is the port named by the annotation of the service,
+// or the only port of the subset
+func endpointPort(service *kubernetes.Service, subset *kubernetes.EndpointSubset) (int, bool) {
+	name := service.Metadata.Annotations[AnnotationPortName]
+	if name == "" {
+		name = DefaultPortName
+	}
+	for _, port := range subset.Ports {
+		if port.Name == name {
+			return port.Port, true
+		}
+	}
+	if len(subset.Ports) == 1 {
+		return subset.Ports[0].Port, true
+	}
+	return 0, false
+}
+
+// buildURL returns the provider url of the endpoint address
+func (l *kubernetesListener) buildURL(service *kubernetes.Service, ip string, port int) *common.URL {
+	protocol := service.Metadata.Annotations[AnnotationProtocol]
+	if protocol == "" {
+		protocol = constant.DEFAULT_PROTOCOL
+	}
+	params := url.Values{}
+	params.Set(constant.INTERFACE_KEY, l.subscribeURL.Service())
+	params.Set(constant.CATEGORY_KEY, constant.PROVIDER_CATEGORY)
+	params.Set(constant.SIDE_KEY, common.DubboRole[common.PROVIDER])
+	if version := service.Metadata.Annotations[AnnotationVersion]; version != "" {
+		params.Set(constant.VERSION_KEY, version)
+	}
+	if group := service.Metadata.Annotations[AnnotationGroup]; group != "" {
+		params.Set(constant.GROUP_KEY, group)
+	}
+	return common.NewURLWithOptions(
+		common.WithProtocol(protocol),
+		common.WithIp(ip),
+		common.WithPort(strconv.Itoa(port)),
+		common.WithPath("/"+l.subscribeURL.Service()),
+		common.WithParams(params),
+	)
+}
+
+// update compares the ready addresses of the endpoints with the last ones, and emits the add and delete events
+func (l *kubernetesListener) update(service *kubernetes.Service, endpoints *kubernetes.Endpoints) {
+	newInstances := make(map[string]*common.URL)
+	for i := range endpoints.Subsets {
+		subset := &endpoints.Subsets[i]
+		port, ok := endpointPort(service, subset)
+		if !ok {
+			logger.Warnf("kubernetes endpoints %s has no port named %s", endpoints.Metadata.Name, service.Metadata.Annotations[AnnotationPortName])
+			continue
+		}
+		for _, address := range subset.Addresses {
+			u := l.buildURL(service, address.IP, port)
+			newInstances[u.Location] = u
+		}
+	}
+
+	for location, u := range newInstances {
+		old, ok := l.instances[location]
+		if !ok {
+			l.process(u, remoting.EventTypeAdd)
+		} else if old.String() != u.String() {
+			l.process(u, remoting.EventTypeUpdate)
+		}
+	}
+	for location, u := range l.instances {
+		if _, ok := newInstances[location]; !ok {
+			l.process(u, remoting.EventTypeDel)
+		}
+	}
+	l.instances = newInstances
+}
+
+func (l *kubernetesListener) process(u *common.URL, action remoting.EventType) {
+	select {
+	case l.events <- &config_center.ConfigChangeEvent{Key: u.Key(), Value: u, ConfigType: action}:
+	case <-l.ctx.Done():
+	}
+}
+
+// Next returns the next service event of the providers
+func (l *kubernetesListener) Next() (*registry.ServiceEvent, error) {
+	select {
+	case <-l.ctx.Done():
+		return nil, perrors.New("listener have been closed")
+	case e := <-l.events:
+		logger.Debugf("got kubernetes event %s", e)
+		return &registry.ServiceEvent{Action: e.ConfigType, Service: *e.Value.(*common.URL).Clone()}, nil
+	}
+}
+
+// Close stops the watch of the listener
+func (l *kubernetesListener) Close() {
+	l.cancel()
+}
@@ -0,0 +1,344 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/kubernetes"
+)
+
+// The labels and annotations of the kubernetes services which map the services to the dubbo interfaces.
+// Only the services labeled with LabelProvider=true are discovered, and the interfaces they provide
+// are listed in the AnnotationInterface as a comma separated list.
+const (
+	LabelProvider       = "dubbo.apache.org/provider"
+	AnnotationInterface = "dubbo.apache.org/interface"
+	AnnotationProtocol  = "dubbo.apache.org/protocol"
+	AnnotationVersion   = "dubbo.apache.org/version"
+	AnnotationGroup     = "dubbo.apache.org/group"
+	AnnotationPortName  = "dubbo.apache.org/port-name"
+)
+
+const (
+	// DefaultPortName is the name of the endpoint port used when the service has several ports
+	DefaultPortName = "dubbo"
+)
+
+/////////////////////////////////////
+// kubernetes registry
+/////////////////////////////////////
+
+// kubernetesRegistry discovers the providers from the endpoints of the kubernetes services, so there is no
+// registry center to deploy. The endpoints are maintained by kubernetes with the readiness of the pods,
+// so Register and UnRegister do nothing.
+type kubernetesRegistry struct {
+	*common.URL
+	client    *kubernetes.Client
+	namespace string
+	timeout   time.Duration
+	lock      sync.Mutex
+	watches   map[string]*serviceWatch // service key -> watch
+	done      chan struct{}
+	closeOnce sync.Once
+
+	subscriptions registry.SubscriptionRecorder
+}
+
+// NewKubernetesRegistry returns a new kubernetes registry. The in cluster service account is used if the
+// location of the url is empty, otherwise the location is the address of the api server and the token is
+// read from the kubernetes.token parameter.
+func NewKubernetesRegistry(url *common.URL) (registry.Registry, error) {
+	timeout, err := time.ParseDuration(url.GetParam(constant.REGISTRY_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT))
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_TIMEOUT_KEY)
+	}
+	var client *kubernetes.Client
+	if len(url.Location) == 0 || url.Location == ":" {
+		if client, err = kubernetes.NewInClusterClient(); err != nil {
+			return nil, err
+		}
+	} else {
+		client = kubernetes.NewClient(url.Location, url.GetParam(constant.KUBERNETES_TOKEN_KEY, ""), nil)
+	}
+	namespace := url.GetParam(constant.KUBERNETES_NAMESPACE_KEY, kubernetes.InClusterNamespace())
+	if namespace == "" {
+		namespace = constant.KUBERNETES_DEFAULT_NAMESPACE
+	}
+	return newKubernetesRegistry(url, client, namespace, timeout), nil
+}
+
+func newKubernetesRegistry(url *common.URL, client *kubernetes.Client, namespace string, timeout time.Duration) *kubernetesRegistry {
+	return &kubernetesRegistry{
+		URL:       url,
+		client:    client,
+		namespace: namespace,
+		timeout:   timeout,
+		watches:   make(map[string]*serviceWatch),
+		done:      make(chan struct{}),
+	}
+}
+
+// Register does nothing, the providers are published by the endpoints of their kubernetes services
+func (r *kubernetesRegistry) Register(url *common.URL) error {
+	logger.Debugf("kubernetes registry ignores the register of %s", url.Key())
+	return nil
+}
+
+// UnRegister does nothing, the providers are removed from the endpoints once their pods are not ready
+func (r *kubernetesRegistry) UnRegister(url *common.URL) error {
+	logger.Debugf("kubernetes registry ignores the unregister of %s", url.Key())
+	return nil
+}
+
+// UnRegisterAll does nothing, as the urls are never registered
+func (r *kubernetesRegistry) UnRegisterAll() error {
+	return nil
+}
+
+// Close destroys the registry
+func (r *kubernetesRegistry) Close(ctx context.Context) error {
+	r.Destroy()
+	return nil
+}
+
+// HealthCheck queries the version of the api server and returns the latency
+func (r *kubernetesRegistry) HealthCheck(ctx context.Context) (time.Duration, error) {
+	if !r.IsAvailable() {
+		return 0, registry.ErrNotAvailable
+	}
+	start := time.Now()
+	_, err := r.client.ServerVersion(ctx)
+	return time.Since(start), err
+}
+
+// serviceWatch fans out the events of the endpoints watch of a service to all the listeners subscribing it
+type serviceWatch struct {
+	listener    *kubernetesListener
+	subscribers map[registry.NotifyListener]*subscriber // guarded by the lock of the registry
+	done        chan struct{}                           // closed once the events are no longer dispatched
+
+	// lock serializes the notifications, so a new subscriber gets the current providers
+	// before the following events, without missing or repeating any of them
+	lock      sync.Mutex
+	instances map[string]*common.URL // ip:port -> url
+}
+
+type subscriber struct {
+	url      *common.URL
+	listener registry.NotifyListener
+	ready    bool // guarded by the lock of the watch, set once the current providers are notified
+	done     chan struct{}
+}
+
+func newServiceWatch(listener *kubernetesListener) *serviceWatch {
+	return &serviceWatch{
+		listener:    listener,
+		subscribers: make(map[registry.NotifyListener]*subscriber),
+		done:        make(chan struct{}),
+		instances:   make(map[string]*common.URL),
+	}
+}
+
+// comparableListener returns whether the listener can be found by UnSubscribe
+func comparableListener(listener registry.NotifyListener) bool {
+	return listener != nil && reflect.TypeOf(listener).Comparable()
+}
+
+func (r *kubernetesRegistry) subscribe(conf *common.URL, notifyListener registry.NotifyListener) (*serviceWatch, *subscriber, error) {
+	if conf.IsAnyService() {
+		return nil, nil, registry.ErrWildcardNotSupported
+	}
+	if !comparableListener(notifyListener) {
+		return nil, nil, perrors.Errorf("notify listener{%T} is not comparable", notifyListener)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	watch, ok := r.watches[conf.ServiceKey()]
+	if !ok {
+		watch = newServiceWatch(newKubernetesListener(r.client, r.namespace, conf, r.timeout))
+		r.watches[conf.ServiceKey()] = watch
+		go watch.listener.run()
+		go r.dispatch(conf.ServiceKey(), watch)
+	} else if _, ok := watch.subscribers[notifyListener]; ok {
+		return nil, nil, errAlreadySubscribed
+	}
+	sub := &subscriber{url: conf, listener: notifyListener, done: make(chan struct{})}
+	watch.subscribers[notifyListener] = sub
+	return watch, sub, nil
+}
+
+// dispatch notifies the events of the watch to its subscribers until the watch is closed
+func (r *kubernetesRegistry) dispatch(serviceKey string, watch *serviceWatch) {
+	defer func() {
+		r.lock.Lock()
+		if r.watches[serviceKey] == watch {
+			delete(r.watches, serviceKey)
+		}
+		r.lock.Unlock()
+		close(watch.done)
+	}()
+	for {
+		serviceEvent, err := watch.listener.Next()
+		if err != nil {
+			logger.Warnf("Selector.watch() = error{%v}", perrors.WithStack(err))
+			return
+		}
+		logger.Infof("update begin, service event: %v", serviceEvent.String())
+		watch.lock.Lock()
+		if serviceEvent.Action == remoting.EventTypeDel {
+			delete(watch.instances, serviceEvent.Service.Location)
+		} else {
+			watch.instances[serviceEvent.Service.Location] = &serviceEvent.Service
+		}
+		r.lock.Lock()
+		subscribers := make([]*subscriber, 0, len(watch.subscribers))
+		for _, sub := range watch.subscribers {
+			subscribers = append(subscribers, sub)
+		}
+		r.lock.Unlock()
+		for _, sub := range subscribers {
+			if sub.ready {
+				r.notify(sub, serviceEvent.Action, &serviceEvent.Service)
+			}
+		}
+		watch.lock.Unlock()
+	}
+}
+
+// notify sends a copy of the event to the subscriber, unless it is unsubscribed
+func (r *kubernetesRegistry) notify(sub *subscriber, action remoting.EventType, service *common.URL) {
+	select {
+	case <-sub.done:
+		return
+	default:
+	}
+	event := &registry.ServiceEvent{Action: action, Service: *service.Clone()}
+	r.subscriptions.Notified(sub.url, event)
+	sub.listener.Notify(event)
+}
+
+// Subscribe watches the endpoints of the service providing the url and notifies the changes, the listeners
+// subscribing the same service share the watch. It blocks until the url is unsubscribed by the listener
+// or the registry is destroyed.
+func (r *kubernetesRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	if !r.IsAvailable() {
+		logger.Warnf("event listener game over.")
+		return perrors.New("kubernetesRegistry is not available.")
+	}
+	watch, sub, err := r.subscribe(url, notifyListener)
+	if err != nil {
+		return err
+	}
+	r.subscriptions.Subscribed(url)
+	defer r.subscriptions.Unsubscribed(url)
+
+	watch.lock.Lock()
+	for _, service := range watch.instances {
+		r.notify(sub, remoting.EventTypeAdd, service)
+	}
+	sub.ready = true
+	watch.lock.Unlock()
+
+	select {
+	case <-sub.done:
+		return perrors.Errorf("subscription of %s has been stopped", url.ServiceKey())
+	case <-watch.done:
+		return perrors.Errorf("watch of %s has been closed", url.ServiceKey())
+	}
+}
+
+// removeSubscriber removes the subscriber of the listener, and closes the watch once it has no subscribers
+func (r *kubernetesRegistry) removeSubscriber(url *common.URL, notifyListener registry.NotifyListener) *subscriber {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	watch, ok := r.watches[url.ServiceKey()]
+	if !ok {
+		return nil
+	}
+	sub, ok := watch.subscribers[notifyListener]
+	if !ok {
+		return nil
+	}
+	delete(watch.subscribers, notifyListener)
+	if len(watch.subscribers) == 0 {
+		delete(r.watches, url.ServiceKey())
+		watch.listener.Close()
+	}
+	return sub
+}
+
+// UnSubscribe stops the subscription of the url by the listener, which makes its Subscribe return,
+// the other listeners subscribing the same service are still notified
+func (r *kubernetesRegistry) UnSubscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	if !comparableListener(notifyListener) {
+		return perrors.Errorf("notify listener{%T} is not comparable", notifyListener)
+	}
+	if sub := r.removeSubscriber(url, notifyListener); sub != nil {
+		close(sub.done)
+	}
+	return nil
+}
+
+// Dump returns the subscriptions, nothing is registered as the providers are the endpoints of the services
+func (r *kubernetesRegistry) Dump() *registry.RegistryDump {
+	return &registry.RegistryDump{
+		URL:           registry.DumpURL(r.URL),
+		Available:     r.IsAvailable(),
+		Registrations: []string{},
+		Subscriptions: r.subscriptions.Dump(),
+	}
+}
+
+// GetUrl gets its registration URL
+func (r *kubernetesRegistry) GetUrl() common.URL {
+	return *r.URL.Clone()
+}
+
+// IsAvailable determines kubernetes registry whether it is available
+func (r *kubernetesRegistry) IsAvailable() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy stops all the listeners
+func (r *kubernetesRegistry) Destroy() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.lock.Lock()
+		watches := r.watches
+		r.watches = make(map[string]*serviceWatch)
+		r.lock.Unlock()
+		for _, watch := range watches {
+			watch.listener.Close()
+		}
+	})
+}
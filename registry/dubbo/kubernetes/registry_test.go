@@ -0,0 +1,188 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/registry/dubbo/remoting/kubernetes"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+type mockNotify struct {
+	events chan *registry.ServiceEvent
+}
+
+func (n *mockNotify) Notify(e *registry.ServiceEvent) {
+	n.events <- e
+}
+
+func endpoints(ips ...string) kubernetes.Endpoints {
+	subset := kubernetes.EndpointSubset{Ports: []kubernetes.EndpointPort{{Name: "dubbo", Port: 20000}, {Name: "http", Port: 8080}}}
+	for _, ip := range ips {
+		subset.Addresses = append(subset.Addresses, kubernetes.EndpointAddress{IP: ip})
+	}
+	return kubernetes.Endpoints{Metadata: kubernetes.ObjectMeta{Name: "user", ResourceVersion: "1"}, Subsets: []kubernetes.EndpointSubset{subset}}
+}
+
+// newEndpointsServer returns the api server providing the user service, the events sent to watch are
+// written to the endpoints watches, and the number of the watches is counted by watches
+func newEndpointsServer(t *testing.T, watch chan kubernetes.EndpointsWatchEvent, watches *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/dubbo/services":
+			assert.Equal(t, LabelProvider+"=true", r.URL.Query().Get("labelSelector"))
+			json.NewEncoder(w).Encode(kubernetes.ServiceList{Items: []kubernetes.Service{
+				{Metadata: kubernetes.ObjectMeta{Name: "other", Annotations: map[string]string{
+					AnnotationInterface: "com.ikurento.user.UserProvider",
+					AnnotationVersion:   "2.0.0",
+				}}},
+				{Metadata: kubernetes.ObjectMeta{Name: "user", Annotations: map[string]string{
+					AnnotationInterface: "com.ikurento.user.OrderProvider, com.ikurento.user.UserProvider",
+					AnnotationVersion:   "1.0.0",
+					AnnotationProtocol:  "tri",
+				}}},
+			}})
+		case r.URL.Path == "/api/v1/namespaces/dubbo/endpoints/user":
+			json.NewEncoder(w).Encode(endpoints("10.0.0.1"))
+		case r.URL.Path == "/api/v1/namespaces/dubbo/endpoints" && r.URL.Query().Get("watch") == "true":
+			watches.Inc()
+			assert.Equal(t, "metadata.name=user", r.URL.Query().Get("fieldSelector"))
+			assert.Equal(t, "1", r.URL.Query().Get("resourceVersion"))
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case e := <-watch:
+					json.NewEncoder(w).Encode(e)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestKubernetesRegistry(t *testing.T) {
+	watch := make(chan kubernetes.EndpointsWatchEvent)
+	server := newEndpointsServer(t, watch, atomic.NewInt32(0))
+	defer server.Close()
+
+	regURL, _ := common.NewURL("registry://" + strings.TrimPrefix(server.URL, "http://") + "?kubernetes.namespace=dubbo&kubernetes.token=secret")
+	r := newKubernetesRegistry(&regURL, kubernetes.NewClient(server.URL, "secret", nil), "dubbo", time.Second)
+	defer r.Destroy()
+
+	notify := &mockNotify{events: make(chan *registry.ServiceEvent, 8)}
+	subscribed := make(chan error, 1)
+	consumerURL, _ := common.NewURL("consumer://10.0.0.9/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&version=1.0.0")
+	go func() {
+		subscribed <- r.Subscribe(&consumerURL, notify)
+	}()
+
+	e := <-notify.events
+	assert.EqualValues(t, remoting.EventTypeAdd, e.Action)
+	assert.Equal(t, "tri", e.Service.Protocol)
+	assert.Equal(t, "10.0.0.1:20000", e.Service.Location)
+	assert.Equal(t, consumerURL.ServiceKey(), e.Service.ServiceKey())
+
+	watch <- kubernetes.EndpointsWatchEvent{Type: kubernetes.WatchModified, Object: endpoints("10.0.0.2")}
+	events := map[remoting.EventType]string{}
+	for i := 0; i < 2; i++ {
+		e = <-notify.events
+		events[e.Action] = e.Service.Location
+	}
+	assert.Equal(t, map[remoting.EventType]string{
+		remoting.EventTypeAdd: "10.0.0.2:20000",
+		remoting.EventTypeDel: "10.0.0.1:20000",
+	}, events)
+
+	assert.Nil(t, r.UnSubscribe(&consumerURL, notify))
+	assert.NotNil(t, <-subscribed)
+}
+
+func TestKubernetesRegistryMultipleListeners(t *testing.T) {
+	watch := make(chan kubernetes.EndpointsWatchEvent)
+	watches := atomic.NewInt32(0)
+	server := newEndpointsServer(t, watch, watches)
+	defer server.Close()
+
+	regURL, _ := common.NewURL("registry://" + strings.TrimPrefix(server.URL, "http://") + "?kubernetes.namespace=dubbo&kubernetes.token=secret")
+	r := newKubernetesRegistry(&regURL, kubernetes.NewClient(server.URL, "secret", nil), "dubbo", time.Second)
+	defer r.Destroy()
+
+	consumerURL, _ := common.NewURL("consumer://10.0.0.9/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&version=1.0.0")
+	first := &mockNotify{events: make(chan *registry.ServiceEvent, 8)}
+	firstSubscribed := make(chan error, 1)
+	go func() {
+		firstSubscribed <- r.Subscribe(&consumerURL, first)
+	}()
+	e := <-first.events
+	assert.EqualValues(t, remoting.EventTypeAdd, e.Action)
+	assert.Equal(t, "10.0.0.1:20000", e.Service.Location)
+
+	// the second listener shares the watch, and gets the current providers first
+	second := &mockNotify{events: make(chan *registry.ServiceEvent, 8)}
+	secondSubscribed := make(chan error, 1)
+	go func() {
+		secondSubscribed <- r.Subscribe(&consumerURL, second)
+	}()
+	e = <-second.events
+	assert.EqualValues(t, remoting.EventTypeAdd, e.Action)
+	assert.Equal(t, "10.0.0.1:20000", e.Service.Location)
+	assert.Equal(t, errAlreadySubscribed, r.Subscribe(&consumerURL, first))
+
+	watch <- kubernetes.EndpointsWatchEvent{Type: kubernetes.WatchModified, Object: endpoints("10.0.0.1", "10.0.0.2")}
+	for _, notify := range []*mockNotify{first, second} {
+		e = <-notify.events
+		assert.EqualValues(t, remoting.EventTypeAdd, e.Action)
+		assert.Equal(t, "10.0.0.2:20000", e.Service.Location)
+	}
+	assert.Equal(t, int32(1), watches.Load())
+
+	// only the subscription of the first listener is stopped
+	assert.Nil(t, r.UnSubscribe(&consumerURL, first))
+	assert.NotNil(t, <-firstSubscribed)
+	watch <- kubernetes.EndpointsWatchEvent{Type: kubernetes.WatchModified, Object: endpoints("10.0.0.2")}
+	e = <-second.events
+	assert.EqualValues(t, remoting.EventTypeDel, e.Action)
+	assert.Equal(t, "10.0.0.1:20000", e.Service.Location)
+	assert.Empty(t, first.events)
+
+	assert.Nil(t, r.UnSubscribe(&consumerURL, second))
+	assert.NotNil(t, <-secondSubscribed)
+	r.lock.Lock()
+	assert.Empty(t, r.watches)
+	r.lock.Unlock()
+}
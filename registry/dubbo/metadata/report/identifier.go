@@ -0,0 +1,135 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"net/url"
+	"strings"
+
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+)
+
+// BaseMetadataIdentifier identifies the metadata of a service interface
+type BaseMetadataIdentifier struct {
+	ServiceInterface string
+	Version          string
+	Group            string
+	Side             string
+}
+
+// getIdentifierKey returns interface:version:group:side:params
+func (m *BaseMetadataIdentifier) getIdentifierKey(params ...string) string {
+	return m.ServiceInterface + constant.KEY_SEPARATOR + m.Version + constant.KEY_SEPARATOR + m.Group +
+		constant.KEY_SEPARATOR + m.Side + joinParams(constant.KEY_SEPARATOR, params)
+}
+
+// getFilePathKey returns metadata/interface/version/group/side/params, the empty segments are skipped
+func (m *BaseMetadataIdentifier) getFilePathKey(params ...string) string {
+	return constant.DEFAULT_PATH_TAG + withPathSeparator(serviceToPath(m.ServiceInterface)) + withPathSeparator(m.Version) +
+		withPathSeparator(m.Group) + withPathSeparator(m.Side) + joinParams(constant.PATH_SEPARATOR, params)
+}
+
+// MetadataIdentifier identifies the service definition of an application
+type MetadataIdentifier struct {
+	BaseMetadataIdentifier
+	Application string
+}
+
+// NewMetadataIdentifier reads the interface, version, group, side and application params of the url
+func NewMetadataIdentifier(url *common.URL) *MetadataIdentifier {
+	return &MetadataIdentifier{
+		BaseMetadataIdentifier: BaseMetadataIdentifier{
+			ServiceInterface: url.GetParam(constant.INTERFACE_KEY, url.Service()),
+			Version:          url.GetParam(constant.VERSION_KEY, ""),
+			Group:            url.GetParam(constant.GROUP_KEY, ""),
+			Side:             url.GetParam(constant.SIDE_KEY, constant.PROVIDER_PROTOCOL),
+		},
+		Application: url.GetParam(constant.APPLICATION_KEY, ""),
+	}
+}
+
+// GetIdentifierKey returns interface:version:group:side:application
+func (m *MetadataIdentifier) GetIdentifierKey() string {
+	return m.getIdentifierKey(m.Application)
+}
+
+// GetFilePathKey returns metadata/interface/version/group/side/application
+func (m *MetadataIdentifier) GetFilePathKey() string {
+	return m.getFilePathKey(m.Application)
+}
+
+// ServiceMetadataIdentifier identifies the exported urls of a service of a revision
+type ServiceMetadataIdentifier struct {
+	BaseMetadataIdentifier
+	Revision string
+	Protocol string
+}
+
+// GetIdentifierKey returns interface:version:group:side:protocol:revision{revision}
+func (m *ServiceMetadataIdentifier) GetIdentifierKey() string {
+	return m.getIdentifierKey(m.Protocol, constant.KEY_REVISON_PREFIX+m.Revision)
+}
+
+// GetFilePathKey returns metadata/interface/version/group/side/protocol/revision{revision}
+func (m *ServiceMetadataIdentifier) GetFilePathKey() string {
+	return m.getFilePathKey(m.Protocol, constant.KEY_REVISON_PREFIX+m.Revision)
+}
+
+// SubscriberMetadataIdentifier identifies the subscribed urls of an application of a revision
+type SubscriberMetadataIdentifier struct {
+	Application string
+	Revision    string
+}
+
+// GetIdentifierKey returns application:revision
+func (m *SubscriberMetadataIdentifier) GetIdentifierKey() string {
+	return m.Application + constant.KEY_SEPARATOR + m.Revision
+}
+
+// GetFilePathKey returns metadata/application/revision
+func (m *SubscriberMetadataIdentifier) GetFilePathKey() string {
+	return constant.DEFAULT_PATH_TAG + withPathSeparator(m.Application) + withPathSeparator(m.Revision)
+}
+
+func serviceToPath(serviceInterface string) string {
+	if serviceInterface == constant.ANY_VALUE {
+		return ""
+	}
+	decoded, err := url.PathUnescape(serviceInterface)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(decoded, constant.PATH_SEPARATOR)
+}
+
+func withPathSeparator(path string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return constant.PATH_SEPARATOR + path
+}
+
+func joinParams(separator string, params []string) string {
+	var joined strings.Builder
+	for _, param := range params {
+		joined.WriteString(separator)
+		joined.WriteString(param)
+	}
+	return joined.String()
+}
@@ -0,0 +1,115 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"encoding/json"
+
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+// MetadataReport stores and retrieves the metadata of the providers and the consumers, e.g. the method
+// signatures which are needed by the generic invocation, in the metadata report center
+type MetadataReport interface {
+	// StoreProviderMetadata stores the json of the FullServiceDefinition of the provider
+	StoreProviderMetadata(id *MetadataIdentifier, serviceDefinitions string) error
+	// StoreConsumerMetadata stores the json of the params of the consumer
+	StoreConsumerMetadata(id *MetadataIdentifier, serviceParameters string) error
+	// SaveServiceMetadata stores the exported url of the service
+	SaveServiceMetadata(id *ServiceMetadataIdentifier, url *common.URL) error
+	// RemoveServiceMetadata removes the exported url of the service
+	RemoveServiceMetadata(id *ServiceMetadataIdentifier) error
+	// GetExportedURLs returns the exported urls of the service
+	GetExportedURLs(id *ServiceMetadataIdentifier) ([]string, error)
+	// SaveSubscribedData stores the json of the subscribed urls of the application
+	SaveSubscribedData(id *SubscriberMetadataIdentifier, urls string) error
+	// GetSubscribedURLs returns the subscribed urls of the application
+	GetSubscribedURLs(id *SubscriberMetadataIdentifier) ([]string, error)
+	// GetServiceDefinition returns the json of the FullServiceDefinition stored by StoreProviderMetadata
+	GetServiceDefinition(id *MetadataIdentifier) (string, error)
+	// Destroy closes the connection to the metadata report center
+	Destroy()
+}
+
+// ServiceDefinition is the definition of a service interface, it is compatible with the json of dubbo
+type ServiceDefinition struct {
+	CanonicalName string             `json:"canonicalName"`
+	CodeSource    string             `json:"codeSource"`
+	Methods       []MethodDefinition `json:"methods"`
+	Types         []TypeDefinition   `json:"types"`
+	Annotations   []string           `json:"annotations,omitempty"`
+}
+
+// FullServiceDefinition is the service definition with the params of the provider
+type FullServiceDefinition struct {
+	ServiceDefinition
+	Parameters map[string]string `json:"parameters"`
+}
+
+// MethodDefinition is the signature of a method
+type MethodDefinition struct {
+	Name           string           `json:"name"`
+	ParameterTypes []string         `json:"parameterTypes"`
+	ReturnType     string           `json:"returnType"`
+	Parameters     []TypeDefinition `json:"parameters"`
+	Annotations    []string         `json:"annotations,omitempty"`
+}
+
+// TypeDefinition is the definition of a type, the items, enums and properties refer to the other types by names
+type TypeDefinition struct {
+	Type            string            `json:"type"`
+	Items           []string          `json:"items,omitempty"`
+	Enums           []string          `json:"enums,omitempty"`
+	Ref             string            `json:"$ref,omitempty"`
+	Properties      map[string]string `json:"properties,omitempty"`
+	TypeBuilderName string            `json:"typeBuilderName,omitempty"`
+}
+
+// FindMethods returns the overloads of the method
+func (d *ServiceDefinition) FindMethods(name string) []MethodDefinition {
+	var methods []MethodDefinition
+	for _, method := range d.Methods {
+		if method.Name == name {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// StoreProviderDefinition stores the service definition of the provider as json
+func StoreProviderDefinition(report MetadataReport, id *MetadataIdentifier, definition *FullServiceDefinition) error {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return perrors.WithMessagef(err, "json.Marshal(definition:%s)", id.GetIdentifierKey())
+	}
+	return report.StoreProviderMetadata(id, string(data))
+}
+
+// GetProviderDefinition returns the service definition stored by the provider
+func GetProviderDefinition(report MetadataReport, id *MetadataIdentifier) (*FullServiceDefinition, error) {
+	data, err := report.GetServiceDefinition(id)
+	if err != nil {
+		return nil, err
+	}
+	definition := &FullServiceDefinition{}
+	if err = json.Unmarshal([]byte(data), definition); err != nil {
+		return nil, perrors.WithMessagef(err, "json.Unmarshal(definition:%s)", id.GetIdentifierKey())
+	}
+	return definition, nil
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+func TestMetadataIdentifier(t *testing.T) {
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&version=1.0.0&group=gray&application=user")
+	id := NewMetadataIdentifier(&url)
+	assert.Equal(t, "com.ikurento.user.UserProvider:1.0.0:gray:provider:user", id.GetIdentifierKey())
+	assert.Equal(t, "metadata/com.ikurento.user.UserProvider/1.0.0/gray/provider/user", id.GetFilePathKey())
+
+	// the empty segments are skipped in the path
+	id.Version, id.Group = "", ""
+	assert.Equal(t, "metadata/com.ikurento.user.UserProvider/provider/user", id.GetFilePathKey())
+
+	serviceID := &ServiceMetadataIdentifier{
+		BaseMetadataIdentifier: BaseMetadataIdentifier{ServiceInterface: "com.ikurento.user.UserProvider", Side: "provider"},
+		Revision:               "1",
+		Protocol:               "dubbo",
+	}
+	assert.Equal(t, "com.ikurento.user.UserProvider:::provider:dubbo:revision1", serviceID.GetIdentifierKey())
+	assert.Equal(t, "metadata/com.ikurento.user.UserProvider/provider/dubbo/revision1", serviceID.GetFilePathKey())
+
+	subscriberID := &SubscriberMetadataIdentifier{Application: "user", Revision: "1"}
+	assert.Equal(t, "user:1", subscriberID.GetIdentifierKey())
+	assert.Equal(t, "metadata/user/1", subscriberID.GetFilePathKey())
+}
+
+// memoryMetadataReport stores the definitions in memory
+type memoryMetadataReport struct {
+	MetadataReport
+	definitions map[string]string
+}
+
+func (r *memoryMetadataReport) StoreProviderMetadata(id *MetadataIdentifier, serviceDefinitions string) error {
+	r.definitions[id.GetFilePathKey()] = serviceDefinitions
+	return nil
+}
+
+func (r *memoryMetadataReport) GetServiceDefinition(id *MetadataIdentifier) (string, error) {
+	definition, ok := r.definitions[id.GetFilePathKey()]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return definition, nil
+}
+
+func TestProviderDefinition(t *testing.T) {
+	r := &memoryMetadataReport{definitions: make(map[string]string)}
+	id := &MetadataIdentifier{
+		BaseMetadataIdentifier: BaseMetadataIdentifier{ServiceInterface: "com.ikurento.user.UserProvider", Side: "provider"},
+		Application:            "user",
+	}
+	_, err := GetProviderDefinition(r, id)
+	assert.Error(t, err)
+
+	// the json stored by a java provider
+	r.definitions[id.GetFilePathKey()] = `{"parameters":{"side":"provider"},"canonicalName":"com.ikurento.user.UserProvider",` +
+		`"codeSource":"file:/user.jar","methods":[{"name":"GetUser","parameterTypes":["java.lang.String"],"returnType":"com.ikurento.user.User",` +
+		`"parameters":[],"annotations":[]},{"name":"GetUser","parameterTypes":["int"],"returnType":"com.ikurento.user.User","parameters":[]}],` +
+		`"types":[{"type":"com.ikurento.user.User","properties":{"name":"java.lang.String","age":"int"}},{"type":"int"}],"annotations":[]}`
+	definition, err := GetProviderDefinition(r, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "provider", definition.Parameters["side"])
+	methods := definition.FindMethods("GetUser")
+	assert.Len(t, methods, 2)
+	assert.Equal(t, []string{"int"}, methods[1].ParameterTypes)
+	assert.Equal(t, "int", definition.Types[0].Properties["age"])
+	assert.Empty(t, definition.FindMethods("GetUsers"))
+
+	assert.NoError(t, StoreProviderDefinition(r, id, definition))
+	stored, err := GetProviderDefinition(r, id)
+	assert.NoError(t, err)
+	assert.Equal(t, definition.Methods[1], stored.Methods[1])
+	assert.Equal(t, definition.Types, stored.Types)
+}
@@ -0,0 +1,219 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/metadata/report"
+	"mosn.io/pkg/registry/dubbo/remoting/zookeeper"
+)
+
+const (
+	// ZkClient is the name of the zookeeper client of the metadata report
+	ZkClient = "zk metadata report"
+	// DefaultRootDir is the root of the metadata if the group param is absent
+	DefaultRootDir = "dubbo"
+)
+
+// zookeeperMetadataReport stores the metadata under /{group}/metadata, which is compatible with dubbo
+type zookeeperMetadataReport struct {
+	url      *common.URL
+	rootDir  string
+	wg       sync.WaitGroup
+	cltLock  sync.Mutex
+	done     chan struct{}
+	client   *zookeeper.ZookeeperClient
+	doneOnce sync.Once
+}
+
+// NewZookeeperMetadataReport connects to the zookeeper of the url, the root of the metadata is
+// the group param, the auth, codec and chroot are the same as the zookeeper registry
+func NewZookeeperMetadataReport(url *common.URL) (report.MetadataReport, error) {
+	rootDir := strings.Trim(url.GetParam(constant.GROUP_KEY, DefaultRootDir), constant.PATH_SEPARATOR)
+	m := &zookeeperMetadataReport{
+		url:     url,
+		rootDir: constant.PATH_SEPARATOR + rootDir + constant.PATH_SEPARATOR,
+		done:    make(chan struct{}),
+	}
+	if rootDir == "" {
+		m.rootDir = constant.PATH_SEPARATOR
+	}
+	if err := zookeeper.ValidateZookeeperClient(m, zookeeper.WithZkName(ZkClient)); err != nil {
+		logger.Errorf("zookeeper client start error ,error message is %v", err)
+		return nil, err
+	}
+	m.wg.Add(1)
+	go zookeeper.HandleClientRestart(m)
+	return m, nil
+}
+
+func (m *zookeeperMetadataReport) path(key string) string {
+	return m.rootDir + key
+}
+
+func (m *zookeeperMetadataReport) getClient() (*zookeeper.ZookeeperClient, error) {
+	m.cltLock.Lock()
+	defer m.cltLock.Unlock()
+	if m.client == nil {
+		return nil, zookeeper.ErrNotConnected
+	}
+	return m.client, nil
+}
+
+// store replaces the content of the node, the node and its parents are created if absent
+func (m *zookeeperMetadataReport) store(key string, value string) error {
+	client, err := m.getClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.UpdateContent(m.path(key), func([]byte) ([]byte, error) {
+		return []byte(value), nil
+	}, zookeeper.DefaultUpdateRetries)
+	return perrors.WithMessagef(err, "store metadata %s", key)
+}
+
+func (m *zookeeperMetadataReport) get(key string) (string, error) {
+	client, err := m.getClient()
+	if err != nil {
+		return "", err
+	}
+	content, _, err := client.GetContent(m.path(key))
+	if err != nil {
+		return "", perrors.WithMessagef(err, "get metadata %s", key)
+	}
+	return string(content), nil
+}
+
+// StoreProviderMetadata stores the service definition of the provider
+func (m *zookeeperMetadataReport) StoreProviderMetadata(id *report.MetadataIdentifier, serviceDefinitions string) error {
+	return m.store(id.GetFilePathKey(), serviceDefinitions)
+}
+
+// StoreConsumerMetadata stores the params of the consumer
+func (m *zookeeperMetadataReport) StoreConsumerMetadata(id *report.MetadataIdentifier, serviceParameters string) error {
+	return m.store(id.GetFilePathKey(), serviceParameters)
+}
+
+// SaveServiceMetadata stores the escaped url as the content of the node
+func (m *zookeeperMetadataReport) SaveServiceMetadata(id *report.ServiceMetadataIdentifier, u *common.URL) error {
+	return m.store(id.GetFilePathKey(), url.QueryEscape(u.String()))
+}
+
+// RemoveServiceMetadata deletes the node of the service
+func (m *zookeeperMetadataReport) RemoveServiceMetadata(id *report.ServiceMetadataIdentifier) error {
+	client, err := m.getClient()
+	if err != nil {
+		return err
+	}
+	err = client.Delete(m.path(id.GetFilePathKey()))
+	if perrors.Cause(err) == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+// GetExportedURLs returns the url stored by SaveServiceMetadata, nothing is returned if it is absent
+func (m *zookeeperMetadataReport) GetExportedURLs(id *report.ServiceMetadataIdentifier) ([]string, error) {
+	content, err := m.get(id.GetFilePathKey())
+	if perrors.Cause(err) == zk.ErrNoNode {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		return []string{}, nil
+	}
+	u, err := url.QueryUnescape(content)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "url.QueryUnescape(%s)", content)
+	}
+	return []string{u}, nil
+}
+
+// SaveSubscribedData stores the subscribed urls of the application
+func (m *zookeeperMetadataReport) SaveSubscribedData(id *report.SubscriberMetadataIdentifier, urls string) error {
+	return m.store(id.GetFilePathKey(), urls)
+}
+
+// GetSubscribedURLs returns the subscribed urls stored by SaveSubscribedData
+func (m *zookeeperMetadataReport) GetSubscribedURLs(id *report.SubscriberMetadataIdentifier) ([]string, error) {
+	content, err := m.get(id.GetFilePathKey())
+	if perrors.Cause(err) == zk.ErrNoNode {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []string{content}, nil
+}
+
+// GetServiceDefinition returns the service definition stored by StoreProviderMetadata
+func (m *zookeeperMetadataReport) GetServiceDefinition(id *report.MetadataIdentifier) (string, error) {
+	return m.get(id.GetFilePathKey())
+}
+
+// Destroy closes the zookeeper client
+func (m *zookeeperMetadataReport) Destroy() {
+	m.doneOnce.Do(func() {
+		close(m.done)
+		m.wg.Wait()
+		m.cltLock.Lock()
+		defer m.cltLock.Unlock()
+		if m.client != nil {
+			m.client.Close()
+			m.client = nil
+		}
+	})
+}
+
+func (m *zookeeperMetadataReport) ZkClient() *zookeeper.ZookeeperClient {
+	return m.client
+}
+
+func (m *zookeeperMetadataReport) SetZkClient(client *zookeeper.ZookeeperClient) {
+	m.client = client
+}
+
+func (m *zookeeperMetadataReport) ZkClientLock() *sync.Mutex {
+	return &m.cltLock
+}
+
+func (m *zookeeperMetadataReport) WaitGroup() *sync.WaitGroup {
+	return &m.wg
+}
+
+func (m *zookeeperMetadataReport) Done() chan struct{} {
+	return m.done
+}
+
+func (m *zookeeperMetadataReport) RestartCallBack() bool {
+	return true
+}
+
+func (m *zookeeperMetadataReport) GetUrl() common.URL {
+	return *m.url.Clone()
+}
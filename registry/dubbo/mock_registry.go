@@ -18,42 +18,236 @@
 package dubbo
 
 import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
+	"go.uber.org/atomic"
 	"mosn.io/pkg/registry/dubbo/common"
 	"mosn.io/pkg/registry/dubbo/common/logger"
-	"go.uber.org/atomic"
 )
 
-// MockRegistry ...
+var (
+	// ErrMockFailure is returned by the calls of MockRegistry failed by MockFailures.RegisterErrorRate
+	ErrMockFailure = errors.New("mock registry failure")
+	// ErrMockDisconnected is returned by the calls of MockRegistry while it is disconnected
+	ErrMockDisconnected = errors.New("mock registry is disconnected")
+)
+
+// the methods recorded in MockCall
+const (
+	MockCallRegister      = "Register"
+	MockCallUnRegister    = "UnRegister"
+	MockCallUnRegisterAll = "UnRegisterAll"
+	MockCallSubscribe     = "Subscribe"
+	MockCallUnSubscribe   = "UnSubscribe"
+)
+
+// MockFailures are the failure modes of MockRegistry, the zero value injects no failure
+type MockFailures struct {
+	// RegisterErrorRate is the probability in [0, 1] of Register and UnRegister failing with ErrMockFailure
+	RegisterErrorRate float64
+	// NotifyDelay delays the notification of every event
+	NotifyDelay time.Duration
+	// Burst notifies every mocked event Burst times, values below 2 notify it once
+	Burst int
+}
+
+// MockCall is a call received by MockRegistry
+type MockCall struct {
+	Method string
+	URL    *common.URL
+	Err    error
+}
+
+// MockRegistry is a scripted registry for testing, the events are mocked by MockEvent and the failures
+// are injected by SetFailures and Disconnect, the received calls are recorded for the assertions
 type MockRegistry struct {
 	listener  *listener
 	destroyed *atomic.Bool
+	done      chan struct{}
+
+	lock        sync.Mutex
+	failures    MockFailures
+	calls       []MockCall
+	registered  map[string]*common.URL // url key -> url
+	connected   bool
+	reconnected chan struct{} // closed once the registry is reconnected
+
+	subscriptions SubscriptionRecorder
 }
 
 // NewMockRegistry ...
 func NewMockRegistry(url *common.URL) (Registry, error) {
 	registry := &MockRegistry{
-		destroyed: atomic.NewBool(false),
+		destroyed:  atomic.NewBool(false),
+		done:       make(chan struct{}),
+		registered: make(map[string]*common.URL),
+		connected:  true,
 	}
 	listener := &listener{count: 0, registry: registry, listenChan: make(chan *ServiceEvent)}
 	registry.listener = listener
 	return registry, nil
 }
 
-// Register ...
-func (*MockRegistry) Register(url *common.URL) error {
+// SetFailures replaces the failure modes of the registry
+func (r *MockRegistry) SetFailures(failures MockFailures) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.failures = failures
+}
+
+// Disconnect makes the calls fail with ErrMockDisconnected and holds the notifications until Reconnect
+func (r *MockRegistry) Disconnect() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.connected {
+		r.connected = false
+		r.reconnected = make(chan struct{})
+	}
+}
+
+// Reconnect recovers the registry from Disconnect, the held notifications are delivered
+func (r *MockRegistry) Reconnect() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.connected {
+		r.connected = true
+		close(r.reconnected)
+	}
+}
+
+// Flap disconnects the registry and reconnects it after @down
+func (r *MockRegistry) Flap(down time.Duration) {
+	r.Disconnect()
+	time.AfterFunc(down, r.Reconnect)
+}
+
+// Calls returns the received calls of the method in order, all the calls if @method is empty
+func (r *MockRegistry) Calls(method string) []MockCall {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var calls []MockCall
+	for _, call := range r.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// CallCount returns the number of the received calls of the method
+func (r *MockRegistry) CallCount(method string) int {
+	return len(r.Calls(method))
+}
+
+// ResetCalls forgets the received calls
+func (r *MockRegistry) ResetCalls() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = nil
+}
+
+// Registered returns the registered urls sorted by their keys
+func (r *MockRegistry) Registered() []*common.URL {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	keys := make([]string, 0, len(r.registered))
+	for key := range r.registered {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	urls := make([]*common.URL, 0, len(keys))
+	for _, key := range keys {
+		urls = append(urls, r.registered[key])
+	}
+	return urls
+}
+
+func (r *MockRegistry) record(method string, url *common.URL, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = append(r.calls, MockCall{Method: method, URL: url, Err: err})
+}
+
+// fail returns the injected failure of the call
+func (r *MockRegistry) fail() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.connected {
+		return ErrMockDisconnected
+	}
+	if r.failures.RegisterErrorRate > 0 && rand.Float64() < r.failures.RegisterErrorRate {
+		return ErrMockFailure
+	}
 	return nil
 }
 
+// Register ...
+func (r *MockRegistry) Register(url *common.URL) error {
+	err := r.fail()
+	if err == nil {
+		r.lock.Lock()
+		r.registered[url.Key()] = url
+		r.lock.Unlock()
+	}
+	r.record(MockCallRegister, url, err)
+	return err
+}
+
 // UnRegister
 func (r *MockRegistry) UnRegister(conf *common.URL) error {
+	err := r.fail()
+	if err == nil {
+		r.lock.Lock()
+		delete(r.registered, conf.Key())
+		r.lock.Unlock()
+	}
+	r.record(MockCallUnRegister, conf, err)
+	return err
+}
+
+// UnRegisterAll ...
+func (r *MockRegistry) UnRegisterAll() error {
+	r.record(MockCallUnRegisterAll, nil, nil)
+	return UnRegisterURLs(context.Background(), r.Registered(), r.UnRegister)
+}
+
+// Close ...
+func (r *MockRegistry) Close(ctx context.Context) error {
+	r.Destroy()
 	return nil
 }
 
+// HealthCheck ...
+func (r *MockRegistry) HealthCheck(ctx context.Context) (time.Duration, error) {
+	if !r.IsAvailable() {
+		return 0, ErrNotAvailable
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.connected {
+		return 0, ErrMockDisconnected
+	}
+	return 0, nil
+}
+
+// Dump ...
+func (r *MockRegistry) Dump() *RegistryDump {
+	return &RegistryDump{
+		Available:     r.IsAvailable(),
+		Registrations: DumpURLs(r.Registered()),
+		Subscriptions: r.subscriptions.Dump(),
+	}
+}
+
 // Destroy ...
 func (r *MockRegistry) Destroy() {
 	if r.destroyed.CAS(false, true) {
+		close(r.done)
 	}
 }
 
@@ -73,7 +267,10 @@ func (r *MockRegistry) subscribe(*common.URL) (Listener, error) {
 
 // Subscribe ...
 func (r *MockRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	r.record(MockCallSubscribe, url, nil)
+	r.subscriptions.Subscribed(url)
 	go func() {
+		defer r.subscriptions.Unsubscribed(url)
 		for {
 			if !r.IsAvailable() {
 				logger.Warnf("event listener game over.")
@@ -99,7 +296,11 @@ func (r *MockRegistry) Subscribe(url *common.URL, notifyListener NotifyListener)
 					return
 				}
 
+				if !r.waitNotify() {
+					return
+				}
 				logger.Infof("update begin, service event: %v", serviceEvent.String())
+				r.subscriptions.Notified(url, serviceEvent)
 				notifyListener.Notify(serviceEvent)
 			}
 		}
@@ -107,8 +308,33 @@ func (r *MockRegistry) Subscribe(url *common.URL, notifyListener NotifyListener)
 	return nil
 }
 
+// waitNotify waits for the reconnection and the notify delay, false is returned if the registry is destroyed
+func (r *MockRegistry) waitNotify() bool {
+	r.lock.Lock()
+	reconnected := r.reconnected
+	connected := r.connected
+	delay := r.failures.NotifyDelay
+	r.lock.Unlock()
+	if !connected {
+		select {
+		case <-reconnected:
+		case <-r.done:
+			return false
+		}
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.done:
+			return false
+		}
+	}
+	return true
+}
+
 // UnSubscribe :
 func (r *MockRegistry) UnSubscribe(url *common.URL, notifyListener NotifyListener) error {
+	r.record(MockCallUnSubscribe, url, nil)
 	return nil
 }
 
@@ -129,7 +355,15 @@ func (*listener) Close() {
 
 }
 
-// MockEvent ...
+// MockEvent notifies the event to a subscription, it is repeated by MockFailures.Burst
 func (r *MockRegistry) MockEvent(event *ServiceEvent) {
-	r.listener.listenChan <- event
+	r.lock.Lock()
+	burst := r.failures.Burst
+	r.lock.Unlock()
+	if burst < 1 {
+		burst = 1
+	}
+	for i := 0; i < burst; i++ {
+		r.listener.listenChan <- event
+	}
 }
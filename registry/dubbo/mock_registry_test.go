@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+type mockNotifyListener struct {
+	events chan *ServiceEvent
+}
+
+func (l *mockNotifyListener) Notify(event *ServiceEvent) {
+	l.events <- event
+}
+
+func newTestMockRegistry(t *testing.T) *MockRegistry {
+	r, err := NewMockRegistry(nil)
+	assert.NoError(t, err)
+	return r.(*MockRegistry)
+}
+
+func TestMockRegistryCalls(t *testing.T) {
+	r := newTestMockRegistry(t)
+	defer r.Destroy()
+	a, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A?interface=com.ikurento.user.A")
+	b, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.B?interface=com.ikurento.user.B")
+
+	assert.NoError(t, r.Register(&a))
+	assert.NoError(t, r.Register(&b))
+	assert.Equal(t, []*common.URL{&a, &b}, r.Registered())
+	assert.Equal(t, 2, r.CallCount(MockCallRegister))
+
+	r.SetFailures(MockFailures{RegisterErrorRate: 1})
+	assert.Equal(t, ErrMockFailure, r.UnRegister(&a))
+	assert.Len(t, r.Registered(), 2)
+	calls := r.Calls(MockCallUnRegister)
+	assert.Len(t, calls, 1)
+	assert.Equal(t, &a, calls[0].URL)
+	assert.Equal(t, ErrMockFailure, calls[0].Err)
+
+	r.SetFailures(MockFailures{})
+	assert.NoError(t, r.UnRegisterAll())
+	assert.Empty(t, r.Registered())
+	assert.Equal(t, 1, r.CallCount(MockCallUnRegisterAll))
+	assert.Equal(t, 3, r.CallCount(MockCallUnRegister))
+	assert.Len(t, r.Calls(""), 6)
+
+	r.ResetCalls()
+	assert.Empty(t, r.Calls(""))
+}
+
+func TestMockRegistryFlap(t *testing.T) {
+	r := newTestMockRegistry(t)
+	defer r.Destroy()
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A?interface=com.ikurento.user.A")
+	notifyListener := &mockNotifyListener{events: make(chan *ServiceEvent, 8)}
+	assert.NoError(t, r.Subscribe(&url, notifyListener))
+	assert.Equal(t, 1, r.CallCount(MockCallSubscribe))
+
+	r.Disconnect()
+	assert.Equal(t, ErrMockDisconnected, r.Register(&url))
+	_, err := r.HealthCheck(context.Background())
+	assert.Equal(t, ErrMockDisconnected, err)
+
+	// the event is held until reconnected
+	r.SetFailures(MockFailures{Burst: 3})
+	go r.MockEvent(&ServiceEvent{Action: remoting.EventTypeAdd, Service: *url.Clone()})
+	select {
+	case <-notifyListener.events:
+		t.Fatal("the event is notified while disconnected")
+	case <-time.After(50 * time.Millisecond):
+	}
+	r.Reconnect()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-notifyListener.events:
+		case <-time.After(time.Second):
+			t.Fatalf("the event %d is not notified", i)
+		}
+	}
+	assert.Equal(t, uint64(3), r.Dump().Subscriptions[0].Events)
+
+	r.SetFailures(MockFailures{NotifyDelay: 50 * time.Millisecond})
+	r.Flap(10 * time.Millisecond)
+	start := time.Now()
+	go r.MockEvent(&ServiceEvent{Action: remoting.EventTypeDel, Service: *url.Clone()})
+	<-notifyListener.events
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	_, err = r.HealthCheck(context.Background())
+	assert.NoError(t, err)
+}
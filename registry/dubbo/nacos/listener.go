@@ -0,0 +1,199 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	nacosClient "github.com/dubbogo/gost/database/kv/nacos"
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+	perrors "github.com/pkg/errors"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/config_center"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+// nacosListener converts the instances pushed by nacos to the service events
+type nacosListener struct {
+	namingClient   *nacosClient.NacosNamingClient
+	listenURL      *common.URL
+	events         chan *config_center.ConfigChangeEvent
+	instanceMap    map[string]model.SubscribeService // ip:port -> instance
+	cacheLock      sync.Mutex
+	done           chan struct{}
+	closeOnce      sync.Once
+	subscribeParam *vo.SubscribeParam
+}
+
+func newNacosListener(url *common.URL, namingClient *nacosClient.NacosNamingClient) *nacosListener {
+	return &nacosListener{
+		namingClient: namingClient,
+		listenURL:    url,
+		events:       make(chan *config_center.ConfigChangeEvent, 32),
+		instanceMap:  make(map[string]model.SubscribeService),
+		done:         make(chan struct{}),
+	}
+}
+
+func generateURL(instance model.SubscribeService) *common.URL {
+	if instance.Metadata == nil {
+		logger.Errorf("nacos instance metadata is empty,instance:%+v", instance)
+		return nil
+	}
+	path := instance.Metadata[constant.NACOS_PATH_KEY]
+	myInterface := instance.Metadata[constant.INTERFACE_KEY]
+	if len(path) == 0 && len(myInterface) == 0 {
+		logger.Errorf("nacos instance metadata does not have  both path key and interface key,instance:%+v", instance)
+		return nil
+	}
+	if len(path) == 0 && len(myInterface) != 0 {
+		path = "/" + myInterface
+	}
+	protocol := instance.Metadata[constant.NACOS_PROTOCOL_KEY]
+	if len(protocol) == 0 {
+		logger.Errorf("nacos instance metadata does not have protocol key,instance:%+v", instance)
+		return nil
+	}
+	urlMap := url.Values{}
+	for k, v := range instance.Metadata {
+		urlMap.Set(k, v)
+	}
+	var methods []string
+	if m := instance.Metadata[constant.METHODS_KEY]; len(m) > 0 {
+		methods = strings.Split(m, ",")
+	}
+	return common.NewURLWithOptions(
+		common.WithIp(instance.Ip),
+		common.WithPort(strconv.Itoa(int(instance.Port))),
+		common.WithProtocol(protocol),
+		common.WithParams(urlMap),
+		common.WithPath(path),
+		common.WithMethods(methods),
+	)
+}
+
+// Callback handles the full instance list pushed by nacos, and emits the add, update and delete
+// events by comparing it with the last list
+func (nl *nacosListener) Callback(services []model.SubscribeService, err error) {
+	if err != nil {
+		logger.Errorf("nacos subscribe callback error:%s , subscribe:%+v ", err.Error(), nl.subscribeParam)
+		return
+	}
+
+	var (
+		addInstances    []model.SubscribeService
+		delInstances    []model.SubscribeService
+		updateInstances []model.SubscribeService
+	)
+	nl.cacheLock.Lock()
+	newInstanceMap := make(map[string]model.SubscribeService, len(services))
+	for _, s := range services {
+		if !s.Enable || !s.Valid {
+			// instance is not available,so ignore it
+			continue
+		}
+		host := s.Ip + ":" + strconv.Itoa(int(s.Port))
+		newInstanceMap[host] = s
+		old, ok := nl.instanceMap[host]
+		if !ok {
+			// instance is not exist in cache,add it to cache
+			addInstances = append(addInstances, s)
+		} else if !reflect.DeepEqual(old, s) {
+			// instance is different from cache,update it to cache
+			updateInstances = append(updateInstances, s)
+		}
+	}
+	for host, old := range nl.instanceMap {
+		if _, ok := newInstanceMap[host]; !ok {
+			// cache instance is not exist in new instance list, remove it from cache
+			delInstances = append(delInstances, old)
+		}
+	}
+	nl.instanceMap = newInstanceMap
+	nl.cacheLock.Unlock()
+
+	nl.process(addInstances, remoting.EventTypeAdd)
+	nl.process(delInstances, remoting.EventTypeDel)
+	nl.process(updateInstances, remoting.EventTypeUpdate)
+}
+
+func (nl *nacosListener) process(instances []model.SubscribeService, action remoting.EventType) {
+	for _, instance := range instances {
+		newURL := generateURL(instance)
+		if newURL == nil {
+			continue
+		}
+		select {
+		case nl.events <- &config_center.ConfigChangeEvent{Value: newURL, ConfigType: action}:
+		case <-nl.done:
+			return
+		}
+	}
+}
+
+func (nl *nacosListener) startListen(serviceName, groupName string, clusters []string) error {
+	if nl.namingClient == nil {
+		return perrors.New("nacos naming client stopped")
+	}
+	nl.subscribeParam = &vo.SubscribeParam{
+		ServiceName:       serviceName,
+		GroupName:         groupName,
+		Clusters:          clusters,
+		SubscribeCallback: nl.Callback,
+	}
+	return nl.namingClient.Client().Subscribe(nl.subscribeParam)
+}
+
+func (nl *nacosListener) stopListen() error {
+	return nl.namingClient.Client().Unsubscribe(nl.subscribeParam)
+}
+
+// Next returns the next service event pushed by nacos
+func (nl *nacosListener) Next() (*registry.ServiceEvent, error) {
+	for {
+		select {
+		case <-nl.done:
+			logger.Warnf("nacos listener is close!listenUrl:%+v", nl.listenURL)
+			return nil, perrors.New("listener stopped")
+
+		case e := <-nl.events:
+			logger.Debugf("got nacos event %s", e)
+			return &registry.ServiceEvent{Action: e.ConfigType, Service: *e.Value.(*common.URL).Clone()}, nil
+		}
+	}
+}
+
+// Close stops the subscription of nacos, it can be called more than once
+func (nl *nacosListener) Close() {
+	nl.closeOnce.Do(func() {
+		if nl.subscribeParam != nil {
+			if err := nl.stopListen(); err != nil {
+				logger.Warnf("nacos unsubscribe %s error {%v}", nl.subscribeParam.ServiceName, err)
+			}
+		}
+		close(nl.done)
+	})
+}
@@ -0,0 +1,69 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"testing"
+
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+func TestNacosListenerCallback(t *testing.T) {
+	url, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider")
+	listener := newNacosListener(&url, nil)
+	metadata := map[string]string{
+		"protocol":  "dubbo",
+		"path":      "/com.ikurento.user.UserProvider",
+		"interface": "com.ikurento.user.UserProvider",
+	}
+	a := model.SubscribeService{Ip: "10.0.0.1", Port: 20000, Enable: true, Valid: true, Weight: 1, Metadata: metadata}
+	b := model.SubscribeService{Ip: "10.0.0.2", Port: 20000, Enable: true, Valid: true, Weight: 1, Metadata: metadata}
+
+	listener.Callback([]model.SubscribeService{a, b}, nil)
+	for i := 0; i < 2; i++ {
+		e, err := listener.Next()
+		assert.Nil(t, err)
+		assert.EqualValues(t, remoting.EventTypeAdd, e.Action)
+		assert.Equal(t, "dubbo", e.Service.Protocol)
+	}
+
+	a2 := a
+	a2.Weight = 2
+	listener.Callback([]model.SubscribeService{a2}, nil)
+	e, err := listener.Next()
+	assert.Nil(t, err)
+	assert.EqualValues(t, remoting.EventTypeDel, e.Action)
+	assert.Equal(t, "10.0.0.2", e.Service.Ip)
+	e, err = listener.Next()
+	assert.Nil(t, err)
+	assert.EqualValues(t, remoting.EventTypeUpdate, e.Action)
+	assert.Equal(t, "10.0.0.1", e.Service.Ip)
+	assert.Equal(t, "/com.ikurento.user.UserProvider", e.Service.Path)
+
+	listener.Close()
+	_, err = listener.Next()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,378 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	nacosClient "github.com/dubbogo/gost/database/kv/nacos"
+	gxnet "github.com/dubbogo/gost/net"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+	perrors "github.com/pkg/errors"
+	registry "mosn.io/pkg/registry/dubbo"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting/nacos"
+)
+
+const (
+	// RegistryNacosClient nacos client name
+	RegistryNacosClient = "nacos registry"
+)
+
+var (
+	localIP = ""
+	// errAlreadySubscribed is returned when subscribing a service which is being subscribed
+	errAlreadySubscribed = perrors.New("service has already been subscribed")
+)
+
+func init() {
+	localIP, _ = gxnet.GetLocalIP()
+}
+
+/////////////////////////////////////
+// nacos registry
+/////////////////////////////////////
+
+// nacosRegistry implements the Registry interface directly, as nacos is not a path based registry
+// and has no use of BaseRegistry. The instances are ephemeral, they are kept alive by the beats of
+// the naming client and removed by the server once the client is gone.
+type nacosRegistry struct {
+	*common.URL
+	namingClient *nacosClient.NacosNamingClient
+	groupName    string
+	clusterName  string
+	listenerLock sync.Mutex
+	listeners    map[string]*nacosListener // service key -> listener
+	done         chan struct{}
+	closeOnce    sync.Once
+
+	registeredLock sync.Mutex
+	registered     map[string]*common.URL // url key -> url
+
+	subscriptions registry.SubscriptionRecorder
+}
+
+// NewNacosRegistry returns a new nacos registry, the group, cluster and namespace of the instances
+// are read from the nacos.group, nacos.cluster and namespaceId parameters of the registry url
+func NewNacosRegistry(url *common.URL) (registry.Registry, error) {
+	namingClient, err := nacos.NewNacosNamingClient(RegistryNacosClient, url)
+	if err != nil {
+		logger.Errorf("nacos naming client create error {%v}", err)
+		return nil, err
+	}
+	return newNacosRegistry(url, namingClient), nil
+}
+
+func newNacosRegistry(url *common.URL, namingClient *nacosClient.NacosNamingClient) *nacosRegistry {
+	return &nacosRegistry{
+		URL:          url,
+		namingClient: namingClient,
+		groupName:    url.GetParam(constant.NACOS_GROUP_KEY, constant.SERVICE_DISCOVERY_DEFAULT_GROUP),
+		clusterName:  url.GetParam(constant.NACOS_CLUSTER_KEY, constant.NACOS_DEFAULT_CLUSTER),
+		listeners:    make(map[string]*nacosListener),
+		done:         make(chan struct{}),
+		registered:   make(map[string]*common.URL),
+	}
+}
+
+// getCategory returns the category of the registry, which depends on the role of the registry url
+func getCategory(url *common.URL) string {
+	role, _ := strconv.Atoi(url.GetParam(constant.ROLE_KEY, strconv.Itoa(constant.NACOS_DEFAULT_ROLETYPE)))
+	if role < 0 || role >= len(common.DubboNodes) {
+		role = constant.NACOS_DEFAULT_ROLETYPE
+	}
+	return common.DubboNodes[role]
+}
+
+// getServiceName returns the nacos service name of the url, like providers:interface:version:group
+func getServiceName(category string, url *common.URL) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(category)
+	appendParam(&buffer, url, constant.INTERFACE_KEY)
+	appendParam(&buffer, url, constant.VERSION_KEY)
+	appendParam(&buffer, url, constant.GROUP_KEY)
+	return buffer.String()
+}
+
+func appendParam(target *bytes.Buffer, url *common.URL, key string) {
+	value := url.GetParam(key, "")
+	target.WriteString(constant.NACOS_SERVICE_NAME_SEPARATOR)
+	if strings.TrimSpace(value) != "" {
+		target.WriteString(value)
+	}
+}
+
+func hostAndPort(url *common.URL) (string, uint64) {
+	ip := url.Ip
+	if len(ip) == 0 {
+		ip = localIP
+	}
+	port, _ := strconv.ParseUint(url.Port, 10, 64)
+	if port == 0 {
+		port = 80
+	}
+	return ip, port
+}
+
+func (nr *nacosRegistry) createRegisterParam(url *common.URL) vo.RegisterInstanceParam {
+	category := getCategory(nr.URL)
+	params := make(map[string]string)
+	url.RangeParams(func(key, value string) bool {
+		params[key] = value
+		return true
+	})
+	params[constant.NACOS_CATEGORY_KEY] = category
+	params[constant.NACOS_PROTOCOL_KEY] = url.Protocol
+	params[constant.NACOS_PATH_KEY] = url.Path
+	if len(url.Methods) > 0 {
+		params[constant.METHODS_KEY] = strings.Join(url.Methods, ",")
+	}
+	ip, port := hostAndPort(url)
+	return vo.RegisterInstanceParam{
+		Ip:          ip,
+		Port:        port,
+		Metadata:    params,
+		Weight:      float64(url.GetInstanceMetadata().Weight),
+		Enable:      true,
+		Healthy:     true,
+		Ephemeral:   true,
+		ServiceName: getServiceName(category, url),
+		GroupName:   nr.groupName,
+		ClusterName: nr.clusterName,
+	}
+}
+
+func (nr *nacosRegistry) createDeregisterParam(url *common.URL) vo.DeregisterInstanceParam {
+	ip, port := hostAndPort(url)
+	return vo.DeregisterInstanceParam{
+		Ip:          ip,
+		Port:        port,
+		ServiceName: getServiceName(getCategory(nr.URL), url),
+		GroupName:   nr.groupName,
+		Cluster:     nr.clusterName,
+		Ephemeral:   true,
+	}
+}
+
+// Register registers the url as an ephemeral instance of nacos
+func (nr *nacosRegistry) Register(url *common.URL) error {
+	param := nr.createRegisterParam(url)
+	isRegistry, err := nr.namingClient.Client().RegisterInstance(param)
+	if err != nil {
+		return perrors.WithMessagef(err, "register service %s to nacos", param.ServiceName)
+	}
+	if !isRegistry {
+		return perrors.Errorf("register service %s to nacos failed", param.ServiceName)
+	}
+	nr.registeredLock.Lock()
+	nr.registered[url.Key()] = url
+	nr.registeredLock.Unlock()
+	logger.Infof("register service %s to nacos, instance %s:%d", param.ServiceName, param.Ip, param.Port)
+	return nil
+}
+
+// UnRegister removes the instance of the url from nacos
+func (nr *nacosRegistry) UnRegister(url *common.URL) error {
+	param := nr.createDeregisterParam(url)
+	isDeregister, err := nr.namingClient.Client().DeregisterInstance(param)
+	if err != nil {
+		return perrors.WithMessagef(err, "deregister service %s from nacos", param.ServiceName)
+	}
+	if !isDeregister {
+		return perrors.Errorf("deregister service %s from nacos failed", param.ServiceName)
+	}
+	nr.registeredLock.Lock()
+	delete(nr.registered, url.Key())
+	nr.registeredLock.Unlock()
+	return nil
+}
+
+// UnRegisterAll removes the instances of all the registered urls from nacos
+func (nr *nacosRegistry) UnRegisterAll() error {
+	return nr.unRegisterAll(context.Background())
+}
+
+func (nr *nacosRegistry) unRegisterAll(ctx context.Context) error {
+	nr.registeredLock.Lock()
+	urls := make([]*common.URL, 0, len(nr.registered))
+	for _, url := range nr.registered {
+		urls = append(urls, url)
+	}
+	nr.registeredLock.Unlock()
+	return registry.UnRegisterURLs(ctx, urls, nr.UnRegister)
+}
+
+// Close removes the registered instances until ctx is done and then destroys the registry
+func (nr *nacosRegistry) Close(ctx context.Context) error {
+	err := nr.unRegisterAll(ctx)
+	nr.Destroy()
+	return err
+}
+
+// HealthCheck lists a page of the services of the group, the naming client can't be cancelled,
+// so the check returns once ctx is done and leaves the request to finish in the background
+func (nr *nacosRegistry) HealthCheck(ctx context.Context) (time.Duration, error) {
+	if !nr.IsAvailable() {
+		return 0, registry.ErrNotAvailable
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		_, err := nr.namingClient.Client().GetAllServicesInfo(vo.GetAllServiceInfoParam{
+			GroupName: nr.groupName,
+			PageNo:    1,
+			PageSize:  1,
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return time.Since(start), err
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+func (nr *nacosRegistry) subscribe(conf *common.URL) (*nacosListener, error) {
+	if conf.IsAnyService() {
+		return nil, registry.ErrWildcardNotSupported
+	}
+	nr.listenerLock.Lock()
+	defer nr.listenerLock.Unlock()
+	if _, ok := nr.listeners[conf.ServiceKey()]; ok {
+		return nil, errAlreadySubscribed
+	}
+	listener := newNacosListener(conf, nr.namingClient)
+	err := listener.startListen(getServiceName(common.DubboNodes[common.PROVIDER], conf), nr.groupName, []string{nr.clusterName})
+	if err != nil {
+		return nil, err
+	}
+	nr.listeners[conf.ServiceKey()] = listener
+	return listener, nil
+}
+
+func (nr *nacosRegistry) removeListener(conf *common.URL, listener *nacosListener) {
+	nr.listenerLock.Lock()
+	defer nr.listenerLock.Unlock()
+	if nr.listeners[conf.ServiceKey()] == listener {
+		delete(nr.listeners, conf.ServiceKey())
+	}
+}
+
+// Subscribe subscribes the providers of the url and notifies the changes pushed by nacos,
+// it blocks until the url is unsubscribed or the registry is destroyed
+func (nr *nacosRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	for {
+		if !nr.IsAvailable() {
+			logger.Warnf("event listener game over.")
+			return perrors.New("nacosRegistry is not available.")
+		}
+
+		listener, err := nr.subscribe(url)
+		if err != nil {
+			if err == errAlreadySubscribed || err == registry.ErrWildcardNotSupported || !nr.IsAvailable() {
+				logger.Warnf("event listener game over.")
+				return err
+			}
+			logger.Warnf("getListener() = err:%v", perrors.WithStack(err))
+			time.Sleep(time.Duration(registry.RegistryConnDelay) * time.Second)
+			continue
+		}
+
+		nr.subscriptions.Subscribed(url)
+		for {
+			serviceEvent, err := listener.Next()
+			if err != nil {
+				logger.Warnf("Selector.watch() = error{%v}", perrors.WithStack(err))
+				listener.Close()
+				nr.removeListener(url, listener)
+				nr.subscriptions.Unsubscribed(url)
+				return err
+			}
+			logger.Infof("update begin, service event: %v", serviceEvent.String())
+			nr.subscriptions.Notified(url, serviceEvent)
+			notifyListener.Notify(serviceEvent)
+		}
+	}
+}
+
+// UnSubscribe stops the subscription of the url, which makes the Subscribe of the url return
+func (nr *nacosRegistry) UnSubscribe(url *common.URL, _ registry.NotifyListener) error {
+	nr.listenerLock.Lock()
+	listener, ok := nr.listeners[url.ServiceKey()]
+	delete(nr.listeners, url.ServiceKey())
+	nr.listenerLock.Unlock()
+	if !ok {
+		return nil
+	}
+	listener.Close()
+	return nil
+}
+
+// Dump returns the registered urls and the subscriptions, the services are pushed by nacos without watches
+func (nr *nacosRegistry) Dump() *registry.RegistryDump {
+	nr.registeredLock.Lock()
+	urls := make([]*common.URL, 0, len(nr.registered))
+	for _, url := range nr.registered {
+		urls = append(urls, url)
+	}
+	nr.registeredLock.Unlock()
+	return &registry.RegistryDump{
+		URL:           registry.DumpURL(nr.URL),
+		Available:     nr.IsAvailable(),
+		Registrations: registry.DumpURLs(urls),
+		Subscriptions: nr.subscriptions.Dump(),
+	}
+}
+
+// GetUrl gets its registration URL
+func (nr *nacosRegistry) GetUrl() common.URL {
+	return *nr.URL.Clone()
+}
+
+// IsAvailable determines nacos registry center whether it is available
+func (nr *nacosRegistry) IsAvailable() bool {
+	select {
+	case <-nr.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy closes all the listeners and the naming client
+func (nr *nacosRegistry) Destroy() {
+	nr.closeOnce.Do(func() {
+		close(nr.done)
+		nr.listenerLock.Lock()
+		listeners := nr.listeners
+		nr.listeners = make(map[string]*nacosListener)
+		nr.listenerLock.Unlock()
+		for _, listener := range listeners {
+			listener.Close()
+		}
+		nr.namingClient.Close()
+	})
+}
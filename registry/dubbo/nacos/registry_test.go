@@ -0,0 +1,56 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+)
+
+func TestCreateRegisterParam(t *testing.T) {
+	regURL, _ := common.NewURL("registry://127.0.0.1:8848?registry.role=3&nacos.group=dubbo&nacos.cluster=hz")
+	nr := newNacosRegistry(&regURL, nil)
+
+	url, _ := common.NewURL("dubbo://10.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&version=1.0.0&group=",
+		common.WithMethods([]string{"GetUser", "AddUser"}))
+	param := nr.createRegisterParam(&url)
+	assert.Equal(t, "providers:com.ikurento.user.UserProvider:1.0.0:", param.ServiceName)
+	assert.Equal(t, "dubbo", param.GroupName)
+	assert.Equal(t, "hz", param.ClusterName)
+	assert.Equal(t, "10.0.0.1", param.Ip)
+	assert.Equal(t, uint64(20000), param.Port)
+	assert.True(t, param.Ephemeral)
+	assert.Equal(t, "providers", param.Metadata[constant.NACOS_CATEGORY_KEY])
+	assert.Equal(t, "dubbo", param.Metadata[constant.NACOS_PROTOCOL_KEY])
+	assert.Equal(t, "/com.ikurento.user.UserProvider", param.Metadata[constant.NACOS_PATH_KEY])
+	assert.Equal(t, "GetUser,AddUser", param.Metadata[constant.METHODS_KEY])
+
+	deregister := nr.createDeregisterParam(&url)
+	assert.Equal(t, param.ServiceName, deregister.ServiceName)
+	assert.Equal(t, "hz", deregister.Cluster)
+
+	regURL, _ = common.NewURL("registry://127.0.0.1:8848?registry.role=0")
+	nr = newNacosRegistry(&regURL, nil)
+	param = nr.createRegisterParam(&url)
+	assert.Equal(t, "consumers:com.ikurento.user.UserProvider:1.0.0:", param.ServiceName)
+	assert.Equal(t, constant.SERVICE_DISCOVERY_DEFAULT_GROUP, param.GroupName)
+	assert.Equal(t, constant.NACOS_DEFAULT_CLUSTER, param.ClusterName)
+}
@@ -18,6 +18,11 @@
 package dubbo
 
 import (
+	"context"
+	"reflect"
+	"time"
+
+	perrors "github.com/pkg/errors"
 	"mosn.io/pkg/registry/dubbo/common"
 )
 
@@ -56,6 +61,20 @@ type Registry interface {
 	// url      Subscription condition, not allowed to be empty, e.g. consumer://10.20.153.10/org.apache.dubbo.foo.BarService?version=1.0.0&application=kylin
 	// listener A listener of the change event, not allowed to be empty
 	UnSubscribe(*common.URL, NotifyListener) error
+
+	// UnRegisterAll unregisters all the urls registered through the registry
+	UnRegisterAll() error
+
+	// Close unregisters all the registered urls until ctx is done, and then destroys the registry,
+	// so the consumers don't keep calling the providers of a stopped process until its session times out
+	Close(ctx context.Context) error
+
+	// HealthCheck performs a lightweight round trip to the registry center until ctx is done and returns
+	// its latency, while IsAvailable only tells whether the registry is destroyed
+	HealthCheck(ctx context.Context) (time.Duration, error)
+
+	// Dump returns the registrations, the subscriptions with their last events and the watches of the registry for debugging
+	Dump() *RegistryDump
 }
 
 // NotifyListener ...
@@ -63,6 +82,20 @@ type NotifyListener interface {
 	Notify(*ServiceEvent)
 }
 
+// subscriptionKey identifies a subscription of the wrapper registries, as one listener may subscribe several urls
+type subscriptionKey struct {
+	url      string
+	listener NotifyListener
+}
+
+// newSubscriptionKey returns an error if the listener is not comparable, which can not be found by UnSubscribe
+func newSubscriptionKey(url *common.URL, listener NotifyListener) (subscriptionKey, error) {
+	if listener == nil || !reflect.TypeOf(listener).Comparable() {
+		return subscriptionKey{}, perrors.Errorf("notify listener{%T} is not comparable", listener)
+	}
+	return subscriptionKey{url: url.Key(), listener: listener}, nil
+}
+
 // Listener Deprecated!
 type Listener interface {
 	Next() (*ServiceEvent, error)
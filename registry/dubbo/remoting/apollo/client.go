@@ -0,0 +1,171 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apollo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	perrors "github.com/pkg/errors"
+)
+
+const (
+	// HeaderAuthorization is the header of the signature of the access key
+	HeaderAuthorization = "Authorization"
+	// HeaderTimestamp is the header of the timestamp in milliseconds signed with the access key
+	HeaderTimestamp = "Timestamp"
+)
+
+// ErrNamespaceNotFound is returned when the namespace is not released in the cluster
+var ErrNamespaceNotFound = perrors.New("apollo namespace not found")
+
+// Config is the released configurations of a namespace
+type Config struct {
+	AppID          string            `json:"appId"`
+	Cluster        string            `json:"cluster"`
+	NamespaceName  string            `json:"namespaceName"`
+	Configurations map[string]string `json:"configurations"`
+	ReleaseKey     string            `json:"releaseKey"`
+}
+
+// Notification is the latest notification id of a namespace, the long poll returns
+// once the notification id of any namespace is larger than the one of the request
+type Notification struct {
+	NamespaceName  string `json:"namespaceName"`
+	NotificationID int64  `json:"notificationId"`
+}
+
+// Client is a client of the config service of apollo
+type Client struct {
+	address    string
+	appID      string
+	cluster    string
+	secret     string
+	ip         string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the config service, the address is like 127.0.0.1:8080 or
+// http://127.0.0.1:8080. The requests are signed if @secret, the access key of the app, is set.
+// The deadlines of the requests are set by their contexts.
+func NewClient(address, appID, cluster, secret, ip string) *Client {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		appID:      appID,
+		cluster:    cluster,
+		secret:     secret,
+		ip:         ip,
+		httpClient: &http.Client{},
+	}
+}
+
+// GetConfig gets the configurations of the namespace, it returns nil if the release key is not changed
+func (c *Client) GetConfig(ctx context.Context, namespace, releaseKey string) (*Config, error) {
+	query := url.Values{}
+	query.Set("ip", c.ip)
+	if releaseKey != "" {
+		query.Set("releaseKey", releaseKey)
+	}
+	path := "/configs/" + url.PathEscape(c.appID) + "/" + url.PathEscape(c.cluster) + "/" + url.PathEscape(namespace)
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, perrors.WithMessagef(ErrNamespaceNotFound, "namespace %s", namespace)
+	}
+	config := &Config{}
+	if err = json.NewDecoder(resp.Body).Decode(config); err != nil {
+		return nil, perrors.WithMessagef(err, "decode the config of namespace %s", namespace)
+	}
+	return config, nil
+}
+
+// PollNotifications blocks until any of the namespaces changes or the server times out the poll,
+// which returns nil. The server holds the poll for 60 seconds, so the deadline of @ctx should be longer.
+func (c *Client) PollNotifications(ctx context.Context, notifications []Notification) ([]Notification, error) {
+	data, err := json.Marshal(notifications)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	query := url.Values{}
+	query.Set("appId", c.appID)
+	query.Set("cluster", c.cluster)
+	query.Set("notifications", string(data))
+	resp, err := c.get(ctx, "/notifications/v2", query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	var changed []Notification
+	if err = json.NewDecoder(resp.Body).Decode(&changed); err != nil {
+		return nil, perrors.WithMessage(err, "decode the notifications")
+	}
+	return changed, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	pathWithQuery := path + "?" + query.Encode()
+	req, err := http.NewRequest(http.MethodGet, c.address+pathWithQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderAuthorization, "Apollo "+c.appID+":"+Signature(timestamp, pathWithQuery, c.secret))
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNotModified, http.StatusNotFound:
+		return resp, nil
+	}
+	msg, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	return nil, perrors.Errorf("apollo GET %s: %s %s", path, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// Signature signs the timestamp and the request uri with the access key, as the config service verifies
+func Signature(timestamp, pathWithQuery, secret string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + pathWithQuery))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
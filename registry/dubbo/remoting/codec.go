@@ -0,0 +1,192 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remoting
+
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+
+	perrors "github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+// The names of the builtin codecs
+const (
+	CodecDubbo    = "dubbo"
+	CodecJSON     = "json"
+	CodecProtobuf = "protobuf"
+)
+
+// Codec encodes the urls as the payloads of the registry nodes and decodes them back,
+// so the nodes can be shared with the consumers which don't parse the dubbo url strings
+type Codec interface {
+	Name() string
+	Encode(url *common.URL) ([]byte, error)
+	Decode(data []byte) (*common.URL, error)
+}
+
+var (
+	codecsLock sync.RWMutex
+	codecs     = map[string]Codec{
+		CodecDubbo:    dubboCodec{},
+		CodecJSON:     jsonCodec{},
+		CodecProtobuf: protobufCodec{},
+	}
+)
+
+// RegisterCodec registers the codec by its name, the codec of the same name is replaced
+func RegisterCodec(codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// GetCodec returns the codec of the name, the dubbo codec is returned if the name is empty
+func GetCodec(name string) (Codec, error) {
+	if name == "" {
+		name = CodecDubbo
+	}
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, perrors.Errorf("codec %s is not registered", name)
+	}
+	return codec, nil
+}
+
+// dubboCodec encodes the url as the dubbo url string
+type dubboCodec struct{}
+
+func (dubboCodec) Name() string {
+	return CodecDubbo
+}
+
+func (dubboCodec) Encode(u *common.URL) ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (dubboCodec) Decode(data []byte) (*common.URL, error) {
+	u, err := common.NewURL(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// urlPayload is the document of the url encoded by the json and protobuf codecs
+type urlPayload struct {
+	Protocol string            `json:"protocol"`
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	Ip       string            `json:"ip"`
+	Port     string            `json:"port"`
+	Path     string            `json:"path"`
+	Methods  []string          `json:"methods,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+func newURLPayload(u *common.URL) *urlPayload {
+	p := &urlPayload{
+		Protocol: u.Protocol,
+		Username: u.Username,
+		Password: u.Password,
+		Ip:       u.Ip,
+		Port:     u.Port,
+		Path:     u.Path,
+		Methods:  u.Methods,
+		Params:   make(map[string]string),
+	}
+	u.RangeParams(func(key, value string) bool {
+		p.Params[key] = value
+		return true
+	})
+	return p
+}
+
+func (p *urlPayload) url() *common.URL {
+	params := url.Values{}
+	for key, value := range p.Params {
+		params.Set(key, value)
+	}
+	return common.NewURLWithOptions(
+		common.WithProtocol(p.Protocol),
+		common.WithUsername(p.Username),
+		common.WithPassword(p.Password),
+		common.WithIp(p.Ip),
+		common.WithPort(p.Port),
+		common.WithPath(p.Path),
+		common.WithMethods(p.Methods),
+		common.WithParams(params),
+	)
+}
+
+// jsonCodec encodes the url as a json document
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return CodecJSON
+}
+
+func (jsonCodec) Encode(u *common.URL) ([]byte, error) {
+	return json.Marshal(newURLPayload(u))
+}
+
+func (jsonCodec) Decode(data []byte) (*common.URL, error) {
+	p := &urlPayload{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return p.url(), nil
+}
+
+// protobufCodec encodes the url as a google.protobuf.Struct message of the json document,
+// so it can be decoded without any generated code
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string {
+	return CodecProtobuf
+}
+
+func (protobufCodec) Encode(u *common.URL) ([]byte, error) {
+	data, err := json.Marshal(newURLPayload(u))
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	s := &structpb.Struct{}
+	if err = protojson.Unmarshal(data, s); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return proto.Marshal(s)
+}
+
+func (protobufCodec) Decode(data []byte) (*common.URL, error) {
+	s := &structpb.Struct{}
+	if err := proto.Unmarshal(data, s); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	data, err := protojson.Marshal(s)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return jsonCodec{}.Decode(data)
+}
@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remoting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+func TestCodecs(t *testing.T) {
+	u, err := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&group=g1&version=1.0.0&methods=GetUser,SetUser")
+	assert.NoError(t, err)
+	for _, name := range []string{"", CodecDubbo, CodecJSON, CodecProtobuf} {
+		codec, err := GetCodec(name)
+		assert.NoError(t, err)
+		data, err := codec.Encode(&u)
+		assert.NoError(t, err)
+		decoded, err := codec.Decode(data)
+		assert.NoError(t, err, codec.Name())
+		assert.Equal(t, u.Protocol, decoded.Protocol, codec.Name())
+		assert.Equal(t, u.Location, decoded.Location, codec.Name())
+		assert.Equal(t, u.Path, decoded.Path, codec.Name())
+		assert.Equal(t, u.ServiceKey(), decoded.ServiceKey(), codec.Name())
+		assert.Equal(t, "GetUser,SetUser", decoded.GetParam("methods", ""), codec.Name())
+	}
+
+	codec, _ := GetCodec(CodecJSON)
+	_, err = codec.Decode([]byte("dubbo://127.0.0.1"))
+	assert.Error(t, err)
+	_, err = GetCodec("xml")
+	assert.Error(t, err)
+}
+
+type mockCodec struct {
+	Codec
+}
+
+func (mockCodec) Name() string {
+	return "mock"
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec(mockCodec{})
+	codec, err := GetCodec("mock")
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", codec.Name())
+}
@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	perrors "github.com/pkg/errors"
+)
+
+const (
+	// HeaderConsulToken is the header of the acl token of consul
+	HeaderConsulToken = "X-Consul-Token"
+	// HeaderConsulIndex is the header of the raft index for blocking queries
+	HeaderConsulIndex = "X-Consul-Index"
+)
+
+// AgentServiceCheck is the check registered with the service
+type AgentServiceCheck struct {
+	CheckID                        string `json:",omitempty"`
+	TTL                            string `json:",omitempty"`
+	DeregisterCriticalServiceAfter string `json:",omitempty"`
+}
+
+// AgentServiceRegistration is the request body of the service registration
+type AgentServiceRegistration struct {
+	ID      string             `json:",omitempty"`
+	Name    string             `json:",omitempty"`
+	Tags    []string           `json:",omitempty"`
+	Address string             `json:",omitempty"`
+	Port    int                `json:",omitempty"`
+	Meta    map[string]string  `json:",omitempty"`
+	Check   *AgentServiceCheck `json:",omitempty"`
+}
+
+// AgentService is the service of the health query
+type AgentService struct {
+	ID      string
+	Service string
+	Tags    []string
+	Address string
+	Port    int
+	Meta    map[string]string
+}
+
+// ServiceEntry is the entry of the health query
+type ServiceEntry struct {
+	Service *AgentService
+}
+
+// Client is a minimal client of the consul http api which covers the service registration,
+// ttl check and the blocking health query
+type Client struct {
+	address    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the consul agent, the address is like 127.0.0.1:8500
+// or http://127.0.0.1:8500. The deadlines of the requests are set by their contexts.
+func NewClient(address, token string) *Client {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+	}
+}
+
+// CheckID returns the id of the check registered with the service
+func CheckID(serviceID string) string {
+	return "service:" + serviceID
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, perrors.WithMessage(err, "marshal consul request")
+		}
+		reader = bytes.NewReader(data)
+	}
+	u := c.address + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c.token != "" {
+		req.Header.Set(HeaderConsulToken, c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, perrors.Errorf("consul %s %s: %s %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (c *Client) put(ctx context.Context, path string, body interface{}) error {
+	resp, err := c.do(ctx, http.MethodPut, path, nil, body)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// RegisterService registers the service to the local agent
+func (c *Client) RegisterService(ctx context.Context, service *AgentServiceRegistration) error {
+	return c.put(ctx, "/v1/agent/service/register", service)
+}
+
+// DeregisterService removes the service from the local agent
+func (c *Client) DeregisterService(ctx context.Context, serviceID string) error {
+	return c.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(serviceID), nil)
+}
+
+// PassTTL marks the ttl check as passing
+func (c *Client) PassTTL(ctx context.Context, checkID string) error {
+	return c.put(ctx, "/v1/agent/check/pass/"+url.PathEscape(checkID), nil)
+}
+
+// Leader returns the address of the raft leader, which is a lightweight round trip to the cluster
+func (c *Client) Leader(ctx context.Context) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v1/status/leader", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var leader string
+	if err = json.NewDecoder(resp.Body).Decode(&leader); err != nil {
+		return "", perrors.WithMessage(err, "decode consul leader")
+	}
+	return leader, nil
+}
+
+// HealthService returns the passing instances of the service with the tag. It is a blocking query
+// if the index is not zero, which returns once the index of the service changes or the wait time elapses.
+// The index of the result is returned for the next query.
+func (c *Client) HealthService(ctx context.Context, service, tag string, index uint64, wait time.Duration) ([]ServiceEntry, uint64, error) {
+	query := url.Values{}
+	query.Set("passing", "1")
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%dms", wait/time.Millisecond))
+	}
+	resp, err := c.do(ctx, http.MethodGet, "/v1/health/service/"+url.PathEscape(service), query, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var entries []ServiceEntry
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, perrors.WithMessage(err, "decode consul health service")
+	}
+	newIndex, _ := strconv.ParseUint(resp.Header.Get(HeaderConsulIndex), 10, 64)
+	// reset the index if it goes backwards, see the consul blocking queries doc
+	if newIndex < index {
+		newIndex = 0
+	}
+	return entries, newIndex, nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dubbogo/getty"
+	gxetcd "github.com/dubbogo/gost/database/kv/etcd/v3"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+const (
+	// ConnDelay connection delay interval
+	ConnDelay = 3
+	// MaxFailTimes max fail times
+	MaxFailTimes = 15
+	// RegistryETCDV3Client etcd client name
+	RegistryETCDV3Client = "etcd registry"
+)
+
+// ClientFacade is the container of the etcd client, such as the etcd registry
+type ClientFacade interface {
+	Client() *gxetcd.Client
+	SetClient(*gxetcd.Client)
+	ClientLock() *sync.Mutex
+	WaitGroup() *sync.WaitGroup // for wait group control, etcd client listener & etcd client container
+	Done() chan struct{}        // for etcd client control
+	RestartCallBack() bool
+	GetUrl() common.URL
+}
+
+// nolint
+type Options struct {
+	name      string
+	heartbeat int
+}
+
+// Option will define a function of handling Options
+type Option func(*Options)
+
+// WithName sets etcd client name
+func WithName(name string) Option {
+	return func(opt *Options) {
+		opt.name = name
+	}
+}
+
+// WithHeartbeat sets the session ttl of etcd client in seconds
+func WithHeartbeat(heartbeat int) Option {
+	return func(opt *Options) {
+		opt.heartbeat = heartbeat
+	}
+}
+
+// ValidateClient validates client and sets options
+func ValidateClient(container ClientFacade, opts ...Option) error {
+	options := &Options{
+		heartbeat: 1, // default heartbeat
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	lock := container.ClientLock()
+	url := container.GetUrl()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	// new client or the stopped client should be recreated
+	if container.Client() == nil || !container.Client().Valid() {
+		timeout, err := time.ParseDuration(url.GetParam(constant.REGISTRY_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT))
+		if err != nil {
+			logger.Errorf("timeout config %v is invalid ,err is %v",
+				url.GetParam(constant.REGISTRY_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT), err.Error())
+			return perrors.WithMessagef(err, "newETCDV3Client(address:%+v)", url.Location)
+		}
+		endpoints := strings.Split(url.Location, ",")
+		newClient, err := gxetcd.NewConfigClientWithErr(
+			gxetcd.WithName(options.name),
+			gxetcd.WithEndpoints(endpoints...),
+			gxetcd.WithTimeout(timeout),
+			gxetcd.WithHeartbeat(options.heartbeat),
+		)
+		if err != nil {
+			logger.Warnf("newETCDV3Client(name{%s}, etcd address{%v}, timeout{%s}) = error{%v}",
+				options.name, url.Location, timeout.String(), err)
+			return perrors.WithMessagef(err, "newETCDV3Client(address:%+v)", url.Location)
+		}
+		container.SetClient(newClient)
+		logger.Infof("Connect to etcd successfully, name{%s}, etcd address{%v}", options.name, url.Location)
+		container.WaitGroup().Add(1) // etcd client start successful, then registry wg +1
+	}
+
+	return nil
+}
+
+// HandleClientRestart keeps the connection between client and server
+func HandleClientRestart(r ClientFacade) {
+	var (
+		err       error
+		failTimes int
+	)
+
+LOOP:
+	for {
+		select {
+		case <-r.Done():
+			r.WaitGroup().Done() // dec the wg when registry is closed
+			logger.Warnf("(ETCDV3ProviderRegistry)reconnectETCDV3 goroutine exit now...")
+			break LOOP
+			// re-register all services
+		case <-r.Client().Done():
+			r.ClientLock().Lock()
+			clientName := RegistryETCDV3Client
+			endpoints := r.Client().GetEndPoints()
+			r.Client().Close()
+			r.SetClient(nil)
+			r.ClientLock().Unlock()
+			r.WaitGroup().Done() // dec the wg when etcd client is closed
+
+			// Connect etcd until success.
+			failTimes = 0
+			for {
+				select {
+				case <-r.Done():
+					r.WaitGroup().Done() // dec the wg when registry is closed
+					logger.Warnf("(ETCDV3ProviderRegistry)reconnectETCDV3 goroutine exit now...")
+					break LOOP
+				case <-getty.GetTimeWheel().After(timeSecondDuration(failTimes * ConnDelay)): // Prevent crazy reconnection etcd.
+				}
+				err = ValidateClient(r, WithName(clientName))
+				logger.Infof("ETCDV3ProviderRegistry.validateETCDV3Client(etcd Addr{%v}) = error{%#v}",
+					endpoints, perrors.WithStack(err))
+				if err == nil && r.RestartCallBack() {
+					break
+				}
+				failTimes++
+				if MaxFailTimes <= failTimes {
+					failTimes = MaxFailTimes
+				}
+			}
+		}
+	}
+}
+
+func timeSecondDuration(sec int) time.Duration {
+	return time.Duration(sec) * time.Second
+}
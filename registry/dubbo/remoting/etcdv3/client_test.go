@@ -0,0 +1,229 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gxetcd "github.com/dubbogo/gost/database/kv/etcd/v3"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/server/v3/embed"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+// freeAddr returns a local address which is not listened
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// startEtcd starts an embedded etcd server listening clients on addr
+func startEtcd(t *testing.T, addr string) *embed.Etcd {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL := url.URL{Scheme: "http", Host: addr}
+	peerURL := url.URL{Scheme: "http", Host: freeAddr(t)}
+	cfg.LCUrls, cfg.ACUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		e.Close()
+		t.Fatal("etcd server is not ready")
+	}
+	return e
+}
+
+type mockFacade struct {
+	client    *gxetcd.Client
+	lock      sync.Mutex
+	wg        sync.WaitGroup
+	done      chan struct{}
+	url       *common.URL
+	restarted chan struct{}
+}
+
+func newMockFacade(t *testing.T, addr string) *mockFacade {
+	u, err := common.NewURL("registry://" + addr + "?registry.timeout=1s")
+	assert.Nil(t, err)
+	return &mockFacade{done: make(chan struct{}), url: &u, restarted: make(chan struct{}, 8)}
+}
+
+func (f *mockFacade) Client() *gxetcd.Client {
+	return f.client
+}
+
+func (f *mockFacade) SetClient(client *gxetcd.Client) {
+	f.client = client
+}
+
+func (f *mockFacade) ClientLock() *sync.Mutex {
+	return &f.lock
+}
+
+func (f *mockFacade) WaitGroup() *sync.WaitGroup {
+	return &f.wg
+}
+
+func (f *mockFacade) Done() chan struct{} {
+	return f.done
+}
+
+func (f *mockFacade) RestartCallBack() bool {
+	f.restarted <- struct{}{}
+	return true
+}
+
+func (f *mockFacade) GetUrl() common.URL {
+	return *f.url.Clone()
+}
+
+func (f *mockFacade) validClient() *gxetcd.Client {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.client == nil || !f.client.Valid() {
+		return nil
+	}
+	return f.client
+}
+
+func TestValidateClient(t *testing.T) {
+	addr := freeAddr(t)
+	e := startEtcd(t, addr)
+	defer e.Close()
+
+	f := newMockFacade(t, addr)
+	assert.Nil(t, ValidateClient(f, WithName(RegistryETCDV3Client)))
+	client := f.Client()
+	assert.True(t, client.Valid())
+	// the valid client is kept
+	assert.Nil(t, ValidateClient(f, WithName(RegistryETCDV3Client)))
+	assert.Equal(t, client, f.Client())
+	client.Close()
+
+	// no server
+	f = newMockFacade(t, freeAddr(t))
+	assert.NotNil(t, ValidateClient(f))
+	assert.Nil(t, f.Client())
+}
+
+func TestListenServiceEvent(t *testing.T) {
+	addr := freeAddr(t)
+	e := startEtcd(t, addr)
+	defer e.Close()
+
+	f := newMockFacade(t, addr)
+	assert.Nil(t, ValidateClient(f))
+	client := f.Client()
+	defer client.Close()
+	assert.Nil(t, client.Create("/dubbo/a/providers/x", "1"))
+
+	events := make(chan remoting.Event, 8)
+	listener := NewEventListener(client)
+	listener.ListenServiceEvent("/dubbo/a/providers", dataListenerFunc(func(e remoting.Event) {
+		events <- e
+	}))
+	// a key is only listened once
+	listener.ListenServiceEvent("/dubbo/a/providers", dataListenerFunc(func(e remoting.Event) {
+		t.Errorf("unexpected event: %v", e)
+	}))
+	assert.Equal(t, []string{"/dubbo/a/providers"}, listener.ListenedKeys())
+	assert.Equal(t, remoting.Event{Path: "/dubbo/a/providers/x", Action: remoting.EventTypeAdd, Content: "1"}, nextEvent(t, events))
+
+	assert.Nil(t, client.Create("/dubbo/a/providers/y", "2"))
+	assert.Equal(t, remoting.Event{Path: "/dubbo/a/providers/y", Action: remoting.EventTypeAdd, Content: "2"}, nextEvent(t, events))
+	assert.Nil(t, client.Delete("/dubbo/a/providers/x"))
+	assert.Equal(t, remoting.Event{Path: "/dubbo/a/providers/x", Action: remoting.EventTypeDel}, nextEvent(t, events))
+
+	listener.Close()
+	assert.Empty(t, listener.ListenedKeys())
+}
+
+func TestHandleClientRestart(t *testing.T) {
+	addr := freeAddr(t)
+	e := startEtcd(t, addr)
+
+	f := newMockFacade(t, addr)
+	assert.Nil(t, ValidateClient(f, WithName(RegistryETCDV3Client)))
+	lost := f.Client()
+	go HandleClientRestart(f)
+
+	// the session is lost once the server is gone
+	e.Close()
+	select {
+	case <-lost.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd client is not stopped")
+	}
+
+	e = startEtcd(t, addr)
+	defer e.Close()
+	select {
+	case <-f.restarted:
+	case <-time.After(15 * time.Second):
+		t.Fatal("etcd client is not reconnected")
+	}
+	client := f.validClient()
+	if assert.NotNil(t, client) {
+		assert.NotEqual(t, lost, client)
+		assert.Nil(t, client.Create("/dubbo/reconnected", "1"))
+	}
+
+	close(f.done)
+	f.wg.Wait()
+	if client != nil {
+		client.Close()
+	}
+}
+
+type dataListenerFunc func(e remoting.Event)
+
+func (f dataListenerFunc) DataChange(e remoting.Event) bool {
+	f(e)
+	return true
+}
+
+func nextEvent(t *testing.T, events <-chan remoting.Event) remoting.Event {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+	return remoting.Event{}
+}
@@ -0,0 +1,151 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"sort"
+	"sync"
+
+	gxetcd "github.com/dubbogo/gost/database/kv/etcd/v3"
+	perrors "github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+// EventListener watches the keys of etcd and notifies the DataListener
+type EventListener struct {
+	client     *gxetcd.Client
+	keyMapLock sync.Mutex
+	keyMap     map[string]struct{}
+	exit       chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewEventListener returns a EventListener instance
+func NewEventListener(client *gxetcd.Client) *EventListener {
+	return &EventListener{
+		client: client,
+		keyMap: make(map[string]struct{}),
+		exit:   make(chan struct{}),
+	}
+}
+
+// ListenedKeys returns the sorted keys being listened
+func (l *EventListener) ListenedKeys() []string {
+	l.keyMapLock.Lock()
+	defer l.keyMapLock.Unlock()
+	keys := make([]string, 0, len(l.keyMap))
+	for k := range l.keyMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ListenServiceEvent watches the children of key, the existing children are notified as add events first.
+// A key is only listened once.
+func (l *EventListener) ListenServiceEvent(key string, listener remoting.DataListener) {
+	l.keyMapLock.Lock()
+	if _, ok := l.keyMap[key]; ok {
+		l.keyMapLock.Unlock()
+		logger.Warnf("etcd key %s has already been listened.", key)
+		return
+	}
+	l.keyMap[key] = struct{}{}
+	l.keyMapLock.Unlock()
+
+	keyList, valueList, err := l.client.GetChildrenKVList(key)
+	if err != nil && perrors.Cause(err) != gxetcd.ErrKVPairNotFound {
+		logger.Warnf("Get new node path {%v} 's content error,message is  {%v}", key, perrors.WithMessage(err, "get children"))
+	}
+	for i, k := range keyList {
+		listener.DataChange(remoting.Event{Path: k, Action: remoting.EventTypeAdd, Content: valueList[i]})
+	}
+
+	logger.Infof("listen dubbo path{%s}", key)
+	l.wg.Add(1)
+	go func(key string, listener remoting.DataListener) {
+		l.listenDirEvent(key, listener)
+		l.keyMapLock.Lock()
+		delete(l.keyMap, key)
+		l.keyMapLock.Unlock()
+		logger.Warnf("listenDirEvent(key{%s}) goroutine exit now", key)
+	}(key, listener)
+}
+
+// listenDirEvent watches the children of key until the client or listener is closed
+func (l *EventListener) listenDirEvent(key string, listener remoting.DataListener) {
+	defer l.wg.Done()
+
+	wc, err := l.client.WatchWithPrefix(key)
+	if err != nil {
+		logger.Warnf("WatchWithPrefix(key{%s}) = error{%v}", key, err)
+		return
+	}
+	for {
+		select {
+		case <-l.exit:
+			return
+		case <-l.client.Done():
+			logger.Warnf("etcd client stopped, listen(key{%s}) goroutine exit now...", key)
+			return
+		case resp, ok := <-wc:
+			if !ok {
+				logger.Warnf("etcd watch channel of key{%s} is closed", key)
+				return
+			}
+			if err := resp.Err(); err != nil {
+				logger.Warnf("etcd watch key{%s} = error{%v}", key, err)
+				return
+			}
+			for _, e := range resp.Events {
+				handleEvent(e, listener)
+			}
+		}
+	}
+}
+
+// handleEvent converts the etcd event to the remoting event
+func handleEvent(e *clientv3.Event, listener remoting.DataListener) {
+	if e == nil || e.Kv == nil {
+		return
+	}
+	event := remoting.Event{
+		Path:    string(e.Kv.Key),
+		Content: string(e.Kv.Value),
+	}
+	switch {
+	case e.Type == clientv3.EventTypeDelete:
+		event.Action = remoting.EventTypeDel
+	case e.IsCreate():
+		event.Action = remoting.EventTypeAdd
+	default:
+		event.Action = remoting.EventTypeUpdate
+	}
+	listener.DataChange(event)
+}
+
+// Close stops all the watches of the listener, the client is not closed
+func (l *EventListener) Close() {
+	l.closeOnce.Do(func() {
+		close(l.exit)
+	})
+	l.wg.Wait()
+}
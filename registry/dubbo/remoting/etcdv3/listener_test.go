@@ -0,0 +1,52 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+type mockDataListener struct {
+	events []remoting.Event
+}
+
+func (l *mockDataListener) DataChange(e remoting.Event) bool {
+	l.events = append(l.events, e)
+	return true
+}
+
+func Test_HandleEvent(t *testing.T) {
+	listener := &mockDataListener{}
+	kv := &mvccpb.KeyValue{Key: []byte("/dubbo/a/providers/x"), Value: []byte("v"), CreateRevision: 2, ModRevision: 2}
+	handleEvent(&clientv3.Event{Type: clientv3.EventTypePut, Kv: kv}, listener)
+	updated := &mvccpb.KeyValue{Key: kv.Key, Value: []byte("v2"), CreateRevision: 2, ModRevision: 3}
+	handleEvent(&clientv3.Event{Type: clientv3.EventTypePut, Kv: updated}, listener)
+	handleEvent(&clientv3.Event{Type: clientv3.EventTypeDelete, Kv: &mvccpb.KeyValue{Key: kv.Key}}, listener)
+	handleEvent(nil, listener)
+
+	assert.Equal(t, []remoting.Event{
+		{Path: "/dubbo/a/providers/x", Action: remoting.EventTypeAdd, Content: "v"},
+		{Path: "/dubbo/a/providers/x", Action: remoting.EventTypeUpdate, Content: "v2"},
+		{Path: "/dubbo/a/providers/x", Action: remoting.EventTypeDel},
+	}, listener.events)
+}
@@ -0,0 +1,264 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	perrors "github.com/pkg/errors"
+)
+
+const (
+	serviceAccountDir   = "/var/run/secrets/kubernetes.io/serviceaccount"
+	serviceAccountToken = serviceAccountDir + "/token"
+	serviceAccountCA    = serviceAccountDir + "/ca.crt"
+	serviceAccountNS    = serviceAccountDir + "/namespace"
+)
+
+// watch event types of the kubernetes api
+const (
+	WatchAdded    = "ADDED"
+	WatchModified = "MODIFIED"
+	WatchDeleted  = "DELETED"
+	WatchError    = "ERROR"
+	WatchBookmark = "BOOKMARK"
+)
+
+// ErrNotInCluster is returned by NewInClusterClient when the process is not running in a pod
+var ErrNotInCluster = perrors.New("not running in a kubernetes cluster")
+
+// ObjectMeta is the metadata of the kubernetes objects
+type ObjectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+}
+
+// ListMeta is the metadata of the kubernetes lists
+type ListMeta struct {
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Service is the part of the v1 Service used by the registry
+type Service struct {
+	Metadata ObjectMeta `json:"metadata"`
+}
+
+// ServiceList is the list of the v1 Service
+type ServiceList struct {
+	Metadata ListMeta  `json:"metadata"`
+	Items    []Service `json:"items"`
+}
+
+// EndpointAddress is an address of the endpoints
+type EndpointAddress struct {
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname,omitempty"`
+	NodeName string `json:"nodeName,omitempty"`
+}
+
+// EndpointPort is a port of the endpoints
+type EndpointPort struct {
+	Name     string `json:"name,omitempty"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// EndpointSubset is a group of addresses with the same ports
+type EndpointSubset struct {
+	Addresses         []EndpointAddress `json:"addresses,omitempty"`
+	NotReadyAddresses []EndpointAddress `json:"notReadyAddresses,omitempty"`
+	Ports             []EndpointPort    `json:"ports,omitempty"`
+}
+
+// Endpoints is the v1 Endpoints
+type Endpoints struct {
+	Metadata ObjectMeta       `json:"metadata"`
+	Subsets  []EndpointSubset `json:"subsets,omitempty"`
+}
+
+// EndpointsWatchEvent is an event of the endpoints watch
+type EndpointsWatchEvent struct {
+	Type   string    `json:"type"`
+	Object Endpoints `json:"object"`
+}
+
+// Client is a minimal client of the kubernetes api which lists the services and watches the endpoints
+type Client struct {
+	host       string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the kubernetes api server at host, like https://10.0.0.1:6443.
+// The token is sent as the bearer token if it is not empty.
+func NewClient(host, token string, tlsConfig *tls.Config) *Client {
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &Client{
+		host:       strings.TrimSuffix(host, "/"),
+		token:      token,
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+// NewInClusterClient returns a client with the service account of the pod
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+	token, err := ioutil.ReadFile(serviceAccountToken)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "read service account token")
+	}
+	ca, err := ioutil.ReadFile(serviceAccountCA)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "read service account ca")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, perrors.New("invalid service account ca")
+	}
+	return NewClient("https://"+net.JoinHostPort(host, port), strings.TrimSpace(string(token)), &tls.Config{RootCAs: pool}), nil
+}
+
+// InClusterNamespace returns the namespace of the pod, or an empty string if it is unknown
+func InClusterNamespace() string {
+	ns, err := ioutil.ReadFile(serviceAccountNS)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(ns))
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.host + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, perrors.Errorf("kubernetes get %s: %s %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return perrors.WithMessagef(json.NewDecoder(resp.Body).Decode(v), "decode %s", path)
+}
+
+// ServerVersion returns the git version of the api server, which is a lightweight round trip to the cluster
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	version := struct {
+		GitVersion string `json:"gitVersion"`
+	}{}
+	if err := c.getJSON(ctx, "/version", nil, &version); err != nil {
+		return "", err
+	}
+	return version.GitVersion, nil
+}
+
+// ListServices lists the services of the namespace matching the label selector
+func (c *Client) ListServices(ctx context.Context, namespace, labelSelector string) (*ServiceList, error) {
+	query := url.Values{}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	list := &ServiceList{}
+	if err := c.getJSON(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/services", query, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetEndpoints gets the endpoints of the service
+func (c *Client) GetEndpoints(ctx context.Context, namespace, name string) (*Endpoints, error) {
+	endpoints := &Endpoints{}
+	path := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/endpoints/" + url.PathEscape(name)
+	if err := c.getJSON(ctx, path, nil, endpoints); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// WatchEndpoints watches the endpoints of the service from the resource version, the events are sent
+// to the returned channel, which is closed once the watch ends or the context is done
+func (c *Client) WatchEndpoints(ctx context.Context, namespace, name, resourceVersion string) (<-chan EndpointsWatchEvent, error) {
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("fieldSelector", "metadata.name="+name)
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+	resp, err := c.get(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/endpoints", query)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan EndpointsWatchEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event EndpointsWatchEvent
+			if err := decoder.Decode(&event); err != nil {
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	nacosClient "github.com/dubbogo/gost/database/kv/nacos"
+	nacosConstant "github.com/nacos-group/nacos-sdk-go/common/constant"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+)
+
+// GetNacosConfig returns the server and client configs of nacos parsed from the registry url,
+// the location of the url is a comma separated list of nacos server addresses
+func GetNacosConfig(url *common.URL) ([]nacosConstant.ServerConfig, nacosConstant.ClientConfig, error) {
+	if url == nil {
+		return nil, nacosConstant.ClientConfig{}, perrors.New("url is empty!")
+	}
+
+	if len(url.Location) == 0 {
+		return nil, nacosConstant.ClientConfig{}, perrors.New("url.location is empty!")
+	}
+
+	addresses := strings.Split(url.Location, ",")
+	serverConfigs := make([]nacosConstant.ServerConfig, 0, len(addresses))
+	for _, addr := range addresses {
+		ip, portStr, err := net.SplitHostPort(strings.TrimSpace(addr))
+		if err != nil {
+			return nil, nacosConstant.ClientConfig{}, perrors.WithMessagef(err, "split nacos address %s", addr)
+		}
+		port, err := strconv.ParseUint(portStr, 10, 64)
+		if err != nil {
+			return nil, nacosConstant.ClientConfig{}, perrors.WithMessagef(err, "parse nacos port of %s", addr)
+		}
+		serverConfigs = append(serverConfigs, nacosConstant.ServerConfig{IpAddr: ip, Port: port})
+	}
+
+	timeout, err := time.ParseDuration(url.GetParam(constant.REGISTRY_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT))
+	if err != nil {
+		return nil, nacosConstant.ClientConfig{}, perrors.WithMessagef(err, "parse timeout %s",
+			url.GetParam(constant.REGISTRY_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT))
+	}
+
+	clientConfig := nacosConstant.ClientConfig{
+		TimeoutMs:           uint64(timeout / time.Millisecond),
+		NamespaceId:         url.GetParam(constant.NACOS_NAMESPACE_ID, ""),
+		Endpoint:            url.GetParam(constant.NACOS_ENDPOINT, ""),
+		CacheDir:            url.GetParam(constant.NACOS_CACHE_DIR_KEY, ""),
+		LogDir:              url.GetParam(constant.NACOS_LOG_DIR_KEY, ""),
+		Username:            url.GetParam(constant.NACOS_USERNAME, url.Username),
+		Password:            url.GetParam(constant.NACOS_PASSWORD, url.Password),
+		NotLoadCacheAtStart: true,
+	}
+	return serverConfigs, clientConfig, nil
+}
+
+// NewNacosNamingClient creates a naming client of nacos by the registry url,
+// the clients of the same name are shared
+func NewNacosNamingClient(name string, url *common.URL) (*nacosClient.NacosNamingClient, error) {
+	serverConfigs, clientConfig, err := GetNacosConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	return nacosClient.NewNacosNamingClient(name, true, serverConfigs, clientConfig)
+}
+
+// NewNacosConfigClient creates a config client of nacos by the config center url, the config.timeout
+// parameter overrides the timeout of the client, the clients of the same name are shared
+func NewNacosConfigClient(name string, url *common.URL) (*nacosClient.NacosConfigClient, error) {
+	serverConfigs, clientConfig, err := GetNacosConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	if t := url.GetParam(constant.CONFIG_TIMEOUT_KET, ""); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil {
+			return nil, perrors.WithMessagef(err, "parse timeout %s", t)
+		}
+		clientConfig.TimeoutMs = uint64(timeout / time.Millisecond)
+	}
+	return nacosClient.NewNacosConfigClient(name, true, serverConfigs, clientConfig)
+}
@@ -0,0 +1,48 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+func TestGetNacosConfig(t *testing.T) {
+	url, err := common.NewURL("registry://127.0.0.1:8848?registry.timeout=3s&namespaceId=dev&username=nacos&password=secret")
+	assert.Nil(t, err)
+	url.Location = "127.0.0.1:8848,127.0.0.2:8849"
+
+	serverConfigs, clientConfig, err := GetNacosConfig(&url)
+	assert.Nil(t, err)
+	assert.Len(t, serverConfigs, 2)
+	assert.Equal(t, "127.0.0.2", serverConfigs[1].IpAddr)
+	assert.Equal(t, uint64(8849), serverConfigs[1].Port)
+	assert.Equal(t, uint64(3000), clientConfig.TimeoutMs)
+	assert.Equal(t, "dev", clientConfig.NamespaceId)
+	assert.Equal(t, "nacos", clientConfig.Username)
+	assert.Equal(t, "secret", clientConfig.Password)
+
+	_, _, err = GetNacosConfig(&common.URL{})
+	assert.NotNil(t, err)
+
+	url, _ = common.NewURL("registry://127.0.0.1?registry.timeout=3s")
+	_, _, err = GetNacosConfig(&url)
+	assert.NotNil(t, err)
+}
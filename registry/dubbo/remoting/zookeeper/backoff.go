@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/constant"
+)
+
+// BackoffPolicy controls how the client reconnects after the connection to zookeeper is lost.
+// The first attempt is made immediately, then the delay starts at InitialDelay and grows
+// by InitialDelay after each failed attempt up to MaxDelay, or doubles if Exponential is set.
+type BackoffPolicy struct {
+	// InitialDelay is the delay before the second attempt
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between the attempts
+	MaxDelay time.Duration
+	// Exponential doubles the delay after each failed attempt instead of growing it linearly
+	Exponential bool
+	// Jitter randomizes the delay by up to this fraction of it in both directions, in [0, 1]
+	Jitter float64
+	// MaxAttempts is the number of attempts before giving up, zero means never giving up
+	MaxAttempts int
+	// OnGiveUp is called with the last error once MaxAttempts attempts are failed
+	OnGiveUp func(err error)
+}
+
+// DefaultBackoffPolicy returns the policy used when none is set, it retries forever
+// with the delay growing linearly from ConnDelay seconds to MaxFailTimes*ConnDelay seconds
+func DefaultBackoffPolicy() BackoffPolicy {
+	return BackoffPolicy{
+		InitialDelay: timeSecondDuration(ConnDelay),
+		MaxDelay:     timeSecondDuration(MaxFailTimes * ConnDelay),
+	}
+}
+
+// Delay returns the delay before the attempt, which counts from zero
+func (p BackoffPolicy) Delay(attempt int) time.Duration {
+	if attempt <= 0 || p.InitialDelay <= 0 {
+		return 0
+	}
+	delay := p.InitialDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		if p.Exponential {
+			delay *= 2
+		} else {
+			delay += p.InitialDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay += time.Duration((rand.Float64()*2 - 1) * jitter * float64(delay))
+	}
+	return delay
+}
+
+// GiveUp returns whether the policy gives up after the number of failed attempts
+func (p BackoffPolicy) GiveUp(failed int) bool {
+	return p.MaxAttempts > 0 && failed >= p.MaxAttempts
+}
+
+// BackoffPolicyFromURL returns the default policy overridden by the registry.backoff.* parameters of the url
+func BackoffPolicyFromURL(url *common.URL) (BackoffPolicy, error) {
+	var err error
+	policy := DefaultBackoffPolicy()
+	if v := url.GetParam(constant.REGISTRY_BACKOFF_INITIAL_KEY, ""); v != "" {
+		if policy.InitialDelay, err = time.ParseDuration(v); err != nil {
+			return policy, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_BACKOFF_INITIAL_KEY)
+		}
+	}
+	if v := url.GetParam(constant.REGISTRY_BACKOFF_MAX_KEY, ""); v != "" {
+		if policy.MaxDelay, err = time.ParseDuration(v); err != nil {
+			return policy, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_BACKOFF_MAX_KEY)
+		}
+	}
+	if v := url.GetParam(constant.REGISTRY_BACKOFF_EXPONENTIAL_KEY, ""); v != "" {
+		if policy.Exponential, err = strconv.ParseBool(v); err != nil {
+			return policy, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_BACKOFF_EXPONENTIAL_KEY)
+		}
+	}
+	if v := url.GetParam(constant.REGISTRY_BACKOFF_JITTER_KEY, ""); v != "" {
+		if policy.Jitter, err = strconv.ParseFloat(v, 64); err != nil {
+			return policy, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_BACKOFF_JITTER_KEY)
+		}
+	}
+	if v := url.GetParam(constant.REGISTRY_BACKOFF_MAX_ATTEMPTS_KEY, ""); v != "" {
+		if policy.MaxAttempts, err = strconv.Atoi(v); err != nil {
+			return policy, perrors.WithMessagef(err, "parse %s", constant.REGISTRY_BACKOFF_MAX_ATTEMPTS_KEY)
+		}
+	}
+	return policy, nil
+}
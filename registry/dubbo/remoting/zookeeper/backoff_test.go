@@ -0,0 +1,83 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+)
+
+func TestBackoffPolicyDelay(t *testing.T) {
+	policy := BackoffPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second, Exponential: true}
+	for attempt, expected := range []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		assert.Equal(t, expected, policy.Delay(attempt), "attempt %d", attempt)
+	}
+	assert.Equal(t, 5*time.Second, policy.Delay(1000))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(2)
+		assert.True(t, delay >= time.Second && delay <= 3*time.Second, "delay %v", delay)
+	}
+
+	assert.False(t, policy.GiveUp(100))
+	policy.MaxAttempts = 3
+	assert.False(t, policy.GiveUp(2))
+	assert.True(t, policy.GiveUp(3))
+}
+
+func TestDefaultBackoffPolicy(t *testing.T) {
+	// the default keeps the linear schedule of failTimes*ConnDelay seconds
+	policy := DefaultBackoffPolicy()
+	for attempt := 0; attempt <= MaxFailTimes+5; attempt++ {
+		failTimes := attempt
+		if failTimes > MaxFailTimes {
+			failTimes = MaxFailTimes
+		}
+		assert.Equal(t, timeSecondDuration(failTimes*ConnDelay), policy.Delay(attempt), "attempt %d", attempt)
+	}
+	assert.False(t, policy.GiveUp(1000))
+
+	policy = BackoffPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, expected := range []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		assert.Equal(t, expected, policy.Delay(attempt), "attempt %d", attempt)
+	}
+}
+
+func TestBackoffPolicyFromURL(t *testing.T) {
+	url, _ := common.NewURL("registry://127.0.0.1:2181")
+	policy, err := BackoffPolicyFromURL(&url)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultBackoffPolicy(), policy)
+
+	url, _ = common.NewURL("registry://127.0.0.1:2181?registry.backoff.initial=100ms&registry.backoff.max=2s&registry.backoff.jitter=0.2&registry.backoff.max-attempts=10&registry.backoff.exponential=true")
+	policy, err = BackoffPolicyFromURL(&url)
+	assert.Nil(t, err)
+	assert.Equal(t, 100*time.Millisecond, policy.InitialDelay)
+	assert.Equal(t, 2*time.Second, policy.MaxDelay)
+	assert.Equal(t, 0.2, policy.Jitter)
+	assert.Equal(t, 10, policy.MaxAttempts)
+	assert.True(t, policy.Exponential)
+
+	url, _ = common.NewURL("registry://127.0.0.1:2181?registry.backoff.max-attempts=x")
+	_, err = BackoffPolicyFromURL(&url)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,148 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+)
+
+// ErrMemberExists is returned by DoubleBarrier.Enter if the id of the member has entered the barrier
+var ErrMemberExists = perrors.New("zookeeper barrier member exists")
+
+// barrierReady is the node created once enough members have entered the barrier
+const barrierReady = "ready"
+
+// DoubleBarrier lets the members start and finish a computation together, the same as the
+// DistributedDoubleBarrier of curator. Enter blocks until @members members have entered, and Leave
+// blocks until all the members have left. A member is left once its client is closed, as the session
+// may expire then.
+type DoubleBarrier struct {
+	client  recipeClient
+	path    string
+	id      string
+	members int
+
+	lock sync.Mutex
+	node string
+}
+
+// NewDoubleBarrier returns the member identified by @id of the barrier on @barrierPath for @members members
+func NewDoubleBarrier(client *ZookeeperClient, barrierPath string, id string, members int) *DoubleBarrier {
+	return newDoubleBarrier(client, barrierPath, id, members)
+}
+
+func newDoubleBarrier(client recipeClient, barrierPath string, id string, members int) *DoubleBarrier {
+	return &DoubleBarrier{client: client, path: barrierPath, id: id, members: members}
+}
+
+// entered returns the number of the members in the children of the barrier
+func (b *DoubleBarrier) entered(children []string) int {
+	n := 0
+	for _, child := range children {
+		if child != barrierReady {
+			n++
+		}
+	}
+	return n
+}
+
+// Enter blocks until enough members have entered the barrier or the ctx is done,
+// the member leaves the barrier if an error is returned
+func (b *DoubleBarrier) Enter(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.node != "" {
+		return ErrAlreadyHeld
+	}
+	readyPath := path.Join(b.path, barrierReady)
+	ready, events, err := b.client.recipeExistsW(readyPath)
+	if err != nil {
+		return err
+	}
+	node, err := b.client.createRecipeNode(path.Join(b.path, b.id), nil, zk.FlagEphemeral)
+	if err == zk.ErrNodeExists {
+		return ErrMemberExists
+	}
+	if err != nil {
+		return err
+	}
+	if !ready {
+		var children []string
+		if children, err = b.client.recipeChildren(b.path); err == nil && b.entered(children) >= b.members {
+			if _, err = b.client.createRecipeNode(readyPath, nil, 0); err == zk.ErrNodeExists {
+				err = nil
+			}
+			ready = true
+		}
+	}
+	for err == nil && !ready {
+		select {
+		case <-events:
+			ready, events, err = b.client.recipeExistsW(readyPath)
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-b.client.Done():
+			err = ErrSessionLost
+		}
+	}
+	if err != nil {
+		_ = b.client.recipeDelete(node)
+		return err
+	}
+	b.node = node
+	return nil
+}
+
+// Leave leaves the barrier and blocks until all the members have left or the ctx is done
+func (b *DoubleBarrier) Leave(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.node == "" {
+		return ErrNotHeld
+	}
+	if err := b.client.recipeDelete(b.node); err != nil {
+		return err
+	}
+	b.node = ""
+
+	for {
+		children, events, err := b.client.recipeChildrenW(b.path)
+		if err == zk.ErrNoNode {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if b.entered(children) == 0 {
+			// the last one resets the barrier for the next round
+			return b.client.recipeDelete(path.Join(b.path, barrierReady))
+		}
+		select {
+		case <-events:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.client.Done():
+			return ErrSessionLost
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"path"
+	"strings"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+)
+
+// WithChroot sets the root path prepended to the paths of all the operations of the client, so that
+// several environments can share one ensemble, e.g. WithChroot("test", "gray") makes the client operate
+// /test/gray/dubbo for /dubbo. The paths returned by the client and notified by its listeners are relative
+// to the root.
+func WithChroot(segments ...string) Option {
+	return func(opt *Options) {
+		root := path.Join(append([]string{"/"}, segments...)...)
+		if root == "/" {
+			root = ""
+		}
+		opt.chroot = root
+	}
+}
+
+// Chroot returns the root path of the client, which is empty if it is not set
+func (z *ZookeeperClient) Chroot() string {
+	return z.chroot
+}
+
+// realPath returns the path in the ensemble of the path relative to the root
+func (z *ZookeeperClient) realPath(zkPath string) string {
+	if z.chroot == "" || zkPath == "" {
+		return zkPath
+	}
+	if zkPath == "/" {
+		return z.chroot
+	}
+	return z.chroot + zkPath
+}
+
+// clientPath returns the path relative to the root of the path in the ensemble,
+// false is returned if the path is out of the root
+func (z *ZookeeperClient) clientPath(zkPath string) (string, bool) {
+	if z.chroot == "" {
+		return zkPath, true
+	}
+	if zkPath == z.chroot {
+		return "/", true
+	}
+	if strings.HasPrefix(zkPath, z.chroot+"/") {
+		return zkPath[len(z.chroot):], true
+	}
+	return zkPath, false
+}
+
+// ClientEvent returns the watcher event with the path relative to the root. The events sent by
+// GetChildrenW and ExistW keep the paths in the ensemble, so that no goroutine is needed per watch,
+// the receivers translate the paths themselves.
+func (z *ZookeeperClient) ClientEvent(event zk.Event) zk.Event {
+	event.Path, _ = z.clientPath(event.Path)
+	return event
+}
@@ -18,6 +18,7 @@
 package zookeeper
 
 import (
+	"context"
 	"path"
 	"strings"
 	"sync"
@@ -27,6 +28,7 @@ import (
 
 	"mosn.io/pkg/registry/dubbo/common/constant"
 	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
 	perrors "github.com/pkg/errors"
 )
 
@@ -35,14 +37,23 @@ const (
 	ConnDelay = 3
 	// MaxFailTimes max fail times
 	MaxFailTimes = 15
+	// DigestScheme is the digest auth scheme of zookeeper
+	DigestScheme = "digest"
 )
 
 var (
-	errNilZkClientConn = perrors.New("zookeeper client{conn} is nil")
-	errNilChildren     = perrors.Errorf("has none children")
-	errNilNode         = perrors.Errorf("node does not exist")
+	// ErrNotConnected is returned by the operations when the client has no connection to zookeeper,
+	// which is the case before the client connects or while it is reconnecting
+	ErrNotConnected = perrors.New("zookeeper client{conn} is nil")
+	errNilChildren  = perrors.Errorf("has none children")
+	errNilNode      = perrors.Errorf("node does not exist")
 )
 
+// IsNotConnected returns whether the error is caused by the client having no connection
+func IsNotConnected(err error) bool {
+	return perrors.Cause(err) == ErrNotConnected
+}
+
 // ZookeeperClient represents zookeeper client Configuration
 type ZookeeperClient struct {
 	name         string
@@ -55,6 +66,21 @@ type ZookeeperClient struct {
 
 	eventRegistry     map[string][]*chan struct{}
 	eventRegistryLock sync.RWMutex
+
+	authScheme string
+	authData   []byte
+	acl        []zk.ACL // acl of the created nodes
+	backoff    BackoffPolicy
+
+	tempNodesLock     sync.Mutex
+	tempNodes         map[string][]byte // ephemeral nodes created by the client, path -> data
+	tempNodeListeners []remoting.DataListener
+	expiredSession    int64 // session of the last closed connection, guarded by the conn lock
+
+	metrics   *clientMetrics
+	codec     remoting.Codec
+	coalescer *eventCoalescer
+	chroot    string
 }
 
 // nolint
@@ -89,8 +115,26 @@ func StateToString(state zk.State) string {
 
 // nolint
 type Options struct {
-	zkName string
-	client *ZookeeperClient
+	zkName     string
+	client     *ZookeeperClient
+	authScheme string
+	authData   []byte
+	acl        []zk.ACL
+	backoff    *BackoffPolicy
+
+	tempNodes         map[string][]byte
+	tempNodeListeners []remoting.DataListener
+	expiredSession    int64
+
+	metrics          *clientMetrics
+	metricsListeners []MetricsListener
+
+	codec remoting.Codec
+
+	coalesceWindow  time.Duration
+	coalesceWindows map[string]time.Duration
+
+	chroot string
 
 	ts *zk.TestCluster
 }
@@ -105,11 +149,64 @@ func WithZkName(name string) Option {
 	}
 }
 
-// ValidateZookeeperClient validates client and sets options
+// WithDigestAuth sets the digest credentials which are added to the session after connecting,
+// the created nodes are only accessible with the same credentials unless WithACL is set
+func WithDigestAuth(username, password string) Option {
+	return func(opt *Options) {
+		opt.authScheme = DigestScheme
+		opt.authData = []byte(username + ":" + password)
+		if opt.acl == nil {
+			opt.acl = zk.DigestACL(zk.PermAll, username, password)
+		}
+	}
+}
+
+// WithACL sets the acl of the nodes created by the client, the default is zk.WorldACL(zk.PermAll)
+func WithACL(acl []zk.ACL) Option {
+	return func(opt *Options) {
+		opt.acl = acl
+	}
+}
+
+// WithBackoffPolicy sets the policy of reconnecting after the connection is lost
+func WithBackoffPolicy(policy BackoffPolicy) Option {
+	return func(opt *Options) {
+		opt.backoff = &policy
+	}
+}
+
+// ValidateZookeeperClient validates client and sets options, the digest credentials are
+// read from the user info of the url and the backoff policy from the registry.backoff.* parameters
 func ValidateZookeeperClient(container ZkClientFacade, opts ...Option) error {
 	var (
 		err error
 	)
+	url := container.GetUrl()
+	policy, err := BackoffPolicyFromURL(&url)
+	if err != nil {
+		return perrors.WithMessagef(err, "newZookeeperClient(address:%+v)", url.Location)
+	}
+	opts = append([]Option{WithBackoffPolicy(policy)}, opts...)
+	if name := url.GetParam(constant.REGISTRY_CODEC_KEY, ""); name != "" {
+		codec, err := remoting.GetCodec(name)
+		if err != nil {
+			return perrors.WithMessagef(err, "newZookeeperClient(address:%+v)", url.Location)
+		}
+		opts = append([]Option{WithCodec(codec)}, opts...)
+	}
+	if window := url.GetParam(constant.REGISTRY_EVENT_COALESCE_KEY, ""); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return perrors.WithMessagef(err, "parse %s", constant.REGISTRY_EVENT_COALESCE_KEY)
+		}
+		opts = append([]Option{WithEventCoalesceWindow(d)}, opts...)
+	}
+	if root := url.GetParam(constant.REGISTRY_CHROOT_KEY, ""); root != "" {
+		opts = append([]Option{WithChroot(root)}, opts...)
+	}
+	if len(url.Username) > 0 {
+		opts = append([]Option{WithDigestAuth(url.Username, url.Password)}, opts...)
+	}
 	options := &Options{}
 	for _, opt := range opts {
 		opt(options)
@@ -117,7 +214,6 @@ func ValidateZookeeperClient(container ZkClientFacade, opts ...Option) error {
 	connected := false
 
 	lock := container.ZkClientLock()
-	url := container.GetUrl()
 
 	lock.Lock()
 	defer lock.Unlock()
@@ -131,7 +227,7 @@ func ValidateZookeeperClient(container ZkClientFacade, opts ...Option) error {
 			return perrors.WithMessagef(err, "newZookeeperClient(address:%+v)", url.Location)
 		}
 		zkAddresses := strings.Split(url.Location, ",")
-		newClient, err := NewZookeeperClient(options.zkName, zkAddresses, timeout)
+		newClient, err := NewZookeeperClient(options.zkName, zkAddresses, timeout, opts...)
 		if err != nil {
 			logger.Warnf("newZookeeperClient(name{%s}, zk address{%v}, timeout{%d}) = error{%v}",
 				options.zkName, url.Location, timeout.String(), err)
@@ -143,11 +239,19 @@ func ValidateZookeeperClient(container ZkClientFacade, opts ...Option) error {
 
 	if container.ZkClient().Conn == nil {
 		var event <-chan zk.Event
-		container.ZkClient().Conn, event, err = zk.Connect(container.ZkClient().ZkAddrs, container.ZkClient().Timeout)
+		var conn *zk.Conn
+		conn, event, err = zk.Connect(container.ZkClient().ZkAddrs, container.ZkClient().Timeout)
+		if err == nil {
+			if err = container.ZkClient().addAuth(conn); err != nil {
+				conn.Close()
+			}
+		}
 		if err == nil {
+			container.ZkClient().Conn = conn
 			container.ZkClient().Wait.Add(1)
 			connected = true
 			go container.ZkClient().HandleZkEvent(event)
+			container.ZkClient().recoverTempNodes()
 		}
 	}
 
@@ -159,8 +263,8 @@ func ValidateZookeeperClient(container ZkClientFacade, opts ...Option) error {
 	return perrors.WithMessagef(err, "newZookeeperClient(address:%+v)", url.PrimitiveURL)
 }
 
-// nolint
-func NewZookeeperClient(name string, zkAddrs []string, timeout time.Duration) (*ZookeeperClient, error) {
+// NewZookeeperClient connects to zookeeper, the auth options are applied to the session
+func NewZookeeperClient(name string, zkAddrs []string, timeout time.Duration, opts ...Option) (*ZookeeperClient, error) {
 	var (
 		err   error
 		event <-chan zk.Event
@@ -174,14 +278,25 @@ func NewZookeeperClient(name string, zkAddrs []string, timeout time.Duration) (*
 		exit:          make(chan struct{}),
 		eventRegistry: make(map[string][]*chan struct{}),
 	}
+	options := &Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	z.applyOptions(options)
+
 	// connect to zookeeper
 	z.Conn, event, err = zk.Connect(zkAddrs, timeout)
 	if err != nil {
 		return nil, perrors.WithMessagef(err, "zk.Connect(zkAddrs:%+v)", zkAddrs)
 	}
+	if err = z.addAuth(z.Conn); err != nil {
+		z.Conn.Close()
+		return nil, err
+	}
 
 	z.Wait.Add(1)
 	go z.HandleZkEvent(event)
+	z.recoverTempNodes()
 
 	return z, nil
 }
@@ -225,14 +340,74 @@ func NewMockZookeeperClient(name string, timeout time.Duration, opts ...Option)
 		}
 	}
 
+	z.applyOptions(options)
 	z.Conn, event, err = ts.ConnectWithOptions(timeout)
 	if err != nil {
 		return nil, nil, nil, perrors.WithMessagef(err, "zk.Connect")
 	}
+	if err = z.addAuth(z.Conn); err != nil {
+		z.Conn.Close()
+		return nil, nil, nil, err
+	}
 
 	return ts, z, event, nil
 }
 
+// applyOptions keeps the auth, reconnect, recovery, metrics, codec, coalescing and chroot options, which are applied to every connection of the client
+func (z *ZookeeperClient) applyOptions(options *Options) {
+	z.authScheme = options.authScheme
+	z.authData = options.authData
+	z.acl = options.acl
+	z.backoff = DefaultBackoffPolicy()
+	if options.backoff != nil {
+		z.backoff = *options.backoff
+	}
+	z.tempNodes = make(map[string][]byte, len(options.tempNodes))
+	for p, data := range options.tempNodes {
+		z.tempNodes[p] = data
+	}
+	z.tempNodeListeners = options.tempNodeListeners
+	z.expiredSession = options.expiredSession
+	z.metrics = options.metrics
+	if z.metrics == nil {
+		z.metrics = newClientMetrics()
+	}
+	for _, listener := range options.metricsListeners {
+		z.AddMetricsListener(listener)
+	}
+	z.codec = options.codec
+	if z.codec == nil {
+		z.codec, _ = remoting.GetCodec(remoting.CodecDubbo)
+	}
+	z.coalescer = newEventCoalescer(options.coalesceWindow, options.coalesceWindows)
+	z.chroot = options.chroot
+}
+
+// BackoffPolicy returns the reconnect policy of the client
+func (z *ZookeeperClient) BackoffPolicy() BackoffPolicy {
+	return z.backoff
+}
+
+// addAuth adds the credentials of the client to the session of the connection,
+// they are resubmitted by the connection after reconnecting
+func (z *ZookeeperClient) addAuth(conn *zk.Conn) error {
+	if z.authScheme == "" {
+		return nil
+	}
+	if err := conn.AddAuth(z.authScheme, z.authData); err != nil {
+		return perrors.WithMessagef(err, "zk.AddAuth(scheme:%s)", z.authScheme)
+	}
+	return nil
+}
+
+// nodeACL returns the acl of the nodes created by the client
+func (z *ZookeeperClient) nodeACL() []zk.ACL {
+	if len(z.acl) > 0 {
+		return z.acl
+	}
+	return zk.WorldACL(zk.PermAll)
+}
+
 // HandleZkEvent handles zookeeper events
 func (z *ZookeeperClient) HandleZkEvent(session <-chan zk.Event) {
 	var (
@@ -252,32 +427,44 @@ func (z *ZookeeperClient) HandleZkEvent(session <-chan zk.Event) {
 		case event = <-session:
 			logger.Infof("client{%s} get a zookeeper event{type:%s, server:%s, path:%s, state:%d-%s, err:%v}",
 				z.name, event.Type, event.Server, event.Path, event.State, StateToString(event.State), event.Err)
+			z.observeEvent(event)
 			switch (int)(event.State) {
 			case (int)(zk.StateDisconnected):
 				logger.Warnf("zk{addr:%s} state is StateDisconnected, so close the zk client{name:%s}.", z.ZkAddrs, z.name)
+				z.setConnState(ConnStateReconnecting)
 				z.stop()
-				z.Lock()
-				conn := z.Conn
-				z.Conn = nil
-				z.Unlock()
+				conn := z.detachConn()
 				if conn != nil {
 					conn.Close()
 				}
 				return
 			case (int)(zk.EventNodeDataChanged), (int)(zk.EventNodeChildrenChanged):
 				logger.Infof("zkClient{%s} get zk node changed event{path:%s}", z.name, event.Path)
+				eventPath, ok := z.clientPath(event.Path)
+				if !ok {
+					break
+				}
+				var paths []string
 				z.eventRegistryLock.RLock()
-				for p, a := range z.eventRegistry {
-					if strings.HasPrefix(p, event.Path) {
-						logger.Infof("send event{state:zk.EventNodeDataChange, Path:%s} notify event to path{%s} related listener",
-							event.Path, p)
-						for _, e := range a {
-							*e <- struct{}{}
-						}
+				for p := range z.eventRegistry {
+					if strings.HasPrefix(p, eventPath) {
+						paths = append(paths, p)
 					}
 				}
 				z.eventRegistryLock.RUnlock()
+				for _, p := range paths {
+					logger.Infof("send event{state:zk.EventNodeDataChange, Path:%s} notify event to path{%s} related listener",
+						event.Path, p)
+					z.notifyEvent(p)
+				}
+			case (int)(zk.StateExpired):
+				z.setConnState(ConnStateExpired)
 			case (int)(zk.StateConnecting), (int)(zk.StateConnected), (int)(zk.StateHasSession):
+				if event.State == zk.StateHasSession {
+					z.setConnState(ConnStateConnected)
+				} else if state == (int)(zk.StateHasSession) {
+					z.setConnState(ConnStateReconnecting)
+				}
 				if state == (int)(zk.StateHasSession) {
 					continue
 				}
@@ -372,10 +559,11 @@ func (z *ZookeeperClient) Close() {
 
 	z.stop()
 	z.Wait.Wait()
-	z.Lock()
-	conn := z.Conn
-	z.Conn = nil
-	z.Unlock()
+	z.stopCoalescing()
+	if z.ConnState() != ConnStateReconnecting {
+		z.setConnState(ConnStateClosed)
+	}
+	conn := z.detachConn()
 	if conn != nil {
 		logger.Infof("zkClient Conn{name:%s, zk addr:%d} exit now.", z.name, conn.SessionID())
 		conn.Close()
@@ -394,6 +582,10 @@ func (z *ZookeeperClient) Create(basePath string) error {
 // CreateWithValue will create the node recursively, which means that if the parent node is absent,
 // it will create parent node first.
 func (z *ZookeeperClient) CreateWithValue(basePath string, value []byte) error {
+	return z.createWithValue(context.Background(), basePath, value)
+}
+
+func (z *ZookeeperClient) createWithValue(ctx context.Context, basePath string, value []byte) error {
 	var (
 		err     error
 		tmpPath string
@@ -401,14 +593,19 @@ func (z *ZookeeperClient) CreateWithValue(basePath string, value []byte) error {
 
 	logger.Debugf("zookeeperClient.Create(basePath{%s})", basePath)
 	conn := z.getConn()
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	if conn == nil {
 		return perrors.WithMessagef(err, "zk.Create(path:%s)", basePath)
 	}
 
-	for _, str := range strings.Split(basePath, "/")[1:] {
+	for _, str := range strings.Split(z.realPath(basePath), "/")[1:] {
 		tmpPath = path.Join(tmpPath, "/", str)
-		_, err = conn.Create(tmpPath, value, 0, zk.WorldACL(zk.PermAll))
+		err = call(ctx, func() error {
+			start := time.Now()
+			_, err := conn.Create(tmpPath, value, 0, z.nodeACL())
+			z.observe(OpCreate, start, err)
+			return err
+		})
 
 		if err != nil {
 			if err == zk.ErrNodeExists {
@@ -434,23 +631,29 @@ func (z *ZookeeperClient) CreateTempWithValue(basePath string, value []byte) err
 
 	logger.Debugf("zookeeperClient.Create(basePath{%s})", basePath)
 	conn := z.getConn()
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	if conn == nil {
 		return perrors.WithMessagef(err, "zk.Create(path:%s)", basePath)
 	}
 
-	pathSlice := strings.Split(basePath, "/")[1:]
+	pathSlice := strings.Split(z.realPath(basePath), "/")[1:]
 	length := len(pathSlice)
 	for i, str := range pathSlice {
 		tmpPath = path.Join(tmpPath, "/", str)
+		start := time.Now()
 		// last child need be ephemeral
 		if i == length-1 {
-			_, err = conn.Create(tmpPath, value, zk.FlagEphemeral, zk.WorldACL(zk.PermAll))
+			_, err = conn.Create(tmpPath, value, zk.FlagEphemeral, z.nodeACL())
+			z.observe(OpCreate, start, err)
 			if err == zk.ErrNodeExists {
 				return err
 			}
+			if err == nil {
+				z.trackTempNode(basePath, value)
+			}
 		} else {
-			_, err = conn.Create(tmpPath, []byte{}, 0, zk.WorldACL(zk.PermAll))
+			_, err = conn.Create(tmpPath, []byte{}, 0, z.nodeACL())
+			z.observe(OpCreate, start, err)
 		}
 		if err != nil {
 			if err == zk.ErrNodeExists {
@@ -467,10 +670,22 @@ func (z *ZookeeperClient) CreateTempWithValue(basePath string, value []byte) err
 
 // nolint
 func (z *ZookeeperClient) Delete(basePath string) error {
-	err := errNilZkClientConn
+	return z.delete(context.Background(), basePath)
+}
+
+func (z *ZookeeperClient) delete(ctx context.Context, basePath string) error {
+	err := ErrNotConnected
 	conn := z.getConn()
 	if conn != nil {
-		err = conn.Delete(basePath, -1)
+		err = call(ctx, func() error {
+			start := time.Now()
+			err := conn.Delete(z.realPath(basePath), -1)
+			z.observe(OpDelete, start, err)
+			return err
+		})
+	}
+	if err == nil || err == zk.ErrNoNode {
+		z.untrackTempNode(basePath)
 	}
 
 	return perrors.WithMessagef(err, "Delete(basePath:%s)", basePath)
@@ -478,23 +693,36 @@ func (z *ZookeeperClient) Delete(basePath string) error {
 
 // RegisterTemp registers temporary node by @basePath and @node
 func (z *ZookeeperClient) RegisterTemp(basePath string, node string) (string, error) {
+	return z.RegisterTempWithValue(basePath, node, []byte(""))
+}
+
+// RegisterTempWithValue registers the temporary node named @node under @basePath with @data as its content
+func (z *ZookeeperClient) RegisterTempWithValue(basePath string, node string, data []byte) (string, error) {
 	var (
 		err     error
 		zkPath  string
 		tmpPath string
 	)
 
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	zkPath = path.Join(basePath) + "/" + node
 	conn := z.getConn()
 	if conn != nil {
-		tmpPath, err = conn.Create(zkPath, []byte(""), zk.FlagEphemeral, zk.WorldACL(zk.PermAll))
+		start := time.Now()
+		_, err = conn.Create(z.realPath(zkPath), data, zk.FlagEphemeral, z.nodeACL())
+		z.observe(OpCreate, start, err)
+		if err == zk.ErrNodeExists && z.ownedBySession(conn, zkPath) {
+			// recovered after reconnecting, see recoverTempNodes
+			err = nil
+		}
+		tmpPath = zkPath
 	}
 
 	if err != nil {
 		logger.Warnf("conn.Create(\"%s\", zk.FlagEphemeral) = error(%v)", zkPath, perrors.WithStack(err))
 		return zkPath, perrors.WithStack(err)
 	}
+	z.trackTempNode(tmpPath, data)
 	logger.Debugf("zkClient{%s} create a temp zookeeper node:%s", z.name, tmpPath)
 
 	return tmpPath, nil
@@ -507,15 +735,18 @@ func (z *ZookeeperClient) RegisterTempSeq(basePath string, data []byte) (string,
 		tmpPath string
 	)
 
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	conn := z.getConn()
 	if conn != nil {
+		start := time.Now()
 		tmpPath, err = conn.Create(
-			path.Join(basePath)+"/",
+			z.realPath(path.Join(basePath))+"/",
 			data,
 			zk.FlagEphemeral|zk.FlagSequence,
-			zk.WorldACL(zk.PermAll),
+			z.nodeACL(),
 		)
+		z.observe(OpCreate, start, err)
+		tmpPath, _ = z.clientPath(tmpPath)
 	}
 
 	logger.Debugf("zookeeperClient.RegisterTempSeq(basePath{%s}) = tempPath{%s}", basePath, tmpPath)
@@ -529,7 +760,7 @@ func (z *ZookeeperClient) RegisterTempSeq(basePath string, data []byte) (string,
 	return tmpPath, nil
 }
 
-// GetChildrenW gets children watch by @path
+// GetChildrenW gets children watch by @path, the path of the event is in the ensemble, see ClientEvent
 func (z *ZookeeperClient) GetChildrenW(path string) ([]string, <-chan zk.Event, error) {
 	var (
 		err      error
@@ -538,10 +769,12 @@ func (z *ZookeeperClient) GetChildrenW(path string) ([]string, <-chan zk.Event,
 		watcher  *zk.Watcher
 	)
 
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	conn := z.getConn()
 	if conn != nil {
-		children, stat, watcher, err = conn.ChildrenW(path)
+		start := time.Now()
+		children, stat, watcher, err = conn.ChildrenW(z.realPath(path))
+		z.observe(OpChildren, start, err)
 	}
 
 	if err != nil {
@@ -566,16 +799,26 @@ func (z *ZookeeperClient) GetChildrenW(path string) ([]string, <-chan zk.Event,
 
 // GetChildren gets children by @path
 func (z *ZookeeperClient) GetChildren(path string) ([]string, error) {
+	return z.getChildren(context.Background(), path)
+}
+
+func (z *ZookeeperClient) getChildren(ctx context.Context, path string) ([]string, error) {
 	var (
 		err      error
 		children []string
 		stat     *zk.Stat
 	)
 
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	conn := z.getConn()
 	if conn != nil {
-		children, stat, err = conn.Children(path)
+		err = call(ctx, func() error {
+			var err error
+			start := time.Now()
+			children, stat, err = conn.Children(z.realPath(path))
+			z.observe(OpChildren, start, err)
+			return err
+		})
 	}
 
 	if err != nil {
@@ -595,7 +838,7 @@ func (z *ZookeeperClient) GetChildren(path string) ([]string, error) {
 	return children, nil
 }
 
-// ExistW to judge watch whether it exists or not by @zkPath
+// ExistW to judge watch whether it exists or not by @zkPath, the path of the event is in the ensemble, see ClientEvent
 func (z *ZookeeperClient) ExistW(zkPath string) (<-chan zk.Event, error) {
 	var (
 		exist   bool
@@ -603,10 +846,12 @@ func (z *ZookeeperClient) ExistW(zkPath string) (<-chan zk.Event, error) {
 		watcher *zk.Watcher
 	)
 
-	err = errNilZkClientConn
+	err = ErrNotConnected
 	conn := z.getConn()
 	if conn != nil {
-		exist, _, watcher, err = conn.ExistsW(zkPath)
+		start := time.Now()
+		exist, _, watcher, err = conn.ExistsW(z.realPath(zkPath))
+		z.observe(OpExists, start, err)
 	}
 
 	if err != nil {
@@ -623,12 +868,66 @@ func (z *ZookeeperClient) ExistW(zkPath string) (<-chan zk.Event, error) {
 
 // GetContent gets content by @zkPath
 func (z *ZookeeperClient) GetContent(zkPath string) ([]byte, *zk.Stat, error) {
-	return z.Conn.Get(zkPath)
+	return z.getContent(context.Background(), zkPath)
+}
+
+func (z *ZookeeperClient) getContent(ctx context.Context, zkPath string) ([]byte, *zk.Stat, error) {
+	var (
+		content []byte
+		stat    *zk.Stat
+	)
+	conn := z.getConn()
+	if conn == nil {
+		return nil, nil, ErrNotConnected
+	}
+	err := call(ctx, func() error {
+		var err error
+		start := time.Now()
+		content, stat, err = conn.Get(z.realPath(zkPath))
+		z.observe(OpGet, start, err)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, stat, nil
 }
 
 // nolint
 func (z *ZookeeperClient) SetContent(zkPath string, content []byte, version int32) (*zk.Stat, error) {
-	return z.Conn.Set(zkPath, content, version)
+	return z.setContent(context.Background(), zkPath, content, version)
+}
+
+func (z *ZookeeperClient) setContent(ctx context.Context, zkPath string, content []byte, version int32) (*zk.Stat, error) {
+	var stat *zk.Stat
+	conn := z.getConn()
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	err := call(ctx, func() error {
+		var err error
+		start := time.Now()
+		stat, err = conn.Set(z.realPath(zkPath), content, version)
+		z.observe(OpSet, start, err)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// detachConn takes the connection away from the client, the session of the connection is recorded,
+// so that the nodes left by the session can be recovered by the next connection
+func (z *ZookeeperClient) detachConn() *zk.Conn {
+	z.Lock()
+	defer z.Unlock()
+	conn := z.Conn
+	z.Conn = nil
+	if conn != nil && conn.SessionID() != 0 {
+		z.expiredSession = conn.SessionID()
+	}
+	return conn
 }
 
 // getConn gets zookeeper connection safely
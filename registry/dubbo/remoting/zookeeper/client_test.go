@@ -0,0 +1,151 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"testing"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+func TestAuthOptions(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	assert.Equal(t, zk.WorldACL(zk.PermAll), z.nodeACL())
+	assert.Nil(t, z.addAuth(nil))
+
+	options := &Options{}
+	WithDigestAuth("dubbo", "secret")(options)
+	z.applyOptions(options)
+	assert.Equal(t, DigestScheme, z.authScheme)
+	assert.Equal(t, []byte("dubbo:secret"), z.authData)
+	assert.Equal(t, zk.DigestACL(zk.PermAll, "dubbo", "secret"), z.nodeACL())
+
+	// the explicit acl is kept regardless of the order of the options
+	acl := append(zk.DigestACL(zk.PermAll, "dubbo", "secret"), zk.WorldACL(zk.PermRead)...)
+	for _, opts := range [][]Option{
+		{WithACL(acl), WithDigestAuth("dubbo", "secret")},
+		{WithDigestAuth("dubbo", "secret"), WithACL(acl)},
+	} {
+		options = &Options{}
+		for _, opt := range opts {
+			opt(options)
+		}
+		z.applyOptions(options)
+		assert.Equal(t, acl, z.nodeACL())
+	}
+}
+
+func TestTempNodesOptions(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	assert.Empty(t, z.TempNodes())
+
+	z.trackTempNode("/dubbo/a", []byte("a"))
+	z.trackTempNode("/dubbo/b", []byte("b"))
+	z.untrackTempNode("/dubbo/b")
+	nodes := z.TempNodes()
+	assert.Equal(t, map[string][]byte{"/dubbo/a": []byte("a")}, nodes)
+	// the returned nodes are a copy
+	delete(nodes, "/dubbo/a")
+	assert.Len(t, z.TempNodes(), 1)
+
+	// the nodes and listeners are carried over to a new client
+	listener := &mockDataListener{}
+	options := &Options{}
+	WithTempNodes(z.TempNodes())(options)
+	WithTempNodeListener(listener)(options)
+	restarted := &ZookeeperClient{}
+	restarted.applyOptions(options)
+	assert.Equal(t, z.TempNodes(), restarted.TempNodes())
+	assert.Len(t, restarted.TempNodeListeners(), 1)
+}
+
+type mockDataListener struct{}
+
+func (l *mockDataListener) DataChange(remoting.Event) bool {
+	return true
+}
+
+func TestCodecOptions(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	assert.Equal(t, remoting.CodecDubbo, z.Codec().Name())
+
+	codec, err := remoting.GetCodec(remoting.CodecJSON)
+	assert.Nil(t, err)
+	options := &Options{}
+	WithCodec(codec)(options)
+	z.applyOptions(options)
+	assert.Equal(t, remoting.CodecJSON, z.Codec().Name())
+}
+
+func TestNotConnected(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	_, _, err := z.GetContent("/dubbo/a")
+	assert.True(t, IsNotConnected(err))
+	_, err = z.SetContent("/dubbo/a", []byte("a"), -1)
+	assert.True(t, IsNotConnected(err))
+
+	// the errors other than the conflicts are not retried
+	updates := 0
+	_, err = z.UpdateContent("/dubbo/a", func(content []byte) ([]byte, error) {
+		updates++
+		return []byte("a"), nil
+	}, DefaultUpdateRetries)
+	assert.True(t, IsNotConnected(err))
+	assert.Equal(t, 0, updates)
+	assert.False(t, IsNotConnected(errNilNode))
+}
+
+func TestChrootOptions(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	assert.Equal(t, "", z.Chroot())
+	assert.Equal(t, "/dubbo", z.realPath("/dubbo"))
+
+	options := &Options{}
+	WithChroot("test/", "gray")(options)
+	z.applyOptions(options)
+	assert.Equal(t, "/test/gray", z.Chroot())
+	assert.Equal(t, "/test/gray", z.realPath("/"))
+	assert.Equal(t, "/test/gray/dubbo", z.realPath("/dubbo"))
+	for real, expected := range map[string]string{
+		"/test/gray":        "/",
+		"/test/gray/dubbo":  "/dubbo",
+		"/test/grayscale/a": "",
+		"/dubbo":            "",
+	} {
+		p, ok := z.clientPath(real)
+		assert.Equal(t, expected != "", ok, real)
+		if ok {
+			assert.Equal(t, expected, p)
+		}
+	}
+
+	event := z.ClientEvent(zk.Event{Type: zk.EventNodeChildrenChanged, Path: "/test/gray/dubbo"})
+	assert.Equal(t, "/dubbo", event.Path)
+	assert.Equal(t, zk.EventNodeChildrenChanged, event.Type)
+
+	// the root is empty
+	WithChroot("/")(options)
+	assert.Equal(t, "", options.chroot)
+}
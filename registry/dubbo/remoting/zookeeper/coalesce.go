@@ -0,0 +1,146 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"sync"
+	"time"
+
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+// eventCoalescer delays the node change events of the registered paths by their windows, the events of
+// a path arriving within its window are merged, so a burst of changes notifies the listeners only once
+type eventCoalescer struct {
+	lock    sync.Mutex
+	window  time.Duration            // the window of the paths without their own windows, 0 to disable
+	windows map[string]time.Duration // path -> window
+	pending map[string]*time.Timer   // path -> timer of the merged event
+}
+
+func newEventCoalescer(window time.Duration, windows map[string]time.Duration) *eventCoalescer {
+	c := &eventCoalescer{
+		window:  window,
+		windows: make(map[string]time.Duration, len(windows)),
+		pending: make(map[string]*time.Timer),
+	}
+	for p, w := range windows {
+		c.windows[p] = w
+	}
+	return c
+}
+
+// WithEventCoalesceWindow merges the node change events of the registered paths within the window,
+// the window applies to @paths if they are given, otherwise to all the paths without their own windows.
+// The events are not merged if the window is 0, which is the default.
+func WithEventCoalesceWindow(window time.Duration, paths ...string) Option {
+	return func(opt *Options) {
+		if len(paths) == 0 {
+			opt.coalesceWindow = window
+			return
+		}
+		if opt.coalesceWindows == nil {
+			opt.coalesceWindows = make(map[string]time.Duration)
+		}
+		for _, p := range paths {
+			opt.coalesceWindows[p] = window
+		}
+	}
+}
+
+// withEventCoalescer carries the windows of a closed client over to the new one
+func withEventCoalescer(c *eventCoalescer) Option {
+	return func(opt *Options) {
+		if c == nil {
+			return
+		}
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		opt.coalesceWindow = c.window
+		opt.coalesceWindows = make(map[string]time.Duration, len(c.windows))
+		for p, w := range c.windows {
+			opt.coalesceWindows[p] = w
+		}
+	}
+}
+
+// SetEventCoalesceWindow sets the window of merging the node change events of the path, 0 to disable
+func (z *ZookeeperClient) SetEventCoalesceWindow(zkPath string, window time.Duration) {
+	z.coalescer.lock.Lock()
+	defer z.coalescer.lock.Unlock()
+	z.coalescer.windows[zkPath] = window
+}
+
+// notifyEvent notifies the listeners of the registered path, or merges the event into the pending one
+// if the path has a window
+func (z *ZookeeperClient) notifyEvent(zkPath string) {
+	c := z.coalescer
+	if c == nil {
+		z.sendEvent(zkPath)
+		return
+	}
+	c.lock.Lock()
+	window, ok := c.windows[zkPath]
+	if !ok {
+		window = c.window
+	}
+	if window <= 0 {
+		c.lock.Unlock()
+		z.sendEvent(zkPath)
+		return
+	}
+	if _, ok = c.pending[zkPath]; ok {
+		c.lock.Unlock()
+		logger.Debugf("zkClient{%s} merge the event of path{%s}", z.name, zkPath)
+		return
+	}
+	c.pending[zkPath] = time.AfterFunc(window, func() {
+		c.lock.Lock()
+		delete(c.pending, zkPath)
+		c.lock.Unlock()
+		select {
+		case <-z.exit:
+			return
+		default:
+		}
+		z.sendEvent(zkPath)
+	})
+	c.lock.Unlock()
+}
+
+// stopCoalescing drops the pending events
+func (z *ZookeeperClient) stopCoalescing() {
+	c := z.coalescer
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for p, timer := range c.pending {
+		timer.Stop()
+		delete(c.pending, p)
+	}
+}
+
+func (z *ZookeeperClient) sendEvent(zkPath string) {
+	z.eventRegistryLock.RLock()
+	defer z.eventRegistryLock.RUnlock()
+	for _, e := range z.eventRegistry[zkPath] {
+		*e <- struct{}{}
+	}
+}
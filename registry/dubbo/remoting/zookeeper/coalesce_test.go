@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCoalescingClient(opts ...Option) (*ZookeeperClient, chan struct{}) {
+	options := &Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	z := &ZookeeperClient{exit: make(chan struct{}), eventRegistry: make(map[string][]*chan struct{})}
+	z.applyOptions(options)
+	event := make(chan struct{}, 8)
+	z.RegisterEvent("/dubbo/a", &event)
+	return z, event
+}
+
+func TestEventCoalescing(t *testing.T) {
+	z, event := newCoalescingClient(WithEventCoalesceWindow(50 * time.Millisecond))
+	for i := 0; i < 5; i++ {
+		z.notifyEvent("/dubbo/a")
+	}
+	assert.Len(t, event, 0)
+	time.Sleep(150 * time.Millisecond)
+	assert.Len(t, event, 1)
+
+	// the path with its own window of 0 is notified at once
+	z.SetEventCoalesceWindow("/dubbo/a", 0)
+	z.notifyEvent("/dubbo/a")
+	assert.Len(t, event, 2)
+}
+
+func TestEventCoalescingStop(t *testing.T) {
+	z, event := newCoalescingClient(WithEventCoalesceWindow(50*time.Millisecond, "/dubbo/a"))
+	z.notifyEvent("/dubbo/a")
+	z.stopCoalescing()
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, event, 0)
+
+	// the windows are carried over to a new client
+	options := &Options{}
+	withEventCoalescer(z.coalescer)(options)
+	assert.Equal(t, time.Duration(0), options.coalesceWindow)
+	assert.Equal(t, map[string]time.Duration{"/dubbo/a": 50 * time.Millisecond}, options.coalesceWindows)
+}
@@ -0,0 +1,68 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+// WithCodec sets the codec of the urls stored as the contents of the nodes, the dubbo url string by default
+func WithCodec(codec remoting.Codec) Option {
+	return func(opt *Options) {
+		opt.codec = codec
+	}
+}
+
+// Codec returns the codec of the urls stored as the contents of the nodes
+func (z *ZookeeperClient) Codec() remoting.Codec {
+	return z.codec
+}
+
+// CreateWithURL creates the node recursively with the url encoded by the codec as its content
+func (z *ZookeeperClient) CreateWithURL(zkPath string, url *common.URL) error {
+	data, err := z.codec.Encode(url)
+	if err != nil {
+		return perrors.WithMessagef(err, "encode url by codec %s", z.codec.Name())
+	}
+	return z.CreateWithValue(zkPath, data)
+}
+
+// CreateTempWithURL creates the ephemeral node recursively with the url encoded by the codec as its content
+func (z *ZookeeperClient) CreateTempWithURL(zkPath string, url *common.URL) error {
+	data, err := z.codec.Encode(url)
+	if err != nil {
+		return perrors.WithMessagef(err, "encode url by codec %s", z.codec.Name())
+	}
+	return z.CreateTempWithValue(zkPath, data)
+}
+
+// GetURL gets the content of the node and decodes it as an url by the codec
+func (z *ZookeeperClient) GetURL(zkPath string) (*common.URL, *zk.Stat, error) {
+	content, stat, err := z.GetContent(zkPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	url, err := z.codec.Decode(content)
+	if err != nil {
+		return nil, stat, perrors.WithMessagef(err, "decode the content of %s by codec %s", zkPath, z.codec.Name())
+	}
+	return url, stat, nil
+}
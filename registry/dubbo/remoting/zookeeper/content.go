@@ -0,0 +1,96 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+// DefaultUpdateRetries is the count of retrying UpdateContent on the conflicts by default
+const DefaultUpdateRetries = 3
+
+// UpdateFunc returns the new content of the node from its current content,
+// the current content is nil if the node doesn't exist
+type UpdateFunc func(content []byte) ([]byte, error)
+
+// UpdateContent reads the content of the node and sets the content returned by update only if the node is not
+// changed in between, which is retried at most @retries times on the conflicts. The absent node is created
+// with its parents, so the content of update(nil) is set.
+func (z *ZookeeperClient) UpdateContent(zkPath string, update UpdateFunc, retries int) (*zk.Stat, error) {
+	return z.UpdateContentContext(context.Background(), zkPath, update, retries)
+}
+
+// UpdateContentContext is UpdateContent honoring the cancellation and the deadline of the ctx
+func (z *ZookeeperClient) UpdateContentContext(ctx context.Context, zkPath string, update UpdateFunc, retries int) (*zk.Stat, error) {
+	var err error
+	for i := 0; i <= retries; i++ {
+		var stat *zk.Stat
+		if stat, err = z.updateContent(ctx, zkPath, update); err == nil {
+			return stat, nil
+		}
+		switch perrors.Cause(err) {
+		case zk.ErrBadVersion, zk.ErrNodeExists, zk.ErrNoNode:
+			logger.Debugf("zkClient{%s} update the content of %s conflicts = error{%v}, retry", z.name, zkPath, err)
+		default:
+			return nil, err
+		}
+	}
+	return nil, perrors.WithMessagef(err, "update the content of %s after %d retries", zkPath, retries)
+}
+
+func (z *ZookeeperClient) updateContent(ctx context.Context, zkPath string, update UpdateFunc) (*zk.Stat, error) {
+	content, stat, err := z.getContent(ctx, zkPath)
+	if err != nil && err != zk.ErrNoNode {
+		return nil, perrors.WithMessagef(err, "zk.Get(path:%s)", zkPath)
+	}
+	exist := err == nil
+	data, err := update(content)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "update the content of %s", zkPath)
+	}
+	if exist {
+		version := stat.Version
+		stat, err = z.setContent(ctx, zkPath, data, version)
+		return stat, perrors.WithMessagef(err, "zk.Set(path:%s, version:%d)", zkPath, version)
+	}
+
+	if err = z.createWithValue(ctx, path.Dir(zkPath), []byte("")); err != nil {
+		return nil, err
+	}
+	conn := z.getConn()
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	err = call(ctx, func() error {
+		start := time.Now()
+		_, err := conn.Create(z.realPath(zkPath), data, 0, z.nodeACL())
+		z.observe(OpCreate, start, err)
+		return err
+	})
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "zk.Create(path:%s)", zkPath)
+	}
+	_, stat, err = z.getContent(ctx, zkPath)
+	return stat, perrors.WithMessagef(err, "zk.Get(path:%s)", zkPath)
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+)
+
+// call runs the zookeeper operation and returns its error, or the error of the ctx once the ctx is done
+// before the operation returns. The abandoned operation is left to finish in the background, as the
+// requests of zookeeper can't be cancelled, and its results are discarded.
+func call(ctx context.Context, op func() error) error {
+	if ctx.Done() == nil {
+		return op()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- op()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CreateContext is Create honoring the cancellation and the deadline of the ctx
+func (z *ZookeeperClient) CreateContext(ctx context.Context, basePath string) error {
+	return z.createWithValue(ctx, basePath, []byte(""))
+}
+
+// CreateWithValueContext is CreateWithValue honoring the cancellation and the deadline of the ctx,
+// the parent nodes created before the ctx is done are kept
+func (z *ZookeeperClient) CreateWithValueContext(ctx context.Context, basePath string, value []byte) error {
+	return z.createWithValue(ctx, basePath, value)
+}
+
+// DeleteContext is Delete honoring the cancellation and the deadline of the ctx
+func (z *ZookeeperClient) DeleteContext(ctx context.Context, basePath string) error {
+	return z.delete(ctx, basePath)
+}
+
+// GetChildrenContext is GetChildren honoring the cancellation and the deadline of the ctx
+func (z *ZookeeperClient) GetChildrenContext(ctx context.Context, path string) ([]string, error) {
+	return z.getChildren(ctx, path)
+}
+
+// GetContentContext is GetContent honoring the cancellation and the deadline of the ctx
+func (z *ZookeeperClient) GetContentContext(ctx context.Context, zkPath string) ([]byte, *zk.Stat, error) {
+	return z.getContent(ctx, zkPath)
+}
+
+// SetContentContext is SetContent honoring the cancellation and the deadline of the ctx
+func (z *ZookeeperClient) SetContentContext(ctx context.Context, zkPath string, content []byte, version int32) (*zk.Stat, error) {
+	return z.setContent(ctx, zkPath, content, version)
+}
+
+// HealthCheck checks the existence of the root node, which is a lightweight round trip to zookeeper,
+// and returns its latency
+func (z *ZookeeperClient) HealthCheck(ctx context.Context) (time.Duration, error) {
+	conn := z.getConn()
+	if conn == nil {
+		return 0, ErrNotConnected
+	}
+	start := time.Now()
+	err := call(ctx, func() error {
+		_, _, err := conn.Exists("/")
+		z.observe(OpExists, start, err)
+		return err
+	})
+	return time.Since(start), err
+}
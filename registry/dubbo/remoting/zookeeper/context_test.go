@@ -0,0 +1,72 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall(t *testing.T) {
+	errOp := perrors.New("op")
+	assert.Equal(t, errOp, call(context.Background(), func() error { return errOp }))
+
+	// the wedged operation is abandoned once the deadline exceeds
+	block := make(chan struct{})
+	defer close(block)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := call(ctx, func() error {
+		<-block
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// the operation doesn't run if the ctx is done already
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	ran := false
+	err = call(ctx, func() error {
+		ran = true
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, ran)
+}
+
+func TestContextOperationsWithoutConn(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	ctx := context.Background()
+	assert.Equal(t, ErrNotConnected, perrors.Cause(z.CreateContext(ctx, "/dubbo/a")))
+	assert.Equal(t, ErrNotConnected, perrors.Cause(z.DeleteContext(ctx, "/dubbo/a")))
+	_, err := z.GetChildrenContext(ctx, "/dubbo")
+	assert.Equal(t, ErrNotConnected, perrors.Cause(err))
+	_, _, err = z.GetContentContext(ctx, "/dubbo/a")
+	assert.Equal(t, ErrNotConnected, err)
+	_, err = z.SetContentContext(ctx, "/dubbo/a", []byte("a"), -1)
+	assert.Equal(t, ErrNotConnected, err)
+	_, err = z.HealthCheck(ctx)
+	assert.Equal(t, ErrNotConnected, err)
+}
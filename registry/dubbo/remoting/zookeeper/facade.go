@@ -42,6 +42,7 @@ func HandleClientRestart(r ZkClientFacade) {
 		err error
 
 		failTimes int
+		policy    BackoffPolicy
 	)
 
 LOOP:
@@ -54,9 +55,19 @@ LOOP:
 			// re-register all services
 		case <-r.ZkClient().Done():
 			r.ZkClientLock().Lock()
+			lastClient := r.ZkClient()
+			metrics := r.ZkClient().metrics
+			r.ZkClient().setConnState(ConnStateReconnecting)
 			r.ZkClient().Close()
 			zkName := r.ZkClient().name
 			zkAddress := r.ZkClient().ZkAddrs
+			policy = r.ZkClient().BackoffPolicy()
+			tempNodes := r.ZkClient().TempNodes()
+			tempNodeListeners := r.ZkClient().TempNodeListeners()
+			expiredSession := r.ZkClient().expiredSessionID()
+			codec := r.ZkClient().Codec()
+			coalescer := r.ZkClient().coalescer
+			chroot := r.ZkClient().Chroot()
 			r.SetZkClient(nil)
 			r.ZkClientLock().Unlock()
 			r.WaitGroup().Done() // dec the wg when zk client is closed
@@ -68,20 +79,49 @@ LOOP:
 				case <-r.Done():
 					r.WaitGroup().Done() // dec the wg when registry is closed
 					logger.Warnf("(ZkProviderRegistry)reconnectZkRegistry goroutine exit now...")
+					metrics.setState(zkName, ConnStateClosed)
 					break LOOP
-				case <-getty.GetTimeWheel().After(timeSecondDuration(failTimes * ConnDelay)): // Prevent crazy reconnection zk.
+				case <-getty.GetTimeWheel().After(policy.Delay(failTimes)): // Prevent crazy reconnection zk.
 				}
-				err = ValidateZookeeperClient(r, WithZkName(zkName))
+				opts := []Option{WithZkName(zkName), WithBackoffPolicy(policy), WithTempNodes(tempNodes), withMetrics(metrics), WithCodec(codec),
+					withEventCoalescer(coalescer), WithChroot(chroot)}
+				for _, listener := range tempNodeListeners {
+					opts = append(opts, WithTempNodeListener(listener))
+				}
+				opts = append(opts, withExpiredSession(expiredSession))
+				err = ValidateZookeeperClient(r, opts...)
 				logger.Infof("ZkProviderRegistry.validateZookeeperClient(zkAddr{%s}) = error{%#v}",
 					zkAddress, perrors.WithStack(err))
 				if err == nil && r.RestartCallBack() {
 					break
 				}
+				if err == nil {
+					err = perrors.New("restart callback failed")
+				}
 				failTimes++
-				if MaxFailTimes <= failTimes {
-					failTimes = MaxFailTimes
+				if policy.GiveUp(failTimes) {
+					logger.Errorf("(ZkProviderRegistry)reconnectZkRegistry gives up after %d attempts, error{%v}", failTimes, err)
+					giveUp(r, lastClient)
+					metrics.setState(zkName, ConnStateClosed)
+					if policy.OnGiveUp != nil {
+						policy.OnGiveUp(err)
+					}
+					break LOOP
 				}
 			}
 		}
 	}
 }
+
+// giveUp puts the closed client back into the facade instead of nil, so that the
+// operations of the facade fail with ErrNotConnected rather than panic
+func giveUp(r ZkClientFacade, closed *ZookeeperClient) {
+	r.ZkClientLock().Lock()
+	defer r.ZkClientLock().Unlock()
+	if client := r.ZkClient(); client != nil {
+		// the client is connected by the last attempt, but the restart callback is failed
+		client.Close()
+		r.WaitGroup().Done() // dec the wg when zk client is closed
+	}
+	r.SetZkClient(closed)
+}
@@ -0,0 +1,90 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"path"
+
+	perrors "github.com/pkg/errors"
+)
+
+// ErrNoLeader is returned by LeaderElection.Leader if there is no participant
+var ErrNoLeader = perrors.New("zookeeper election has no leader")
+
+// LeaderElection elects the leader among the participants, the same as the LeaderLatch of curator.
+// The participant with the earliest ephemeral node is the leader, so the leadership is lost once the
+// connection is suspended or lost, as the session may expire then.
+type LeaderElection struct {
+	contender
+}
+
+// NewLeaderElection returns a participant identified by @id of the election on @electionPath
+func NewLeaderElection(client *ZookeeperClient, electionPath string, id string) *LeaderElection {
+	return newLeaderElection(client, electionPath, id)
+}
+
+func newLeaderElection(client recipeClient, electionPath string, id string) *LeaderElection {
+	return &LeaderElection{contender: contender{client: client, path: electionPath, name: "latch-", data: []byte(id)}}
+}
+
+// Campaign blocks until the participant is the leader or the ctx is done, the returned channel is closed
+// once the leadership is resigned or lost. Resign must be called even if the leadership is lost.
+func (e *LeaderElection) Campaign(ctx context.Context) (<-chan struct{}, error) {
+	return e.acquire(ctx)
+}
+
+// Resign gives up the leadership
+func (e *LeaderElection) Resign() error {
+	return e.release()
+}
+
+// IsLeader returns whether the participant is the leader, it is false once the leadership is lost
+func (e *LeaderElection) IsLeader() bool {
+	return e.held()
+}
+
+// Leader returns the id of the leader, which may be campaigning only while the election is in progress
+func (e *LeaderElection) Leader() (string, error) {
+	participants, err := e.Participants()
+	if err != nil {
+		return "", err
+	}
+	if len(participants) == 0 {
+		return "", ErrNoLeader
+	}
+	return participants[0], nil
+}
+
+// Participants returns the ids of the participants in the order of the leadership
+func (e *LeaderElection) Participants() ([]string, error) {
+	nodes, err := e.nodes()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		data, err := e.client.recipeData(path.Join(e.path, node))
+		if err != nil {
+			// left just now
+			continue
+		}
+		ids = append(ids, string(data))
+	}
+	return ids, nil
+}
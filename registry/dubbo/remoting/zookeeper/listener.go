@@ -19,6 +19,7 @@ package zookeeper
 
 import (
 	"path"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -57,6 +58,18 @@ func (l *ZkEventListener) SetClient(client *ZookeeperClient) {
 	l.client = client
 }
 
+// ListenedPaths returns the sorted dubbo paths being listened
+func (l *ZkEventListener) ListenedPaths() []string {
+	l.pathMapLock.Lock()
+	defer l.pathMapLock.Unlock()
+	paths := make([]string, 0, len(l.pathMap))
+	for p := range l.pathMap {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // ListenServiceNodeEvent listen a path node event
 func (l *ZkEventListener) ListenServiceNodeEvent(zkPath string, listener remoting.DataListener) {
 	// listen l service node
@@ -82,13 +95,14 @@ func (l *ZkEventListener) listenServiceNodeEvent(zkPath string, listener ...remo
 
 		select {
 		case zkEvent = <-keyEventCh:
+			zkEvent = l.client.ClientEvent(zkEvent)
 			logger.Warnf("get a zookeeper zkEvent{type:%s, server:%s, path:%s, state:%d-%s, err:%s}",
 				zkEvent.Type.String(), zkEvent.Server, zkEvent.Path, zkEvent.State, StateToString(zkEvent.State), zkEvent.Err)
 			switch zkEvent.Type {
 			case zk.EventNodeDataChanged:
 				logger.Warnf("zk.ExistW(key{%s}) = event{EventNodeDataChanged}", zkPath)
 				if len(listener) > 0 {
-					content, _, err := l.client.Conn.Get(zkEvent.Path)
+					content, _, err := l.client.GetContent(zkEvent.Path)
 					if err != nil {
 						logger.Warnf("zk.Conn.Get{key:%s} = error{%v}", zkPath, err)
 						return false
@@ -98,7 +112,7 @@ func (l *ZkEventListener) listenServiceNodeEvent(zkPath string, listener ...remo
 			case zk.EventNodeCreated:
 				logger.Warnf("zk.ExistW(key{%s}) = event{EventNodeCreated}", zkPath)
 				if len(listener) > 0 {
-					content, _, err := l.client.Conn.Get(zkEvent.Path)
+					content, _, err := l.client.GetContent(zkEvent.Path)
 					if err != nil {
 						logger.Warnf("zk.Conn.Get{key:%s} = error{%v}", zkPath, err)
 						return false
@@ -130,7 +144,7 @@ func (l *ZkEventListener) handleZkNodeEvent(zkPath string, children []string, li
 	newChildren, err := l.client.GetChildren(zkPath)
 	if err != nil {
 		if err == errNilChildren {
-			content, _, err := l.client.Conn.Get(zkPath)
+			content, _, err := l.client.GetContent(zkPath)
 			if err != nil {
 				logger.Errorf("Get new node path {%v} 's content error,message is  {%v}", zkPath, perrors.WithStack(err))
 			} else {
@@ -153,7 +167,7 @@ func (l *ZkEventListener) handleZkNodeEvent(zkPath string, children []string, li
 
 		newNode = path.Join(zkPath, n)
 		logger.Infof("add zkNode{%s}", newNode)
-		content, _, err := l.client.Conn.Get(newNode)
+		content, _, err := l.client.GetContent(newNode)
 		if err != nil {
 			logger.Errorf("Get new node path {%v} 's content error,message is  {%v}", newNode, perrors.WithStack(err))
 		}
@@ -256,7 +270,7 @@ func (l *ZkEventListener) listenDirEvent(conf *common.URL, zkPath string, listen
 			// Only need to compare Path when subscribing to provider
 			if strings.LastIndex(zkPath, constant.PROVIDER_CATEGORY) != -1 {
 				provider, _ := common.NewURL(c)
-				if provider.ServiceKey() != conf.ServiceKey() {
+				if !conf.MatchService(&provider) {
 					continue
 				}
 			}
@@ -277,13 +291,10 @@ func (l *ZkEventListener) listenDirEvent(conf *common.URL, zkPath string, listen
 			l.pathMap[dubboPath] = struct{}{}
 			l.pathMapLock.Unlock()
 			// When Zk disconnected, the Conn will be set to nil, so here need check the value of Conn
-			l.client.RLock()
-			if l.client.Conn == nil {
-				l.client.RUnlock()
+			content, _, err := l.client.GetContent(dubboPath)
+			if IsNotConnected(err) {
 				break
 			}
-			content, _, err := l.client.Conn.Get(dubboPath)
-			l.client.RUnlock()
 			if err != nil {
 				logger.Errorf("Get new node path {%v} 's content error,message is  {%v}", dubboPath, perrors.WithStack(err))
 			}
@@ -323,6 +334,7 @@ func (l *ZkEventListener) listenDirEvent(conf *common.URL, zkPath string, listen
 			case <-ticker.C:
 				l.handleZkNodeEvent(zkEvent.Path, children, listener)
 			case zkEvent = <-childEventCh:
+				zkEvent = l.client.ClientEvent(zkEvent)
 				logger.Warnf("get a zookeeper zkEvent{type:%s, server:%s, path:%s, state:%d-%s, err:%s}",
 					zkEvent.Type.String(), zkEvent.Server, zkEvent.Path, zkEvent.State, StateToString(zkEvent.State), zkEvent.Err)
 				ticker.Stop()
@@ -358,6 +370,72 @@ func (l *ZkEventListener) ListenServiceEvent(conf *common.URL, zkPath string, li
 	}(zkPath, listener)
 }
 
+// ListenServicesEvent listens the providers of all the services under @root for the wildcard
+// subscription @conf, the services created later are listened as well
+func (l *ZkEventListener) ListenServicesEvent(conf *common.URL, root string, listener remoting.DataListener) {
+	logger.Infof("listen dubbo services under path{%s}", root)
+	l.wg.Add(1)
+	go func() {
+		l.listenServicesEvent(conf, root, listener)
+		logger.Warnf("listenServicesEvent(root{%s}) goroutine exit now", root)
+	}()
+}
+
+func (l *ZkEventListener) listenServicesEvent(conf *common.URL, root string, listener remoting.DataListener) {
+	defer l.wg.Done()
+
+	var failTimes int
+	listened := make(map[string]struct{})
+	for {
+		children, childEventCh, err := l.client.GetChildrenW(root)
+		if err != nil && err != errNilChildren {
+			failTimes++
+			if MaxFailTimes <= failTimes {
+				failTimes = MaxFailTimes
+			}
+			logger.Infof("listenServicesEvent(root{%s}) = error{%v}", root, err)
+			select {
+			case <-getty.GetTimeWheel().After(timeSecondDuration(failTimes * ConnDelay)):
+				continue
+			case <-l.client.Done():
+				return
+			}
+		}
+		failTimes = 0
+
+		current := make(map[string]struct{}, len(children))
+		for _, c := range children {
+			current[c] = struct{}{}
+			if _, ok := listened[c]; ok {
+				continue
+			}
+			listened[c] = struct{}{}
+			l.ListenServiceEvent(conf, path.Join(root, c, constant.DEFAULT_CATEGORY), listener)
+		}
+		// the removed service is listened again once it is created
+		for c := range listened {
+			if _, ok := current[c]; !ok {
+				delete(listened, c)
+			}
+		}
+
+		if childEventCh == nil {
+			// the root has no children, so there is no watcher, check it later
+			select {
+			case <-getty.GetTimeWheel().After(timeSecondDuration(ConnDelay)):
+			case <-l.client.Done():
+				return
+			}
+			continue
+		}
+		select {
+		case <-childEventCh:
+		case <-l.client.Done():
+			return
+		}
+	}
+}
+
 func (l *ZkEventListener) valid() bool {
 	return l.client.ZkConnValid()
 }
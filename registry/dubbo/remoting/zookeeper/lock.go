@@ -0,0 +1,55 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+)
+
+// Lock is a distributed lock, the same as the InterProcessMutex of curator except that it is not reentrant.
+// The lock is held by an ephemeral node, so it is lost once the connection is suspended or lost, as the
+// session may expire then. A Lock is bound to a client, a new one should be
+// created with the new client after the facade restarts the client.
+type Lock struct {
+	contender
+}
+
+// NewLock returns a lock on @lockPath
+func NewLock(client *ZookeeperClient, lockPath string) *Lock {
+	return newLock(client, lockPath)
+}
+
+func newLock(client recipeClient, lockPath string) *Lock {
+	return &Lock{contender: contender{client: client, path: lockPath, name: "lock-"}}
+}
+
+// Lock blocks until the lock is acquired or the ctx is done, the returned channel is closed once the lock
+// is released or lost. Unlock must be called even if the lock is lost.
+func (l *Lock) Lock(ctx context.Context) (<-chan struct{}, error) {
+	return l.acquire(ctx)
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	return l.release()
+}
+
+// Held returns whether the lock is held, it is false once the lock is lost
+func (l *Lock) Held() bool {
+	return l.held()
+}
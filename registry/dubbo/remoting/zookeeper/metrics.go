@@ -0,0 +1,257 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"mosn.io/pkg/metrics"
+)
+
+// ConnState is the connection state of the zookeeper client
+type ConnState int32
+
+const (
+	// ConnStateConnecting means the client is connecting for the first time
+	ConnStateConnecting ConnState = iota
+	// ConnStateConnected means the client has a session
+	ConnStateConnected
+	// ConnStateReconnecting means the connection is lost and the client is reconnecting
+	ConnStateReconnecting
+	// ConnStateExpired means the session is expired, the ephemeral nodes and watches are lost
+	ConnStateExpired
+	// ConnStateClosed means the client is closed and will not reconnect
+	ConnStateClosed
+)
+
+var connStateNames = map[ConnState]string{
+	ConnStateConnecting:   "connecting",
+	ConnStateConnected:    "connected",
+	ConnStateReconnecting: "reconnecting",
+	ConnStateExpired:      "expired",
+	ConnStateClosed:       "closed",
+}
+
+func (s ConnState) String() string {
+	if name, ok := connStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// the operations observed by the metrics
+const (
+	OpCreate   = "create"
+	OpDelete   = "delete"
+	OpChildren = "children"
+	OpExists   = "exists"
+	OpGet      = "get"
+	OpSet      = "set"
+)
+
+// MetricsListener is notified of the connection state changes, the session events
+// and the operations of the zookeeper client, the methods must not block.
+// The metrics are always reported to the global sink of the metrics package as well.
+type MetricsListener interface {
+	OnStateChange(name string, state ConnState)
+	OnEvent(name string, event zk.Event)
+	OnOperation(name string, op string, latency time.Duration, err error)
+}
+
+// OperationStats is the statistics of an operation
+type OperationStats struct {
+	Count uint64
+	// Errors doesn't count zk.ErrNodeExists and zk.ErrNoNode, which are expected results
+	Errors       uint64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// ClientMetrics is a snapshot of the metrics of the zookeeper client
+type ClientMetrics struct {
+	State      ConnState
+	StateSince time.Time
+	Events     map[zk.EventType]uint64
+	Operations map[string]OperationStats
+}
+
+// clientMetrics is kept across the reconnections of a registry, see HandleClientRestart
+type clientMetrics struct {
+	sync.Mutex
+	state      ConnState
+	stateSince time.Time
+	events     map[zk.EventType]uint64
+	operations map[string]*OperationStats
+	listeners  []MetricsListener
+	// suspended is closed once the state leaves ConnStateConnected
+	suspended chan struct{}
+}
+
+func newClientMetrics() *clientMetrics {
+	suspended := make(chan struct{})
+	close(suspended)
+	return &clientMetrics{
+		state:      ConnStateConnecting,
+		stateSince: time.Now(),
+		events:     make(map[zk.EventType]uint64),
+		operations: make(map[string]*OperationStats),
+		listeners:  []MetricsListener{sinkMetricsListener{}},
+		suspended:  suspended,
+	}
+}
+
+// WithMetricsListener adds a listener of the metrics of the client
+func WithMetricsListener(listener MetricsListener) Option {
+	return func(opt *Options) {
+		opt.metricsListeners = append(opt.metricsListeners, listener)
+	}
+}
+
+// withMetrics carries the metrics over to the reconnected client
+func withMetrics(m *clientMetrics) Option {
+	return func(opt *Options) {
+		opt.metrics = m
+	}
+}
+
+// AddMetricsListener adds a listener of the metrics of the client, it is kept after reconnecting
+func (z *ZookeeperClient) AddMetricsListener(listener MetricsListener) {
+	z.metrics.Lock()
+	defer z.metrics.Unlock()
+	z.metrics.listeners = append(z.metrics.listeners, listener)
+}
+
+// Metrics returns a snapshot of the metrics of the client
+func (z *ZookeeperClient) Metrics() ClientMetrics {
+	m := z.metrics
+	m.Lock()
+	defer m.Unlock()
+	snapshot := ClientMetrics{
+		State:      m.state,
+		StateSince: m.stateSince,
+		Events:     make(map[zk.EventType]uint64, len(m.events)),
+		Operations: make(map[string]OperationStats, len(m.operations)),
+	}
+	for t, count := range m.events {
+		snapshot.Events[t] = count
+	}
+	for op, stats := range m.operations {
+		snapshot.Operations[op] = *stats
+	}
+	return snapshot
+}
+
+// ConnState returns the connection state of the client
+func (z *ZookeeperClient) ConnState() ConnState {
+	z.metrics.Lock()
+	defer z.metrics.Unlock()
+	return z.metrics.state
+}
+
+func (z *ZookeeperClient) setConnState(state ConnState) {
+	z.metrics.setState(z.name, state)
+}
+
+func (m *clientMetrics) setState(name string, state ConnState) {
+	m.Lock()
+	if m.state == state {
+		m.Unlock()
+		return
+	}
+	if state == ConnStateConnected {
+		m.suspended = make(chan struct{})
+	} else if m.state == ConnStateConnected {
+		close(m.suspended)
+	}
+	m.state = state
+	m.stateSince = time.Now()
+	listeners := m.listeners
+	m.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnStateChange(name, state)
+	}
+}
+
+func (z *ZookeeperClient) observeEvent(event zk.Event) {
+	m := z.metrics
+	m.Lock()
+	m.events[event.Type]++
+	listeners := m.listeners
+	m.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnEvent(z.name, event)
+	}
+}
+
+// observe records the latency and the result of the operation started at @start
+func (z *ZookeeperClient) observe(op string, start time.Time, err error) {
+	latency := time.Since(start)
+	m := z.metrics
+	m.Lock()
+	stats, ok := m.operations[op]
+	if !ok {
+		stats = &OperationStats{}
+		m.operations[op] = stats
+	}
+	stats.Count++
+	if isOperationError(err) {
+		stats.Errors++
+	}
+	stats.TotalLatency += latency
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+	listeners := m.listeners
+	m.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnOperation(z.name, op, latency, err)
+	}
+}
+
+// isOperationError returns whether the operation fails, zk.ErrNodeExists and zk.ErrNoNode are expected results
+func isOperationError(err error) bool {
+	return err != nil && err != zk.ErrNodeExists && err != zk.ErrNoNode
+}
+
+// sinkMetricsListener reports the metrics of all the clients to the global sink of the metrics package, the
+// metrics of the client named name are the gauge zookeeper.{name}.state, the counters zookeeper.{name}.event.{type},
+// zookeeper.{name}.op.{op} and zookeeper.{name}.op.{op}.errors, and the histogram zookeeper.{name}.op.{op}.latency
+// in milliseconds
+type sinkMetricsListener struct{}
+
+func (sinkMetricsListener) OnStateChange(name string, state ConnState) {
+	metrics.SetGauge("zookeeper."+name+".state", float64(state))
+}
+
+func (sinkMetricsListener) OnEvent(name string, event zk.Event) {
+	metrics.IncrCounter("zookeeper."+name+".event."+event.Type.String(), 1)
+}
+
+func (sinkMetricsListener) OnOperation(name string, op string, latency time.Duration, err error) {
+	prefix := "zookeeper." + name + ".op." + op
+	metrics.IncrCounter(prefix, 1)
+	if isOperationError(err) {
+		metrics.IncrCounter(prefix+".errors", 1)
+	}
+	metrics.AddSample(prefix+".latency", float64(latency)/float64(time.Millisecond))
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/metrics"
+)
+
+type mockMetricsListener struct {
+	states []ConnState
+	events []zk.EventType
+	ops    []string
+}
+
+func (l *mockMetricsListener) OnStateChange(name string, state ConnState) {
+	l.states = append(l.states, state)
+}
+
+func (l *mockMetricsListener) OnEvent(name string, event zk.Event) {
+	l.events = append(l.events, event.Type)
+}
+
+func (l *mockMetricsListener) OnOperation(name string, op string, latency time.Duration, err error) {
+	l.ops = append(l.ops, op)
+}
+
+func TestClientMetrics(t *testing.T) {
+	listener := &mockMetricsListener{}
+	options := &Options{}
+	WithMetricsListener(listener)(options)
+	z := &ZookeeperClient{name: "zk"}
+	z.applyOptions(options)
+	assert.Equal(t, ConnStateConnecting, z.ConnState())
+
+	z.setConnState(ConnStateConnected)
+	z.setConnState(ConnStateConnected)
+	z.observeEvent(zk.Event{Type: zk.EventSession, State: zk.StateHasSession})
+	z.observe(OpCreate, time.Now(), nil)
+	z.observe(OpCreate, time.Now(), zk.ErrNodeExists)
+	z.observe(OpCreate, time.Now(), errors.New("connection loss"))
+
+	metrics := z.Metrics()
+	assert.Equal(t, ConnStateConnected, metrics.State)
+	assert.Equal(t, uint64(1), metrics.Events[zk.EventSession])
+	assert.Equal(t, uint64(3), metrics.Operations[OpCreate].Count)
+	assert.Equal(t, uint64(1), metrics.Operations[OpCreate].Errors)
+	assert.Equal(t, []ConnState{ConnStateConnected}, listener.states)
+	assert.Equal(t, []zk.EventType{zk.EventSession}, listener.events)
+	assert.Equal(t, []string{OpCreate, OpCreate, OpCreate}, listener.ops)
+
+	// the recipes are suspended once the connection is lost
+	suspended := z.recipeSuspended()
+	select {
+	case <-suspended:
+		t.Fatal("connected client is suspended")
+	default:
+	}
+
+	// the metrics and listeners are carried over to the reconnected client
+	z.setConnState(ConnStateReconnecting)
+	assertClosed(t, suspended)
+	restarted := &ZookeeperClient{name: "zk"}
+	restarted.applyOptions(&Options{metrics: z.metrics})
+	restarted.setConnState(ConnStateConnected)
+	assert.Equal(t, uint64(3), restarted.Metrics().Operations[OpCreate].Count)
+	assert.Equal(t, []ConnState{ConnStateConnected, ConnStateReconnecting, ConnStateConnected}, listener.states)
+	assert.Equal(t, "reconnecting", ConnStateReconnecting.String())
+}
+
+func TestClientMetricsSink(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
+
+	// the metrics are reported to the global sink by default
+	z := &ZookeeperClient{name: "zk"}
+	z.applyOptions(&Options{})
+	z.setConnState(ConnStateConnected)
+	z.observeEvent(zk.Event{Type: zk.EventSession, State: zk.StateHasSession})
+	z.observe(OpGet, time.Now(), zk.ErrNoNode)
+	z.observe(OpGet, time.Now(), errors.New("connection loss"))
+
+	snapshot := sink.Snapshot()
+	assert.Equal(t, float64(ConnStateConnected), snapshot.Gauges["zookeeper.zk.state"])
+	assert.Equal(t, int64(1), snapshot.Counters["zookeeper.zk.event.EventSession"])
+	assert.Equal(t, int64(2), snapshot.Counters["zookeeper.zk.op.get"])
+	assert.Equal(t, int64(1), snapshot.Counters["zookeeper.zk.op.get.errors"])
+	assert.Equal(t, uint64(2), snapshot.Histograms["zookeeper.zk.op.get.latency"].Count)
+}
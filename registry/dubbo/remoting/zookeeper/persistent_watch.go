@@ -0,0 +1,278 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+// WatchMode is the kind of the states watched by PersistentWatcher
+type WatchMode int
+
+const (
+	// WatchModeChildren watches the children of the path
+	WatchModeChildren WatchMode = 1 << iota
+	// WatchModeData watches the content of the path
+	WatchModeData
+)
+
+// WatchEvent is the state of the path watched by PersistentWatcher
+type WatchEvent struct {
+	Path string
+	// Type is either WatchModeChildren or WatchModeData, which tells the state carried by the event
+	Type   WatchMode
+	Exists bool
+	// Children are the sorted children of the path for the children events
+	Children []string
+	// Data is the content of the path for the data events
+	Data []byte
+	// Err is the error of watching the path, the path is watched again after ConnDelay seconds
+	Err error
+}
+
+type watchDataFunc func(path string) ([]byte, bool, <-chan zk.Event, error)
+
+// PersistentWatcher keeps watching the children and/or the content of a path, the one-shot zookeeper
+// watches are armed again every time they fire or fail. The current state is sent to EvtCh at first,
+// then only the changed states are sent, and a state not received yet is replaced by the newer one
+// of the same type, so a slow receiver gets the latest states instead of every intermediate one.
+type PersistentWatcher struct {
+	EvtCh <-chan WatchEvent
+
+	path          string
+	mode          WatchMode
+	watchChildren watchChildrenFunc
+	watchData     watchDataFunc
+	evtCh         chan WatchEvent
+	done          <-chan struct{}
+	stop          chan struct{}
+	once          sync.Once
+	wait          sync.WaitGroup
+	retry         time.Duration
+
+	last    map[WatchMode]WatchEvent // the latest state of each type, sent or pending
+	sent    map[WatchMode]WatchEvent // the latest state of each type received by the receiver
+	pending []WatchEvent
+	failed  WatchMode
+}
+
+// WatchPersistent watches the states of @path selected by @mode until the watcher or the client is closed,
+// EvtCh is closed then
+func (z *ZookeeperClient) WatchPersistent(path string, mode WatchMode) (*PersistentWatcher, error) {
+	if z.getConn() == nil {
+		return nil, ErrNotConnected
+	}
+	return newPersistentWatcher(path, mode, z.watchChildren, z.watchData, z.Done(), ConnDelay*time.Second), nil
+}
+
+// watchData watches the content of @path, or its creation if it does not exist
+func (z *ZookeeperClient) watchData(path string) ([]byte, bool, <-chan zk.Event, error) {
+	for {
+		conn := z.getConn()
+		if conn == nil {
+			return nil, false, nil, ErrNotConnected
+		}
+		start := time.Now()
+		data, _, watcher, err := conn.GetW(z.realPath(path))
+		z.observe(OpGet, start, err)
+		if err == nil {
+			if data == nil {
+				data = []byte{}
+			}
+			return data, true, watcher.EvtCh, nil
+		}
+		if err != zk.ErrNoNode {
+			return nil, false, nil, err
+		}
+		start = time.Now()
+		exist, _, watcher, err := conn.ExistsW(z.realPath(path))
+		z.observe(OpExists, start, err)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		if !exist {
+			return nil, false, watcher.EvtCh, nil
+		}
+		// created just now, get its content again
+	}
+}
+
+func newPersistentWatcher(path string, mode WatchMode, watchChildren watchChildrenFunc, watchData watchDataFunc,
+	done <-chan struct{}, retry time.Duration) *PersistentWatcher {
+	w := &PersistentWatcher{
+		path:          path,
+		mode:          mode,
+		watchChildren: watchChildren,
+		watchData:     watchData,
+		evtCh:         make(chan WatchEvent),
+		done:          done,
+		stop:          make(chan struct{}),
+		retry:         retry,
+		last:          make(map[WatchMode]WatchEvent),
+		sent:          make(map[WatchMode]WatchEvent),
+	}
+	w.EvtCh = w.evtCh
+	w.wait.Add(1)
+	go w.run()
+	return w
+}
+
+// Close stops watching and waits for the watcher goroutine to exit
+func (w *PersistentWatcher) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	w.wait.Wait()
+}
+
+func (w *PersistentWatcher) run() {
+	defer func() {
+		close(w.evtCh)
+		w.wait.Done()
+	}()
+
+	var (
+		childrenCh, dataCh <-chan zk.Event
+		timer              *time.Timer
+		retryCh            <-chan time.Time
+	)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	arm := func(mode WatchMode) {
+		ch := w.arm(mode)
+		if mode == WatchModeChildren {
+			childrenCh = ch
+		} else {
+			dataCh = ch
+		}
+	}
+	armAll := func(modes WatchMode) {
+		for _, mode := range []WatchMode{WatchModeChildren, WatchModeData} {
+			if modes&mode != 0 {
+				arm(mode)
+			}
+		}
+	}
+
+	armAll(w.mode)
+	for {
+		if timer == nil && w.failed != 0 {
+			timer = time.NewTimer(w.retry)
+			retryCh = timer.C
+		}
+		var (
+			sendCh chan WatchEvent
+			next   WatchEvent
+		)
+		if len(w.pending) > 0 {
+			sendCh, next = w.evtCh, w.pending[0]
+		}
+
+		select {
+		case <-w.done:
+			return
+		case <-w.stop:
+			return
+		case sendCh <- next:
+			w.sent[next.Type] = next
+			w.pending = w.pending[1:]
+		case <-retryCh:
+			timer, retryCh = nil, nil
+			failed := w.failed
+			w.failed = 0
+			armAll(failed)
+		case <-childrenCh:
+			// the zookeeper watches fire only once, so the path is watched again on any event
+			arm(WatchModeChildren)
+		case <-dataCh:
+			arm(WatchModeData)
+		}
+	}
+}
+
+// arm watches the state of the mode and queues it, it returns the channel of the watch
+func (w *PersistentWatcher) arm(mode WatchMode) <-chan zk.Event {
+	var (
+		event = WatchEvent{Path: w.path, Type: mode}
+		ch    <-chan zk.Event
+		err   error
+	)
+	if mode == WatchModeChildren {
+		event.Children, ch, err = w.watchChildren(w.path)
+		event.Exists = event.Children != nil
+		sort.Strings(event.Children)
+	} else {
+		event.Data, event.Exists, ch, err = w.watchData(w.path)
+	}
+	if err != nil {
+		logger.Warnf("watch path{%s} = error{%v}, retry after %s", w.path, err, w.retry)
+		w.failed |= mode
+		event = WatchEvent{Path: w.path, Type: mode, Err: err}
+		ch = nil
+	}
+	w.push(event)
+	return ch
+}
+
+// push queues the event unless the state is not changed, the pending event of the same type is replaced,
+// or dropped if the state is changed back to the one received
+func (w *PersistentWatcher) push(event WatchEvent) {
+	if last, ok := w.last[event.Type]; ok && sameState(last, event) {
+		return
+	}
+	w.last[event.Type] = event
+	for i := range w.pending {
+		if w.pending[i].Type != event.Type {
+			continue
+		}
+		if sent, ok := w.sent[event.Type]; ok && sameState(sent, event) {
+			w.pending = append(w.pending[:i], w.pending[i+1:]...)
+		} else {
+			w.pending[i] = event
+		}
+		return
+	}
+	w.pending = append(w.pending, event)
+}
+
+func sameState(a, b WatchEvent) bool {
+	if a.Err != nil || b.Err != nil || a.Exists != b.Exists {
+		return false
+	}
+	if a.Type == WatchModeData {
+		return bytes.Equal(a.Data, b.Data)
+	}
+	if len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i := range a.Children {
+		if a.Children[i] != b.Children[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNode struct {
+	sync.Mutex
+	children []string
+	data     []byte
+	dataErr  error
+	watchers map[WatchMode]chan zk.Event
+	arms     int
+}
+
+func (f *fakeNode) watch(mode WatchMode) chan zk.Event {
+	ch := make(chan zk.Event, 1)
+	f.watchers[mode] = ch
+	f.arms++
+	return ch
+}
+
+func (f *fakeNode) watchChildren(string) ([]string, <-chan zk.Event, error) {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string(nil), f.children...), f.watch(WatchModeChildren), nil
+}
+
+func (f *fakeNode) watchData(string) ([]byte, bool, <-chan zk.Event, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.dataErr; err != nil {
+		f.dataErr = nil
+		f.arms++
+		return nil, false, nil, err
+	}
+	return f.data, true, f.watch(WatchModeData), nil
+}
+
+// update changes the node, fires the watch of the mode and waits for the watch to be armed again
+func (f *fakeNode) update(t *testing.T, mode WatchMode, change func()) {
+	f.Lock()
+	change()
+	arms := f.arms
+	f.watchers[mode] <- zk.Event{Path: "/a"}
+	f.Unlock()
+	assert.Eventually(t, func() bool {
+		f.Lock()
+		defer f.Unlock()
+		return f.arms > arms
+	}, time.Second, time.Millisecond)
+}
+
+func nextWatchEvent(t *testing.T, w *PersistentWatcher) WatchEvent {
+	select {
+	case e := <-w.EvtCh:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no watch event")
+	}
+	return WatchEvent{}
+}
+
+func assertNoWatchEvent(t *testing.T, w *PersistentWatcher) {
+	select {
+	case e := <-w.EvtCh:
+		t.Fatalf("unexpected watch event %+v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestPersistentWatcher(t *testing.T) {
+	f := &fakeNode{
+		children: []string{"2", "1"},
+		data:     []byte("v1"),
+		watchers: make(map[WatchMode]chan zk.Event),
+	}
+	w := newPersistentWatcher("/a", WatchModeChildren|WatchModeData, f.watchChildren, f.watchData, nil, 10*time.Millisecond)
+
+	// the initial states are replayed
+	assert.Equal(t, WatchEvent{Path: "/a", Type: WatchModeChildren, Exists: true, Children: []string{"1", "2"}}, nextWatchEvent(t, w))
+	assert.Equal(t, WatchEvent{Path: "/a", Type: WatchModeData, Exists: true, Data: []byte("v1")}, nextWatchEvent(t, w))
+
+	// the watches are armed again and the unchanged states are not sent
+	f.update(t, WatchModeData, func() {})
+	assertNoWatchEvent(t, w)
+	f.update(t, WatchModeChildren, func() { f.children = []string{"1"} })
+	assert.Equal(t, []string{"1"}, nextWatchEvent(t, w).Children)
+
+	// the pending states are coalesced
+	f.update(t, WatchModeData, func() { f.data = []byte("v2") })
+	f.update(t, WatchModeData, func() { f.data = []byte("v3") })
+	assert.Equal(t, []byte("v3"), nextWatchEvent(t, w).Data)
+	f.update(t, WatchModeData, func() { f.data = []byte("v4") })
+	f.update(t, WatchModeData, func() { f.data = []byte("v3") })
+	assertNoWatchEvent(t, w)
+
+	// the failed watch is retried
+	f.update(t, WatchModeData, func() {
+		f.dataErr = errors.New("connection loss")
+		f.data = []byte("v5")
+	})
+	assert.Error(t, nextWatchEvent(t, w).Err)
+	assert.Equal(t, []byte("v5"), nextWatchEvent(t, w).Data)
+
+	w.Close()
+	_, ok := <-w.EvtCh
+	assert.False(t, ok)
+}
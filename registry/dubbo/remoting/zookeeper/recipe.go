@@ -0,0 +1,335 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+var (
+	// ErrSessionLost is returned by the recipes if the node of the recipe is lost, as the session of the
+	// client may be expired after the connection is lost
+	ErrSessionLost = perrors.New("zookeeper recipe node is lost")
+	// ErrNotHeld is returned by releasing the lock or the leadership which is not held
+	ErrNotHeld = perrors.New("zookeeper recipe is not held")
+	// ErrAlreadyHeld is returned by acquiring the lock or the leadership which is held or being acquired
+	ErrAlreadyHeld = perrors.New("zookeeper recipe is already held")
+)
+
+// protectedPrefix marks the sequential nodes with a unique id, so that the node created by a request
+// whose response is lost can be found and deleted
+const protectedPrefix = "_c_"
+
+// recipeClient is the primitives of the zookeeper client the recipes are built on
+type recipeClient interface {
+	// createRecipeNode creates the node with the flags and its parents, the created path is returned
+	createRecipeNode(zkPath string, data []byte, flags int32) (string, error)
+	// recipeChildren returns the children of the path, none if it does not exist
+	recipeChildren(zkPath string) ([]string, error)
+	recipeChildrenW(zkPath string) ([]string, <-chan zk.Event, error)
+	recipeExistsW(zkPath string) (bool, <-chan zk.Event, error)
+	recipeData(zkPath string) ([]byte, error)
+	// recipeDelete deletes the node, it is not an error if the node does not exist
+	recipeDelete(zkPath string) error
+	// recipeSuspended returns a channel closed once the connection is suspended or lost, which is closed
+	// already if the client is not connected
+	recipeSuspended() <-chan struct{}
+	Done() <-chan struct{}
+}
+
+func (z *ZookeeperClient) createRecipeNode(zkPath string, data []byte, flags int32) (string, error) {
+	for {
+		conn := z.getConn()
+		if conn == nil {
+			return "", ErrNotConnected
+		}
+		start := time.Now()
+		created, err := conn.Create(z.realPath(zkPath), data, flags, z.nodeACL())
+		z.observe(OpCreate, start, err)
+		if err == zk.ErrNoNode {
+			if err = z.Create(path.Dir(zkPath)); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		created, _ = z.clientPath(created)
+		return created, nil
+	}
+}
+
+func (z *ZookeeperClient) recipeChildren(zkPath string) ([]string, error) {
+	conn := z.getConn()
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	start := time.Now()
+	children, _, err := conn.Children(z.realPath(zkPath))
+	z.observe(OpChildren, start, err)
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	return children, err
+}
+
+func (z *ZookeeperClient) recipeChildrenW(zkPath string) ([]string, <-chan zk.Event, error) {
+	conn := z.getConn()
+	if conn == nil {
+		return nil, nil, ErrNotConnected
+	}
+	start := time.Now()
+	children, _, watcher, err := conn.ChildrenW(z.realPath(zkPath))
+	z.observe(OpChildren, start, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return children, watcher.EvtCh, nil
+}
+
+func (z *ZookeeperClient) recipeExistsW(zkPath string) (bool, <-chan zk.Event, error) {
+	conn := z.getConn()
+	if conn == nil {
+		return false, nil, ErrNotConnected
+	}
+	start := time.Now()
+	exist, _, watcher, err := conn.ExistsW(z.realPath(zkPath))
+	z.observe(OpExists, start, err)
+	if err != nil {
+		return false, nil, err
+	}
+	return exist, watcher.EvtCh, nil
+}
+
+func (z *ZookeeperClient) recipeData(zkPath string) ([]byte, error) {
+	data, _, err := z.GetContent(zkPath)
+	return data, err
+}
+
+func (z *ZookeeperClient) recipeDelete(zkPath string) error {
+	conn := z.getConn()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	start := time.Now()
+	err := conn.Delete(z.realPath(zkPath), -1)
+	z.observe(OpDelete, start, err)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+func (z *ZookeeperClient) recipeSuspended() <-chan struct{} {
+	z.metrics.Lock()
+	defer z.metrics.Unlock()
+	return z.metrics.suspended
+}
+
+// sequenceOf returns the sequence suffix appended to the sequential node by zookeeper
+func sequenceOf(node string) string {
+	if len(node) < 10 {
+		return node
+	}
+	return node[len(node)-10:]
+}
+
+// contender competes for the lock or the leadership with an ephemeral sequential node under the path,
+// the one with the lowest sequence wins. The others watch their predecessors only, so that a release
+// wakes up one contender instead of all of them.
+type contender struct {
+	client recipeClient
+	path   string
+	name   string // the name of the nodes, e.g. "lock-"
+	data   []byte
+
+	lock     sync.Mutex
+	acquired bool
+	node     string // the path of the node, empty if none
+	lost     chan struct{}
+	stop     chan struct{}
+}
+
+// nodes returns the nodes of the contenders sorted by their sequences
+func (c *contender) nodes() ([]string, error) {
+	children, err := c.client.recipeChildren(c.path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := children[:0]
+	for _, child := range children {
+		if strings.Contains(child, c.name) {
+			nodes = append(nodes, child)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return sequenceOf(nodes[i]) < sequenceOf(nodes[j])
+	})
+	return nodes, nil
+}
+
+// held returns whether the node is created and wins
+func (c *contender) held() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.acquired
+}
+
+// acquire creates the node and waits until it wins, the returned channel is closed once it is released
+// or lost, which happens if the node is deleted, the connection is suspended or the client is closed, as
+// the session may expire. The node is kept until release if it is lost with the connection, the same
+// as curator, since the contender can't tell whether the session is still alive.
+func (c *contender) acquire(ctx context.Context) (<-chan struct{}, error) {
+	c.lock.Lock()
+	if c.node != "" {
+		c.lock.Unlock()
+		return nil, ErrAlreadyHeld
+	}
+	// reserve the contender before the node is created
+	c.node = c.path
+	c.lock.Unlock()
+
+	id := uuid.NewV4().String()
+	node, err := c.client.createRecipeNode(path.Join(c.path, protectedPrefix+id+"-"+c.name), c.data,
+		zk.FlagEphemeral|zk.FlagSequence)
+	if err == nil {
+		err = c.wait(ctx, node)
+	} else {
+		node = c.findProtected(id)
+	}
+	if err != nil {
+		if node != "" {
+			_ = c.client.recipeDelete(node)
+		}
+		c.lock.Lock()
+		c.node = ""
+		c.lock.Unlock()
+		return nil, err
+	}
+
+	lost := make(chan struct{})
+	stop := make(chan struct{})
+	c.lock.Lock()
+	c.node, c.acquired, c.lost, c.stop = node, true, lost, stop
+	c.lock.Unlock()
+	go c.watch(node, lost, stop)
+	return lost, nil
+}
+
+// findProtected returns the node created with the id, which may be created by a failed request
+func (c *contender) findProtected(id string) string {
+	children, err := c.client.recipeChildren(c.path)
+	if err != nil {
+		return ""
+	}
+	for _, child := range children {
+		if strings.HasPrefix(child, protectedPrefix+id) {
+			return path.Join(c.path, child)
+		}
+	}
+	return ""
+}
+
+// wait waits until the node has the lowest sequence
+func (c *contender) wait(ctx context.Context, node string) error {
+	name := path.Base(node)
+	for {
+		nodes, err := c.nodes()
+		if err != nil {
+			return err
+		}
+		i := sort.Search(len(nodes), func(i int) bool {
+			return sequenceOf(nodes[i]) >= sequenceOf(name)
+		})
+		if i == len(nodes) || nodes[i] != name {
+			return ErrSessionLost
+		}
+		if i == 0 {
+			return nil
+		}
+		exists, events, err := c.client.recipeExistsW(path.Join(c.path, nodes[i-1]))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		select {
+		case <-events:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.client.Done():
+			return ErrSessionLost
+		}
+	}
+}
+
+// watch closes lost once the node is deleted, the connection is suspended, the client is closed
+// or stop is closed
+func (c *contender) watch(node string, lost chan struct{}, stop chan struct{}) {
+	defer close(lost)
+	suspended := c.client.recipeSuspended()
+	for {
+		exists, events, err := c.client.recipeExistsW(node)
+		if err != nil || !exists {
+			c.lock.Lock()
+			c.acquired = false
+			c.lock.Unlock()
+			return
+		}
+		select {
+		case <-events:
+		case <-suspended:
+			c.lock.Lock()
+			c.acquired = false
+			c.lock.Unlock()
+			return
+		case <-c.client.Done():
+			c.lock.Lock()
+			c.acquired = false
+			c.lock.Unlock()
+			return
+		case <-stop:
+			return
+		}
+	}
+}
+
+// release deletes the node, ErrNotHeld is returned if it is not acquired
+func (c *contender) release() error {
+	c.lock.Lock()
+	node, stop := c.node, c.stop
+	if node == "" || stop == nil {
+		c.lock.Unlock()
+		return ErrNotHeld
+	}
+	c.node, c.acquired, c.stop = "", false, nil
+	c.lock.Unlock()
+	close(stop)
+	return c.client.recipeDelete(node)
+}
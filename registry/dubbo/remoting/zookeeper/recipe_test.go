@@ -0,0 +1,344 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeZk is an in memory zookeeper tree shared by the fakeRecipeClients
+type fakeZk struct {
+	sync.Mutex
+	nodes    map[string][]byte
+	owners   map[string]*fakeRecipeClient // ephemeral node -> session
+	seq      int
+	watchers map[string][]chan zk.Event
+}
+
+func newFakeZk() *fakeZk {
+	return &fakeZk{
+		nodes:    map[string][]byte{"/": nil},
+		owners:   make(map[string]*fakeRecipeClient),
+		watchers: make(map[string][]chan zk.Event),
+	}
+}
+
+// fire notifies the watchers of the path, the watches are one-shot, f must be locked
+func (f *fakeZk) fire(zkPath string) {
+	for _, w := range f.watchers[zkPath] {
+		w <- zk.Event{Path: zkPath}
+	}
+	delete(f.watchers, zkPath)
+}
+
+func (f *fakeZk) watch(zkPath string) <-chan zk.Event {
+	w := make(chan zk.Event, 1)
+	f.watchers[zkPath] = append(f.watchers[zkPath], w)
+	return w
+}
+
+// the watchers of the children are keyed by the parent with a trailing slash
+func childrenKey(zkPath string) string {
+	return strings.TrimSuffix(zkPath, "/") + "/"
+}
+
+func (f *fakeZk) children(zkPath string) []string {
+	prefix := childrenKey(zkPath)
+	var children []string
+	for p := range f.nodes {
+		if p != "/" && strings.HasPrefix(p, prefix) && !strings.Contains(p[len(prefix):], "/") {
+			children = append(children, p[len(prefix):])
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+func (f *fakeZk) delete(zkPath string) {
+	if _, ok := f.nodes[zkPath]; !ok {
+		return
+	}
+	delete(f.nodes, zkPath)
+	delete(f.owners, zkPath)
+	f.fire(zkPath)
+	f.fire(childrenKey(path.Dir(zkPath)))
+}
+
+type fakeRecipeClient struct {
+	zk        *fakeZk
+	done      chan struct{}
+	suspended chan struct{}
+}
+
+func (f *fakeZk) client() *fakeRecipeClient {
+	return &fakeRecipeClient{zk: f, done: make(chan struct{}), suspended: make(chan struct{})}
+}
+
+// suspend disconnects the client, the session and its ephemeral nodes are kept
+func (c *fakeRecipeClient) suspend() {
+	close(c.suspended)
+}
+
+// expire closes the client and deletes its ephemeral nodes
+func (c *fakeRecipeClient) expire() {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	close(c.done)
+	for p, owner := range c.zk.owners {
+		if owner == c {
+			c.zk.delete(p)
+		}
+	}
+}
+
+func (c *fakeRecipeClient) createRecipeNode(zkPath string, data []byte, flags int32) (string, error) {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	for dir := path.Dir(zkPath); ; dir = path.Dir(dir) {
+		if _, ok := c.zk.nodes[dir]; ok {
+			break
+		}
+		c.zk.nodes[dir] = nil
+	}
+	if flags&zk.FlagSequence != 0 {
+		c.zk.seq++
+		zkPath = fmt.Sprintf("%s%010d", zkPath, c.zk.seq)
+	}
+	if _, ok := c.zk.nodes[zkPath]; ok {
+		return "", zk.ErrNodeExists
+	}
+	c.zk.nodes[zkPath] = data
+	if flags&zk.FlagEphemeral != 0 {
+		c.zk.owners[zkPath] = c
+	}
+	c.zk.fire(zkPath)
+	c.zk.fire(childrenKey(path.Dir(zkPath)))
+	return zkPath, nil
+}
+
+func (c *fakeRecipeClient) recipeChildren(zkPath string) ([]string, error) {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	return c.zk.children(zkPath), nil
+}
+
+func (c *fakeRecipeClient) recipeChildrenW(zkPath string) ([]string, <-chan zk.Event, error) {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	if _, ok := c.zk.nodes[zkPath]; !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return c.zk.children(zkPath), c.zk.watch(childrenKey(zkPath)), nil
+}
+
+func (c *fakeRecipeClient) recipeExistsW(zkPath string) (bool, <-chan zk.Event, error) {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	_, ok := c.zk.nodes[zkPath]
+	return ok, c.zk.watch(zkPath), nil
+}
+
+func (c *fakeRecipeClient) recipeData(zkPath string) ([]byte, error) {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	data, ok := c.zk.nodes[zkPath]
+	if !ok {
+		return nil, zk.ErrNoNode
+	}
+	return data, nil
+}
+
+func (c *fakeRecipeClient) recipeDelete(zkPath string) error {
+	c.zk.Lock()
+	defer c.zk.Unlock()
+	c.zk.delete(zkPath)
+	return nil
+}
+
+func (c *fakeRecipeClient) recipeSuspended() <-chan struct{} {
+	return c.suspended
+}
+
+func (c *fakeRecipeClient) Done() <-chan struct{} {
+	return c.done
+}
+
+func assertClosed(t *testing.T, ch <-chan struct{}) {
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("channel is not closed")
+	}
+}
+
+func TestLock(t *testing.T) {
+	f := newFakeZk()
+	a, b := newLock(f.client(), "/locks/a"), newLock(f.client(), "/locks/a")
+
+	lost, err := a.Lock(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, a.Held())
+	_, err = a.Lock(context.Background())
+	assert.Equal(t, ErrAlreadyHeld, err)
+
+	// the waiting contender leaves on timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = b.Lock(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.False(t, b.Held())
+	children, _ := f.client().recipeChildren("/locks/a")
+	assert.Len(t, children, 1)
+
+	acquired := make(chan (<-chan struct{}))
+	go func() {
+		bLost, err := b.Lock(context.Background())
+		assert.NoError(t, err)
+		acquired <- bLost
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, b.Held())
+	assert.NoError(t, a.Unlock())
+	assertClosed(t, lost)
+	assert.Equal(t, ErrNotHeld, a.Unlock())
+
+	var bLost <-chan struct{}
+	select {
+	case bLost = <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock is not acquired")
+	}
+	assert.True(t, b.Held())
+
+	// the lock is lost with the session
+	b.client.(*fakeRecipeClient).expire()
+	assertClosed(t, bLost)
+	assert.False(t, b.Held())
+	assert.NoError(t, b.Unlock())
+}
+
+func TestLockSuspended(t *testing.T) {
+	f := newFakeZk()
+	a, b := newLock(f.client(), "/locks/a"), newLock(f.client(), "/locks/a")
+
+	lost, err := a.Lock(context.Background())
+	assert.NoError(t, err)
+	acquired := make(chan struct{})
+	go func() {
+		_, err := b.Lock(context.Background())
+		assert.NoError(t, err)
+		close(acquired)
+	}()
+
+	// the lock is lost once the connection is suspended, but the node is kept until Unlock
+	a.client.(*fakeRecipeClient).suspend()
+	assertClosed(t, lost)
+	assert.False(t, a.Held())
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, b.Held())
+	assert.NoError(t, a.Unlock())
+	assertClosed(t, acquired)
+	assert.True(t, b.Held())
+	assert.NoError(t, b.Unlock())
+}
+
+func TestLeaderElection(t *testing.T) {
+	f := newFakeZk()
+	a := newLeaderElection(f.client(), "/election", "a")
+	b := newLeaderElection(f.client(), "/election", "b")
+
+	_, err := a.Leader()
+	assert.Equal(t, ErrNoLeader, err)
+	lost, err := a.Campaign(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, a.IsLeader())
+
+	elected := make(chan struct{})
+	go func() {
+		_, err := b.Campaign(context.Background())
+		assert.NoError(t, err)
+		close(elected)
+	}()
+	assert.Eventually(t, func() bool {
+		participants, _ := a.Participants()
+		return len(participants) == 2
+	}, time.Second, time.Millisecond)
+	participants, err := b.Participants()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, participants)
+	leader, err := b.Leader()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", leader)
+
+	a.client.(*fakeRecipeClient).expire()
+	assertClosed(t, lost)
+	assert.False(t, a.IsLeader())
+	assertClosed(t, elected)
+	assert.True(t, b.IsLeader())
+	leader, _ = b.Leader()
+	assert.Equal(t, "b", leader)
+	assert.NoError(t, b.Resign())
+	assert.False(t, b.IsLeader())
+}
+
+func TestDoubleBarrier(t *testing.T) {
+	f := newFakeZk()
+	const members = 3
+	barriers := make([]*DoubleBarrier, members)
+	for i := range barriers {
+		barriers[i] = newDoubleBarrier(f.client(), "/barrier", fmt.Sprintf("m%d", i), members)
+	}
+	assert.Equal(t, ErrNotHeld, barriers[0].Leave(context.Background()))
+
+	// a member can't pass the barrier alone
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, barriers[0].Enter(ctx))
+	children, _ := f.client().recipeChildren("/barrier")
+	assert.Empty(t, children)
+
+	run := func(op func(b *DoubleBarrier) error) {
+		var wg sync.WaitGroup
+		for _, b := range barriers {
+			wg.Add(1)
+			go func(b *DoubleBarrier) {
+				defer wg.Done()
+				assert.NoError(t, op(b))
+			}(b)
+		}
+		wg.Wait()
+	}
+	run(func(b *DoubleBarrier) error { return b.Enter(context.Background()) })
+	children, _ = f.client().recipeChildren("/barrier")
+	assert.Equal(t, []string{"m0", "m1", "m2", barrierReady}, children)
+	assert.Equal(t, ErrAlreadyHeld, barriers[0].Enter(context.Background()))
+
+	run(func(b *DoubleBarrier) error { return b.Leave(context.Background()) })
+	children, _ = f.client().recipeChildren("/barrier")
+	assert.Empty(t, children)
+}
@@ -0,0 +1,187 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+// ErrTempNodeConflict is returned when an ephemeral node to recover is owned by another live session
+var ErrTempNodeConflict = perrors.New("zookeeper temp node is owned by another session")
+
+// tempNodeConn is the part of *zk.Conn used to resolve the existing ephemeral node while recovering
+type tempNodeConn interface {
+	Exists(path string) (bool, *zk.Stat, error)
+	Delete(path string, version int32) error
+	SessionID() int64
+}
+
+// WithTempNodes sets the ephemeral nodes which are recreated once the client connects,
+// it carries the nodes of a closed client over to the new one
+func WithTempNodes(nodes map[string][]byte) Option {
+	return func(opt *Options) {
+		opt.tempNodes = nodes
+	}
+}
+
+// WithTempNodeListener adds a listener which is notified with an add event for every
+// ephemeral node recreated after reconnecting
+func WithTempNodeListener(listener remoting.DataListener) Option {
+	return func(opt *Options) {
+		opt.tempNodeListeners = append(opt.tempNodeListeners, listener)
+	}
+}
+
+// withExpiredSession carries the session of the closed client over to the new one,
+// the nodes left by the session can be replaced while recovering
+func withExpiredSession(sessionID int64) Option {
+	return func(opt *Options) {
+		opt.expiredSession = sessionID
+	}
+}
+
+// expiredSessionID returns the session of the last closed connection, 0 if no connection is closed
+func (z *ZookeeperClient) expiredSessionID() int64 {
+	z.RLock()
+	defer z.RUnlock()
+	return z.expiredSession
+}
+
+// TempNodes returns a copy of the ephemeral nodes created by the client, sequential nodes are not included
+func (z *ZookeeperClient) TempNodes() map[string][]byte {
+	z.tempNodesLock.Lock()
+	defer z.tempNodesLock.Unlock()
+	nodes := make(map[string][]byte, len(z.tempNodes))
+	for p, data := range z.tempNodes {
+		nodes[p] = data
+	}
+	return nodes
+}
+
+// TempNodeListeners returns the listeners notified after the ephemeral nodes are recreated
+func (z *ZookeeperClient) TempNodeListeners() []remoting.DataListener {
+	z.tempNodesLock.Lock()
+	defer z.tempNodesLock.Unlock()
+	return append([]remoting.DataListener(nil), z.tempNodeListeners...)
+}
+
+func (z *ZookeeperClient) trackTempNode(zkPath string, data []byte) {
+	z.tempNodesLock.Lock()
+	defer z.tempNodesLock.Unlock()
+	if z.tempNodes == nil {
+		z.tempNodes = make(map[string][]byte)
+	}
+	z.tempNodes[zkPath] = data
+}
+
+func (z *ZookeeperClient) untrackTempNode(zkPath string) {
+	z.tempNodesLock.Lock()
+	defer z.tempNodesLock.Unlock()
+	delete(z.tempNodes, zkPath)
+}
+
+// ownedBySession returns whether the node is an ephemeral node of the session of the connection
+func (z *ZookeeperClient) ownedBySession(conn tempNodeConn, zkPath string) bool {
+	stat, err := z.nodeStat(conn, zkPath)
+	return err == nil && stat.EphemeralOwner == conn.SessionID()
+}
+
+func (z *ZookeeperClient) nodeStat(conn tempNodeConn, zkPath string) (*zk.Stat, error) {
+	start := time.Now()
+	exist, stat, err := conn.Exists(z.realPath(zkPath))
+	z.observe(OpExists, start, err)
+	if err == nil && (!exist || stat == nil) {
+		err = zk.ErrNoNode
+	}
+	return stat, err
+}
+
+// recoverTempNodes recreates the tracked ephemeral nodes, which vanish with the expired session.
+// A node left by the expired session is deleted and created again, so that it is owned by the new one,
+// but the node owned by another session is kept and reported as a conflict.
+func (z *ZookeeperClient) recoverTempNodes() {
+	nodes := z.TempNodes()
+	if len(nodes) == 0 {
+		return
+	}
+	listeners := z.TempNodeListeners()
+	for zkPath, data := range nodes {
+		if err := z.recoverTempNode(zkPath, data); err != nil {
+			logger.Errorf("zkClient{%s} recover temp node{%s} = error{%v}", z.name, zkPath, perrors.WithStack(err))
+			continue
+		}
+		logger.Infof("zkClient{%s} recover temp node{%s}", z.name, zkPath)
+		for _, listener := range listeners {
+			listener.DataChange(remoting.Event{Path: zkPath, Action: remoting.EventTypeAdd, Content: string(data)})
+		}
+	}
+}
+
+func (z *ZookeeperClient) recoverTempNode(zkPath string, data []byte) error {
+	err := z.CreateTempWithValue(zkPath, data)
+	if err != zk.ErrNodeExists {
+		return err
+	}
+	conn := z.getConn()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	owned, err := z.resolveTempNode(conn, zkPath, z.expiredSessionID())
+	if err != nil {
+		return err
+	}
+	if owned {
+		z.trackTempNode(zkPath, data)
+		return nil
+	}
+	return z.CreateTempWithValue(zkPath, data)
+}
+
+// resolveTempNode resolves the existing node to recover, it returns true if the node is owned by the session of conn.
+// The node of the expired session is deleted, as it is not removed by the server until the session timeout,
+// otherwise ErrTempNodeConflict is returned and the node is kept.
+func (z *ZookeeperClient) resolveTempNode(conn tempNodeConn, zkPath string, expiredSession int64) (bool, error) {
+	stat, err := z.nodeStat(conn, zkPath)
+	if err == zk.ErrNoNode {
+		// removed by the server just now
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	switch stat.EphemeralOwner {
+	case 0:
+		return false, perrors.WithMessagef(ErrTempNodeConflict, "node{%s} is persistent", zkPath)
+	case conn.SessionID():
+		return true, nil
+	case expiredSession:
+		start := time.Now()
+		err = conn.Delete(z.realPath(zkPath), stat.Version)
+		z.observe(OpDelete, start, err)
+		if err != nil && err != zk.ErrNoNode {
+			return false, err
+		}
+		return false, nil
+	}
+	return false, perrors.WithMessagef(ErrTempNodeConflict, "node{%s} is owned by session{%#x}", zkPath, stat.EphemeralOwner)
+}
@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"testing"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	perrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTempNodeConn struct {
+	session int64
+	owner   int64 // owner of the existing node, -1 means no node
+	deleted []int32
+}
+
+func (c *fakeTempNodeConn) Exists(string) (bool, *zk.Stat, error) {
+	if c.owner < 0 {
+		return false, nil, nil
+	}
+	return true, &zk.Stat{EphemeralOwner: c.owner, Version: 3}, nil
+}
+
+func (c *fakeTempNodeConn) Delete(_ string, version int32) error {
+	c.deleted = append(c.deleted, version)
+	c.owner = -1
+	return nil
+}
+
+func (c *fakeTempNodeConn) SessionID() int64 {
+	return c.session
+}
+
+func TestResolveTempNode(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+
+	// owned by the current session
+	conn := &fakeTempNodeConn{session: 2, owner: 2}
+	owned, err := z.resolveTempNode(conn, "/dubbo/a", 1)
+	assert.NoError(t, err)
+	assert.True(t, owned)
+	assert.True(t, z.ownedBySession(conn, "/dubbo/a"))
+	assert.Empty(t, conn.deleted)
+
+	// left by the expired session
+	conn = &fakeTempNodeConn{session: 2, owner: 1}
+	owned, err = z.resolveTempNode(conn, "/dubbo/a", 1)
+	assert.NoError(t, err)
+	assert.False(t, owned)
+	assert.Equal(t, []int32{3}, conn.deleted)
+
+	// removed by the server
+	conn = &fakeTempNodeConn{session: 2, owner: -1}
+	owned, err = z.resolveTempNode(conn, "/dubbo/a", 1)
+	assert.NoError(t, err)
+	assert.False(t, owned)
+}
+
+func TestResolveTempNodeConflict(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+
+	// owned by another live client
+	conn := &fakeTempNodeConn{session: 2, owner: 3}
+	owned, err := z.resolveTempNode(conn, "/dubbo/a", 1)
+	assert.Equal(t, ErrTempNodeConflict, perrors.Cause(err))
+	assert.False(t, owned)
+	assert.Empty(t, conn.deleted)
+	assert.False(t, z.ownedBySession(conn, "/dubbo/a"))
+
+	// no expired session is recorded
+	owned, err = z.resolveTempNode(conn, "/dubbo/a", 0)
+	assert.Equal(t, ErrTempNodeConflict, perrors.Cause(err))
+	assert.Empty(t, conn.deleted)
+
+	// persistent node
+	conn = &fakeTempNodeConn{session: 2, owner: 0}
+	_, err = z.resolveTempNode(conn, "/dubbo/a", 0)
+	assert.Equal(t, ErrTempNodeConflict, perrors.Cause(err))
+	assert.Empty(t, conn.deleted)
+}
+
+func TestExpiredSessionOption(t *testing.T) {
+	z := &ZookeeperClient{}
+	z.applyOptions(&Options{})
+	assert.Zero(t, z.expiredSessionID())
+	assert.Nil(t, z.detachConn())
+
+	options := &Options{}
+	withExpiredSession(42)(options)
+	z.applyOptions(options)
+	assert.Equal(t, int64(42), z.expiredSessionID())
+}
@@ -0,0 +1,201 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+// ChildrenEvent is the event of a path watched by ChildrenWatcher
+type ChildrenEvent struct {
+	Path string
+	// Children are the current children of the path, nil if the path does not exist
+	Children []string
+	// Err is the error of watching the path, the path is watched again after ConnDelay seconds
+	Err error
+}
+
+type watchChildrenFunc func(path string) ([]string, <-chan zk.Event, error)
+
+// ChildrenWatcher watches the children of many paths in one goroutine and merges the events into EvtCh
+type ChildrenWatcher struct {
+	EvtCh <-chan ChildrenEvent
+
+	paths  []string
+	watch  watchChildrenFunc
+	evtCh  chan ChildrenEvent
+	done   <-chan struct{}
+	stop   chan struct{}
+	once   sync.Once
+	wait   sync.WaitGroup
+	retry  time.Duration
+	failed map[int]struct{}
+}
+
+// WatchChildren watches the children of @paths, an event with the current children is sent for
+// every path at first and every time its children change. EvtCh is closed after the watcher or
+// the client is closed.
+func (z *ZookeeperClient) WatchChildren(paths []string) (*ChildrenWatcher, error) {
+	if z.getConn() == nil {
+		return nil, ErrNotConnected
+	}
+	return newChildrenWatcher(paths, z.watchChildren, z.Done(), ConnDelay*time.Second), nil
+}
+
+// watchChildren watches the children of @path, or its creation if it does not exist
+func (z *ZookeeperClient) watchChildren(path string) ([]string, <-chan zk.Event, error) {
+	for {
+		conn := z.getConn()
+		if conn == nil {
+			return nil, nil, ErrNotConnected
+		}
+		start := time.Now()
+		children, _, watcher, err := conn.ChildrenW(z.realPath(path))
+		z.observe(OpChildren, start, err)
+		if err == nil {
+			if children == nil {
+				children = []string{}
+			}
+			return children, watcher.EvtCh, nil
+		}
+		if err != zk.ErrNoNode {
+			return nil, nil, err
+		}
+		start = time.Now()
+		exist, _, watcher, err := conn.ExistsW(z.realPath(path))
+		z.observe(OpExists, start, err)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exist {
+			return nil, watcher.EvtCh, nil
+		}
+		// created just now, watch its children again
+	}
+}
+
+func newChildrenWatcher(paths []string, watch watchChildrenFunc, done <-chan struct{}, retry time.Duration) *ChildrenWatcher {
+	w := &ChildrenWatcher{
+		paths:  append([]string(nil), paths...),
+		watch:  watch,
+		evtCh:  make(chan ChildrenEvent, len(paths)),
+		done:   done,
+		stop:   make(chan struct{}),
+		retry:  retry,
+		failed: make(map[int]struct{}),
+	}
+	w.EvtCh = w.evtCh
+	w.wait.Add(1)
+	go w.run()
+	return w
+}
+
+// Close stops watching and waits for the watcher goroutine to exit
+func (w *ChildrenWatcher) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	w.wait.Wait()
+}
+
+const (
+	doneCase = iota
+	stopCase
+	retryCase
+	pathCase
+)
+
+func (w *ChildrenWatcher) run() {
+	defer func() {
+		close(w.evtCh)
+		w.wait.Done()
+	}()
+
+	var (
+		timer *time.Timer
+		cases = make([]reflect.SelectCase, pathCase+len(w.paths))
+	)
+	for i := range cases {
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf((<-chan zk.Event)(nil))}
+	}
+	cases[doneCase].Chan = reflect.ValueOf(w.done)
+	cases[stopCase].Chan = reflect.ValueOf(w.stop)
+	cases[retryCase].Chan = reflect.ValueOf((<-chan time.Time)(nil))
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for i := range w.paths {
+		if !w.arm(i, cases) {
+			return
+		}
+	}
+	for {
+		if timer == nil && len(w.failed) > 0 {
+			timer = time.NewTimer(w.retry)
+			cases[retryCase].Chan = reflect.ValueOf(timer.C)
+		}
+
+		chosen, _, _ := reflect.Select(cases)
+		switch chosen {
+		case doneCase, stopCase:
+			return
+		case retryCase:
+			timer = nil
+			cases[retryCase].Chan = reflect.ValueOf((<-chan time.Time)(nil))
+			for i := range w.failed {
+				delete(w.failed, i)
+				if !w.arm(i, cases) {
+					return
+				}
+			}
+		default:
+			// the zookeeper watches fire only once, so the path is watched again on any event
+			if !w.arm(chosen-pathCase, cases) {
+				return
+			}
+		}
+	}
+}
+
+// arm watches the i-th path and sends its children, it returns false if the watcher is stopped
+func (w *ChildrenWatcher) arm(i int, cases []reflect.SelectCase) bool {
+	path := w.paths[i]
+	children, ch, err := w.watch(path)
+	if err != nil {
+		logger.Warnf("watch children of path{%s} = error{%v}, retry after %s", path, err, w.retry)
+		w.failed[i] = struct{}{}
+		ch = nil
+	}
+	cases[pathCase+i].Chan = reflect.ValueOf(ch)
+
+	select {
+	case w.evtCh <- ChildrenEvent{Path: path, Children: children, Err: err}:
+		return true
+	case <-w.done:
+	case <-w.stop:
+	}
+	return false
+}
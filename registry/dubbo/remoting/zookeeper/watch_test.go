@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dubbogo/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+type fakeChildren struct {
+	sync.Mutex
+	children map[string][]string
+	watchers map[string]chan zk.Event
+	errs     map[string]error
+}
+
+func (f *fakeChildren) watch(path string) ([]string, <-chan zk.Event, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.errs[path]; err != nil {
+		delete(f.errs, path)
+		return nil, nil, err
+	}
+	ch := make(chan zk.Event, 1)
+	f.watchers[path] = ch
+	return f.children[path], ch, nil
+}
+
+func (f *fakeChildren) set(path string, children []string) {
+	f.Lock()
+	defer f.Unlock()
+	f.children[path] = children
+	f.watchers[path] <- zk.Event{Type: zk.EventNodeChildrenChanged, Path: path}
+}
+
+func nextChildrenEvent(t *testing.T, w *ChildrenWatcher) ChildrenEvent {
+	select {
+	case e := <-w.EvtCh:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no children event")
+	}
+	return ChildrenEvent{}
+}
+
+func TestChildrenWatcher(t *testing.T) {
+	f := &fakeChildren{
+		children: map[string][]string{"/a": {"1"}},
+		watchers: make(map[string]chan zk.Event),
+		errs:     map[string]error{"/b": errors.New("connection loss")},
+	}
+	done := make(chan struct{})
+	w := newChildrenWatcher([]string{"/a", "/b"}, f.watch, done, 10*time.Millisecond)
+
+	assert.Equal(t, ChildrenEvent{Path: "/a", Children: []string{"1"}}, nextChildrenEvent(t, w))
+	e := nextChildrenEvent(t, w)
+	assert.Equal(t, "/b", e.Path)
+	assert.Error(t, e.Err)
+	// the failed path is watched again
+	assert.Equal(t, ChildrenEvent{Path: "/b"}, nextChildrenEvent(t, w))
+
+	f.set("/a", []string{"1", "2"})
+	assert.Equal(t, ChildrenEvent{Path: "/a", Children: []string{"1", "2"}}, nextChildrenEvent(t, w))
+	f.set("/b", []string{"3"})
+	assert.Equal(t, ChildrenEvent{Path: "/b", Children: []string{"3"}}, nextChildrenEvent(t, w))
+
+	close(done)
+	w.Close()
+	_, ok := <-w.EvtCh
+	assert.False(t, ok)
+}
+
+func TestChildrenWatcherClose(t *testing.T) {
+	f := &fakeChildren{
+		children: make(map[string][]string),
+		watchers: make(map[string]chan zk.Event),
+	}
+	// nobody reads the events
+	w := newChildrenWatcher([]string{"/a", "/b", "/c"}, f.watch, nil, time.Second)
+	w.Close()
+	w.Close()
+}
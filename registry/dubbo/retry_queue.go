@@ -0,0 +1,500 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/metrics"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/utils"
+)
+
+// the operations retried by RetryQueue
+const (
+	RetryRegister  = "register"
+	RetrySubscribe = "subscribe"
+)
+
+// DefaultRetryInterval is the default min interval between two retries of RetryQueue
+const DefaultRetryInterval = 100 * time.Millisecond
+
+// DefaultRetryGracePeriod is the default time after which a running retry of RetryQueue is established
+const DefaultRetryGracePeriod = 10 * time.Second
+
+// the counters of all the RetryQueue reported to the global sink of the metrics package
+var (
+	retryQueuedCounter    = metrics.NewCounter("registry.retry.queued")
+	retryAttemptCounter   = metrics.NewCounter("registry.retry.attempts")
+	retrySucceededCounter = metrics.NewCounter("registry.retry.succeeded")
+	retryGiveUpCounter    = metrics.NewCounter("registry.retry.given_up")
+)
+
+// DefaultRegistryRetryPolicy returns the policy retrying the failed operations until they succeed or
+// are cancelled, with the backoff growing from 1s to 1min. The errors which can't be recovered by a
+// retry, e.g. RegisteredError, are not retried.
+func DefaultRegistryRetryPolicy() *utils.RetryPolicy {
+	return &utils.RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Retryable: func(err error) bool {
+			switch perrors.Cause(err) {
+			case RegisteredError, ErrNotAvailable, ErrWildcardNotSupported:
+				return false
+			}
+			return true
+		},
+	}
+}
+
+// RetryQueueOptions are the options of RetryQueue
+type RetryQueueOptions struct {
+	// Policy decides the backoff and the errors to retry, DefaultRegistryRetryPolicy if it is nil
+	Policy *utils.RetryPolicy
+	// Interval is the min interval between two retries of the queue, which limits the load of the retries
+	// on the registry center after an outage, DefaultRetryInterval if it is not positive
+	Interval time.Duration
+	// GracePeriod is the time after which a running retry which has not returned is established, e.g. a
+	// subscription blocking for its lifetime, DefaultRetryGracePeriod if it is not positive
+	GracePeriod time.Duration
+}
+
+// RetryStatus is the status of an operation waiting for the retry
+type RetryStatus struct {
+	Op        string    `json:"op"`
+	URL       string    `json:"url"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError,omitempty"`
+	NextRetry time.Time `json:"nextRetry"`
+	// Running is true while the operation is being retried
+	Running bool `json:"running"`
+}
+
+// RetryQueue retries the failed operations with backoff in the background until they succeed,
+// the error is not retryable, the max attempts of the policy is reached or they are cancelled.
+// The retries are started one by one with the min interval, an operation is never retried concurrently.
+// An operation blocking after it succeeds, e.g. a subscription, is removed from the queue once it is
+// established by Established or the grace period, and it is queued again if it fails later.
+type RetryQueue struct {
+	policy   *utils.RetryPolicy
+	interval time.Duration
+	grace    time.Duration
+	queue    *utils.DelayQueue[*retryTask]
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	lock  sync.Mutex
+	tasks map[retryKey]*retryTask
+}
+
+type retryKey struct {
+	op  string
+	key string
+}
+
+type retryTask struct {
+	key         retryKey
+	url         *common.URL
+	fn          func() error
+	attempts    int
+	lastErr     error
+	nextRetry   time.Time
+	running     bool
+	established bool
+	cancelled   bool
+}
+
+// NewRetryQueue returns a RetryQueue, it must be closed to stop the retries
+func NewRetryQueue(options RetryQueueOptions) *RetryQueue {
+	if options.Policy == nil {
+		options.Policy = DefaultRegistryRetryPolicy()
+	}
+	if options.Interval <= 0 {
+		options.Interval = DefaultRetryInterval
+	}
+	if options.GracePeriod <= 0 {
+		options.GracePeriod = DefaultRetryGracePeriod
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &RetryQueue{
+		policy:   options.Policy,
+		interval: options.Interval,
+		grace:    options.GracePeriod,
+		queue:    utils.NewDelayQueue[*retryTask](),
+		ctx:      ctx,
+		cancel:   cancel,
+		tasks:    make(map[retryKey]*retryTask),
+	}
+	q.wg.Add(1)
+	go q.run()
+	return q
+}
+
+// Add queues the operation @op of the url which failed with @err, @fn is called to retry it.
+// The operations are identified by @op and @key, the queued operation with the same identity is replaced.
+func (q *RetryQueue) Add(op string, key string, url *common.URL, err error, fn func() error) {
+	if !q.retryable(err) {
+		return
+	}
+	task := &retryTask{key: retryKey{op: op, key: key}, url: url, fn: fn, attempts: 1, lastErr: err}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.ctx.Err() != nil {
+		return
+	}
+	if old, ok := q.tasks[task.key]; ok {
+		old.cancelled = true
+	}
+	q.tasks[task.key] = task
+	retryQueuedCounter.Inc()
+	q.schedule(task)
+}
+
+// Cancel stops retrying the operation, false is returned if it is not queued
+func (q *RetryQueue) Cancel(op string, key string) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	task, ok := q.tasks[retryKey{op: op, key: key}]
+	if ok {
+		task.cancelled = true
+		delete(q.tasks, task.key)
+	}
+	return ok
+}
+
+// Established removes the running retry of the operation from the queue as it has succeeded but not
+// returned, e.g. a subscription receiving the first event. It is queued again if the retry returns an error.
+func (q *RetryQueue) Established(op string, key string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if task, ok := q.tasks[retryKey{op: op, key: key}]; ok {
+		q.establish(task)
+	}
+}
+
+// CancelAll stops retrying the operations @op
+func (q *RetryQueue) CancelAll(op string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for key, task := range q.tasks {
+		if key.op == op {
+			task.cancelled = true
+			delete(q.tasks, key)
+		}
+	}
+}
+
+// Len returns the number of the operations waiting for the retry
+func (q *RetryQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.tasks)
+}
+
+// Status returns the status of the operations waiting for the retry, sorted by the operations and urls
+func (q *RetryQueue) Status() []RetryStatus {
+	q.lock.Lock()
+	status := make([]RetryStatus, 0, len(q.tasks))
+	for _, task := range q.tasks {
+		s := RetryStatus{
+			Op:        task.key.op,
+			URL:       DumpURL(task.url),
+			Attempts:  task.attempts,
+			NextRetry: task.nextRetry,
+			Running:   task.running,
+		}
+		if task.lastErr != nil {
+			s.LastError = task.lastErr.Error()
+		}
+		status = append(status, s)
+	}
+	q.lock.Unlock()
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Op != status[j].Op {
+			return status[i].Op < status[j].Op
+		}
+		return status[i].URL < status[j].URL
+	})
+	return status
+}
+
+// Close stops the retries, the running retries are not waited
+func (q *RetryQueue) Close() {
+	q.cancel()
+	q.wg.Wait()
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for key, task := range q.tasks {
+		task.cancelled = true
+		delete(q.tasks, key)
+	}
+}
+
+// establish removes the running task from the queue, q.lock must be held
+func (q *RetryQueue) establish(task *retryTask) {
+	if !task.running || task.established || task.cancelled {
+		return
+	}
+	task.established = true
+	delete(q.tasks, task.key)
+	retrySucceededCounter.Inc()
+	logger.Infof("retry %s %s is established after %d attempts", task.key.op, task.url.Key(), task.attempts+1)
+}
+
+func (q *RetryQueue) retryable(err error) bool {
+	return q.policy.Retryable == nil || q.policy.Retryable(err)
+}
+
+// schedule queues the task for the next retry or drops it with the max attempts, q.lock must be held
+func (q *RetryQueue) schedule(task *retryTask) {
+	if q.policy.MaxAttempts > 0 && task.attempts >= q.policy.MaxAttempts {
+		delete(q.tasks, task.key)
+		retryGiveUpCounter.Inc()
+		logger.Errorf("give up the retry of %s %s after %d attempts, last error: %v",
+			task.key.op, task.url.Key(), task.attempts, task.lastErr)
+		return
+	}
+	task.nextRetry = time.Now().Add(q.policy.Backoff(task.attempts))
+	q.queue.Offer(task, task.nextRetry)
+}
+
+func (q *RetryQueue) run() {
+	defer q.wg.Done()
+	timer := time.NewTimer(q.interval)
+	defer timer.Stop()
+	for {
+		task, err := q.queue.Take(q.ctx)
+		if err != nil {
+			return
+		}
+		q.lock.Lock()
+		if task.cancelled {
+			q.lock.Unlock()
+			continue
+		}
+		task.running = true
+		q.lock.Unlock()
+		retryAttemptCounter.Inc()
+		// the retry may block, e.g. a subscription, so it doesn't hold the queue
+		go q.retry(task)
+
+		timer.Reset(q.interval)
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *RetryQueue) retry(task *retryTask) {
+	timer := time.AfterFunc(q.grace, func() {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		q.establish(task)
+	})
+	err := task.fn()
+	timer.Stop()
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	task.running = false
+	if task.cancelled || q.ctx.Err() != nil {
+		return
+	}
+	if task.established {
+		if err == nil {
+			return
+		}
+		// the established operation fails later, it is retried from the start unless it is replaced
+		if _, ok := q.tasks[task.key]; ok {
+			return
+		}
+		task.established = false
+		task.attempts = 0
+		q.tasks[task.key] = task
+		retryQueuedCounter.Inc()
+	}
+	task.attempts++
+	if err == nil {
+		delete(q.tasks, task.key)
+		retrySucceededCounter.Inc()
+		logger.Infof("retry %s %s succeeded after %d attempts", task.key.op, task.url.Key(), task.attempts)
+		return
+	}
+	task.lastErr = err
+	if !q.retryable(err) {
+		delete(q.tasks, task.key)
+		retryGiveUpCounter.Inc()
+		logger.Errorf("stop the retry of %s %s, error: %v", task.key.op, task.url.Key(), err)
+		return
+	}
+	logger.Warnf("retry %s %s = error{%v}, attempts: %d", task.key.op, task.url.Key(), err, task.attempts)
+	q.schedule(task)
+}
+
+// RetryRegistry retries the failed registrations and subscriptions of the registry in a RetryQueue,
+// the errors are still returned to the callers. The retries are cancelled by UnRegister and UnSubscribe.
+type RetryRegistry struct {
+	Registry
+	queue *RetryQueue
+
+	lock          sync.Mutex
+	seq           uint64
+	subscriptions map[subscriptionKey]*retryNotifyListener
+}
+
+// retryNotifyListener establishes the retry of the subscription on the first event
+type retryNotifyListener struct {
+	NotifyListener
+	queue *RetryQueue
+	key   string
+}
+
+func (l *retryNotifyListener) Notify(event *ServiceEvent) {
+	l.queue.Established(RetrySubscribe, l.key)
+	l.NotifyListener.Notify(event)
+}
+
+// NewRetryRegistry wraps the registry with a RetryQueue
+func NewRetryRegistry(registry Registry, options RetryQueueOptions) *RetryRegistry {
+	return &RetryRegistry{
+		Registry:      registry,
+		queue:         NewRetryQueue(options),
+		subscriptions: make(map[subscriptionKey]*retryNotifyListener),
+	}
+}
+
+// Register registers the url, it is retried in the background if it fails
+func (r *RetryRegistry) Register(url *common.URL) error {
+	err := r.Registry.Register(url)
+	if err != nil && r.IsAvailable() {
+		r.queue.Add(RetryRegister, url.Key(), url, err, func() error {
+			return r.Registry.Register(url)
+		})
+	}
+	return err
+}
+
+// UnRegister cancels the retry of the url and unregisters it, the error of the unregistration is
+// ignored if the url is still waiting for the retry, as it has not been registered
+func (r *RetryRegistry) UnRegister(url *common.URL) error {
+	retrying := r.queue.Cancel(RetryRegister, url.Key())
+	err := r.Registry.UnRegister(url)
+	if retrying {
+		return nil
+	}
+	return err
+}
+
+// UnRegisterAll cancels the retries of the registrations and unregisters the urls
+func (r *RetryRegistry) UnRegisterAll() error {
+	r.queue.CancelAll(RetryRegister)
+	return r.Registry.UnRegisterAll()
+}
+
+// Subscribe subscribes the url, it is retried in the background if it fails before UnSubscribe.
+// The retry is removed from the queue once the subscription receives the first event.
+func (r *RetryRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	sub, err := newSubscriptionKey(url, notifyListener)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	r.seq++
+	l := &retryNotifyListener{
+		NotifyListener: notifyListener,
+		queue:          r.queue,
+		key:            url.Key() + "#" + strconv.FormatUint(r.seq, 10),
+	}
+	r.subscriptions[sub] = l
+	r.lock.Unlock()
+
+	subscribe := func() error {
+		if !r.subscribed(sub, l) {
+			return nil
+		}
+		err := r.Registry.Subscribe(url, l)
+		if err != nil && !r.subscribed(sub, l) {
+			// the error is caused by UnSubscribe
+			return nil
+		}
+		return err
+	}
+	err = subscribe()
+	if err != nil && r.IsAvailable() {
+		r.queue.Add(RetrySubscribe, l.key, url, err, subscribe)
+	}
+	return err
+}
+
+func (r *RetryRegistry) subscribed(sub subscriptionKey, l *retryNotifyListener) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.subscriptions[sub] == l
+}
+
+// UnSubscribe cancels the retry of the subscription and unsubscribes the url
+func (r *RetryRegistry) UnSubscribe(url *common.URL, notifyListener NotifyListener) error {
+	sub, err := newSubscriptionKey(url, notifyListener)
+	if err != nil {
+		return r.Registry.UnSubscribe(url, notifyListener)
+	}
+	r.lock.Lock()
+	l, ok := r.subscriptions[sub]
+	delete(r.subscriptions, sub)
+	r.lock.Unlock()
+	if !ok {
+		return r.Registry.UnSubscribe(url, notifyListener)
+	}
+	r.queue.Cancel(RetrySubscribe, l.key)
+	return r.Registry.UnSubscribe(url, l)
+}
+
+// RetryStatus returns the status of the operations waiting for the retry
+func (r *RetryRegistry) RetryStatus() []RetryStatus {
+	return r.queue.Status()
+}
+
+// Dump returns the dump of the registry with the operations waiting for the retry
+func (r *RetryRegistry) Dump() *RegistryDump {
+	dump := r.Registry.Dump()
+	dump.Retries = r.queue.Status()
+	return dump
+}
+
+// Destroy destroys the registry and stops the retries
+func (r *RetryRegistry) Destroy() {
+	r.Registry.Destroy()
+	r.queue.Close()
+}
+
+// Close closes the registry and stops the retries
+func (r *RetryRegistry) Close(ctx context.Context) error {
+	err := r.Registry.Close(ctx)
+	r.queue.Close()
+	return err
+}
@@ -0,0 +1,208 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"mosn.io/pkg/metrics"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/utils"
+)
+
+func newTestRetryOptions() RetryQueueOptions {
+	policy := DefaultRegistryRetryPolicy()
+	policy.InitialBackoff = 10 * time.Millisecond
+	policy.MaxBackoff = 20 * time.Millisecond
+	return RetryQueueOptions{Policy: policy, Interval: time.Millisecond}
+}
+
+func TestRetryRegistryRegister(t *testing.T) {
+	mock := newTestMockRegistry(t)
+	r := NewRetryRegistry(mock, newTestRetryOptions())
+	defer r.Destroy()
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A?interface=com.ikurento.user.A")
+
+	mock.Disconnect()
+	assert.ErrorIs(t, r.Register(&url), ErrMockDisconnected)
+	assert.Eventually(t, func() bool {
+		return mock.CallCount(MockCallRegister) > 2
+	}, time.Second, 5*time.Millisecond)
+	status := r.RetryStatus()
+	assert.Len(t, status, 1)
+	assert.Equal(t, RetryRegister, status[0].Op)
+	assert.Equal(t, DumpURL(&url), status[0].URL)
+	assert.Equal(t, ErrMockDisconnected.Error(), status[0].LastError)
+	assert.Equal(t, status, r.Dump().Retries)
+
+	mock.Reconnect()
+	assert.Eventually(t, func() bool {
+		return r.queue.Len() == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []*common.URL{&url}, mock.Registered())
+	assert.Empty(t, r.Dump().Retries)
+}
+
+func TestRetryRegistryUnRegister(t *testing.T) {
+	mock := newTestMockRegistry(t)
+	r := NewRetryRegistry(mock, newTestRetryOptions())
+	defer r.Destroy()
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A?interface=com.ikurento.user.A")
+
+	mock.SetFailures(MockFailures{RegisterErrorRate: 1})
+	assert.Error(t, r.Register(&url))
+	assert.Equal(t, 1, r.queue.Len())
+	// the unregistration fails as well, but the url has never been registered
+	assert.NoError(t, r.UnRegister(&url))
+	assert.Equal(t, 0, r.queue.Len())
+
+	count := mock.CallCount(MockCallRegister)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, mock.CallCount(MockCallRegister))
+}
+
+// failingSubscribeRegistry fails the subscriptions until failures is zero
+type failingSubscribeRegistry struct {
+	*MockRegistry
+	failures *atomic.Int32
+}
+
+var errSubscribe = errors.New("subscribe failed")
+
+func (r *failingSubscribeRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	if r.failures.Dec() >= 0 {
+		return errSubscribe
+	}
+	return r.MockRegistry.Subscribe(url, notifyListener)
+}
+
+func TestRetryRegistrySubscribe(t *testing.T) {
+	mock := &failingSubscribeRegistry{MockRegistry: newTestMockRegistry(t), failures: atomic.NewInt32(3)}
+	r := NewRetryRegistry(mock, newTestRetryOptions())
+	defer r.Destroy()
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A?interface=com.ikurento.user.A")
+
+	assert.ErrorIs(t, r.Subscribe(&url, newGatedNotifyListener()), errSubscribe)
+	assert.Eventually(t, func() bool {
+		return mock.CallCount(MockCallSubscribe) == 1 && r.queue.Len() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// the retry is cancelled by UnSubscribe
+	mock.failures.Store(1)
+	listener := newGatedNotifyListener()
+	assert.Error(t, r.Subscribe(&url, listener))
+	status := r.RetryStatus()
+	assert.Len(t, status, 1)
+	assert.Equal(t, RetrySubscribe, status[0].Op)
+	assert.NoError(t, r.UnSubscribe(&url, listener))
+	assert.Equal(t, 0, r.queue.Len())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, mock.CallCount(MockCallSubscribe))
+}
+
+// blockingSubscribeRegistry fails the subscriptions until failures is zero, the succeeded subscription
+// notifies the url if notify is true and blocks until it is unsubscribed
+type blockingSubscribeRegistry struct {
+	*MockRegistry
+	failures *atomic.Int32
+	notify   bool
+	done     chan struct{}
+}
+
+func (r *blockingSubscribeRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	if r.failures.Dec() >= 0 {
+		return errSubscribe
+	}
+	if r.notify {
+		notifyListener.Notify(&ServiceEvent{Action: remoting.EventTypeAdd, Service: *url.Clone()})
+	}
+	<-r.done
+	return errSubscribe
+}
+
+func (r *blockingSubscribeRegistry) UnSubscribe(url *common.URL, notifyListener NotifyListener) error {
+	close(r.done)
+	return nil
+}
+
+func TestRetryRegistrySubscribeEstablished(t *testing.T) {
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A?interface=com.ikurento.user.A")
+	for _, notify := range []bool{true, false} {
+		mock := &blockingSubscribeRegistry{
+			MockRegistry: newTestMockRegistry(t),
+			failures:     atomic.NewInt32(1),
+			notify:       notify,
+			done:         make(chan struct{}),
+		}
+		options := newTestRetryOptions()
+		options.GracePeriod = 50 * time.Millisecond
+		r := NewRetryRegistry(mock, options)
+		listener := &snapshotTestListener{}
+
+		assert.ErrorIs(t, r.Subscribe(&url, listener), errSubscribe)
+		// the blocking subscription is removed from the queue by the first event or the grace period
+		assert.Eventually(t, func() bool {
+			return r.queue.Len() == 0
+		}, time.Second, 5*time.Millisecond)
+		assert.Empty(t, r.Dump().Retries)
+		if notify {
+			assert.Len(t, listener.take(), 1)
+		}
+		// the error of the established subscription caused by UnSubscribe is not retried
+		assert.NoError(t, r.UnSubscribe(&url, listener))
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 0, r.queue.Len())
+		r.Destroy()
+	}
+}
+
+func TestRetryQueuePolicy(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
+	policy := &utils.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	q := NewRetryQueue(RetryQueueOptions{Policy: policy, Interval: time.Millisecond})
+	defer q.Close()
+	url, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.A")
+
+	calls := atomic.NewInt32(0)
+	q.Add(RetryRegister, url.Key(), &url, ErrMockFailure, func() error {
+		calls.Inc()
+		return ErrMockFailure
+	})
+	assert.Eventually(t, func() bool {
+		return q.Len() == 0
+	}, time.Second, time.Millisecond)
+	// the first attempt is the failed call before Add
+	assert.EqualValues(t, 2, calls.Load())
+	counters := sink.Snapshot().Counters
+	assert.Equal(t, int64(1), counters["registry.retry.queued"])
+	assert.Equal(t, int64(2), counters["registry.retry.attempts"])
+	assert.Equal(t, int64(1), counters["registry.retry.given_up"])
+
+	// the unrecoverable errors are not retried by the default policy
+	q = NewRetryQueue(RetryQueueOptions{})
+	defer q.Close()
+	q.Add(RetryRegister, url.Key(), &url, RegisteredError, func() error { return nil })
+	assert.Equal(t, 0, q.Len())
+}
@@ -0,0 +1,322 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	perrors "github.com/pkg/errors"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+	"mosn.io/pkg/utils"
+)
+
+// snapshotSaveDelay coalesces the saves of the events notified in a burst, e.g. the initial services
+var snapshotSaveDelay = 100 * time.Millisecond
+
+// Snapshot is the services of a subscription persisted in the local file
+type Snapshot struct {
+	Subscription string    `json:"subscription"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	Services     []string  `json:"services"`
+}
+
+// SnapshotCache stores a snapshot file per subscription in a directory
+type SnapshotCache struct {
+	dir string
+}
+
+// NewSnapshotCache creates the cache, @dir is created if it does not exist
+func NewSnapshotCache(dir string) (*SnapshotCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, perrors.WithMessagef(err, "os.MkdirAll(dir:%s)", dir)
+	}
+	return &SnapshotCache{dir: dir}, nil
+}
+
+func (c *SnapshotCache) file(subscription string) string {
+	return filepath.Join(c.dir, url.QueryEscape(subscription)+".json")
+}
+
+// Load loads the snapshot of the subscription, it returns nil if there is none
+func (c *SnapshotCache) Load(subscription string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(c.file(subscription))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	snapshot := &Snapshot{}
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, perrors.WithMessagef(err, "json.Unmarshal(snapshot:%s)", subscription)
+	}
+	return snapshot, nil
+}
+
+// Save replaces the snapshot of the subscription atomically, a partially written file is never loaded
+func (c *SnapshotCache) Save(snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	return perrors.WithStack(utils.WriteFileAtomic(c.file(snapshot.Subscription), data, 0644))
+}
+
+// SnapshotRegistry persists the services notified to every subscription of the registry.
+// If the registry notifies nothing in @wait after subscribing, e.g. it is unreachable on startup,
+// the services in the snapshot are notified with ServiceEvent.Stale set. The stale services
+// which are not notified by the registry in @wait after it recovers are deleted.
+type SnapshotRegistry struct {
+	Registry
+	cache *SnapshotCache
+	wait  time.Duration
+
+	lock      sync.Mutex
+	listeners map[subscriptionKey]*snapshotListener
+}
+
+// NewSnapshotRegistry wraps the registry with the snapshot cache
+func NewSnapshotRegistry(registry Registry, cache *SnapshotCache, wait time.Duration) *SnapshotRegistry {
+	return &SnapshotRegistry{
+		Registry:  registry,
+		cache:     cache,
+		wait:      wait,
+		listeners: make(map[subscriptionKey]*snapshotListener),
+	}
+}
+
+// Subscribe subscribes the url from the registry, the notified services are saved to the snapshot
+func (r *SnapshotRegistry) Subscribe(url *common.URL, notifyListener NotifyListener) error {
+	subscription := url.ServiceKey()
+	if subscription == "" {
+		return perrors.Errorf("url{%s} has no service key to snapshot", url)
+	}
+	key, err := newSubscriptionKey(url, notifyListener)
+	if err != nil {
+		return err
+	}
+	l := newSnapshotListener(r.cache, subscription, notifyListener, r.wait)
+	r.lock.Lock()
+	r.listeners[key] = l
+	r.lock.Unlock()
+	return r.Registry.Subscribe(url, l)
+}
+
+// UnSubscribe unsubscribes the url and saves the pending snapshot
+func (r *SnapshotRegistry) UnSubscribe(url *common.URL, notifyListener NotifyListener) error {
+	key, err := newSubscriptionKey(url, notifyListener)
+	if err != nil {
+		return r.Registry.UnSubscribe(url, notifyListener)
+	}
+	r.lock.Lock()
+	l, ok := r.listeners[key]
+	delete(r.listeners, key)
+	r.lock.Unlock()
+	if !ok {
+		return r.Registry.UnSubscribe(url, notifyListener)
+	}
+	l.close()
+	return r.Registry.UnSubscribe(url, l)
+}
+
+// Destroy destroys the registry and saves the pending snapshots
+func (r *SnapshotRegistry) Destroy() {
+	r.closeListeners()
+	r.Registry.Destroy()
+}
+
+// Close closes the registry and saves the pending snapshots
+func (r *SnapshotRegistry) Close(ctx context.Context) error {
+	r.closeListeners()
+	return r.Registry.Close(ctx)
+}
+
+func (r *SnapshotRegistry) closeListeners() {
+	r.lock.Lock()
+	listeners := r.listeners
+	r.listeners = make(map[subscriptionKey]*snapshotListener)
+	r.lock.Unlock()
+	for _, l := range listeners {
+		l.close()
+	}
+}
+
+type snapshotListener struct {
+	cache        *SnapshotCache
+	subscription string
+	listener     NotifyListener
+	wait         time.Duration
+
+	notifyLock sync.Mutex // keeps the order of the notified events
+	lock       sync.Mutex
+	services   map[string]*common.URL // key is URL.Key()
+	stale      map[string]*common.URL // the services notified from the snapshot and not confirmed by the registry
+	notified   bool                   // the registry notified any event
+	closed     bool
+	timer      *time.Timer
+	saveTimer  *time.Timer
+}
+
+func newSnapshotListener(cache *SnapshotCache, subscription string, listener NotifyListener, wait time.Duration) *snapshotListener {
+	l := &snapshotListener{
+		cache:        cache,
+		subscription: subscription,
+		listener:     listener,
+		wait:         wait,
+		services:     make(map[string]*common.URL),
+	}
+	snapshot, err := cache.Load(subscription)
+	if err != nil {
+		logger.Warnf("load the snapshot of subscription{%s} = error{%v}", subscription, err)
+	}
+	if snapshot != nil && len(snapshot.Services) > 0 {
+		l.lock.Lock()
+		l.timer = time.AfterFunc(wait, func() {
+			l.notifySnapshot(snapshot)
+		})
+		l.lock.Unlock()
+	}
+	return l
+}
+
+// notifySnapshot notifies the services in the snapshot if the registry notifies nothing yet
+func (l *snapshotListener) notifySnapshot(snapshot *Snapshot) {
+	l.notifyLock.Lock()
+	defer l.notifyLock.Unlock()
+
+	l.lock.Lock()
+	if l.notified || l.closed {
+		l.lock.Unlock()
+		return
+	}
+	l.timer = nil
+	l.stale = make(map[string]*common.URL, len(snapshot.Services))
+	for _, s := range snapshot.Services {
+		u, err := common.NewURL(s)
+		if err != nil {
+			logger.Warnf("parse the service{%s} of snapshot{%s} = error{%v}", s, l.subscription, err)
+			continue
+		}
+		l.stale[u.Key()] = &u
+	}
+	stale := make([]*common.URL, 0, len(l.stale))
+	for _, u := range l.stale {
+		stale = append(stale, u)
+	}
+	l.lock.Unlock()
+
+	logger.Warnf("registry notifies nothing in %s, notify %d services of the snapshot{%s} updated at %s",
+		l.wait, len(stale), l.subscription, snapshot.UpdatedAt)
+	for _, u := range stale {
+		l.listener.Notify(&ServiceEvent{Action: remoting.EventTypeAdd, Service: *u.Clone(), Stale: true})
+	}
+}
+
+// Notify forwards the event of the registry and saves the services to the snapshot
+func (l *snapshotListener) Notify(event *ServiceEvent) {
+	l.notifyLock.Lock()
+	defer l.notifyLock.Unlock()
+
+	key := event.Service.Key()
+	l.lock.Lock()
+	if !l.notified {
+		l.notified = true
+		if l.timer != nil {
+			l.timer.Stop()
+			l.timer = nil
+		}
+		if l.stale != nil {
+			l.timer = time.AfterFunc(l.wait, l.deleteStale)
+		}
+	}
+	delete(l.stale, key)
+	if event.Action == remoting.EventTypeDel {
+		delete(l.services, key)
+	} else {
+		l.services[key] = event.Service.Clone()
+	}
+	if l.saveTimer == nil && !l.closed {
+		l.saveTimer = time.AfterFunc(snapshotSaveDelay, l.save)
+	}
+	l.lock.Unlock()
+
+	l.listener.Notify(event)
+}
+
+// deleteStale deletes the stale services which are not notified by the registry after it recovers
+func (l *snapshotListener) deleteStale() {
+	l.notifyLock.Lock()
+	defer l.notifyLock.Unlock()
+
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		return
+	}
+	stale := l.stale
+	l.stale = nil
+	l.timer = nil
+	l.lock.Unlock()
+
+	for _, u := range stale {
+		l.listener.Notify(&ServiceEvent{Action: remoting.EventTypeDel, Service: *u.Clone(), Stale: true})
+	}
+}
+
+func (l *snapshotListener) save() {
+	l.lock.Lock()
+	l.saveTimer = nil
+	snapshot := &Snapshot{
+		Subscription: l.subscription,
+		UpdatedAt:    time.Now(),
+		Services:     make([]string, 0, len(l.services)),
+	}
+	for _, u := range l.services {
+		snapshot.Services = append(snapshot.Services, u.String())
+	}
+	l.lock.Unlock()
+
+	if err := l.cache.Save(snapshot); err != nil {
+		logger.Warnf("save the snapshot of subscription{%s} = error{%v}", l.subscription, err)
+	}
+}
+
+// close stops the timers and saves the pending snapshot
+func (l *snapshotListener) close() {
+	l.lock.Lock()
+	l.closed = true
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	pending := l.saveTimer != nil && l.saveTimer.Stop()
+	l.lock.Unlock()
+
+	if pending {
+		l.save()
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting"
+)
+
+func init() {
+	logger.InitLogger(nil)
+	snapshotSaveDelay = time.Millisecond
+}
+
+type snapshotTestRegistry struct {
+	Registry
+	listener     NotifyListener
+	unsubscribed NotifyListener
+}
+
+func (r *snapshotTestRegistry) Subscribe(url *common.URL, listener NotifyListener) error {
+	r.listener = listener
+	return nil
+}
+
+func (r *snapshotTestRegistry) UnSubscribe(url *common.URL, listener NotifyListener) error {
+	r.unsubscribed = listener
+	return nil
+}
+
+type snapshotTestListener struct {
+	sync.Mutex
+	events []*ServiceEvent
+}
+
+func (l *snapshotTestListener) Notify(event *ServiceEvent) {
+	l.Lock()
+	defer l.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *snapshotTestListener) take() []*ServiceEvent {
+	l.Lock()
+	defer l.Unlock()
+	events := l.events
+	l.events = nil
+	return events
+}
+
+func TestSnapshotRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache, err := NewSnapshotCache(dir)
+	assert.NoError(t, err)
+
+	subscription, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider")
+	provider1, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider")
+	provider2, _ := common.NewURL("dubbo://127.0.0.2:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider")
+
+	// the services notified by the registry are saved
+	r := &snapshotTestRegistry{}
+	listener := &snapshotTestListener{}
+	registry := NewSnapshotRegistry(r, cache, 10*time.Millisecond)
+	assert.NoError(t, registry.Subscribe(&subscription, listener))
+	r.listener.Notify(&ServiceEvent{Action: remoting.EventTypeAdd, Service: *provider1.Clone()})
+	r.listener.Notify(&ServiceEvent{Action: remoting.EventTypeAdd, Service: *provider2.Clone()})
+	assert.NoError(t, registry.UnSubscribe(&subscription, listener))
+	assert.Len(t, listener.take(), 2)
+	snapshot, err := cache.Load(subscription.ServiceKey())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{provider1.String(), provider2.String()}, snapshot.Services)
+
+	// the snapshot is notified if the registry notifies nothing in time
+	r = &snapshotTestRegistry{}
+	registry = NewSnapshotRegistry(r, cache, 10*time.Millisecond)
+	assert.NoError(t, registry.Subscribe(&subscription, listener))
+	time.Sleep(50 * time.Millisecond)
+	events := listener.take()
+	assert.Len(t, events, 2)
+	for _, e := range events {
+		assert.True(t, e.Stale)
+		assert.EqualValues(t, remoting.EventTypeAdd, e.Action)
+	}
+
+	// the stale services are deleted if the registry doesn't notify them after it recovers
+	r.listener.Notify(&ServiceEvent{Action: remoting.EventTypeAdd, Service: *provider1.Clone()})
+	time.Sleep(50 * time.Millisecond)
+	events = listener.take()
+	assert.Len(t, events, 2)
+	assert.False(t, events[0].Stale)
+	assert.True(t, events[1].Stale)
+	assert.EqualValues(t, remoting.EventTypeDel, events[1].Action)
+	assert.Equal(t, provider2.Key(), events[1].Service.Key())
+	assert.NoError(t, registry.UnSubscribe(&subscription, listener))
+
+	snapshot, err = cache.Load(subscription.ServiceKey())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{provider1.String()}, snapshot.Services)
+}
+
+type snapshotFuncListener func(event *ServiceEvent)
+
+func (f snapshotFuncListener) Notify(event *ServiceEvent) {
+	f(event)
+}
+
+func TestSnapshotRegistrySameListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache, err := NewSnapshotCache(dir)
+	assert.NoError(t, err)
+
+	user, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider")
+	order, _ := common.NewURL("consumer://127.0.0.1/com.ikurento.user.OrderProvider?interface=com.ikurento.user.OrderProvider")
+	r := &snapshotTestRegistry{}
+	listener := &snapshotTestListener{}
+	registry := NewSnapshotRegistry(r, cache, time.Hour)
+	assert.NoError(t, registry.Subscribe(&user, listener))
+	userListener := r.listener
+	assert.NoError(t, registry.Subscribe(&order, listener))
+	orderListener := r.listener
+	assert.True(t, userListener != orderListener)
+
+	// every subscription of the listener unsubscribes its own wrapper
+	assert.NoError(t, registry.UnSubscribe(&user, listener))
+	assert.True(t, r.unsubscribed == userListener)
+	assert.NoError(t, registry.UnSubscribe(&order, listener))
+	assert.True(t, r.unsubscribed == orderListener)
+
+	// the listener not comparable can not be unsubscribed
+	assert.Error(t, registry.Subscribe(&user, snapshotFuncListener(func(*ServiceEvent) {})))
+}
+
+func TestSnapshotCacheLoadMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache, err := NewSnapshotCache(dir)
+	assert.NoError(t, err)
+	snapshot, err := cache.Load("com.ikurento.user.UserProvider")
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
@@ -80,8 +80,10 @@ func (l *RegistryDataListener) DataChange(eventType remoting.Event) bool {
 	if l.closed {
 		return false
 	}
+	// the event is sent to the listener of its service key and the wildcard listeners it matches
+	matched := false
 	for serviceKey, listener := range l.subscribed {
-		if serviceURL.ServiceKey() == serviceKey {
+		if serviceURL.ServiceKey() == serviceKey || isWildcardMatch(listener, &serviceURL) {
 			listener.Process(
 				&config_center.ConfigChangeEvent{
 					Key:        eventType.Path,
@@ -89,10 +91,15 @@ func (l *RegistryDataListener) DataChange(eventType remoting.Event) bool {
 					ConfigType: eventType.Action,
 				},
 			)
-			return true
+			matched = true
 		}
 	}
-	return false
+	return matched
+}
+
+func isWildcardMatch(listener config_center.ConfigurationListener, serviceURL *common.URL) bool {
+	l, ok := listener.(*RegistryConfigurationListener)
+	return ok && l.subscribeURL.IsAnyService() && l.subscribeURL.MatchService(serviceURL)
 }
 
 // Close all RegistryConfigurationListener in subscribed
@@ -39,3 +39,26 @@ type MockConfigurationListener struct {
 
 func (*MockConfigurationListener) Process(configType *config_center.ConfigChangeEvent) {
 }
+
+func Test_DataChangeWildcard(t *testing.T) {
+	listener := NewRegistryDataListener()
+	newListener := func(rawURL string) *RegistryConfigurationListener {
+		url, _ := common.NewURL(rawURL)
+		l := &RegistryConfigurationListener{subscribeURL: &url, events: make(chan *config_center.ConfigChangeEvent, 2)}
+		listener.SubscribeURL(&url, l)
+		return l
+	}
+	all := newListener("consumer://127.0.0.1/*?interface=*")
+	group := newListener("consumer://127.0.0.1/*?interface=*&group=gray")
+	service := newListener("consumer://127.0.0.1/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider")
+
+	assert.True(t, listener.DataChange(remoting.Event{Path: "/dubbo/com.ikurento.user.UserProvider/providers/dubbo%3A%2F%2F127.0.0.1%3A20000%2Fcom.ikurento.user.UserProvider%3Finterface%3Dcom.ikurento.user.UserProvider"}))
+	assert.Len(t, all.events, 1)
+	assert.Len(t, group.events, 0)
+	assert.Len(t, service.events, 1)
+
+	assert.True(t, listener.DataChange(remoting.Event{Path: "/dubbo/com.ikurento.user.OrderProvider/providers/dubbo%3A%2F%2F127.0.0.1%3A20000%2Fcom.ikurento.user.OrderProvider%3Finterface%3Dcom.ikurento.user.OrderProvider%26group%3Dgray"}))
+	assert.Len(t, all.events, 2)
+	assert.Len(t, group.events, 1)
+	assert.Len(t, service.events, 1)
+}
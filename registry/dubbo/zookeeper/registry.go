@@ -18,6 +18,7 @@
 package zookeeper
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"path"
@@ -131,7 +132,7 @@ func (r *zkRegistry) InitListeners() {
 					regConfigListener.Close()
 				}
 				newDataListener.SubscribeURL(regConfigListener.subscribeURL, NewRegistryConfigurationListener(r.client, r, regConfigListener.subscribeURL))
-				go r.listener.ListenServiceEvent(regConfigListener.subscribeURL, fmt.Sprintf("/dubbo/%s/"+constant.DEFAULT_CATEGORY, url.QueryEscape(regConfigListener.subscribeURL.Service())), newDataListener)
+				r.listenServiceEvent(regConfigListener.subscribeURL, newDataListener)
 
 			}
 		}
@@ -188,6 +189,40 @@ func (r *zkRegistry) ZkClientLock() *sync.Mutex {
 	return &r.cltLock
 }
 
+// DoHealthCheck checks the existence of the root node
+func (r *zkRegistry) DoHealthCheck(ctx context.Context) error {
+	r.cltLock.Lock()
+	client := r.client
+	r.cltLock.Unlock()
+	if client == nil {
+		return zookeeper.ErrNotConnected
+	}
+	_, err := client.HealthCheck(ctx)
+	return err
+}
+
+// DoDumpWatches returns the listened dubbo paths with the connection state of the client
+func (r *zkRegistry) DoDumpWatches() []registry.WatchDump {
+	r.listenerLock.Lock()
+	listener := r.listener
+	r.listenerLock.Unlock()
+	if listener == nil {
+		return nil
+	}
+	state := zookeeper.ConnStateClosed
+	r.cltLock.Lock()
+	if r.client != nil {
+		state = r.client.ConnState()
+	}
+	r.cltLock.Unlock()
+	paths := listener.ListenedPaths()
+	watches := make([]registry.WatchDump, 0, len(paths))
+	for _, p := range paths {
+		watches = append(watches, registry.WatchDump{Path: p, State: state.String()})
+	}
+	return watches
+}
+
 // CloseListener closes listeners
 func (r *zkRegistry) CloseListener() {
 	if r.dataListener != nil {
@@ -209,15 +244,19 @@ func (r *zkRegistry) registerTempZookeeperNode(root string, node string) error {
 		return perrors.WithStack(err)
 	}
 
+	data, err := r.nodeContent(node)
+	if err != nil {
+		return err
+	}
 	// try to register the node
-	zkPath, err = r.client.RegisterTemp(root, node)
+	zkPath, err = r.client.RegisterTempWithValue(root, node, data)
 	if err != nil {
 		logger.Errorf("Register temp node(root{%s}, node{%s}) = error{%v}", root, node, perrors.WithStack(err))
 		if perrors.Cause(err) == zk.ErrNodeExists {
 			// should delete the old node
 			logger.Info("Register temp node failed, try to delete the old and recreate  (root{%s}, node{%s}) , ignore!", root, node)
 			if err = r.client.Delete(zkPath); err == nil {
-				_, err = r.client.RegisterTemp(root, node)
+				_, err = r.client.RegisterTempWithValue(root, node, data)
 			}
 			if err != nil {
 				logger.Errorf("Recreate the temp node failed, (root{%s}, node{%s}) = error{%v}", root, node, perrors.WithStack(err))
@@ -230,6 +269,19 @@ func (r *zkRegistry) registerTempZookeeperNode(root string, node string) error {
 	return nil
 }
 
+// nodeContent returns the content of the registered node, which is the url encoded by the codec of the
+// registry.codec parameter, the content is empty if the parameter is absent as the url is the node name
+func (r *zkRegistry) nodeContent(node string) ([]byte, error) {
+	if r.GetParam(constant.REGISTRY_CODEC_KEY, "") == "" {
+		return []byte(""), nil
+	}
+	url, err := common.NewURL(node)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse node %s", node)
+	}
+	return r.client.Codec().Encode(&url)
+}
+
 func (r *zkRegistry) getListener(conf *common.URL) (*RegistryConfigurationListener, error) {
 
 	var zkListener *RegistryConfigurationListener
@@ -272,11 +324,21 @@ func (r *zkRegistry) getListener(conf *common.URL) (*RegistryConfigurationListen
 	//Interested register to dataconfig.
 	r.dataListener.SubscribeURL(conf, zkListener)
 
-	go r.listener.ListenServiceEvent(conf, fmt.Sprintf("/dubbo/%s/"+constant.DEFAULT_CATEGORY, url.QueryEscape(conf.Service())), r.dataListener)
+	r.listenServiceEvent(conf, r.dataListener)
 
 	return zkListener, nil
 }
 
+// listenServiceEvent listens the providers of the subscribed service, or of all the services
+// if the interface of @conf is the wildcard
+func (r *zkRegistry) listenServiceEvent(conf *common.URL, dataListener *RegistryDataListener) {
+	if conf.IsAnyService() {
+		r.listener.ListenServicesEvent(conf, "/dubbo", dataListener)
+		return
+	}
+	go r.listener.ListenServiceEvent(conf, fmt.Sprintf("/dubbo/%s/"+constant.DEFAULT_CATEGORY, url.QueryEscape(conf.Service())), dataListener)
+}
+
 func (r *zkRegistry) getCloseListener(conf *common.URL) (*RegistryConfigurationListener, error) {
 
 	var zkListener *RegistryConfigurationListener
@@ -0,0 +1,70 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"mosn.io/pkg/registry/dubbo/common"
+	"mosn.io/pkg/registry/dubbo/common/logger"
+	"mosn.io/pkg/registry/dubbo/remoting/zookeeper"
+)
+
+func init() {
+	logger.InitLogger(nil)
+}
+
+func TestRegisterAfterGiveUp(t *testing.T) {
+	// the invalid timeout fails every reconnect attempt
+	url, err := common.NewURL("registry://127.0.0.1:1?registry.timeout=invalid")
+	assert.Nil(t, err)
+	r := &zkRegistry{
+		zkPath: make(map[string]int),
+	}
+	r.InitBaseRegistry(&url, r)
+
+	gaveUp := make(chan error, 1)
+	policy := zookeeper.BackoffPolicy{
+		MaxAttempts: 1,
+		OnGiveUp: func(err error) {
+			gaveUp <- err
+		},
+	}
+	r.client, err = zookeeper.NewZookeeperClient(RegistryZkClient, []string{"127.0.0.1:1"}, time.Second, zookeeper.WithBackoffPolicy(policy))
+	assert.Nil(t, err)
+	r.WaitGroup().Add(1) //zk client start successful, then wg +1
+	client := r.client
+	go zookeeper.HandleClientRestart(r)
+	client.Close()
+
+	select {
+	case err := <-gaveUp:
+		assert.NotNil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnect does not give up")
+	}
+	assert.NotNil(t, r.ZkClient())
+	assert.False(t, r.ZkClient().ZkConnValid())
+
+	service, _ := common.NewURL("dubbo://127.0.0.1:20000/com.mosn.test.UserProvider?category=providers")
+	err = r.Register(&service)
+	assert.True(t, zookeeper.IsNotConnected(err), "unexpected error: %v", err)
+	r.Destroy()
+}
@@ -0,0 +1,188 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// atomicBox boxes the value, so atomic.Value can store nil interfaces and different dynamic types
+type atomicBox[T any] struct {
+	v T
+}
+
+// AtomicValue is a typed wrapper of atomic.Value, the zero value holds the zero value of T.
+type AtomicValue[T comparable] struct {
+	v atomic.Value
+}
+
+// NewAtomicValue returns an AtomicValue holds v.
+func NewAtomicValue[T comparable](v T) *AtomicValue[T] {
+	a := &AtomicValue[T]{}
+	a.Store(v)
+	return a
+}
+
+// Load atomically loads the value.
+func (a *AtomicValue[T]) Load() T {
+	b, _ := a.v.Load().(atomicBox[T])
+	return b.v
+}
+
+// Store atomically stores v.
+func (a *AtomicValue[T]) Store(v T) {
+	a.v.Store(atomicBox[T]{v})
+}
+
+// Swap atomically stores v and returns the old value.
+func (a *AtomicValue[T]) Swap(v T) T {
+	b, _ := a.v.Swap(atomicBox[T]{v}).(atomicBox[T])
+	return b.v
+}
+
+// CompareAndSwap atomically stores new if the current value equals old, and reports whether it is stored.
+func (a *AtomicValue[T]) CompareAndSwap(old, new T) bool {
+	if a.v.CompareAndSwap(atomicBox[T]{old}, atomicBox[T]{new}) {
+		return true
+	}
+	// the value is never stored, which holds the zero value
+	var zero T
+	return old == zero && a.v.CompareAndSwap(nil, atomicBox[T]{new})
+}
+
+// AtomicString is an atomic string, the zero value is an empty string.
+type AtomicString = AtomicValue[string]
+
+// NewAtomicString returns an AtomicString holds s.
+func NewAtomicString(s string) *AtomicString {
+	return NewAtomicValue(s)
+}
+
+// AtomicError is an atomic error, the zero value is a nil error.
+type AtomicError struct {
+	v atomic.Value
+}
+
+// NewAtomicError returns an AtomicError holds err.
+func NewAtomicError(err error) *AtomicError {
+	a := &AtomicError{}
+	a.Store(err)
+	return a
+}
+
+// Load atomically loads the error.
+func (a *AtomicError) Load() error {
+	b, _ := a.v.Load().(atomicBox[error])
+	return b.v
+}
+
+// Store atomically stores err.
+func (a *AtomicError) Store(err error) {
+	a.v.Store(atomicBox[error]{err})
+}
+
+// Swap atomically stores err and returns the old error.
+func (a *AtomicError) Swap(err error) error {
+	b, _ := a.v.Swap(atomicBox[error]{err}).(atomicBox[error])
+	return b.v
+}
+
+// CompareAndSwap atomically stores new if the current error equals old, and reports whether it is stored.
+// It panics if the errors are not comparable.
+func (a *AtomicError) CompareAndSwap(old, new error) bool {
+	if a.v.CompareAndSwap(atomicBox[error]{old}, atomicBox[error]{new}) {
+		return true
+	}
+	// the error is never stored, which holds nil
+	return old == nil && a.v.CompareAndSwap(nil, atomicBox[error]{new})
+}
+
+// AtomicDuration is an atomic time.Duration, the zero value is 0.
+type AtomicDuration struct {
+	v int64
+}
+
+// NewAtomicDuration returns an AtomicDuration holds d.
+func NewAtomicDuration(d time.Duration) *AtomicDuration {
+	return &AtomicDuration{v: int64(d)}
+}
+
+// Load atomically loads the duration.
+func (a *AtomicDuration) Load() time.Duration {
+	return time.Duration(atomic.LoadInt64(&a.v))
+}
+
+// Store atomically stores d.
+func (a *AtomicDuration) Store(d time.Duration) {
+	atomic.StoreInt64(&a.v, int64(d))
+}
+
+// Swap atomically stores d and returns the old duration.
+func (a *AtomicDuration) Swap(d time.Duration) time.Duration {
+	return time.Duration(atomic.SwapInt64(&a.v, int64(d)))
+}
+
+// CompareAndSwap atomically stores new if the current duration equals old, and reports whether it is stored.
+func (a *AtomicDuration) CompareAndSwap(old, new time.Duration) bool {
+	return atomic.CompareAndSwapInt64(&a.v, int64(old), int64(new))
+}
+
+// Add atomically adds delta to the duration and returns the new duration.
+func (a *AtomicDuration) Add(delta time.Duration) time.Duration {
+	return time.Duration(atomic.AddInt64(&a.v, int64(delta)))
+}
+
+// AtomicBool is an atomic bool, the zero value is false.
+type AtomicBool struct {
+	v uint32
+}
+
+// NewAtomicBool returns an AtomicBool holds b.
+func NewAtomicBool(b bool) *AtomicBool {
+	a := &AtomicBool{}
+	a.Store(b)
+	return a
+}
+
+// Load atomically loads the bool.
+func (a *AtomicBool) Load() bool {
+	return atomic.LoadUint32(&a.v) == 1
+}
+
+// Store atomically stores b.
+func (a *AtomicBool) Store(b bool) {
+	atomic.StoreUint32(&a.v, boolToUint32(b))
+}
+
+// Swap atomically stores b and returns the old bool.
+func (a *AtomicBool) Swap(b bool) bool {
+	return atomic.SwapUint32(&a.v, boolToUint32(b)) == 1
+}
+
+// CompareAndSwap atomically stores new if the current bool equals old, and reports whether it is stored.
+func (a *AtomicBool) CompareAndSwap(old, new bool) bool {
+	return atomic.CompareAndSwapUint32(&a.v, boolToUint32(old), boolToUint32(new))
+}
+
+func boolToUint32(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAtomicValue(t *testing.T) {
+	var s AtomicString
+	if s.Load() != "" {
+		t.Fatalf("zero value should be empty, but got: %s", s.Load())
+	}
+	if !s.CompareAndSwap("", "a") || s.Load() != "a" {
+		t.Fatalf("compare and swap the zero value failed: %s", s.Load())
+	}
+	if s.CompareAndSwap("b", "c") {
+		t.Fatal("compare and swap should fail if not equal")
+	}
+	if old := s.Swap("b"); old != "a" || s.Load() != "b" {
+		t.Fatalf("unexpected swap result: %s, %s", old, s.Load())
+	}
+
+	type config struct {
+		name    string
+		timeout int
+	}
+	c := NewAtomicValue(config{"a", 1})
+	if !c.CompareAndSwap(config{"a", 1}, config{"b", 2}) || c.Load().name != "b" {
+		t.Fatalf("compare and swap failed: %v", c.Load())
+	}
+}
+
+func TestAtomicValueConcurrent(t *testing.T) {
+	v := NewAtomicValue(0)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				old := v.Load()
+				if v.CompareAndSwap(old, old+1) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if v.Load() != 50 {
+		t.Errorf("expected 50, but got: %d", v.Load())
+	}
+}
+
+func TestAtomicError(t *testing.T) {
+	var e AtomicError
+	if e.Load() != nil {
+		t.Fatal("zero value should be nil")
+	}
+	err1 := errors.New("1")
+	if !e.CompareAndSwap(nil, err1) || e.Load() != err1 {
+		t.Fatalf("compare and swap the zero value failed: %v", e.Load())
+	}
+	if old := e.Swap(nil); old != err1 || e.Load() != nil {
+		t.Fatalf("unexpected swap result: %v, %v", old, e.Load())
+	}
+	e.Store(err1)
+	if NewAtomicError(err1).Load() != err1 {
+		t.Fatal("new atomic error failed")
+	}
+}
+
+func TestAtomicDurationAndBool(t *testing.T) {
+	d := NewAtomicDuration(time.Second)
+	if d.Add(time.Second) != 2*time.Second {
+		t.Fatalf("unexpected duration: %v", d.Load())
+	}
+	if !d.CompareAndSwap(2*time.Second, time.Minute) || d.Swap(0) != time.Minute || d.Load() != 0 {
+		t.Fatalf("unexpected duration: %v", d.Load())
+	}
+
+	var b AtomicBool
+	if b.Load() || !b.CompareAndSwap(false, true) || !b.Load() {
+		t.Fatal("unexpected bool compare and swap")
+	}
+	if !b.Swap(false) || b.Load() {
+		t.Fatal("unexpected bool swap")
+	}
+	if !NewAtomicBool(true).Load() {
+		t.Fatal("new atomic bool failed")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultClockResolution is the default update interval of the cached clock.
+const DefaultClockResolution = time.Millisecond
+
+var (
+	// cachedNow is the cached unix nano timestamp, updated by the clock goroutine
+	cachedNow int64
+
+	clockMux  sync.Mutex
+	clockStop chan struct{}
+	// clockDone is closed after the clock goroutine exits
+	clockDone chan struct{}
+)
+
+// NowCached returns a coarse current time, which is updated by a background goroutine
+// at the clock resolution instead of calling time.Now every time.
+// It is suitable for log timestamps and expire checks at high QPS, where the precision
+// of the resolution is acceptable. The clock is started at the first call with
+// DefaultClockResolution if SetClockResolution is not called.
+// The returned time has no monotonic clock reading.
+func NowCached() time.Time {
+	now := atomic.LoadInt64(&cachedNow)
+	if now == 0 {
+		clockMux.Lock()
+		if clockStop == nil {
+			startClockLocked(DefaultClockResolution)
+		}
+		clockMux.Unlock()
+		now = atomic.LoadInt64(&cachedNow)
+	}
+	return time.Unix(0, now)
+}
+
+// SetClockResolution (re)starts the cached clock with the update interval d.
+// A non-positive d means DefaultClockResolution.
+func SetClockResolution(d time.Duration) {
+	if d <= 0 {
+		d = DefaultClockResolution
+	}
+	clockMux.Lock()
+	defer clockMux.Unlock()
+	stopClockLocked()
+	startClockLocked(d)
+}
+
+func startClockLocked(d time.Duration) {
+	atomic.StoreInt64(&cachedNow, time.Now().UnixNano())
+	stop, done := make(chan struct{}), make(chan struct{})
+	clockStop, clockDone = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				atomic.StoreInt64(&cachedNow, now.UnixNano())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopCachedClock stops the background goroutine of the cached clock,
+// NowCached will start it again if called later.
+func StopCachedClock() {
+	clockMux.Lock()
+	defer clockMux.Unlock()
+	stopClockLocked()
+	atomic.StoreInt64(&cachedNow, 0)
+}
+
+// stopClockLocked waits for the clock goroutine to exit,
+// so cachedNow is never stored by the stopped clock after it returns.
+func stopClockLocked() {
+	if clockStop != nil {
+		close(clockStop)
+		<-clockDone
+		clockStop, clockDone = nil, nil
+	}
+}
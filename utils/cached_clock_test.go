@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNowCached(t *testing.T) {
+	defer StopCachedClock()
+	now := NowCached()
+	if d := time.Since(now); d < 0 || d > 100*time.Millisecond {
+		t.Fatalf("cached time is too far from now: %v", d)
+	}
+	SetClockResolution(10 * time.Millisecond)
+	first := NowCached()
+	time.Sleep(50 * time.Millisecond)
+	if second := NowCached(); !second.After(first) {
+		t.Errorf("cached time should be updated, first: %v, second: %v", first, second)
+	}
+
+	StopCachedClock()
+	stopped := NowCached()
+	if d := time.Since(stopped); d < 0 || d > 100*time.Millisecond {
+		t.Fatalf("cached clock should restart after stopped: %v", d)
+	}
+}
+
+func TestStopCachedClock(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		SetClockResolution(time.Microsecond)
+		StopCachedClock()
+		// the stopped clock never stores again, so the clock is restarted by NowCached
+		if now := atomic.LoadInt64(&cachedNow); now != 0 {
+			t.Fatalf("cached time is stored after stopped: %d", now)
+		}
+	}
+	if d := time.Since(NowCached()); d < 0 || d > 100*time.Millisecond {
+		t.Fatalf("cached clock should restart after stopped: %v", d)
+	}
+	StopCachedClock()
+}
+
+func BenchmarkNowCached(b *testing.B) {
+	defer StopCachedClock()
+	for n := 0; n < b.N; n++ {
+		NowCached()
+	}
+}
@@ -0,0 +1,299 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mosn.io/pkg/metrics"
+)
+
+// ErrCircuitOpen is returned when a call is rejected by an open CircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int32
+
+const (
+	// CircuitClosed means calls are allowed and their results are recorded.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means calls are rejected until the open timeout is reached.
+	CircuitOpen
+	// CircuitHalfOpen means a limited number of calls are allowed to probe the recovery.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig is the config of a CircuitBreaker, zero values are replaced by defaults.
+type CircuitBreakerConfig struct {
+	// FailureRateThreshold opens the circuit when the failure rate in the window
+	// is greater than or equal to it, in (0, 1]. Default is 0.5.
+	FailureRateThreshold float64
+	// SlowCallRateThreshold opens the circuit when the slow call rate in the window
+	// is greater than or equal to it, in (0, 1]. Default is 1, which means only when all the calls are slow.
+	SlowCallRateThreshold float64
+	// SlowCallDuration is the duration a call is considered slow, zero means never slow.
+	SlowCallDuration time.Duration
+	// WindowSize is the number of recent calls recorded in closed state. Default is 100.
+	WindowSize int
+	// MinimumCalls is the minimum number of recorded calls before the rates are evaluated. Default is 10.
+	MinimumCalls int
+	// OpenTimeout is the duration the circuit stays open before half-open. Default is 30s.
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of calls allowed in half-open state, the rates are evaluated
+	// once all their results are recorded. Default is 10.
+	HalfOpenMaxCalls int
+	// IsFailure reports whether a call result is a failure, nil means any non-nil error.
+	IsFailure func(err error) bool
+	// OnStateChange is called after the state changed, it is called without lock held.
+	OnStateChange func(from, to CircuitState)
+	// Name names the metrics reported to the global sink of the metrics package, which are the gauge
+	// circuit_breaker.{name}.state, the counter circuit_breaker.{name}.rejected and the counters
+	// circuit_breaker.{name}.{state} of the state changes. Default is "breaker-{n}", where n is unique
+	// in the process, so the unnamed circuit breakers never share their metrics.
+	Name string
+}
+
+// circuitBreakerSeq numbers the unnamed circuit breakers
+var circuitBreakerSeq uint64
+
+// CircuitBreaker stops calling a resource which keeps failing or responding slowly,
+// and probes its recovery after a while.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	stateGauge metrics.Gauge
+	rejected   metrics.Counter
+
+	mux        sync.Mutex
+	state      CircuitState
+	generation uint64
+	openedAt   time.Time
+	// window records the outcomes of recent calls
+	window   []callOutcome
+	next     int
+	count    int
+	failures int
+	slows    int
+	// inflight is the number of allowed calls in half-open state
+	inflight int
+	// probes is the number of recorded results in half-open state
+	probes int
+}
+
+type callOutcome struct {
+	failure bool
+	slow    bool
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = 0.5
+	}
+	if config.SlowCallRateThreshold <= 0 {
+		config.SlowCallRateThreshold = 1
+	}
+	if config.WindowSize <= 0 {
+		config.WindowSize = 100
+	}
+	if config.MinimumCalls <= 0 {
+		config.MinimumCalls = 10
+	}
+	if config.MinimumCalls > config.WindowSize {
+		config.MinimumCalls = config.WindowSize
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMaxCalls <= 0 {
+		config.HalfOpenMaxCalls = 10
+	}
+	if config.Name == "" {
+		config.Name = "breaker-" + strconv.FormatUint(atomic.AddUint64(&circuitBreakerSeq, 1), 10)
+	}
+	cb := &CircuitBreaker{
+		config:     config,
+		stateGauge: metrics.NewGauge("circuit_breaker." + config.Name + ".state"),
+		rejected:   metrics.NewCounter("circuit_breaker." + config.Name + ".rejected"),
+		state:      CircuitClosed,
+		window:     make([]callOutcome, config.WindowSize),
+	}
+	cb.stateGauge.Set(float64(CircuitClosed))
+	return cb
+}
+
+// State returns the current state of the circuit breaker.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mux.Lock()
+	from := cb.state
+	cb.refresh(time.Now())
+	to := cb.state
+	cb.mux.Unlock()
+	cb.notify(from, to)
+	return to
+}
+
+// Allow checks whether a call is permitted. If permitted, the returned done function
+// must be called with the call result exactly once, otherwise ErrCircuitOpen is returned.
+func (cb *CircuitBreaker) Allow() (done func(err error), err error) {
+	now := time.Now()
+	cb.mux.Lock()
+	from := cb.state
+	cb.refresh(now)
+	to := cb.state
+	if cb.state == CircuitOpen || (cb.state == CircuitHalfOpen && cb.inflight >= cb.config.HalfOpenMaxCalls) {
+		cb.mux.Unlock()
+		cb.notify(from, to)
+		cb.rejected.Inc()
+		return nil, ErrCircuitOpen
+	}
+	if cb.state == CircuitHalfOpen {
+		cb.inflight++
+	}
+	generation := cb.generation
+	cb.mux.Unlock()
+	cb.notify(from, to)
+
+	return func(err error) {
+		cb.record(generation, time.Since(now), err)
+	}, nil
+}
+
+// Execute calls fn if permitted and records its result.
+// It returns ErrCircuitOpen without calling fn if the call is rejected.
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err)
+	return err
+}
+
+func (cb *CircuitBreaker) record(generation uint64, d time.Duration, err error) {
+	outcome := callOutcome{
+		slow: cb.config.SlowCallDuration > 0 && d >= cb.config.SlowCallDuration,
+	}
+	if cb.config.IsFailure != nil {
+		outcome.failure = cb.config.IsFailure(err)
+	} else {
+		outcome.failure = err != nil
+	}
+
+	cb.mux.Lock()
+	from := cb.state
+	// ignore the result of a call allowed in a previous state
+	if generation == cb.generation && cb.state != CircuitOpen {
+		cb.add(outcome)
+		switch cb.state {
+		case CircuitClosed:
+			if cb.count >= cb.config.MinimumCalls && cb.exceeded() {
+				cb.setState(CircuitOpen, time.Now())
+			}
+		case CircuitHalfOpen:
+			cb.probes++
+			if cb.probes < cb.config.HalfOpenMaxCalls {
+				break
+			}
+			if cb.exceeded() {
+				cb.setState(CircuitOpen, time.Now())
+			} else {
+				cb.setState(CircuitClosed, time.Now())
+			}
+		}
+	}
+	to := cb.state
+	cb.mux.Unlock()
+	cb.notify(from, to)
+}
+
+func (cb *CircuitBreaker) add(outcome callOutcome) {
+	if cb.count == len(cb.window) {
+		old := cb.window[cb.next]
+		if old.failure {
+			cb.failures--
+		}
+		if old.slow {
+			cb.slows--
+		}
+	} else {
+		cb.count++
+	}
+	cb.window[cb.next] = outcome
+	cb.next = (cb.next + 1) % len(cb.window)
+	if outcome.failure {
+		cb.failures++
+	}
+	if outcome.slow {
+		cb.slows++
+	}
+}
+
+func (cb *CircuitBreaker) exceeded() bool {
+	if cb.count == 0 {
+		return false
+	}
+	total := float64(cb.count)
+	return float64(cb.failures)/total >= cb.config.FailureRateThreshold ||
+		float64(cb.slows)/total >= cb.config.SlowCallRateThreshold
+}
+
+// refresh moves an open circuit to half-open once the open timeout is reached.
+func (cb *CircuitBreaker) refresh(now time.Time) {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.setState(CircuitHalfOpen, now)
+	}
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	cb.state = state
+	cb.generation++
+	cb.count, cb.next, cb.failures, cb.slows, cb.inflight, cb.probes = 0, 0, 0, 0, 0, 0
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+}
+
+func (cb *CircuitBreaker) notify(from, to CircuitState) {
+	if from == to {
+		return
+	}
+	cb.stateGauge.Set(float64(to))
+	metrics.IncrCounter("circuit_breaker."+cb.config.Name+"."+to.String(), 1)
+	if cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(from, to)
+	}
+}
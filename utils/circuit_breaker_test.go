@@ -0,0 +1,188 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mosn.io/pkg/metrics"
+)
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	changes := []CircuitState{}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureRateThreshold: 0.5,
+		WindowSize:           10,
+		MinimumCalls:         4,
+		OpenTimeout:          50 * time.Millisecond,
+		HalfOpenMaxCalls:     2,
+		OnStateChange: func(from, to CircuitState) {
+			changes = append(changes, to)
+		},
+	})
+	failed := errors.New("failed")
+	cb.Execute(func() error { return nil })
+	cb.Execute(func() error { return failed })
+	cb.Execute(func() error { return nil })
+	if cb.State() != CircuitClosed {
+		t.Fatalf("expected closed, but got: %v", cb.State())
+	}
+	cb.Execute(func() error { return failed })
+	if cb.State() != CircuitOpen {
+		t.Fatalf("expected open, but got: %v", cb.State())
+	}
+	called := false
+	if err := cb.Execute(func() error { called = true; return nil }); err != ErrCircuitOpen || called {
+		t.Fatalf("call should be rejected, but got: %v, %v", err, called)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open, but got: %v", cb.State())
+	}
+	done1, err1 := cb.Allow()
+	done2, err2 := cb.Allow()
+	if _, err := cb.Allow(); err1 != nil || err2 != nil || err != ErrCircuitOpen {
+		t.Fatalf("half-open should allow 2 calls only, but got: %v, %v, %v", err1, err2, err)
+	}
+	done1(nil)
+	done2(nil)
+	if cb.State() != CircuitClosed {
+		t.Fatalf("expected closed, but got: %v", cb.State())
+	}
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected state changes %v, but got: %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Fatalf("expected state changes %v, but got: %v", expected, changes)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		MinimumCalls:     1,
+		OpenTimeout:      10 * time.Millisecond,
+		HalfOpenMaxCalls: 3,
+	})
+	cb.Execute(func() error { return errors.New("failed") })
+	time.Sleep(20 * time.Millisecond)
+	// the rates are evaluated after all the probes are recorded
+	cb.Execute(func() error { return errors.New("failed") })
+	cb.Execute(func() error { return errors.New("failed") })
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open, but got: %v", cb.State())
+	}
+	cb.Execute(func() error { return nil })
+	if cb.State() != CircuitOpen {
+		t.Fatalf("expected open, but got: %v", cb.State())
+	}
+}
+
+func TestCircuitBreakerHalfOpenRecovery(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		MinimumCalls:     1,
+		OpenTimeout:      10 * time.Millisecond,
+		HalfOpenMaxCalls: 3,
+	})
+	cb.Execute(func() error { return errors.New("failed") })
+	time.Sleep(20 * time.Millisecond)
+	// a failed first probe doesn't open the circuit, as the failure rate of the probes is below the threshold
+	cb.Execute(func() error { return errors.New("failed") })
+	cb.Execute(func() error { return nil })
+	cb.Execute(func() error { return nil })
+	if cb.State() != CircuitClosed {
+		t.Fatalf("expected closed, but got: %v", cb.State())
+	}
+}
+
+func TestCircuitBreakerSlowCall(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		SlowCallRateThreshold: 0.5,
+		SlowCallDuration:      10 * time.Millisecond,
+		MinimumCalls:          2,
+	})
+	cb.Execute(func() error { return nil })
+	cb.Execute(func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	if cb.State() != CircuitOpen {
+		t.Fatalf("expected open, but got: %v", cb.State())
+	}
+}
+
+func TestCircuitBreakerStaleResult(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		MinimumCalls: 1,
+		OpenTimeout:  10 * time.Millisecond,
+	})
+	done, _ := cb.Allow()
+	cb.Execute(func() error { return errors.New("failed") })
+	time.Sleep(20 * time.Millisecond)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open, but got: %v", cb.State())
+	}
+	// the result of a call allowed in closed state should be ignored
+	done(errors.New("failed"))
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open, but got: %v", cb.State())
+	}
+}
+
+func TestCircuitBreakerMetrics(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
+
+	cb := NewCircuitBreaker(CircuitBreakerConfig{MinimumCalls: 1, Name: "test"})
+	cb.Execute(func() error { return errors.New("failed") })
+	if err := cb.Execute(func() error { return nil }); err != ErrCircuitOpen {
+		t.Fatalf("expected ErrCircuitOpen, but got: %v", err)
+	}
+	snapshot := sink.Snapshot()
+	if snapshot.Gauges["circuit_breaker.test.state"] != float64(CircuitOpen) {
+		t.Fatalf("expected open state gauge, but got: %v", snapshot.Gauges["circuit_breaker.test.state"])
+	}
+	if snapshot.Counters["circuit_breaker.test.open"] != 1 || snapshot.Counters["circuit_breaker.test.rejected"] != 1 {
+		t.Fatalf("unexpected counters: %v", snapshot.Counters)
+	}
+}
+
+func TestCircuitBreakerDefaultName(t *testing.T) {
+	sink := metrics.NewMemorySink()
+	metrics.SetSink(sink)
+	defer metrics.SetSink(metrics.Default())
+
+	failing := NewCircuitBreaker(CircuitBreakerConfig{MinimumCalls: 1})
+	healthy := NewCircuitBreaker(CircuitBreakerConfig{MinimumCalls: 1})
+	if failing.config.Name == healthy.config.Name {
+		t.Fatalf("unnamed circuit breakers should have unique names, but got: %s", failing.config.Name)
+	}
+	failing.Execute(func() error { return errors.New("failed") })
+	healthy.Execute(func() error { return nil })
+	snapshot := sink.Snapshot()
+	if snapshot.Gauges["circuit_breaker."+failing.config.Name+".state"] != float64(CircuitOpen) ||
+		snapshot.Gauges["circuit_breaker."+healthy.config.Name+".state"] != float64(CircuitClosed) {
+		t.Fatalf("unexpected gauges: %v", snapshot.Gauges)
+	}
+}
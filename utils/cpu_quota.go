@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const gomaxprocsEnv = "GOMAXPROCS"
+
+var (
+	// cgroupRoot is the mount point of the cgroup file system
+	cgroupRoot = "/sys/fs/cgroup"
+	// procSelfCgroup is the cgroup file of the current process
+	procSelfCgroup = "/proc/self/cgroup"
+
+	ErrNoCPUQuota = errors.New("no cpu quota is detected")
+)
+
+// CPUQuota is the cpu limit detected from cgroup.
+type CPUQuota struct {
+	// Quota is the cpu quota in each period, in microseconds.
+	Quota int64
+	// Period is the length of the period, in microseconds.
+	Period int64
+}
+
+// CPUs returns the cpu limit of the quota, which may be fractional.
+func (q CPUQuota) CPUs() float64 {
+	return float64(q.Quota) / float64(q.Period)
+}
+
+// DetectCPUQuota reads the cpu quota of the current process from cgroup v2 or v1.
+// It returns ErrNoCPUQuota if the cpu is not limited.
+func DetectCPUQuota() (CPUQuota, error) {
+	paths, err := readCgroupPaths(procSelfCgroup)
+	if err != nil {
+		return CPUQuota{}, err
+	}
+	// cgroup v2, hierarchy id is 0 and controller is empty
+	if p, ok := paths[""]; ok {
+		if q, err := readCgroupV2Quota(filepath.Join(cgroupRoot, p, "cpu.max")); err != ErrNoCPUQuota {
+			return q, err
+		}
+		// the process may be in a namespace which the path is the root
+		if q, err := readCgroupV2Quota(filepath.Join(cgroupRoot, "cpu.max")); err != ErrNoCPUQuota {
+			return q, err
+		}
+	}
+	if p, ok := paths["cpu"]; ok {
+		for _, dir := range []string{filepath.Join(cgroupRoot, "cpu", p), filepath.Join(cgroupRoot, "cpu")} {
+			q, err := readCgroupV1Quota(dir)
+			if err != ErrNoCPUQuota {
+				return q, err
+			}
+		}
+	}
+	return CPUQuota{}, ErrNoCPUQuota
+}
+
+// AdjustGOMAXPROCS sets GOMAXPROCS to the cpu quota of the container rounded up,
+// and at least 1, which avoids the cpu throttling when the cpu limit is less than the host cpus.
+// It does nothing if the GOMAXPROCS environment variable is set or the cpu is not limited.
+// It returns the GOMAXPROCS value after adjusted.
+func AdjustGOMAXPROCS() (int, error) {
+	current := runtime.GOMAXPROCS(0)
+	if _, ok := os.LookupEnv(gomaxprocsEnv); ok {
+		return current, nil
+	}
+	quota, err := DetectCPUQuota()
+	if err != nil {
+		if err == ErrNoCPUQuota {
+			return current, nil
+		}
+		return current, err
+	}
+	procs := int(math.Ceil(quota.CPUs()))
+	if procs < 1 {
+		procs = 1
+	}
+	if procs < current {
+		runtime.GOMAXPROCS(procs)
+		return procs, nil
+	}
+	return current, nil
+}
+
+// readCgroupPaths returns the cgroup paths of the process keyed by the controller
+func readCgroupPaths(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	paths := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[1] == "" {
+			paths[""] = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			paths[controller] = fields[2]
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// readCgroupV2Quota reads the cpu.max file in format "$MAX $PERIOD", MAX may be "max"
+func readCgroupV2Quota(path string) (CPUQuota, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return CPUQuota{}, ErrNoCPUQuota
+		}
+		return CPUQuota{}, err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 || len(fields) > 2 {
+		return CPUQuota{}, errors.New("invalid cpu.max format: " + string(b))
+	}
+	if fields[0] == "max" {
+		return CPUQuota{}, ErrNoCPUQuota
+	}
+	q := CPUQuota{Period: 100000}
+	if q.Quota, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return CPUQuota{}, err
+	}
+	if len(fields) == 2 {
+		if q.Period, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return CPUQuota{}, err
+		}
+	}
+	if q.Quota <= 0 || q.Period <= 0 {
+		return CPUQuota{}, ErrNoCPUQuota
+	}
+	return q, nil
+}
+
+// readCgroupV1Quota reads the cpu.cfs_quota_us and cpu.cfs_period_us in the dir, quota is -1 if not limited
+func readCgroupV1Quota(dir string) (CPUQuota, error) {
+	quota, err := readInt64File(filepath.Join(dir, "cpu.cfs_quota_us"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return CPUQuota{}, ErrNoCPUQuota
+		}
+		return CPUQuota{}, err
+	}
+	if quota <= 0 {
+		return CPUQuota{}, ErrNoCPUQuota
+	}
+	period, err := readInt64File(filepath.Join(dir, "cpu.cfs_period_us"))
+	if err != nil {
+		return CPUQuota{}, err
+	}
+	if period <= 0 {
+		return CPUQuota{}, ErrNoCPUQuota
+	}
+	return CPUQuota{Quota: quota, Period: period}, nil
+}
+
+func readInt64File(path string) (int64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
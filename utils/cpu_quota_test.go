@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func mockCgroup(t *testing.T, cgroup string, files map[string]string) func() {
+	dir := t.TempDir()
+	oldRoot, oldSelf := cgroupRoot, procSelfCgroup
+	cgroupRoot = filepath.Join(dir, "cgroup")
+	procSelfCgroup = filepath.Join(dir, "self_cgroup")
+	if err := os.WriteFile(procSelfCgroup, []byte(cgroup), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		p := filepath.Join(cgroupRoot, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		cgroupRoot, procSelfCgroup = oldRoot, oldSelf
+	}
+}
+
+func TestDetectCPUQuotaV2(t *testing.T) {
+	defer mockCgroup(t, "0::/pod/container\n", map[string]string{
+		"pod/container/cpu.max": "150000 100000\n",
+	})()
+	q, err := DetectCPUQuota()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.CPUs() != 1.5 {
+		t.Errorf("expected 1.5 cpus, but got: %v", q.CPUs())
+	}
+}
+
+func TestDetectCPUQuotaV2Unlimited(t *testing.T) {
+	defer mockCgroup(t, "0::/\n", map[string]string{
+		"cpu.max": "max 100000\n",
+	})()
+	if _, err := DetectCPUQuota(); err != ErrNoCPUQuota {
+		t.Errorf("expected no cpu quota, but got: %v", err)
+	}
+}
+
+func TestDetectCPUQuotaV1(t *testing.T) {
+	defer mockCgroup(t, "4:memory:/docker/abc\n3:cpu,cpuacct:/docker/abc\n", map[string]string{
+		"cpu/docker/abc/cpu.cfs_quota_us":  "50000\n",
+		"cpu/docker/abc/cpu.cfs_period_us": "100000\n",
+	})()
+	q, err := DetectCPUQuota()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Quota != 50000 || q.Period != 100000 || q.CPUs() != 0.5 {
+		t.Errorf("unexpected quota: %+v", q)
+	}
+}
+
+func TestDetectCPUQuotaV1Unlimited(t *testing.T) {
+	defer mockCgroup(t, "3:cpu,cpuacct:/\n", map[string]string{
+		"cpu/cpu.cfs_quota_us":  "-1\n",
+		"cpu/cpu.cfs_period_us": "100000\n",
+	})()
+	if _, err := DetectCPUQuota(); err != ErrNoCPUQuota {
+		t.Errorf("expected no cpu quota, but got: %v", err)
+	}
+}
+
+func TestAdjustGOMAXPROCS(t *testing.T) {
+	if _, ok := os.LookupEnv(gomaxprocsEnv); ok {
+		t.Skip("GOMAXPROCS is set by environment")
+	}
+	current := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(current)
+	defer mockCgroup(t, "0::/\n", map[string]string{
+		"cpu.max": "50000 100000\n",
+	})()
+	procs, err := AdjustGOMAXPROCS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if procs != 1 || runtime.GOMAXPROCS(0) != 1 {
+		t.Errorf("expected GOMAXPROCS 1, but got: %d, %d", procs, runtime.GOMAXPROCS(0))
+	}
+}
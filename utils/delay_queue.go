@@ -0,0 +1,168 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue is an unbounded queue, an item can be taken only after it is ready.
+// Items are ordered by the ready time in a min-heap.
+type DelayQueue[T any] struct {
+	mux   sync.Mutex
+	items delayHeap[T]
+	seq   uint64
+	// wakeup notifies the waiting Take that an earlier item is offered
+	wakeup chan struct{}
+}
+
+type delayItem[T any] struct {
+	value   T
+	readyAt time.Time
+	// seq keeps the FIFO order for the items with the same ready time
+	seq uint64
+}
+
+type delayHeap[T any] []delayItem[T]
+
+func (h delayHeap[T]) Len() int { return len(h) }
+
+func (h delayHeap[T]) Less(i, j int) bool {
+	if h[i].readyAt.Equal(h[j].readyAt) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].readyAt.Before(h[j].readyAt)
+}
+
+func (h delayHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *delayHeap[T]) Push(x interface{}) { *h = append(*h, x.(delayItem[T])) }
+
+func (h *delayHeap[T]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = delayItem[T]{}
+	*h = old[:n-1]
+	return item
+}
+
+// NewDelayQueue returns an empty DelayQueue.
+func NewDelayQueue[T any]() *DelayQueue[T] {
+	return &DelayQueue[T]{
+		wakeup: make(chan struct{}, 1),
+	}
+}
+
+// Offer adds the value into the queue, which is ready at readyAt.
+func (q *DelayQueue[T]) Offer(v T, readyAt time.Time) {
+	q.mux.Lock()
+	q.seq++
+	heap.Push(&q.items, delayItem[T]{value: v, readyAt: readyAt, seq: q.seq})
+	earliest := q.items[0].seq == q.seq
+	q.mux.Unlock()
+	if earliest {
+		select {
+		case q.wakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// OfferAfter adds the value into the queue, which is ready after the duration d.
+func (q *DelayQueue[T]) OfferAfter(v T, d time.Duration) {
+	q.Offer(v, time.Now().Add(d))
+}
+
+// Poll takes the earliest ready value without blocking, and reports whether there is a ready value.
+func (q *DelayQueue[T]) Poll() (T, bool) {
+	v, _, ok := q.poll(time.Now())
+	return v, ok
+}
+
+// poll returns the ready value, or the duration to wait for the earliest value
+// which is negative if the queue is empty.
+func (q *DelayQueue[T]) poll(now time.Time) (v T, wait time.Duration, ok bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if len(q.items) == 0 {
+		return v, -1, false
+	}
+	if wait = q.items[0].readyAt.Sub(now); wait > 0 {
+		return v, wait, false
+	}
+	item := heap.Pop(&q.items).(delayItem[T])
+	// let the other waiting Take recalculate the wait duration for the new earliest value
+	if len(q.items) > 0 {
+		select {
+		case q.wakeup <- struct{}{}:
+		default:
+		}
+	}
+	return item.value, 0, true
+}
+
+// Take takes the earliest value, blocking until it is ready or the context is done.
+// It returns the context error if the context is done before a value is ready.
+// Concurrent Take calls are safe, each ready value is taken only once.
+func (q *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		v, wait, ok := q.poll(time.Now())
+		if ok {
+			return v, nil
+		}
+		var timeout <-chan time.Time
+		if wait > 0 {
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(wait)
+			}
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-q.wakeup:
+		case <-timeout:
+		}
+	}
+}
+
+// Len returns the number of values in the queue, including the values not ready.
+func (q *DelayQueue[T]) Len() int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	return len(q.items)
+}
@@ -0,0 +1,135 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDelayQueueOrder(t *testing.T) {
+	q := NewDelayQueue[int]()
+	now := time.Now()
+	q.Offer(3, now.Add(-time.Second))
+	q.Offer(1, now.Add(-3*time.Second))
+	q.Offer(2, now.Add(-2*time.Second))
+	q.Offer(4, now.Add(-time.Second))
+	q.Offer(5, now.Add(time.Hour))
+	for i := 1; i <= 4; i++ {
+		v, ok := q.Poll()
+		if !ok || v != i {
+			t.Fatalf("expected %d, but got: %d, %v", i, v, ok)
+		}
+	}
+	if _, ok := q.Poll(); ok {
+		t.Fatal("the value is not ready")
+	}
+	if q.Len() != 1 {
+		t.Errorf("expected 1 value left, but got: %d", q.Len())
+	}
+}
+
+func TestDelayQueueTake(t *testing.T) {
+	q := NewDelayQueue[string]()
+	q.OfferAfter("late", time.Hour)
+	start := time.Now()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		// an earlier value should wake up the waiting take
+		q.OfferAfter("early", 20*time.Millisecond)
+	}()
+	v, err := q.Take(context.Background())
+	if err != nil || v != "early" {
+		t.Fatalf("expected early, but got: %s, %v", v, err)
+	}
+	if d := time.Since(start); d < 30*time.Millisecond || d > time.Second {
+		t.Errorf("unexpected take duration: %v", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Take(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, but got: %v", err)
+	}
+}
+
+func TestDelayQueueConcurrentTake(t *testing.T) {
+	q := NewDelayQueue[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := make(chan int, 100)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := q.Take(ctx)
+				if err != nil {
+					return
+				}
+				results <- v
+				if len(results) == cap(results) {
+					cancel()
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		q.OfferAfter(i, time.Duration(i%10)*time.Millisecond)
+	}
+	wg.Wait()
+	if len(results) != 100 {
+		t.Fatalf("expected 100 values taken, but got: %d", len(results))
+	}
+	seen := map[int]bool{}
+	close(results)
+	for v := range results {
+		if seen[v] {
+			t.Fatalf("value %d is taken twice", v)
+		}
+		seen[v] = true
+	}
+}
+
+func BenchmarkDelayQueueOffer(b *testing.B) {
+	q := NewDelayQueue[int]()
+	now := time.Now()
+	for i := 0; i < 200000; i++ {
+		q.Offer(i, now.Add(time.Duration(i%1000)*time.Millisecond))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Offer(i, now.Add(time.Duration(i%1000)*time.Millisecond))
+	}
+}
+
+func BenchmarkDelayQueueOfferPoll(b *testing.B) {
+	q := NewDelayQueue[int]()
+	past := time.Now().Add(-time.Hour)
+	for i := 0; i < 200000; i++ {
+		q.Offer(i, past.Add(time.Duration(i%1000)*time.Millisecond))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Offer(i, past.Add(time.Duration(i%1000)*time.Millisecond))
+		q.Poll()
+	}
+}
@@ -0,0 +1,171 @@
+// +build !windows
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// InheritFilesEnv is the environment variable describing the files inherited by a child process
+// through exec.Cmd.ExtraFiles.
+const InheritFilesEnv = "MOSN_INHERIT_FILES"
+
+// inheritFdStart is the first fd of the files in exec.Cmd.ExtraFiles in the child process
+const inheritFdStart = 3
+
+var (
+	ErrNoFileDescriptor = errors.New("the object has no file descriptor")
+	ErrTooManyFiles     = errors.New("received more files than expected")
+)
+
+// InheritFile describes an inherited file, such as a listener or a connection.
+type InheritFile struct {
+	// Name identifies the file, such as the listener name
+	Name string `json:"name"`
+	// Network is the network of the listener or connection, such as tcp and unix
+	Network string `json:"network,omitempty"`
+	// Address is the address of the listener or the local address of the connection
+	Address string `json:"address,omitempty"`
+	// Metadata is the extra information of the file
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// FileOf returns a duplicated file of the listener or connection, which implements
+// File() (*os.File, error), such as *net.TCPListener and *net.TCPConn.
+// The caller should close the returned file after it is sent.
+func FileOf(v interface{}) (*os.File, error) {
+	f, ok := v.(interface {
+		File() (*os.File, error)
+	})
+	if !ok {
+		return nil, ErrNoFileDescriptor
+	}
+	return f.File()
+}
+
+// SendFiles sends the file descriptors with the metadata over the unix socket connection by SCM_RIGHTS.
+// The metadata should not be empty, it is usually the encoded InheritFile list.
+func SendFiles(conn *net.UnixConn, meta []byte, files ...*os.File) error {
+	fds := make([]int, 0, len(files))
+	for _, f := range files {
+		fds = append(fds, int(f.Fd()))
+	}
+	var rights []byte
+	if len(fds) > 0 {
+		rights = syscall.UnixRights(fds...)
+	}
+	n, oobn, err := conn.WriteMsgUnix(meta, rights, nil)
+	if err != nil {
+		return err
+	}
+	if n != len(meta) || oobn != len(rights) {
+		return fmt.Errorf("send files short write, data: %d/%d, oob: %d/%d", n, len(meta), oobn, len(rights))
+	}
+	return nil
+}
+
+// ReceiveFiles receives at most maxFiles file descriptors and the metadata at most maxMeta bytes
+// from the unix socket connection, which are sent by SendFiles.
+func ReceiveFiles(conn *net.UnixConn, maxFiles, maxMeta int) ([]byte, []*os.File, error) {
+	meta := make([]byte, maxMeta)
+	oob := make([]byte, syscall.CmsgSpace(maxFiles*4))
+	n, oobn, flags, _, err := conn.ReadMsgUnix(meta, oob)
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []*os.File{}
+	for i := range msgs {
+		fds, err := syscall.ParseUnixRights(&msgs[i])
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, err
+		}
+		for _, fd := range fds {
+			files = append(files, os.NewFile(uintptr(fd), fmt.Sprintf("inherit-%d", fd)))
+		}
+	}
+	// the control message space is aligned, so it may hold more files than expected
+	if flags&syscall.MSG_CTRUNC != 0 || len(files) > maxFiles {
+		closeFiles(files)
+		return nil, nil, ErrTooManyFiles
+	}
+	return meta[:n], files, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// EncodeInheritFiles encodes the inherit files description
+func EncodeInheritFiles(files []InheritFile) (string, error) {
+	b, err := json.Marshal(files)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// DecodeInheritFiles decodes the inherit files description encoded by EncodeInheritFiles
+func DecodeInheritFiles(s string) ([]InheritFile, error) {
+	files := []InheritFile{}
+	if s == "" {
+		return files, nil
+	}
+	if err := json.Unmarshal([]byte(s), &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// InheritFilesEnvValue returns the environment variable item describing the files
+// which are passed to the child process by exec.Cmd.ExtraFiles in the same order.
+func InheritFilesEnvValue(files []InheritFile) (string, error) {
+	s, err := EncodeInheritFiles(files)
+	if err != nil {
+		return "", err
+	}
+	return InheritFilesEnv + "=" + s, nil
+}
+
+// InheritedFiles returns the files inherited from the parent process by exec.Cmd.ExtraFiles,
+// which are described by the InheritFilesEnv environment variable.
+// It returns empty if the process is not started with inherited files.
+func InheritedFiles() ([]InheritFile, []*os.File, error) {
+	infos, err := DecodeInheritFiles(os.Getenv(InheritFilesEnv))
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make([]*os.File, 0, len(infos))
+	for i, info := range infos {
+		files = append(files, os.NewFile(uintptr(inheritFdStart+i), info.Name))
+	}
+	return infos, files, nil
+}
@@ -0,0 +1,133 @@
+// +build !windows
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func unixConnPair(t *testing.T) (*net.UnixConn, *net.UnixConn) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socketpair")
+		c, err := net.FileConn(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+	}
+	return conns[0], conns[1]
+}
+
+func TestSendReceiveFiles(t *testing.T) {
+	parent, child := unixConnPair(t)
+	defer parent.Close()
+	defer child.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lnFile, err := FileOf(ln)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lnFile.Close()
+	if _, err := FileOf(struct{}{}); err != ErrNoFileDescriptor {
+		t.Fatalf("expected no file descriptor error, but got: %v", err)
+	}
+
+	infos := []InheritFile{{Name: "listener", Network: "tcp", Address: ln.Addr().String()}}
+	meta, err := EncodeInheritFiles(infos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SendFiles(parent, []byte(meta), lnFile); err != nil {
+		t.Fatal(err)
+	}
+
+	data, files, err := ReceiveFiles(child, 4, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles(files)
+	received, err := DecodeInheritFiles(string(data))
+	if err != nil || !reflect.DeepEqual(received, infos) {
+		t.Fatalf("unexpected received meta: %v, %v", received, err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, but got: %d", len(files))
+	}
+	inherited, err := net.FileListener(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inherited.Close()
+	if inherited.Addr().String() != ln.Addr().String() {
+		t.Errorf("inherited listener address %s, expected %s", inherited.Addr(), ln.Addr())
+	}
+}
+
+func TestReceiveTooManyFiles(t *testing.T) {
+	parent, child := unixConnPair(t)
+	defer parent.Close()
+	defer child.Close()
+	f1, _ := ioutil.TempFile("", "fd")
+	f2, _ := ioutil.TempFile("", "fd")
+	defer os.Remove(f1.Name())
+	defer os.Remove(f2.Name())
+	if err := SendFiles(parent, []byte("meta"), f1, f2); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReceiveFiles(child, 1, 16); err != ErrTooManyFiles {
+		t.Errorf("expected too many files error, but got: %v", err)
+	}
+}
+
+func TestInheritFilesEnv(t *testing.T) {
+	infos, files, err := InheritedFiles()
+	if err != nil || len(infos) != 0 || len(files) != 0 {
+		t.Fatalf("expected no inherited files, but got: %v, %v, %v", infos, files, err)
+	}
+	expected := []InheritFile{{Name: "a", Metadata: map[string]string{"k": "v"}}, {Name: "b"}}
+	env, err := InheritFilesEnvValue(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(env, InheritFilesEnv+"=") {
+		t.Fatalf("unexpected env: %s", env)
+	}
+	infos, err = DecodeInheritFiles(strings.TrimPrefix(env, InheritFilesEnv+"="))
+	if err != nil || !reflect.DeepEqual(infos, expected) {
+		t.Errorf("unexpected decoded files: %v, %v", infos, err)
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"runtime"
 )
 
 // WriteFileSafety trys to over write a file safety.
@@ -43,6 +45,78 @@ Try:
 	return
 }
 
+// WriteFileAtomic writes data to a file atomically, the file is either the old content or the new content
+// even if the process or the system crashes.
+// The data is written into a temp file in the same directory and synced, then the temp file is renamed to
+// the filename, and the directory is synced to persist the rename.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(filename)
+	f, err := ioutil.TempFile(dir, filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tempFile := f.Name()
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tempFile)
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tempFile, filename); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// SafeAppendFile appends data to a file and syncs it, the file is created if it does not exist.
+// It returns after the data is persisted, so the appended data survives the crashes.
+func SafeAppendFile(filename string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+	return SafeAppend(f, data)
+}
+
+// SafeAppend writes data to a file opened with os.O_APPEND, syncs and closes it.
+func SafeAppend(f *os.File, data []byte) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir syncs the directory to persist the entries changes, such as rename.
+func syncDir(dir string) error {
+	// windows does not support sync a directory
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 const JsonExt = ".json"
 
 var ErrIgnore = errors.New("error ignore")
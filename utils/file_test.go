@@ -22,6 +22,8 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -49,6 +51,49 @@ func TestWriteFileSafety(t *testing.T) {
 	}
 }
 
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "test_write_file_atomic")
+	if err := ioutil.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("test_data")
+	if err := WriteFileAtomic(target, data, 0644); err != nil {
+		t.Fatal("write file error: ", err)
+	}
+	b, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal("read target file failed: ", err)
+	}
+	if !bytes.Equal(data, b) {
+		t.Error("write data is not expected")
+	}
+	if stat, _ := os.Stat(target); runtime.GOOS != "windows" && stat.Mode().Perm() != 0644 {
+		t.Errorf("unexpected file mode: %v", stat.Mode())
+	}
+	// no temp file left
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("expected 1 file in dir, but got: %d", len(files))
+	}
+	// write into a not exists directory failed
+	if err := WriteFileAtomic(filepath.Join(dir, "not_exists", "file"), data, 0644); err == nil {
+		t.Error("write into a not exists directory should fail")
+	}
+}
+
+func TestSafeAppendFile(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "test_safe_append")
+	for _, s := range []string{"a", "b", "c"} {
+		if err := SafeAppendFile(target, []byte(s), 0644); err != nil {
+			t.Fatal("append file error: ", err)
+		}
+	}
+	b, err := ioutil.ReadFile(target)
+	if err != nil || string(b) != "abc" {
+		t.Errorf("unexpected file content: %s, %v", b, err)
+	}
+}
+
 func TestReadJsonFile(t *testing.T) {
 	AnyError := errors.New("any error")
 	tcs := []struct {
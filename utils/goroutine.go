@@ -18,10 +18,13 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"runtime/debug"
+	"strconv"
 )
 
 var recoverLogger func(w io.Writer, r interface{}) = defaultRecoverLogger
@@ -58,3 +61,17 @@ func GoWithRecover(handler func(), recoverHandler func(r interface{})) {
 		handler()
 	}()
 }
+
+var goroutinePrefix = []byte("goroutine ")
+
+// goroutineID returns the id of the current goroutine, parsed from the stack header.
+func goroutineID() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, goroutinePrefix)
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
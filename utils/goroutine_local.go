@@ -0,0 +1,123 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// GoroutineLocal is an opt-in goroutine local storage keyed by goroutine id.
+// It is designed for the code that can not thread a context, such as third-party callbacks,
+// prefer context.Context whenever possible.
+// The value must be cleared explicitly by Clear before the goroutine exits, otherwise it is leaked,
+// use Leaks to detect the values that are not cleared in time.
+// The value is not inherited by the goroutines started in the current goroutine.
+type GoroutineLocal struct {
+	mux    sync.RWMutex
+	values map[int64]goroutineLocalValue
+}
+
+type goroutineLocalValue struct {
+	value interface{}
+	setAt time.Time
+}
+
+// LeakedValue is a goroutine local value that is not cleared in time.
+type LeakedValue struct {
+	GoroutineID int64
+	Value       interface{}
+	Age         time.Duration
+}
+
+// NewGoroutineLocal returns an empty GoroutineLocal.
+func NewGoroutineLocal() *GoroutineLocal {
+	return &GoroutineLocal{
+		values: make(map[int64]goroutineLocalValue),
+	}
+}
+
+// Set sets the value of the current goroutine.
+func (g *GoroutineLocal) Set(v interface{}) {
+	id := goroutineID()
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.values[id] = goroutineLocalValue{value: v, setAt: time.Now()}
+}
+
+// Get returns the value of the current goroutine, and reports whether it is set.
+func (g *GoroutineLocal) Get() (interface{}, bool) {
+	id := goroutineID()
+	g.mux.RLock()
+	defer g.mux.RUnlock()
+	v, ok := g.values[id]
+	return v.value, ok
+}
+
+// Clear removes the value of the current goroutine.
+func (g *GoroutineLocal) Clear() {
+	id := goroutineID()
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	delete(g.values, id)
+}
+
+// Run sets the value of the current goroutine, calls f, and clears the value after f returns
+// even if f panics.
+func (g *GoroutineLocal) Run(v interface{}, f func()) {
+	g.Set(v)
+	defer g.Clear()
+	f()
+}
+
+// Len returns the number of goroutines that have values.
+func (g *GoroutineLocal) Len() int {
+	g.mux.RLock()
+	defer g.mux.RUnlock()
+	return len(g.values)
+}
+
+// Leaks returns the values that are set longer than maxAge, which are likely leaked
+// because the goroutine exits without Clear.
+func (g *GoroutineLocal) Leaks(maxAge time.Duration) []LeakedValue {
+	now := time.Now()
+	g.mux.RLock()
+	defer g.mux.RUnlock()
+	leaks := []LeakedValue{}
+	for id, v := range g.values {
+		if age := now.Sub(v.setAt); age > maxAge {
+			leaks = append(leaks, LeakedValue{GoroutineID: id, Value: v.value, Age: age})
+		}
+	}
+	return leaks
+}
+
+// RemoveLeaks removes the values that are set longer than maxAge, and returns the number of removed values.
+func (g *GoroutineLocal) RemoveLeaks(maxAge time.Duration) int {
+	now := time.Now()
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	removed := 0
+	for id, v := range g.values {
+		if now.Sub(v.setAt) > maxAge {
+			delete(g.values, id)
+			removed++
+		}
+	}
+	return removed
+}
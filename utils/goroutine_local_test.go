@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGoroutineLocal(t *testing.T) {
+	g := NewGoroutineLocal()
+	if _, ok := g.Get(); ok {
+		t.Fatal("value should not be set")
+	}
+	g.Set("main")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, ok := g.Get(); ok {
+				t.Error("value should not be inherited by new goroutine")
+			}
+			g.Run(i, func() {
+				if v, ok := g.Get(); !ok || v.(int) != i {
+					t.Errorf("expected value %d, but got: %v", i, v)
+				}
+			})
+			if _, ok := g.Get(); ok {
+				t.Error("value should be cleared after run")
+			}
+		}(i)
+	}
+	wg.Wait()
+	if v, ok := g.Get(); !ok || v.(string) != "main" {
+		t.Errorf("expected value main, but got: %v", v)
+	}
+	g.Clear()
+	if g.Len() != 0 {
+		t.Errorf("expected no values, but got: %d", g.Len())
+	}
+}
+
+func TestGoroutineLocalLeaks(t *testing.T) {
+	g := NewGoroutineLocal()
+	done := make(chan struct{})
+	go func() {
+		g.Set("leaked")
+		close(done)
+	}()
+	<-done
+	time.Sleep(20 * time.Millisecond)
+	g.Set("fresh")
+	leaks := g.Leaks(10 * time.Millisecond)
+	if len(leaks) != 1 || leaks[0].Value.(string) != "leaked" {
+		t.Fatalf("expected 1 leaked value, but got: %v", leaks)
+	}
+	if removed := g.RemoveLeaks(10 * time.Millisecond); removed != 1 || g.Len() != 1 {
+		t.Errorf("expected 1 removed and 1 left, but got: %d, %d", removed, g.Len())
+	}
+}
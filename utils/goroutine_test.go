@@ -88,3 +88,17 @@ func TestGoWithRecoverAgain(t *testing.T) {
 		t.Errorf("panic handler is not restart expectedly, noPanic: %v, count: %d", r.noPanic, r.count)
 	}
 }
+
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	if id <= 0 {
+		t.Fatalf("invalid goroutine id: %d", id)
+	}
+	ch := make(chan int64)
+	go func() {
+		ch <- goroutineID()
+	}()
+	if other := <-ch; other == id || other <= 0 {
+		t.Errorf("unexpected goroutine id: %d, current: %d", other, id)
+	}
+}
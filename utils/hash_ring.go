@@ -0,0 +1,193 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const defaultVirtualNodes = 160
+
+// HashRingConfig is the config of a HashRing.
+type HashRingConfig struct {
+	// VirtualNodes is the number of virtual nodes for each node on the ring, default is 160.
+	VirtualNodes int
+	// Hash hashes the keys and virtual nodes, default is XXHash64String.
+	Hash func(s string) uint64
+	// LoadFactor enables the consistent hashing with bounded loads if it is greater than 1,
+	// a node can not be acquired if its load exceeds ceil(LoadFactor * average load).
+	LoadFactor float64
+}
+
+// HashRing is a consistent hash ring with virtual nodes, it is safe for concurrent use.
+type HashRing struct {
+	config HashRingConfig
+
+	mux    sync.RWMutex
+	hashes []uint64
+	// owners maps the virtual node hash to the node
+	owners map[uint64]string
+	nodes  map[string]struct{}
+	// loads records the load of each node, used by bounded loads
+	loads     map[string]int64
+	totalLoad int64
+}
+
+// NewHashRing returns an empty HashRing.
+func NewHashRing(config HashRingConfig) *HashRing {
+	if config.VirtualNodes <= 0 {
+		config.VirtualNodes = defaultVirtualNodes
+	}
+	if config.Hash == nil {
+		config.Hash = XXHash64String
+	}
+	return &HashRing{
+		config: config,
+		owners: make(map[uint64]string),
+		nodes:  make(map[string]struct{}),
+		loads:  make(map[string]int64),
+	}
+}
+
+// Add adds the nodes into the ring, the existing nodes are ignored.
+func (r *HashRing) Add(nodes ...string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, node := range nodes {
+		if _, ok := r.nodes[node]; ok {
+			continue
+		}
+		r.nodes[node] = struct{}{}
+		for i := 0; i < r.config.VirtualNodes; i++ {
+			h := r.config.Hash(node + "#" + strconv.Itoa(i))
+			// the first node wins the hash conflict
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+			r.owners[h] = node
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+}
+
+// Remove removes the nodes from the ring.
+func (r *HashRing) Remove(nodes ...string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	removed := map[string]struct{}{}
+	for _, node := range nodes {
+		if _, ok := r.nodes[node]; ok {
+			delete(r.nodes, node)
+			r.totalLoad -= r.loads[node]
+			delete(r.loads, node)
+			removed[node] = struct{}{}
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+	hashes := r.hashes[:0]
+	for _, h := range r.hashes {
+		if _, ok := removed[r.owners[h]]; ok {
+			delete(r.owners, h)
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+	r.hashes = hashes
+}
+
+// Nodes returns the nodes in the ring, in no particular order.
+func (r *HashRing) Nodes() []string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Get returns the node of the key, it returns false if the ring is empty.
+// The loads are ignored.
+func (r *HashRing) Get(key string) (string, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	if len(r.hashes) == 0 {
+		return "", false
+	}
+	return r.owners[r.hashes[r.search(key)]], true
+}
+
+// Acquire returns the node of the key and increases its load by 1.
+// If the bounded loads is enabled, the nodes whose load is full are skipped clockwise.
+// The caller should call Release with the node after the work is done.
+func (r *HashRing) Acquire(key string) (string, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.hashes) == 0 {
+		return "", false
+	}
+	i := r.search(key)
+	node := r.owners[r.hashes[i]]
+	if r.config.LoadFactor > 1 {
+		maxLoad := int64(math.Ceil(float64(r.totalLoad+1) / float64(len(r.nodes)) * r.config.LoadFactor))
+		for n := 0; n < len(r.hashes) && r.loads[node]+1 > maxLoad; n++ {
+			i = (i + 1) % len(r.hashes)
+			node = r.owners[r.hashes[i]]
+		}
+	}
+	r.loads[node]++
+	r.totalLoad++
+	return node, true
+}
+
+// Release decreases the load of the node by 1.
+func (r *HashRing) Release(node string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.loads[node] > 0 {
+		r.loads[node]--
+		r.totalLoad--
+	}
+}
+
+// Load returns the current load of the node.
+func (r *HashRing) Load(node string) int64 {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.loads[node]
+}
+
+// search returns the index of the first virtual node clockwise from the key
+func (r *HashRing) search(key string) int {
+	h := r.config.Hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return i
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"hash/fnv"
+	"strconv"
+	"testing"
+)
+
+func TestXXHash64(t *testing.T) {
+	cases := map[string]uint64{
+		"":    0xef46db3751d8e999,
+		"a":   0xd24ec4f1a98c6e5b,
+		"abc": 0x44bc2cf5ad770999,
+		"Nobody inspects the spammish repetition": 0xfbcea83c8a378bf1,
+	}
+	for s, expected := range cases {
+		if h := XXHash64String(s); h != expected {
+			t.Errorf("xxhash64 of %q expected %x, but got: %x", s, expected, h)
+		}
+		if h := XXHash64([]byte(s)); h != expected {
+			t.Errorf("xxhash64 of %q expected %x, but got: %x", s, expected, h)
+		}
+	}
+}
+
+func TestFNV(t *testing.T) {
+	for _, s := range []string{"", "a", "mosn.io/pkg"} {
+		h64 := fnv.New64a()
+		h64.Write([]byte(s))
+		if FNV64a([]byte(s)) != h64.Sum64() || FNV64aString(s) != h64.Sum64() {
+			t.Errorf("fnv64a of %q is not expected", s)
+		}
+		h32 := fnv.New32a()
+		h32.Write([]byte(s))
+		if FNV32a([]byte(s)) != h32.Sum32() {
+			t.Errorf("fnv32a of %q is not expected", s)
+		}
+	}
+}
+
+func TestHashRing(t *testing.T) {
+	r := NewHashRing(HashRingConfig{})
+	if _, ok := r.Get("key"); ok {
+		t.Fatal("empty ring should not return a node")
+	}
+	r.Add("node1", "node2", "node3")
+	counts := map[string]int{}
+	owners := map[string]string{}
+	for i := 0; i < 3000; i++ {
+		key := "key" + strconv.Itoa(i)
+		node, _ := r.Get(key)
+		counts[node]++
+		owners[key] = node
+	}
+	for node, count := range counts {
+		if count < 700 || count > 1300 {
+			t.Errorf("node %s is not balanced: %d", node, count)
+		}
+	}
+
+	// only the keys of the removed node are moved
+	r.Remove("node2")
+	for key, owner := range owners {
+		node, _ := r.Get(key)
+		if owner != "node2" && node != owner {
+			t.Fatalf("key %s moved from %s to %s", key, owner, node)
+		}
+		if node == "node2" {
+			t.Fatalf("key %s is mapped to removed node", key)
+		}
+	}
+	if len(r.Nodes()) != 2 {
+		t.Errorf("expected 2 nodes, but got: %v", r.Nodes())
+	}
+}
+
+func TestHashRingBoundedLoad(t *testing.T) {
+	r := NewHashRing(HashRingConfig{LoadFactor: 1.25})
+	r.Add("node1", "node2", "node3", "node4")
+	// the same key is acquired, the loads should be spread
+	for i := 0; i < 100; i++ {
+		r.Acquire("hot")
+	}
+	for _, node := range r.Nodes() {
+		if load := r.Load(node); load > 32 {
+			t.Errorf("node %s load %d exceeds the bound", node, load)
+		}
+	}
+	node, _ := r.Get("hot")
+	load := r.Load(node)
+	r.Release(node)
+	if r.Load(node) != load-1 {
+		t.Errorf("release should decrease the load")
+	}
+}
+
+func BenchmarkXXHash64String(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		XXHash64String("outbound|8080||service.namespace.svc.cluster.local")
+	}
+}
+
+func BenchmarkHashRingGet(b *testing.B) {
+	r := NewHashRing(HashRingConfig{})
+	for i := 0; i < 100; i++ {
+		r.Add("node" + strconv.Itoa(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get("key")
+	}
+}
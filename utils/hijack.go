@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"os"
+	"time"
+)
+
+// stdPipe is a standard output pipe that can be hijacked
+type stdPipe int
+
+const (
+	stdoutPipe stdPipe = iota
+	stderrPipe
+)
+
+// HijackRotator rotates the file that std pipes are hijacked into.
+type HijackRotator interface {
+	// Wait returns the duration to wait before the next rotation check.
+	Wait(now time.Time) time.Duration
+	// Rotate moves the hijack file away if it should be rotated, and reports whether it is rotated.
+	// The std pipes are hijacked into a new file at the path after it is rotated.
+	Rotate(path string, now time.Time) bool
+	// Rotated is called after the std pipes are hijacked into the new file,
+	// the rotated file is no longer written and can be handled, such as compressed.
+	Rotated(path string)
+}
+
+// SetHijackStdPipeline hijacks stdout and stderr outputs into the file path,
+// the file is rotated by day, and the rotated files are kept.
+func SetHijackStdPipeline(filepath string, stdout, stderr bool) {
+	SetHijackStdPipelineWithRotator(filepath, stdout, stderr, dailyRotator{})
+}
+
+// SetHijackStdPipelineWithRotator hijacks stdout and stderr outputs into the file path,
+// and the file is rotated by the rotator.
+func SetHijackStdPipelineWithRotator(filepath string, stdout, stderr bool, rotator HijackRotator) {
+	pipes := []stdPipe{}
+	if stdout {
+		pipes = append(pipes, stdoutPipe)
+	}
+	if stderr {
+		pipes = append(pipes, stderrPipe)
+	}
+	GoWithRecover(func() {
+		ResetHjiackStdPipeline()
+		setHijackFile(pipes, filepath, rotator)
+	}, nil)
+}
+
+// setHijackFile hijacks the std pipes outputs into the new file
+// the new file will be rotated by the rotator
+func setHijackFile(pipes []stdPipe, newFilePath string, rotator HijackRotator) {
+	hijack := func() {
+		fp, err := openHijackFile(newFilePath)
+		if err != nil {
+			return
+		}
+		redirectStdPipes(fp, pipes)
+	}
+	if len(pipes) > 0 {
+		// call
+		hijack()
+		for {
+			time.Sleep(rotator.Wait(time.Now()))
+			if rotator.Rotate(newFilePath, time.Now()) {
+				hijack()
+				rotator.Rotated(newFilePath)
+			}
+		}
+	}
+
+}
+
+// dailyRotator rotates the hijack file by day, the rotated file is named with the date.
+type dailyRotator struct{}
+
+func (dailyRotator) Wait(now time.Time) time.Duration {
+	// use system localtion
+	return nextDayDuration(now, time.Local)
+}
+
+func (dailyRotator) Rotate(path string, now time.Time) bool {
+	// the rotated file is named with the day before, since we wait to the next day
+	today := now.Add(-time.Hour).Format("2006-01-02")
+	return os.Rename(path, path+"."+today) == nil
+}
+
+func (dailyRotator) Rotated(path string) {
+}
+
+// nextDayDuration returns the duration to next day
+func nextDayDuration(now time.Time, local *time.Location) time.Duration {
+	today, _ := time.ParseInLocation("2006-01-02", now.Format("2006-01-02"), local)
+	nextday := today.AddDate(0, 0, 1)
+	return nextday.Sub(now)
+}
@@ -0,0 +1,99 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNextDay(t *testing.T) {
+	t.Run("test dst in America/Los_Angeles", func(t *testing.T) {
+		loc, _ := time.LoadLocation("America/Los_Angeles")
+		firstTimeStr := "2020-11-01 00:00:00 -0700 PDT"
+		ft, _ := time.ParseInLocation("2006-01-02 15:04:05 -0700 MST", firstTimeStr, loc)
+		d := nextDayDuration(ft, loc)
+		if d != 25*time.Hour { // rollback an hour, so next day is 25 hour
+			t.Fatalf("next day duration is %s", d)
+		}
+
+		secondTimeStr := "2021-03-14 00:00:00 -0800 PST"
+		st, _ := time.ParseInLocation("2006-01-02 15:04:05 -0700 MST", secondTimeStr, loc)
+		d2 := nextDayDuration(st, loc)
+		if d2 != 23*time.Hour { // dst, next day is 23 hour
+			t.Fatalf("next day duration is %s", d)
+		}
+	})
+	t.Run("test utc time zone", func(t *testing.T) {
+		loc, _ := time.LoadLocation("UTC")
+		firstTimeStr := "2020-11-01 00:00:00 +0000 UTC"
+		ft, _ := time.ParseInLocation("2006-01-02 15:04:05 -0700 MST", firstTimeStr, loc)
+		d := nextDayDuration(ft, loc)
+		if d != 24*time.Hour {
+			t.Fatalf("next day duration is %s", d)
+		}
+
+		secondTimeStr := "2021-03-14 00:00:00 +0000 UTC"
+		st, _ := time.ParseInLocation("2006-01-02 15:04:05 -0700 MST", secondTimeStr, loc)
+		d2 := nextDayDuration(st, loc)
+		if d2 != 24*time.Hour {
+			t.Fatalf("next day duration is %s", d)
+		}
+
+	})
+	t.Run("test time.loal", func(t *testing.T) {
+		firstTimeStr := "2020-11-01 00:00:00"
+		ft, _ := time.ParseInLocation("2006-01-02 15:04:05", firstTimeStr, time.Local)
+		d := nextDayDuration(ft, time.Local)
+		if d != 24*time.Hour {
+			t.Fatalf("next day duration is %s", d)
+		}
+
+		secondTimeStr := "2021-03-14 00:00:00"
+		st, _ := time.ParseInLocation("2006-01-02 15:04:05", secondTimeStr, time.Local)
+		d2 := nextDayDuration(st, time.Local)
+		if d2 != 24*time.Hour {
+			t.Fatalf("next day duration is %s", d)
+		}
+	})
+}
+
+func TestDailyRotator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stderr.log")
+	if err := ioutil.WriteFile(path, []byte("crash"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2021, 3, 15, 0, 0, 1, 0, time.Local)
+	if !(dailyRotator{}).Rotate(path, now) {
+		t.Fatal("rotate should succeed")
+	}
+	if _, err := os.Stat(path + ".2021-03-14"); err != nil {
+		t.Errorf("rotated file should be named with the previous day: %v", err)
+	}
+	if (dailyRotator{}).Rotate(path, now) {
+		t.Error("rotate a not existed file should fail")
+	}
+}
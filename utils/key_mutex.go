@@ -0,0 +1,92 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+const defaultKeyMutexStripes = 64
+
+// KeyMutex locks by key. Keys are mapped onto a fixed number of striped locks,
+// so critical sections on different keys can run in parallel without a global lock,
+// while the memory cost does not grow with the number of keys.
+// Different keys may share one stripe, so never lock two keys at the same time
+// in one goroutine, it may dead lock.
+type KeyMutex struct {
+	locks []sync.Mutex
+}
+
+// NewKeyMutex returns a KeyMutex with n striped locks, a default value is used if n <= 0.
+func NewKeyMutex(n int) *KeyMutex {
+	if n <= 0 {
+		n = defaultKeyMutexStripes
+	}
+	return &KeyMutex{
+		locks: make([]sync.Mutex, n),
+	}
+}
+
+// Lock locks the stripe that key belongs to.
+func (m *KeyMutex) Lock(key interface{}) {
+	m.get(key).Lock()
+}
+
+// Unlock unlocks the stripe that key belongs to.
+func (m *KeyMutex) Unlock(key interface{}) {
+	m.get(key).Unlock()
+}
+
+// Do calls f while holding the lock of key.
+func (m *KeyMutex) Do(key interface{}, f func()) {
+	l := m.get(key)
+	l.Lock()
+	defer l.Unlock()
+	f()
+}
+
+func (m *KeyMutex) get(key interface{}) *sync.Mutex {
+	return &m.locks[keyHash(key)%uint64(len(m.locks))]
+}
+
+func keyHash(key interface{}) uint64 {
+	switch k := key.(type) {
+	case string:
+		return FNV64aString(k)
+	case []byte:
+		return FNV64a(k)
+	case int:
+		return uint64(k)
+	case int32:
+		return uint64(k)
+	case int64:
+		return uint64(k)
+	case uint:
+		return uint64(k)
+	case uint32:
+		return uint64(k)
+	case uint64:
+		return k
+	default:
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%v", k)
+		return h.Sum64()
+	}
+}
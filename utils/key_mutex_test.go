@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestKeyMutex(t *testing.T) {
+	m := NewKeyMutex(0)
+	if len(m.locks) != defaultKeyMutexStripes {
+		t.Fatalf("expected %d stripes, but got: %d", defaultKeyMutexStripes, len(m.locks))
+	}
+	m.Lock("host1")
+	if m.get("host1") != m.get("host1") {
+		t.Error("the same key should be mapped to the same lock")
+	}
+	m.Unlock("host1")
+}
+
+func TestKeyMutexConcurrent(t *testing.T) {
+	m := NewKeyMutex(8)
+	counts := map[string]int{}
+	keys := []string{"a", "b", "c", "d"}
+	var mapMux sync.Mutex
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		key := keys[i%len(keys)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Do(key, func() {
+				mapMux.Lock()
+				counts[key]++
+				mapMux.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+	for _, key := range keys {
+		if counts[key] != 25 {
+			t.Errorf("key %s expected count 25, but got: %d", key, counts[key])
+		}
+	}
+}
+
+func TestKeyHash(t *testing.T) {
+	if keyHash("key") != keyHash([]byte("key")) {
+		t.Error("string and bytes key should have the same hash")
+	}
+	type host struct {
+		ip   string
+		port int
+	}
+	if keyHash(host{"127.0.0.1", 80}) != keyHash(host{"127.0.0.1", 80}) {
+		t.Error("equal keys should have the same hash")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+)
+
+// ReentrantMutex is a mutex that can be locked several times by the same goroutine.
+// The owner goroutine must call Unlock as many times as Lock before other goroutines
+// can acquire it.
+// It is useful for callback-heavy code where a notification may re-enter a locked section.
+type ReentrantMutex struct {
+	mux   sync.Mutex
+	cond  *sync.Cond
+	owner int64
+	count int
+}
+
+// NewReentrantMutex returns an unlocked ReentrantMutex.
+func NewReentrantMutex() *ReentrantMutex {
+	m := &ReentrantMutex{}
+	m.cond = sync.NewCond(&m.mux)
+	return m
+}
+
+// Lock locks m. If m is already held by the current goroutine, the hold count is increased
+// and Lock returns immediately, otherwise it blocks until m is available.
+func (m *ReentrantMutex) Lock() {
+	id := goroutineID()
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.count > 0 && m.owner == id {
+		m.count++
+		return
+	}
+	for m.count != 0 {
+		m.cond.Wait()
+	}
+	m.owner = id
+	m.count = 1
+}
+
+// TryLock tries to lock m without blocking and reports whether it succeeded.
+func (m *ReentrantMutex) TryLock() bool {
+	id := goroutineID()
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.count == 0 {
+		m.owner = id
+		m.count = 1
+		return true
+	}
+	if m.owner == id {
+		m.count++
+		return true
+	}
+	return false
+}
+
+// Unlock decreases the hold count of m, and releases it when the count reaches zero.
+// It panics if m is not held by the current goroutine.
+func (m *ReentrantMutex) Unlock() {
+	id := goroutineID()
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.count == 0 || m.owner != id {
+		panic("utils: unlock of reentrant mutex not held by current goroutine")
+	}
+	m.count--
+	if m.count == 0 {
+		m.owner = 0
+		m.cond.Signal()
+	}
+}
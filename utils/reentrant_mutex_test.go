@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReentrantMutex(t *testing.T) {
+	m := NewReentrantMutex()
+	m.Lock()
+	m.Lock()
+	if !m.TryLock() {
+		t.Fatal("owner goroutine should re-enter the lock")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if m.TryLock() {
+			t.Error("other goroutine should not acquire a held lock")
+		}
+		m.Lock()
+		close(acquired)
+		m.Unlock()
+	}()
+
+	m.Unlock()
+	m.Unlock()
+	select {
+	case <-acquired:
+		t.Fatal("lock released before all unlocks")
+	case <-time.After(100 * time.Millisecond):
+	}
+	m.Unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock should be acquired after owner released it")
+	}
+}
+
+func TestReentrantMutexConcurrent(t *testing.T) {
+	m := NewReentrantMutex()
+	count := 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			defer m.Unlock()
+			m.Lock()
+			defer m.Unlock()
+			count++
+		}()
+	}
+	wg.Wait()
+	if count != 50 {
+		t.Errorf("expected count 50, but got: %d", count)
+	}
+}
+
+func TestReentrantMutexUnlockPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("unlock of an unlocked mutex should panic")
+		}
+	}()
+	NewReentrantMutex().Unlock()
+}
@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how Retry calls a function again after it failed.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of calls, including the first one.
+	// Zero or a negative value means retry until the context is done.
+	MaxAttempts int
+	// InitialBackoff is the wait duration before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the wait duration between retries, zero means no limit.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows with after each retry,
+	// a value less than 1 is treated as 1.
+	Multiplier float64
+	// Jitter randomizes the backoff in the range [backoff*(1-Jitter), backoff*(1+Jitter)],
+	// it should be in [0, 1].
+	Jitter float64
+	// Retryable reports whether an error should be retried, nil means all errors are retryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a policy that tries 3 times with exponential backoff from 100ms.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the wait duration after the attempt-th failed call, attempt starts from 1.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		backoff *= multiplier
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff = backoff * (1 - p.Jitter + 2*p.Jitter*rand.Float64())
+	}
+	return time.Duration(backoff)
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// Retry calls fn until it returns nil, the error is not retryable,
+// the max attempts is reached or the context is done.
+// It returns the last error returned by fn, or the context error if the context
+// is done before fn succeeds. A nil policy means DefaultRetryPolicy.
+func Retry(ctx context.Context, policy *RetryPolicy, fn func() error) error {
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !policy.retryable(err) {
+			return err
+		}
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return err
+		}
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
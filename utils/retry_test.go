@@ -0,0 +1,106 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		Multiplier:     2,
+	}
+	calls := 0
+	err := Retry(context.Background(), policy, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected success after 3 calls, but got: %v, %d", err, calls)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), policy, func() error {
+		calls++
+		return errors.New("failed")
+	})
+	if err == nil || calls != 5 {
+		t.Errorf("expected failed after 5 calls, but got: %v, %d", err, calls)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	permanent := errors.New("permanent")
+	policy := &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		Retryable: func(err error) bool {
+			return err != permanent
+		},
+	}
+	calls := 0
+	err := Retry(context.Background(), policy, func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Errorf("expected no retry, but got: %v, %d", err, calls)
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	policy := &RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+	}
+	err := Retry(ctx, policy, func() error {
+		return errors.New("failed")
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, but got: %v", err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, d := range expected {
+		if b := policy.Backoff(i + 1); b != d*time.Millisecond {
+			t.Errorf("attempt %d expected backoff %v, but got: %v", i+1, d*time.Millisecond, b)
+		}
+	}
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if b := policy.Backoff(1); b < 50*time.Millisecond || b > 150*time.Millisecond {
+			t.Fatalf("backoff out of jitter range: %v", b)
+		}
+	}
+}
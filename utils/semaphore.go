@@ -0,0 +1,135 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Semaphore is a weighted semaphore, waiters are served in FIFO order,
+// so a large acquire will not be starved by small ones.
+type Semaphore struct {
+	size    int64
+	cur     int64
+	mux     sync.Mutex
+	waiters list.List
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewSemaphore returns a Semaphore with the max combined weight n.
+func NewSemaphore(n int64) *Semaphore {
+	return &Semaphore{size: n}
+}
+
+// Acquire acquires the semaphore with a weight of n, blocking until resources are available
+// or the context is done. On failure it returns the context error and leaves the semaphore unchanged.
+// If n is larger than the semaphore size, it waits until the context is done.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mux.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mux.Unlock()
+		return nil
+	}
+	if n > s.size {
+		s.mux.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(semaphoreWaiter{n: n, ready: ready})
+	s.mux.Unlock()
+
+	select {
+	case <-ctx.Done():
+		err := ctx.Err()
+		s.mux.Lock()
+		select {
+		case <-ready:
+			// acquired after the context is done, takes it as success.
+			err = nil
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// the following waiters may be able to acquire now.
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.mux.Unlock()
+		return err
+	case <-ready:
+		return nil
+	}
+}
+
+// AcquireWithTimeout acquires the semaphore with a weight of n, and reports whether
+// it succeeded before the timeout.
+func (s *Semaphore) AcquireWithTimeout(n int64, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.Acquire(ctx, n) == nil
+}
+
+// TryAcquire acquires the semaphore with a weight of n without blocking,
+// and reports whether it succeeded.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release releases the semaphore with a weight of n.
+// It panics if it releases more than held.
+func (s *Semaphore) Release(n int64) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("utils: semaphore released more than held")
+	}
+	s.notifyWaiters()
+}
+
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			// keep FIFO order, do not let the smaller waiters behind go first.
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	s := NewSemaphore(3)
+	if !s.TryAcquire(2) {
+		t.Fatal("try acquire 2 should succeed")
+	}
+	if s.TryAcquire(2) {
+		t.Fatal("try acquire 2 should fail")
+	}
+	if s.AcquireWithTimeout(2, 50*time.Millisecond) {
+		t.Fatal("acquire 2 should timeout")
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.Release(2)
+	}()
+	if !s.AcquireWithTimeout(3, time.Second) {
+		t.Fatal("acquire 3 should succeed after release")
+	}
+	s.Release(3)
+}
+
+func TestSemaphoreContextCancel(t *testing.T) {
+	s := NewSemaphore(1)
+	s.TryAcquire(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if err := s.Acquire(ctx, 1); err != context.Canceled {
+		t.Fatalf("expected canceled, but got: %v", err)
+	}
+	s.Release(1)
+	if !s.TryAcquire(1) {
+		t.Fatal("canceled waiter should not hold the semaphore")
+	}
+}
+
+func TestSemaphoreConcurrent(t *testing.T) {
+	s := NewSemaphore(4)
+	var running, max int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Acquire(context.Background(), 2); err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			s.Release(2)
+		}()
+	}
+	wg.Wait()
+	if max > 2 {
+		t.Errorf("expected at most 2 concurrent holders, but got: %d", max)
+	}
+}
+
+func TestSemaphoreReleasePanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("release more than held should panic")
+		}
+	}()
+	NewSemaphore(1).Release(1)
+}
@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+const maxSpinBackoff = 16
+
+// SpinLock is a lock for ultra-short critical sections, it spins with exponential backoff
+// and yields the processor by runtime.Gosched when the backoff reaches the limit.
+// Do not use it if the critical section may block, use sync.Mutex instead.
+// The zero value is an unlocked lock.
+type SpinLock struct {
+	state uint32
+}
+
+// Lock locks the spin lock, spinning until it is available.
+func (l *SpinLock) Lock() {
+	backoff := 1
+	for !atomic.CompareAndSwapUint32(&l.state, 0, 1) {
+		// wait until the lock looks free before next CAS, which reduces cache line contention
+		for i := 0; i < backoff && atomic.LoadUint32(&l.state) == 1; i++ {
+		}
+		if backoff < maxSpinBackoff {
+			backoff <<= 1
+		} else {
+			runtime.Gosched()
+		}
+	}
+}
+
+// TryLock tries to lock the spin lock without spinning, and reports whether it succeeded.
+func (l *SpinLock) TryLock() bool {
+	return atomic.CompareAndSwapUint32(&l.state, 0, 1)
+}
+
+// Unlock unlocks the spin lock.
+func (l *SpinLock) Unlock() {
+	atomic.StoreUint32(&l.state, 0)
+}
+
+// UpdateInt64 atomically replaces the value of addr with f(old) by a CAS loop, and returns the new value.
+// f may be called several times under contention, so it should be side-effect free.
+func UpdateInt64(addr *int64, f func(old int64) int64) int64 {
+	for {
+		old := atomic.LoadInt64(addr)
+		n := f(old)
+		if atomic.CompareAndSwapInt64(addr, old, n) {
+			return n
+		}
+	}
+}
+
+// UpdateUint32 atomically replaces the value of addr with f(old) by a CAS loop, and returns the new value.
+// f may be called several times under contention, so it should be side-effect free.
+func UpdateUint32(addr *uint32, f func(old uint32) uint32) uint32 {
+	for {
+		old := atomic.LoadUint32(addr)
+		n := f(old)
+		if atomic.CompareAndSwapUint32(addr, old, n) {
+			return n
+		}
+	}
+}
+
+// StoreMaxInt64 atomically stores v into addr if v is greater than the current value,
+// and reports whether it is stored.
+func StoreMaxInt64(addr *int64, v int64) bool {
+	for {
+		old := atomic.LoadInt64(addr)
+		if v <= old {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(addr, old, v) {
+			return true
+		}
+	}
+}
+
+// StoreMinInt64 atomically stores v into addr if v is less than the current value,
+// and reports whether it is stored.
+func StoreMinInt64(addr *int64, v int64) bool {
+	for {
+		old := atomic.LoadInt64(addr)
+		if v >= old {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(addr, old, v) {
+			return true
+		}
+	}
+}
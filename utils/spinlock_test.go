@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSpinLock(t *testing.T) {
+	var l SpinLock
+	if !l.TryLock() {
+		t.Fatal("try lock an unlocked spin lock should succeed")
+	}
+	if l.TryLock() {
+		t.Fatal("try lock a locked spin lock should fail")
+	}
+	l.Unlock()
+
+	count := 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Lock()
+				count++
+				l.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if count != 10000 {
+		t.Errorf("expected count 10000, but got: %d", count)
+	}
+}
+
+func TestCASHelpers(t *testing.T) {
+	var max int64
+	var n uint32
+	min := int64(1 << 62)
+	wg := sync.WaitGroup{}
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func(v int64) {
+			defer wg.Done()
+			StoreMaxInt64(&max, v)
+			StoreMinInt64(&min, v)
+			UpdateUint32(&n, func(old uint32) uint32 {
+				return old + 2
+			})
+		}(int64(i))
+	}
+	wg.Wait()
+	if max != 100 || min != 1 || n != 200 {
+		t.Errorf("unexpected results, max: %d, min: %d, n: %d", max, min, n)
+	}
+	if v := UpdateInt64(&max, func(old int64) int64 { return old * 2 }); v != 200 {
+		t.Errorf("expected 200, but got: %d", v)
+	}
+}
+
+func BenchmarkSpinLock(b *testing.B) {
+	var l SpinLock
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Lock()
+			l.Unlock()
+		}
+	})
+}
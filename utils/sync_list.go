@@ -22,17 +22,26 @@ import (
 	"sync"
 )
 
-// SyncList similar with the builtin List container while it is concurrency safe.
-type SyncList struct {
+// TypedSyncList similar with the builtin List container while it is concurrency safe,
+// and the element values are typed.
+type TypedSyncList[T any] struct {
 	list     *list.List
 	curr     *list.Element
 	mux      sync.Mutex
 	visitMux sync.Mutex
 }
 
+// SyncList is a TypedSyncList with untyped element values.
+type SyncList = TypedSyncList[interface{}]
+
 // NewSyncList returns an initialized SyncList.
 func NewSyncList() *SyncList {
-	return &SyncList{
+	return NewTypedSyncList[interface{}]()
+}
+
+// NewTypedSyncList returns an initialized TypedSyncList.
+func NewTypedSyncList[T any]() *TypedSyncList[T] {
+	return &TypedSyncList[T]{
 		list:     list.New(),
 		curr:     nil,
 		mux:      sync.Mutex{},
@@ -41,29 +50,102 @@ func NewSyncList() *SyncList {
 }
 
 // PushBack inserts a new element e with value v at the back of list l and returns e.
-func (l *SyncList) PushBack(v interface{}) *list.Element {
+func (l *TypedSyncList[T]) PushBack(v T) *list.Element {
 	l.mux.Lock()
 	defer l.mux.Unlock()
 	return l.list.PushBack(v)
 }
 
+// PushFront inserts a new element e with value v at the front of list l and returns e.
+func (l *TypedSyncList[T]) PushFront(v T) *list.Element {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.list.PushFront(v)
+}
+
+// InsertBefore inserts a new element e with value v immediately before mark and returns e.
+// If mark is not an element of l, the list is not modified.
+// The mark must not be nil.
+func (l *TypedSyncList[T]) InsertBefore(v T, mark *list.Element) *list.Element {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.list.InsertBefore(v, mark)
+}
+
+// InsertAfter inserts a new element e with value v immediately after mark and returns e.
+// If mark is not an element of l, the list is not modified.
+// The mark must not be nil.
+func (l *TypedSyncList[T]) InsertAfter(v T, mark *list.Element) *list.Element {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.list.InsertAfter(v, mark)
+}
+
+// MoveToBack moves element e to the back of list l.
+// If e is not an element of l, the list is not modified.
+// The element must not be nil.
+func (l *TypedSyncList[T]) MoveToBack(e *list.Element) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if e == l.curr && l.list.Back() != e {
+		l.curr = l.curr.Prev()
+	}
+	l.list.MoveToBack(e)
+}
+
+// Front returns the first element of list l or nil if the list is empty.
+func (l *TypedSyncList[T]) Front() *list.Element {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.list.Front()
+}
+
+// Back returns the last element of list l or nil if the list is empty.
+func (l *TypedSyncList[T]) Back() *list.Element {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.list.Back()
+}
+
 // Remove removes e from l if e is an element of list l.
 // It returns the element value e.Value.
 // The element must not be nil.
-func (l *SyncList) Remove(e *list.Element) interface{} {
+func (l *TypedSyncList[T]) Remove(e *list.Element) T {
 	l.mux.Lock()
 	defer l.mux.Unlock()
 	if e == l.curr {
 		l.curr = l.curr.Prev()
 	}
 
-	return l.list.Remove(e)
+	return valueOf[T](l.list.Remove(e))
+}
+
+// RemoveIf removes all the elements whose value satisfies the predicate f,
+// and returns the number of removed elements.
+// The predicate f is called with lock held, so it should not block or modify list l.
+func (l *TypedSyncList[T]) RemoveIf(f func(v T) bool) int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	removed := 0
+	for e := l.list.Front(); e != nil; {
+		next := e.Next()
+		if f(valueOf[T](e.Value)) {
+			if e == l.curr {
+				l.curr = l.curr.Prev()
+			}
+			l.list.Remove(e)
+			removed++
+		}
+		e = next
+	}
+	return removed
 }
 
 // VisitSafe means the visit function f can visit each element safely even f may block some time.
 // Also, it won't block other operations(e.g. Remove) when the visit function f is blocked.
 // But, it can not run parallel since there is an instance level curr point.
-func (l *SyncList) VisitSafe(f func(v interface{})) {
+func (l *TypedSyncList[T]) VisitSafe(f func(v T)) {
 	l.visitMux.Lock()
 	defer l.visitMux.Unlock()
 
@@ -86,14 +168,34 @@ func (l *SyncList) VisitSafe(f func(v interface{})) {
 			break
 		}
 
-		f(curr.Value)
+		f(valueOf[T](curr.Value))
 	}
 }
 
+// Snapshot returns a copy of the element values of list l, in order.
+// The returned slice can be iterated without holding any lock, which is suitable
+// for read-mostly consumers that do not care about later modifications.
+func (l *TypedSyncList[T]) Snapshot() []T {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	values := make([]T, 0, l.list.Len())
+	for e := l.list.Front(); e != nil; e = e.Next() {
+		values = append(values, valueOf[T](e.Value))
+	}
+	return values
+}
+
 // Len returns the number of elements of list l.
-func (l *SyncList) Len() int {
+func (l *TypedSyncList[T]) Len() int {
 	l.mux.Lock()
 	defer l.mux.Unlock()
 
 	return l.list.Len()
 }
+
+// valueOf converts an element value to T, a nil value returns the zero value of T.
+func valueOf[T any](v interface{}) T {
+	t, _ := v.(T)
+	return t
+}
@@ -103,13 +103,13 @@ func TestSyncListVisitSafeWithRemove(t *testing.T) {
 	count := 0
 	l.VisitSafe(func(v interface{}) {
 		n := v.(int)
-		first := l.list.Front().Value.(int)
+		first := l.Front().Value.(int)
 
 		if n != first {
 			t.Errorf("visit value not match the first value, %v vs %v", n, first)
 		}
-		l.Remove(l.list.Front().Next())
-		l.Remove(l.list.Front())
+		l.Remove(l.Front().Next())
+		l.Remove(l.Front())
 
 		count++
 	})
@@ -157,3 +157,125 @@ func TestSyncListVisitSafeWithPushBack(t *testing.T) {
 		t.Errorf("sync list length expect %v while got: %v", count, len)
 	}
 }
+
+func TestSyncListSnapshot(t *testing.T) {
+	l := NewSyncList()
+	for i := 0; i < 10; i++ {
+		l.PushBack(i)
+	}
+	values := l.Snapshot()
+	l.Remove(l.Front())
+	if len(values) != 10 {
+		t.Fatalf("snapshot length expect 10 while got: %v", len(values))
+	}
+	for i, v := range values {
+		if v.(int) != i {
+			t.Errorf("snapshot value expect %v while got: %v", i, v)
+		}
+	}
+}
+
+func TestTypedSyncList(t *testing.T) {
+	l := NewTypedSyncList[string]()
+	e := l.PushBack("a")
+	l.PushBack("b")
+	l.PushBack("c")
+	if v := l.Remove(e); v != "a" {
+		t.Errorf("removed value expect a while got: %v", v)
+	}
+	visited := ""
+	l.VisitSafe(func(v string) {
+		visited += v
+	})
+	if visited != "bc" {
+		t.Errorf("visited values expect bc while got: %v", visited)
+	}
+	snapshot := l.Snapshot()
+	if len(snapshot) != 2 || snapshot[0] != "b" || snapshot[1] != "c" {
+		t.Errorf("unexpected snapshot: %v", snapshot)
+	}
+}
+
+func TestSyncListInsert(t *testing.T) {
+	l := NewTypedSyncList[int]()
+	two := l.PushBack(2)
+	l.PushFront(0)
+	l.InsertBefore(1, two)
+	l.InsertAfter(3, two)
+	values := l.Snapshot()
+	for i, v := range values {
+		if v != i {
+			t.Errorf("value at %d expect %d while got: %v", i, i, v)
+		}
+	}
+	if l.Front().Value.(int) != 0 || l.Back().Value.(int) != 3 {
+		t.Errorf("unexpected front or back: %v", values)
+	}
+}
+
+func TestSyncListRemoveIf(t *testing.T) {
+	l := NewTypedSyncList[int]()
+	for i := 0; i < 100; i++ {
+		l.PushBack(i)
+	}
+	removed := l.RemoveIf(func(v int) bool {
+		return v%2 == 0
+	})
+	if removed != 50 || l.Len() != 50 {
+		t.Errorf("expect 50 removed and 50 left while got: %v, %v", removed, l.Len())
+	}
+	l.VisitSafe(func(v int) {
+		if v%2 == 0 {
+			t.Errorf("value %v should be removed", v)
+		}
+	})
+}
+
+func TestSyncListRemoveIfWhileVisit(t *testing.T) {
+	l := NewTypedSyncList[int]()
+	for i := 0; i < 10; i++ {
+		l.PushBack(i)
+	}
+	visited := []int{}
+	l.VisitSafe(func(v int) {
+		visited = append(visited, v)
+		if v == 4 {
+			l.RemoveIf(func(n int) bool {
+				return n == 4 || n == 5
+			})
+		}
+	})
+	expected := []int{0, 1, 2, 3, 4, 6, 7, 8, 9}
+	if len(visited) != len(expected) {
+		t.Fatalf("visited expect %v while got: %v", expected, visited)
+	}
+	for i := range expected {
+		if visited[i] != expected[i] {
+			t.Fatalf("visited expect %v while got: %v", expected, visited)
+		}
+	}
+}
+
+func TestSyncListMoveToBack(t *testing.T) {
+	l := NewTypedSyncList[int]()
+	first := l.PushBack(0)
+	l.PushBack(1)
+	l.PushBack(2)
+	l.MoveToBack(first)
+	values := l.Snapshot()
+	if values[0] != 1 || values[1] != 2 || values[2] != 0 {
+		t.Errorf("unexpected values after move to back: %v", values)
+	}
+
+	// move the visiting element should not skip the following elements
+	visited := []int{}
+	l.VisitSafe(func(v int) {
+		visited = append(visited, v)
+		if len(visited) == 1 {
+			l.MoveToBack(l.Front())
+		}
+	})
+	if len(visited) != 4 || visited[1] != 2 || visited[2] != 0 || visited[3] != 1 {
+		t.Errorf("unexpected visited values: %v", visited)
+	}
+}
@@ -19,12 +19,73 @@
 
 package utils
 
-import "fmt"
+import (
+	"os"
+	"sync"
 
-// SetHijackStdPipeline hijacks stdout and stderr outputs into the file path
-func SetHijackStdPipeline(filepath string, stdout, stderr bool) {
-	fmt.Println("windows not not support SetHijackStdPipeline")
-}
+	"golang.org/x/sys/windows"
+)
+
+var (
+	// keep the standard for recover
+	standardStdout       = os.Stdout
+	standardStderr       = os.Stderr
+	standardStdoutHandle = windows.Handle(os.Stdout.Fd())
+	standardStderrHandle = windows.Handle(os.Stderr.Fd())
+
+	hijackMux sync.Mutex
+	// hijackedFile is the current file hijacking the std pipes
+	hijackedFile *os.File
+)
 
 func ResetHjiackStdPipeline() {
+	hijackMux.Lock()
+	defer hijackMux.Unlock()
+	windows.SetStdHandle(windows.STD_OUTPUT_HANDLE, standardStdoutHandle)
+	windows.SetStdHandle(windows.STD_ERROR_HANDLE, standardStderrHandle)
+	os.Stdout = standardStdout
+	os.Stderr = standardStderr
+	if hijackedFile != nil {
+		hijackedFile.Close()
+		hijackedFile = nil
+	}
+}
+
+// openHijackFile opens the file with FILE_SHARE_DELETE, so it can be renamed when rotating
+// while it is still used as std pipes.
+func openHijackFile(path string) (*os.File, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := windows.CreateFile(p,
+		windows.GENERIC_READ|windows.FILE_APPEND_DATA,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_ALWAYS, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(h), path), nil
+}
+
+// redirectStdPipes sets the file as the process std handles, which are used by the runtime
+// to write crash outputs, and replaces the os std files.
+func redirectStdPipes(fp *os.File, pipes []stdPipe) {
+	hijackMux.Lock()
+	defer hijackMux.Unlock()
+	for _, p := range pipes {
+		switch p {
+		case stdoutPipe:
+			windows.SetStdHandle(windows.STD_OUTPUT_HANDLE, windows.Handle(fp.Fd()))
+			os.Stdout = fp
+		case stderrPipe:
+			windows.SetStdHandle(windows.STD_ERROR_HANDLE, windows.Handle(fp.Fd()))
+			os.Stderr = fp
+		}
+	}
+	// the previous file is no longer used after rotated
+	if hijackedFile != nil {
+		hijackedFile.Close()
+	}
+	hijackedFile = fp
 }
@@ -0,0 +1,64 @@
+// +build windows
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetHijackStdPipeline(t *testing.T) {
+	// init
+	stderrFile := filepath.Join(os.TempDir(), "test_stderr")
+	os.Remove(stderrFile)
+	// call, test std error only
+	SetHijackStdPipeline(stderrFile, false, true)
+	time.Sleep(time.Second) // wait goroutine run
+	fmt.Fprintf(os.Stderr, "test stderr")
+	// verify
+	b, err := ioutil.ReadFile(stderrFile)
+	if err != nil || string(b) != "test stderr" {
+		t.Errorf("stderr hijack failed: %s, %v", b, err)
+	}
+	ResetHjiackStdPipeline()
+	if os.Stderr != standardStderr {
+		t.Error("stderr should be reset")
+	}
+	fmt.Fprintf(os.Stderr, "repaired\n")
+}
+
+func TestHijackFileRename(t *testing.T) {
+	path := filepath.Join(os.TempDir(), "test_hijack_rename")
+	os.Remove(path)
+	os.Remove(path + ".old")
+	fp, err := openHijackFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+	// the hijack file should be renamed while it is still opened, which is required by rotation
+	if err := os.Rename(path, path+".old"); err != nil {
+		t.Fatalf("rename an opened hijack file failed: %v", err)
+	}
+}